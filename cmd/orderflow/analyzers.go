@@ -0,0 +1,8 @@
+package main
+
+// Compiled-in custom analyzers (see internal/analyzer). Each import registers
+// its modules; enable them at runtime with -analyzers. Add yours here.
+import (
+	_ "market-indikator/internal/analyzer/bigprints"
+	_ "market-indikator/internal/analyzer/ml"
+)
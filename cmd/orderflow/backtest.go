@@ -0,0 +1,193 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"market-indikator/internal/engine"
+	"market-indikator/internal/export"
+	"market-indikator/internal/model"
+	oi "market-indikator/internal/oi"
+	"market-indikator/internal/orderbook"
+	"market-indikator/internal/signals"
+)
+
+// orderflow backtest — the score signals (internal/signals) over recorded
+// tape: the trade log (orderflow run -trade-log) replayed through the
+// engine with the live engine's scoring and signal flags, and a summary of
+// the signals' outcomes.
+//
+//   orderflow backtest -from 2024-05-01 -to 2024-05-07
+//   orderflow backtest -signal-trigger 45 -signal-stop-bps 20 -out signals.csv
+//
+// Between trades the engine gets the live heartbeat (Tick 250 ms after each
+// exchange second), on the trades' own clock. -out writes every signal state
+// change in the signal log's columns (logs/signals).
+//
+// The trade log has no order book or open interest, so the passive and
+// positioning domains stay flat and the score only carries aggressive flow
+// (at its weight: -score-weights aggressive=1,passive=0,positioning=0 puts
+// it back on the ±100 scale). Compare settings with each other, not with
+// the live signal log.
+
+func cmdBacktest(args []string) {
+	fs := flag.NewFlagSet("backtest", flag.ExitOnError)
+	logDir := fs.String("logs", "logs", "log directory")
+	var rf rangeFlags
+	rf.register(fs)
+	var ec engineFlags
+	ec.register(fs)
+	cfg := signals.DefaultConfig()
+	signalFlags(fs, &cfg)
+	format := fs.String("format", "csv", "-out format: csv or parquet")
+	out := fs.String("out", "", "write every signal state change to this file")
+	fs.Parse(args)
+
+	from, to, err := rf.parse()
+	if err != nil {
+		log.Fatalf("Invalid %v", err)
+	}
+	if err := ec.setGlobals(); err != nil {
+		log.Fatalf("Invalid %v", err)
+	}
+	var now int64 // replay clock: the event being fed, unix ms
+	eng := engine.NewEngine(orderbook.NewBook(), oi.NewEngine())
+	eng.SetClock(func() int64 { return now })
+	if err := ec.configure(eng, *logDir, from); err != nil {
+		log.Fatalf("Invalid %v", err)
+	}
+	gen := signals.NewGenerator(cfg)
+
+	var w *export.Table
+	if *out != "" {
+		if w, err = export.CreateTable(*out, *format, signalColumns); err != nil {
+			log.Fatal(err)
+		}
+	}
+	var (
+		stats    backtestStats
+		lastSig  model.Signal
+		trades   int
+		nextBeat int64 // next heartbeat, 0 = before the first trade
+	)
+	observe := func(snap *model.Snapshot) error {
+		sigs, n := gen.Observe(snap)
+		for _, sig := range sigs[:n] {
+			stats.add(&sig)
+			lastSig = sig
+			if w != nil {
+				at, _ := sig.Last()
+				if err := w.Write(at, sig.ID, sig.SideName(), sig.StateName(), sig.Reason,
+					sig.ArmedAt, sig.TriggeredAt, sig.ClosedAt,
+					sig.ArmPrice, sig.EntryPrice, sig.ExitPrice, sig.Score, sig.ReturnBps); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	err = export.ScanTicks(*logDir, from, to, func(t *export.Tick) error {
+		for nextBeat != 0 && nextBeat <= t.Time {
+			now = nextBeat
+			if snap, ok := eng.Tick(nextBeat); ok {
+				if err := observe(&snap); err != nil {
+					return err
+				}
+			}
+			nextBeat += 1000
+		}
+		now = t.Time
+		snap := eng.ProcessTrade(model.Trade{
+			ID: t.AggID, Price: t.Price, Quantity: t.Qty, Time: t.Time,
+			IsBuyer: t.Buy == (model.Side == model.BuyerIsTaker),
+		})
+		trades++
+		nextBeat = (t.Time/1000+1)*1000 + heartbeatLag.Milliseconds()
+		return observe(&snap)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if w != nil {
+		if err := w.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if trades == 0 {
+		log.Fatalf("No trades in %s for the range", *logDir)
+	}
+	fmt.Printf("%d trades replayed\n", trades)
+	stats.print()
+	if lastSig.Open() {
+		fmt.Printf("1 signal still %s at the end of the range (not counted)\n", lastSig.StateName())
+	}
+}
+
+// backtestStats — signal outcomes.
+type backtestStats struct {
+	armed, triggered, completed, stopped, dropped int
+	wins                                          int
+	totalBps, best, worst                         float64
+}
+
+func (s *backtestStats) add(sig *model.Signal) {
+	switch sig.State {
+	case model.SignalArmed:
+		s.armed++
+		return
+	case model.SignalTriggered:
+		s.triggered++
+		return
+	case model.SignalCompleted:
+		s.completed++
+	case model.SignalInvalidated:
+		if sig.TriggeredAt == 0 {
+			s.dropped++ // expired or reversed before the entry
+			return
+		}
+		s.stopped++
+	}
+	first := s.completed+s.stopped == 1
+	if first || sig.ReturnBps > s.best {
+		s.best = sig.ReturnBps
+	}
+	if first || sig.ReturnBps < s.worst {
+		s.worst = sig.ReturnBps
+	}
+	if sig.ReturnBps > 0 {
+		s.wins++
+	}
+	s.totalBps += sig.ReturnBps
+}
+
+func (s *backtestStats) print() {
+	fmt.Printf("signals: %d armed, %d triggered, %d expired or reversed before the entry\n",
+		s.armed, s.triggered, s.dropped)
+	closed := s.completed + s.stopped
+	if closed == 0 {
+		fmt.Println("no closed trades")
+		return
+	}
+	fmt.Printf("trades:  %d closed (%d completed, %d stopped), win rate %.1f%%\n",
+		closed, s.completed, s.stopped, 100*float64(s.wins)/float64(closed))
+	fmt.Printf("returns: total %.1f bps, mean %.2f bps, best %.1f, worst %.1f\n",
+		s.totalBps, s.totalBps/float64(closed), s.best, s.worst)
+}
+
+// signalColumns — the signal log's columns (logger/signals.go).
+var signalColumns = []export.Column{
+	{Name: "time", Kind: export.TimestampMillis},
+	{Name: "id", Kind: export.Int64},
+	{Name: "side", Kind: export.String},
+	{Name: "state", Kind: export.String},
+	{Name: "reason", Kind: export.String},
+	{Name: "armed_at", Kind: export.Int64},
+	{Name: "triggered_at", Kind: export.Int64},
+	{Name: "closed_at", Kind: export.Int64},
+	{Name: "arm_price", Kind: export.Double},
+	{Name: "entry_price", Kind: export.Double},
+	{Name: "exit_price", Kind: export.Double},
+	{Name: "score", Kind: export.Double},
+	{Name: "return_bps", Kind: export.Double},
+}
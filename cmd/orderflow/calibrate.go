@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"market-indikator/internal/evaluation"
+	"market-indikator/internal/export"
+)
+
+// orderflow calibrate — how well the logged final score predicted price:
+// the /admin/eval statistics (internal/evaluation) over a range of the
+// snapshot log, every logged second weighted equally.
+//
+//   orderflow calibrate -from 2024-05-01 -to 2024-05-07
+//   orderflow calibrate -horizons 10s,1m,5m,15m -json
+//
+// Per horizon: the score / forward-return correlation, the hit rate of
+// |score| ≥ 10, and per score band the mean return and hit rate — the
+// numbers the band edges in pressure/score.go and the -signal-* levels are
+// tuned on. Forward returns are the label tool's (export.ForwardReturns).
+
+func cmdCalibrate(args []string) {
+	fs := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	logDir := fs.String("logs", "logs", "log directory")
+	var rf rangeFlags
+	rf.register(fs)
+	horizonSpec := fs.String("horizons", "10s,1m,5m", "comma-separated forward-return horizons")
+	maxGap := fs.Duration("max-gap", 5*time.Second, "skip a sample if the log has a gap this long at t+h")
+	asJSON := fs.Bool("json", false, "print the report as JSON (the /admin/eval format)")
+	fs.Parse(args)
+
+	from, to, err := rf.parse()
+	if err != nil {
+		log.Fatalf("Invalid %v", err)
+	}
+	horizons, labels, err := parseHorizons(*horizonSpec)
+	if err != nil {
+		log.Fatalf("Invalid %v", err)
+	}
+	var longest int64
+	for _, h := range horizons {
+		longest = max(longest, h)
+	}
+
+	t, err := export.ReadSnapshots(*logDir, from, to+longest)
+	if err != nil {
+		log.Fatal(err)
+	}
+	col := -1
+	for i, name := range t.Header {
+		if name == "final_score" {
+			col = i
+		}
+	}
+	if col < 0 || len(t.Rows) == 0 {
+		log.Fatalf("No snapshot rows in %s for the range", *logDir)
+	}
+	n := 0 // rows inside the range; the rest is lookahead
+	for n < len(t.Times) && t.Times[n] < to {
+		n++
+	}
+	scores := make([]float64, n)
+	for i := range scores {
+		if scores[i], err = strconv.ParseFloat(t.Rows[i][col], 64); err != nil {
+			scores[i] = math.NaN()
+		}
+	}
+	fwd := export.ForwardReturns(t.Times, t.Prices, horizons, maxGap.Milliseconds())
+
+	var rep evaluation.Report
+	for k, h := range horizons {
+		rep.Horizons = append(rep.Horizons, evaluation.Calibrate(h/1000, scores, fwd[k][:n]))
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	for k, hr := range rep.Horizons {
+		fmt.Printf("%s: %d samples, correlation %+.3f, hit rate %.1f%% (|score| ≥ 10)\n",
+			labels[k], hr.Samples, hr.Correlation, 100*hr.HitRate)
+		fmt.Printf("  %-12s %9s %10s %9s\n", "band", "samples", "mean bps", "hit rate")
+		for _, b := range hr.Buckets {
+			fmt.Printf("  [%4.0f,%4.0f) %9.0f %+10.2f %8.1f%%\n", b.Lo, b.Hi, b.Weight, b.MeanReturnBps, 100*b.HitRate)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"market-indikator/internal/export"
+	"market-indikator/internal/features"
+	"market-indikator/internal/model"
+)
+
+// orderflow export — convert the logs into standard formats for research tools.
+//
+//   orderflow export -kind candles -tf 1m,1h -from 2024-05-01 -to 2024-05-07 -out btc.csv
+//   orderflow export -kind ticks -from 2024-05-01 -format parquet -out ticks.parquet
+//   orderflow export -kind features -from 2024-05-01 -format parquet -out features.parquet
+//   orderflow export -logs /srv/logs -symbol BTCUSDT,ETHUSDT -kind candles -tf 5m -format parquet -out bars.parquet
+//
+// -from / -to are UTC dates (YYYY-MM-DD, -to inclusive), RFC 3339 times or
+// unix ms (-to exclusive). Several -tf write one file each (btc_1m.csv,
+// btc_1h.csv); one CSV without -out goes to stdout.
+//
+// Logs carry no symbol: a deployment logs one symbol. With several symbols
+// under one root, each is read from <logs>/<SYMBOL>; a single -symbol whose
+// directory doesn't exist reads <logs> itself and only labels the rows.
+
+func cmdExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	logDir := fs.String("logs", "logs", "log directory")
+	symbols := fs.String("symbol", model.Symbol, "comma-separated symbols (see above)")
+	kind := fs.String("kind", "candles", "what to export: candles, ticks or features")
+	tfSpec := fs.String("tf", "1m", "candle timeframes, e.g. 1m,5m,1h,1d")
+	var rf rangeFlags
+	rf.register(fs)
+	format := fs.String("format", "csv", "output format: csv or parquet")
+	out := fs.String("out", "", "output file (default stdout; required for parquet)")
+	fs.Parse(args)
+
+	from, to, err := rf.parse()
+	if err != nil {
+		log.Fatalf("Invalid %v", err)
+	}
+	if *format != "csv" && *format != "parquet" {
+		log.Fatalf("Invalid -format %q: want csv or parquet", *format)
+	}
+
+	var syms []string
+	for _, s := range strings.Split(*symbols, ",") {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+			syms = append(syms, s)
+		}
+	}
+	if len(syms) == 0 {
+		log.Fatal("No -symbol")
+	}
+	dirs := make([]string, len(syms))
+	for i, sym := range syms {
+		dir := filepath.Join(*logDir, sym)
+		if st, err := os.Stat(dir); err == nil && st.IsDir() {
+			dirs[i] = dir
+		} else if len(syms) == 1 {
+			dirs[i] = *logDir
+		} else {
+			log.Fatalf("No log directory %s (several symbols need <logs>/<SYMBOL>)", dir)
+		}
+	}
+
+	switch *kind {
+	case "ticks":
+		w, err := export.CreateTable(*out, *format, tickColumns)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rows := 0
+		for i, sym := range syms {
+			err := export.ScanTicks(dirs[i], from, to, func(t *export.Tick) error {
+				rows++
+				return w.Write(sym, t.Time, t.Price, t.Qty, t.Side(), t.AggID)
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("%d ticks exported", rows)
+
+	case "candles":
+		tfs, err := model.ParseTimeframes(*tfSpec)
+		if err != nil {
+			log.Fatalf("Invalid -tf: %v", err)
+		}
+		if len(tfs) > 1 && *out == "" {
+			log.Fatal("Several -tf need -out (one file per timeframe)")
+		}
+		tables := make([]*export.Table, len(tfs))
+		for i, tf := range tfs {
+			path := *out
+			if len(tfs) > 1 {
+				ext := filepath.Ext(path)
+				path = strings.TrimSuffix(path, ext) + "_" + tf.Label + ext
+			}
+			if tables[i], err = export.CreateTable(path, *format, candleColumns); err != nil {
+				log.Fatal(err)
+			}
+		}
+		rows := make([]int, len(tfs))
+		for i, sym := range syms {
+			err := export.ScanCandles(dirs[i], from, to, tfs, func(tf int, c *export.Candle) error {
+				rows[tf]++
+				return tables[tf].Write(sym, c.Time, c.Open, c.High, c.Low, c.Close,
+					c.Volume, c.BuyVol, c.SellVol, c.Trades)
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		for i, t := range tables {
+			if err := t.Close(); err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("%s: %d candles exported", tfs[i].Label, rows[i])
+		}
+
+	case "features":
+		cols := append([]export.Column{}, featureColumns...)
+		for _, name := range features.Names {
+			cols = append(cols, export.Column{Name: name, Kind: export.Double})
+		}
+		w, err := export.CreateTable(*out, *format, cols)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rows := 0
+		row := make([]any, len(cols))
+		for i, sym := range syms {
+			err := export.ScanFeatures(dirs[i], from, to, func(ms int64, v []float32) error {
+				rows++
+				row[0], row[1], row[2] = sym, ms, int64(features.Version)
+				for j, x := range v {
+					row[len(featureColumns)+j] = x
+				}
+				return w.Write(row...)
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("%d feature vectors (version %d) exported", rows, features.Version)
+
+	default:
+		log.Fatalf("Invalid -kind %q: want candles, ticks or features", *kind)
+	}
+}
+
+var (
+	tickColumns = []export.Column{
+		{Name: "symbol", Kind: export.String},
+		{Name: "time", Kind: export.TimestampMillis},
+		{Name: "price", Kind: export.Double},
+		{Name: "qty", Kind: export.Double},
+		{Name: "side", Kind: export.String},
+		{Name: "agg_id", Kind: export.Int64},
+	}
+	candleColumns = []export.Column{
+		{Name: "symbol", Kind: export.String},
+		{Name: "time", Kind: export.TimestampMillis},
+		{Name: "open", Kind: export.Double},
+		{Name: "high", Kind: export.Double},
+		{Name: "low", Kind: export.Double},
+		{Name: "close", Kind: export.Double},
+		{Name: "volume", Kind: export.Double},
+		{Name: "buy_vol", Kind: export.Double},
+		{Name: "sell_vol", Kind: export.Double},
+		{Name: "trades", Kind: export.Int64},
+	}
+	// followed by one Double per features.Names entry
+	featureColumns = []export.Column{
+		{Name: "symbol", Kind: export.String},
+		{Name: "time", Kind: export.TimestampMillis},
+		{Name: "feature_version", Kind: export.Int64},
+	}
+)
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"market-indikator/internal/gateway"
+)
+
+// orderflow gateway — one WebSocket/REST endpoint in front of one engine
+// process per symbol (internal/gateway):
+//
+//   orderflow gateway -spawn BTCUSDT,ETHUSDT,SOLUSDT -- -log-keep-days 30
+//   orderflow gateway -shard BTCUSDT=http://10.0.0.2:8080 -shard ETHUSDT=http://10.0.0.3:8080
+//
+// -spawn starts `orderflow run -symbol S -addr 127.0.0.1:<port>` for each
+// symbol, in its own directory (<spawn-dir>/<S>, so logs and state don't
+// mix) with its output in orderflow.log there, on consecutive ports from
+// -spawn-port; flags after -- go to every engine. An engine that exits is
+// restarted (backoff up to a minute); on SIGINT/SIGTERM each gets SIGTERM
+// and time for its final checkpoint. -shard adds engines run elsewhere.
+
+const (
+	spawnStopWait   = 15 * time.Second // an engine's shutdown, final checkpoint included
+	spawnMaxBackoff = time.Minute
+	spawnStable     = time.Minute // an engine up this long restarts after the shortest backoff
+)
+
+func cmdGateway(args []string) {
+	fs := flag.NewFlagSet("gateway", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "listen address")
+	spawn := fs.String("spawn", "", "comma-separated symbols to run as engine processes")
+	spawnPort := fs.Int("spawn-port", 8081, "port of the first spawned engine (127.0.0.1), the next symbol's is +1")
+	spawnDir := fs.String("spawn-dir", "shards", "parent of the spawned engines' working directories")
+	g := gateway.New()
+	fs.Func("shard", "an engine run elsewhere, SYMBOL=http://host:port, repeatable", func(spec string) error {
+		sym, u, ok := strings.Cut(spec, "=")
+		if !ok {
+			return fmt.Errorf("want SYMBOL=http://host:port")
+		}
+		return g.Add(sym, u)
+	})
+	fs.Parse(args)
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var engines sync.WaitGroup
+	if *spawn != "" {
+		exe, err := os.Executable()
+		if err != nil {
+			log.Fatalf("Gateway: %v", err)
+		}
+		port := *spawnPort
+		for _, sym := range strings.Split(*spawn, ",") {
+			if sym = strings.ToUpper(strings.TrimSpace(sym)); sym == "" {
+				continue
+			}
+			listen := fmt.Sprintf("127.0.0.1:%d", port)
+			if err := g.Add(sym, "http://"+listen); err != nil {
+				log.Fatalf("Invalid -spawn: %v", err)
+			}
+			dir := filepath.Join(*spawnDir, sym)
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				log.Fatalf("Gateway: %v", err)
+			}
+			runArgs := append([]string{"run", "-symbol", sym, "-addr", listen}, fs.Args()...)
+			engines.Add(1)
+			go func() {
+				defer engines.Done()
+				superviseEngine(ctx, sym, exe, dir, runArgs)
+			}()
+			port++
+		}
+	} else if fs.NArg() > 0 {
+		log.Fatalf("Engine flags after -- need -spawn")
+	}
+	if len(g.Shards()) == 0 {
+		log.Fatalf("No shards: use -spawn and/or -shard")
+	}
+	for _, sh := range g.Shards() {
+		log.Printf("Gateway: %s → %s", sh.Symbol, sh.URL)
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: g}
+	go func() {
+		log.Printf("Gateway listening on %s (%d shards, default %s)", *addr, len(g.Shards()), g.Shards()[0].Symbol)
+		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
+	<-ctx.Done()
+	log.Println("Gateway shutting down...")
+	srv.Close()
+	engines.Wait()
+}
+
+// superviseEngine runs one engine process until ctx is done, restarting it
+// when it exits.
+func superviseEngine(ctx context.Context, sym, exe, dir string, args []string) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		out, err := os.OpenFile(filepath.Join(dir, "orderflow.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Printf("Gateway: %s engine: %v", sym, err)
+			return
+		}
+		cmd := exec.CommandContext(ctx, exe, args...)
+		cmd.Dir, cmd.Stdout, cmd.Stderr = dir, out, out
+		cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+		cmd.WaitDelay = spawnStopWait
+		started := time.Now()
+		if err = cmd.Start(); err == nil {
+			log.Printf("Gateway: %s engine started (pid %d, log %s)", sym, cmd.Process.Pid, filepath.Join(dir, "orderflow.log"))
+			err = cmd.Wait()
+		}
+		out.Close()
+		if ctx.Err() != nil {
+			log.Printf("Gateway: %s engine stopped", sym)
+			return
+		}
+		if time.Since(started) >= spawnStable {
+			backoff = time.Second
+		}
+		log.Printf("Gateway: %s engine exited (%v), restarting in %v", sym, err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, spawnMaxBackoff)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"market-indikator/internal/export"
+)
+
+// orderflow label — the snapshot log with realized forward returns, as
+// training and calibration targets.
+//
+//   orderflow label -from 2024-05-01 -to 2024-05-07 -out labeled.csv
+//   orderflow label -horizons 30s,5m -max-gap 10s -format parquet -out labeled.parquet
+//
+// Every snapshot row keeps its logged columns and gains fwd_<h> per horizon,
+// the return over h in basis points (see export/label.go); rows whose label
+// can't be known are kept with an empty cell unless -complete. The log is
+// read past -to by the longest horizon, so the last rows of the range are
+// labeled too.
+
+func cmdLabel(args []string) {
+	fs := flag.NewFlagSet("label", flag.ExitOnError)
+	logDir := fs.String("logs", "logs", "log directory")
+	horizonSpec := fs.String("horizons", "10s,1m,5m,15m", "comma-separated forward-return horizons")
+	maxGap := fs.Duration("max-gap", 5*time.Second, "missing label if the log has a gap this long at t+h")
+	complete := fs.Bool("complete", false, "drop rows with any missing label")
+	var rf rangeFlags
+	rf.register(fs)
+	format := fs.String("format", "csv", "output format: csv or parquet")
+	out := fs.String("out", "", "output file (default stdout; required for parquet)")
+	fs.Parse(args)
+
+	from, to, err := rf.parse()
+	if err != nil {
+		log.Fatalf("Invalid %v", err)
+	}
+
+	horizons, labels, err := parseHorizons(*horizonSpec)
+	if err != nil {
+		log.Fatalf("Invalid %v", err)
+	}
+	var longest int64
+	for i, h := range horizons {
+		labels[i] = "fwd_" + labels[i]
+		longest = max(longest, h)
+	}
+
+	t, err := export.ReadSnapshots(*logDir, from, to+longest)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(t.Rows) == 0 {
+		log.Fatalf("No snapshot rows in %s for the range", *logDir)
+	}
+	fwd := export.ForwardReturns(t.Times, t.Prices, horizons, maxGap.Milliseconds())
+
+	cols := make([]export.Column, 0, len(t.Header)+len(labels))
+	for _, name := range t.Header {
+		cols = append(cols, export.Column{Name: name, Kind: columnKind(name)})
+	}
+	for _, name := range labels {
+		cols = append(cols, export.Column{Name: name, Kind: export.Double})
+	}
+	w, err := export.CreateTable(*out, *format, cols)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	written, missing := 0, 0
+	row := make([]any, len(cols))
+	for i, cells := range t.Rows {
+		if t.Times[i] >= to {
+			break // lookahead only
+		}
+		known := true
+		for k := range horizons {
+			if math.IsNaN(fwd[k][i]) {
+				known = false
+			}
+			row[len(t.Header)+k] = math.Round(fwd[k][i]*100) / 100
+		}
+		if !known {
+			missing++
+			if *complete {
+				continue
+			}
+		}
+		for c, v := range cells {
+			row[c] = cellValue(cols[c].Kind, v)
+		}
+		if err := w.Write(row...); err != nil {
+			log.Fatal(err)
+		}
+		written++
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if *complete {
+		log.Printf("%d rows labeled, %d with a missing label dropped", written, missing)
+	} else {
+		log.Printf("%d rows labeled, %d with a missing label", written, missing)
+	}
+}
+
+// columnKind — Parquet types for the snapshot log columns (logger/csv.go).
+func columnKind(name string) int {
+	switch name {
+	case "timestamp":
+		return export.TimestampMillis
+	case "htf_bias", "market_state", "action_hint":
+		return export.String
+	case "ob_score", "behavior", "event_flags", "buy_count", "sell_count":
+		return export.Int64
+	}
+	return export.Double
+}
+
+// cellValue converts a logged cell; an empty cell (a column an older schema
+// lacks) is NaN / 0.
+func cellValue(kind int, s string) any {
+	switch kind {
+	case export.String:
+		return s
+	case export.Int64, export.TimestampMillis:
+		n, _ := strconv.ParseInt(s, 10, 64)
+		return n
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return f
+}
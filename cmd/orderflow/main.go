@@ -1,107 +1,85 @@
 package main
 
 import (
-	"context"
-	"log"
+	"encoding/json"
+	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 
-	"market-indikator/internal/broadcast"
-	"market-indikator/internal/bus"
-	"market-indikator/internal/engine"
-	"market-indikator/internal/ingest"
-	csvlogger "market-indikator/internal/logger"
-	"market-indikator/internal/model"
-	oi "market-indikator/internal/oi"
-	"market-indikator/internal/orderbook"
-	"market-indikator/internal/state"
+	"market-indikator/internal/buildinfo"
 )
 
-const (
-	bufferSize = 3600 // 1 hour of 1s snapshots
-	logDir     = "logs"
-)
+// orderflow — one binary, one mode per subcommand:
+//
+//   orderflow run [flags]        the live engine (run.go); also the default,
+//                                so "orderflow -symbol ETHUSDT" still works
+//   orderflow replay [flags]     record / replay pipeline fixtures (replay.go)
+//   orderflow backtest [flags]   score signals over the trade log (backtest.go)
+//   orderflow calibrate [flags]  score vs forward returns from the logs (calibrate.go)
+//   orderflow export [flags]     candles, ticks, features as CSV / Parquet (export.go)
+//   orderflow label [flags]      snapshot log with forward-return labels (label.go)
+//   orderflow gateway [flags]    one endpoint for per-symbol engines (gateway.go)
+//   orderflow version [-json]    build and schema versions (internal/buildinfo)
+//
+// The modes share the internal packages and, where they overlap, their
+// flags (shared.go): run and backtest take the same engine and signal
+// settings, the log readers the same -logs / -from / -to.
+
+var commands = []struct {
+	name, summary string
+	run           func(args []string)
+}{
+	{"run", "run the live engine (default)", cmdRun},
+	{"replay", "record synthetic fixtures, replay them against golden frames", cmdReplay},
+	{"backtest", "replay the trade log through the engine and score its signals", cmdBacktest},
+	{"calibrate", "score vs forward-return statistics from the snapshot log", cmdCalibrate},
+	{"export", "export candles, ticks or feature vectors as CSV or Parquet", cmdExport},
+	{"label", "label the snapshot log with forward returns", cmdLabel},
+	{"gateway", "serve per-symbol engine processes behind one endpoint", cmdGateway},
+	{"version", "print the build and schema versions", cmdVersion},
+}
 
 func main() {
-	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
-	log.Println("Starting Market Indikator v6 (Stateful Snapshot Engine)...")
-
-	ctx, cancel := context.WithCancel(context.Background())
-
-	// 1. Trade Bus
-	eventBus := bus.NewBus()
-
-	// 2. Orderbook
-	book := orderbook.NewBook()
-
-	// 3. OI Engine
-	oiEngine := oi.NewEngine()
-
-	// 4. Trade Engine (merges all analytics)
-	eng := engine.NewEngine(book, oiEngine)
-
-	// 5. Snapshot Logger (async, zero hot-path impact)
-	snapLogger := csvlogger.NewLogger()
-
-	// 6. Snapshot Ring Buffer (in-memory state for new clients)
-	snapBuffer := state.NewRingBuffer(bufferSize)
-
-	// 7. Load history from CSV on startup (restart recovery)
-	csvSnapshots := state.LoadFromCSV(logDir, bufferSize)
-	for _, snap := range csvSnapshots {
-		snapBuffer.Add(snap)
+	registerSchemas()
+	args := os.Args[1:]
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") && !isHelp(args[0]) {
+		cmdRun(args)
+		return
 	}
-	log.Printf("Ring buffer pre-loaded with %d snapshots from CSV", snapBuffer.Size())
-
-	// 8. Start Binance AggTrade Ingest
-	ingester := ingest.NewIngester(eventBus)
-	ingester.Start(ctx)
-
-	// 9. Start Binance Depth Ingest
-	depthIngester := ingest.NewDepthIngester(book)
-	depthIngester.Start(ctx)
-
-	// 10. Start OI Poller (reads latest price from engine via closure)
-	oiPoller := ingest.NewOIPoller(oiEngine, eng.GetPrice)
-	oiPoller.Start(ctx)
-
-	// 11. Engine goroutine — single owner, no locks
-	tradeCh := eventBus.Subscribe(1024)
-	snapshotCh := make(chan model.Snapshot, 1024)
-
-	go func() {
-		var lastLogTime int64
-		for trade := range tradeCh {
-			snap := eng.ProcessTrade(trade)
-
-			// Push to ring buffer (thread-safe)
-			snapBuffer.Add(snap)
-
-			// Broadcast to WebSocket clients (non-blocking)
-			select {
-			case snapshotCh <- snap:
-			default:
-			}
-
-			// Log at most once per second (same candle time = same second)
-			if snap.Candle1s.Time != lastLogTime {
-				lastLogTime = snap.Candle1s.Time
-				row := csvlogger.BuildLogRow(&snap, 0) // eventFlags=0 for now
-				snapLogger.Log(row)
-			}
+	if isHelp(args[0]) || args[0] == "help" {
+		usage()
+		return
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			c.run(args[1:])
+			return
 		}
-	}()
+	}
+	fmt.Fprintf(os.Stderr, "orderflow: unknown command %q\n\n", args[0])
+	usage()
+	os.Exit(2)
+}
 
-	// 12. Broadcaster (now with ring buffer for snapshot history)
-	broadcaster := broadcast.NewBroadcaster(snapshotCh, snapBuffer)
-	go broadcaster.Start(":8080")
+func isHelp(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
 
-	// 13. Shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: orderflow <command> [flags]   (orderflow <command> -h for its flags)")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.summary)
+	}
+}
 
-	log.Println("Shutting down...")
-	cancel()
+func cmdVersion(args []string) {
+	info := buildinfo.Get()
+	if len(args) > 0 && args[0] == "-json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(info)
+		return
+	}
+	fmt.Println(info)
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"market-indikator/internal/harness"
+	"market-indikator/internal/ingest"
+)
+
+// orderflow replay — record fixtures and replay them through the pipeline,
+// verifying it against golden frames (see internal/harness).
+//
+//   orderflow replay -record fixture.jsonl [-regime mixed -seed 1 -minutes 10 -rate 20]
+//   orderflow replay -fixture fixture.jsonl -golden golden.jsonl [-update] [-delta-keyframe 30]
+//
+// Verification exits 1 on the first differing frame.
+
+// fixtureStart — fixed virtual clock origin so recordings are reproducible.
+const fixtureStart = 1_700_000_000_000
+
+func cmdReplay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	record := fs.String("record", "", "write a synthetic fixture to this path")
+	regime := fs.String("regime", ingest.RegimeMixed, "synthetic regime for -record (trend, chop, crash or mixed)")
+	seed := fs.Int64("seed", 1, "synthetic seed for -record")
+	minutes := fs.Int("minutes", 10, "fixture length for -record")
+	rate := fs.Float64("rate", ingest.DefaultSyntheticConfig().TradesPerSec, "synthetic base trades per second for -record")
+	fixture := fs.String("fixture", "", "fixture to replay")
+	golden := fs.String("golden", "", "golden frames to verify against (or write with -update)")
+	update := fs.Bool("update", false, "rewrite -golden from the current pipeline output")
+	keyframe := fs.Int("delta-keyframe", 30, "delta keyframe interval (0 = full frames only)")
+	fs.Parse(args)
+
+	switch {
+	case *record != "":
+		cfg := ingest.DefaultSyntheticConfig()
+		cfg.Regime, cfg.Seed, cfg.StartMs, cfg.TradesPerSec = *regime, *seed, fixtureStart, *rate
+		if err := cfg.Validate(); err != nil {
+			log.Fatalf("Invalid -regime / -rate: %v", err)
+		}
+		events := harness.RecordSynthetic(cfg, int64(*minutes)*60_000)
+		if err := writeFile(*record, func(f *os.File) error { return harness.WriteFixture(f, events) }); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d events written to %s\n", len(events), *record)
+
+	case *fixture != "" && *golden != "":
+		f, err := os.Open(*fixture)
+		if err != nil {
+			log.Fatal(err)
+		}
+		events, err := harness.ReadFixture(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", *fixture, err)
+		}
+		frames, err := harness.Run(events, harness.Options{DeltaKeyframe: *keyframe})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *update {
+			if err := writeFile(*golden, func(f *os.File) error { return harness.WriteGolden(f, frames) }); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("%d frames written to %s\n", len(frames), *golden)
+			return
+		}
+		g, err := os.Open(*golden)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer g.Close()
+		if err := harness.VerifyGolden(g, frames); err != nil {
+			fmt.Fprintf(os.Stderr, "FAIL: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("OK: %d frames match %s\n", len(frames), *golden)
+
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+}
+
+func writeFile(path string, write func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
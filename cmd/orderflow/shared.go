@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"market-indikator/internal/buildinfo"
+	"market-indikator/internal/engine"
+	"market-indikator/internal/export"
+	"market-indikator/internal/features"
+	csvlogger "market-indikator/internal/logger"
+	"market-indikator/internal/model"
+	"market-indikator/internal/pressure"
+	"market-indikator/internal/signals"
+	"market-indikator/internal/state"
+)
+
+// Settings shared by the modes that run the engine (run, backtest), so a
+// backtest scores the tape exactly as the live engine would with the same
+// flags.
+
+// engineFlags — timeframes and the analytics / scoring configuration.
+type engineFlags struct {
+	intensityGain float64
+	weights       string
+	smoother      string
+	norm          string
+	vpinBucket    float64
+	vpinBuckets   int
+	cvdReset      string
+	profileTick   float64
+	timeframes    string
+	ribbon        string
+	seasonal      bool
+	seasonalDays  int
+	thinBookPct   float64
+	thinBookDamp  float64
+	thinBookWiden float64
+	structure     time.Duration
+	openRange     string
+}
+
+func (c *engineFlags) register(fs *flag.FlagSet) {
+	fs.Float64Var(&c.intensityGain, "score-intensity-gain", 0,
+		"scale aggressive pressure by clamp(1+gain·tapeIntensityZ, 0.5, 1.5) (0 = off)")
+	fs.StringVar(&c.weights, "score-weights", "",
+		"composite score weights overriding the defaults, e.g. 'aggressive=0.5,passive=0.3,positioning=0.2' (also cvd, delta, oi, behavior)")
+	fs.StringVar(&c.smoother, "score-smoother", "ema",
+		"final score smoothing: ema[:N], kalman, median[:N] or hull[:N]")
+	fs.StringVar(&c.norm, "score-norm", "ema",
+		"score input normalization: ema (x/EMA|x|), zscore[:N] (winsorized rolling z) or rank[:N]")
+	fs.Float64Var(&c.vpinBucket, "vpin-bucket", engine.DefaultVPINBucket, "VPIN volume bucket size (base asset units)")
+	fs.IntVar(&c.vpinBuckets, "vpin-buckets", engine.DefaultVPINBuckets, "VPIN window in buckets")
+	fs.StringVar(&c.cvdReset, "cvd-reset", "daily",
+		"anchor of the snapshot's anchored CVD: none, daily (00:00 UTC), session or rolling:<duration> (e.g. rolling:4h); the lifetime CVD is always sent too")
+	fs.Float64Var(&c.profileTick, "profile-tick", engine.DefaultProfileTick,
+		"volume-profile and market-profile (TPO) bin width for support/resistance levels (price units)")
+	fs.StringVar(&c.timeframes, "timeframes", "5m,15m,1h,4h,1d",
+		"higher-timeframe candles beyond 1s/1m, units s/m/h/d/w (e.g. 15s,5m,15m,30m,1h,4h,1d,1w)")
+	fs.StringVar(&c.ribbon, "ema-ribbon", "8,13,21,34,55", "EMA ribbon periods on the 1m/5m/1h indicator candles")
+	fs.BoolVar(&c.seasonal, "score-seasonal", false,
+		"normalize score inputs against hour-of-week baselines of trade intensity, delta and ΔOI (learned online, checkpointed)")
+	fs.IntVar(&c.seasonalDays, "score-seasonal-seed-days", 0,
+		"with -score-seasonal, seed the baselines from this many days of the snapshot log at startup (0 = learn online only)")
+	fs.Float64Var(&c.thinBookPct, "thin-book-pct", engine.DefaultThinBookPct,
+		"flag a thin book when top-N depth is below this percentile of the last hour (0 = off)")
+	fs.Float64Var(&c.thinBookDamp, "thin-book-damp", 0,
+		"on a thin book, scale the score's passive (orderbook) domain by 1-damp (0 = off, 1 = drop it)")
+	fs.Float64Var(&c.thinBookWiden, "thin-book-widen", 1,
+		"on a thin book, widen the market-state / action-hint thresholds by this factor (1 = off)")
+	fs.DurationVar(&c.structure, "structure-window", engine.DefaultStructureWindow*time.Minute,
+		"rolling high/low range for distance-to-breakout and breakout events, whole minutes up to 24h (0 = off)")
+	fs.StringVar(&c.openRange, "open-range", "london:30m,ny:30m",
+		"opening-range breakout tracking: SESSION:DURATION list (asia, london, ny; e.g. london:15m,ny:60m) or none")
+}
+
+// setGlobals installs the timeframe set and EMA ribbon. Must be called
+// before anything builds a snapshot.
+func (c *engineFlags) setGlobals() error {
+	tfs, err := model.ParseTimeframes(c.timeframes)
+	if err != nil {
+		return fmt.Errorf("-timeframes: %v", err)
+	}
+	model.SetTimeframes(tfs)
+	periods, err := model.ParseRibbon(c.ribbon)
+	if err != nil {
+		return fmt.Errorf("-ema-ribbon: %v", err)
+	}
+	model.SetRibbon(periods)
+	if c.thinBookWiden < 1 {
+		return fmt.Errorf("-thin-book-widen: %v (want >= 1)", c.thinBookWiden)
+	}
+	csvlogger.SetThinBookWiden(c.thinBookWiden)
+	return nil
+}
+
+// configure applies the scoring and analytics settings to a new engine,
+// before Restore and the first trade. The seasonal baselines are seeded from
+// the snapshot log in logDir up to seedTo (unix ms).
+func (c *engineFlags) configure(eng *engine.Engine, logDir string, seedTo int64) error {
+	eng.SetIntensityGain(c.intensityGain)
+	weights, err := pressure.ParseWeights(c.weights)
+	if err != nil {
+		return fmt.Errorf("-score-weights: %v", err)
+	}
+	eng.SetScoreWeights(weights)
+	smoother, err := pressure.ParseSmoother(c.smoother)
+	if err != nil {
+		return fmt.Errorf("-score-smoother: %v", err)
+	}
+	eng.SetScoreSmoother(smoother)
+	norm, err := pressure.ParseNormalizer(c.norm)
+	if err != nil {
+		return fmt.Errorf("-score-norm: %v", err)
+	}
+	eng.SetScoreNormalizer(norm)
+	if c.vpinBucket <= 0 || c.vpinBuckets <= 0 {
+		return fmt.Errorf("VPIN settings: bucket %v, buckets %d", c.vpinBucket, c.vpinBuckets)
+	}
+	eng.SetVPIN(c.vpinBucket, c.vpinBuckets)
+	reset, err := engine.ParseCVDReset(c.cvdReset)
+	if err != nil {
+		return fmt.Errorf("-cvd-reset: %v", err)
+	}
+	eng.SetCVDReset(reset)
+	if c.profileTick <= 0 {
+		return fmt.Errorf("-profile-tick: %v", c.profileTick)
+	}
+	eng.SetProfileTick(c.profileTick)
+	if c.thinBookPct < 0 || c.thinBookPct >= 100 || c.thinBookDamp < 0 || c.thinBookDamp > 1 {
+		return fmt.Errorf("thin-book settings: pct %v, damp %v", c.thinBookPct, c.thinBookDamp)
+	}
+	eng.SetThinBook(c.thinBookPct, c.thinBookDamp)
+	if c.structure < 0 || c.structure%time.Minute != 0 || c.structure > engine.MaxStructureWindow*time.Minute {
+		return fmt.Errorf("-structure-window: %v (whole minutes, up to 24h)", c.structure)
+	}
+	eng.SetStructure(int(c.structure / time.Minute))
+	or, err := engine.ParseOpenRange(c.openRange)
+	if err != nil {
+		return fmt.Errorf("-open-range: %v", err)
+	}
+	eng.SetOpenRange(or)
+	if c.seasonalDays < 0 {
+		return fmt.Errorf("-score-seasonal-seed-days: %d", c.seasonalDays)
+	}
+	if c.seasonal {
+		s := pressure.NewSeasonal()
+		if c.seasonalDays > 0 {
+			from := seedTo - int64(c.seasonalDays)*86400*1000
+			log.Printf("Seasonal baselines: %d of %d hours of the week seeded from %s",
+				engine.SeedSeasonal(s, logDir, from, seedTo), pressure.HoursOfWeek, logDir)
+		}
+		eng.SetSeasonal(s)
+	}
+	return nil
+}
+
+// signalFlags registers the score signal thresholds on cfg.
+func signalFlags(fs *flag.FlagSet, cfg *signals.Config) {
+	fs.Float64Var(&cfg.Arm, "signal-arm", cfg.Arm, "|final score| crossing that arms a score signal")
+	fs.Float64Var(&cfg.Trigger, "signal-trigger", cfg.Trigger, "|final score| that triggers an armed signal (the entry)")
+	fs.Float64Var(&cfg.Exit, "signal-exit", cfg.Exit, "|final score| below which a triggered signal completes")
+	fs.DurationVar(&cfg.ArmTTL, "signal-arm-ttl", cfg.ArmTTL, "armed signal expiry")
+	fs.DurationVar(&cfg.MaxHold, "signal-max-hold", cfg.MaxHold, "triggered signal completes after this")
+	fs.Float64Var(&cfg.StopBps, "signal-stop-bps", cfg.StopBps,
+		"adverse move from entry that invalidates a triggered signal, bps (0 = off)")
+	fs.BoolVar(&cfg.RespectBias, "signal-respect-bias", cfg.RespectBias,
+		"no long signals under a BEARISH HTF bias, no shorts under BULLISH")
+}
+
+// rangeFlags — the -from / -to range of the modes reading the logs.
+type rangeFlags struct {
+	from, to string
+}
+
+func (r *rangeFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&r.from, "from", "", "range start (default: 7 days ago)")
+	fs.StringVar(&r.to, "to", "", "range end (default: now)")
+}
+
+// parse returns the range in unix ms (see export.ParseTime).
+func (r *rangeFlags) parse() (from, to int64, err error) {
+	now := time.Now().UTC()
+	if from, err = export.ParseTime(r.from, now.AddDate(0, 0, -7).UnixMilli(), false); err != nil {
+		return 0, 0, fmt.Errorf("-from: %v", err)
+	}
+	if to, err = export.ParseTime(r.to, now.UnixMilli(), true); err != nil {
+		return 0, 0, fmt.Errorf("-to: %v", err)
+	}
+	if to <= from {
+		return 0, 0, fmt.Errorf("range: -to must be after -from")
+	}
+	return from, to, nil
+}
+
+// parseHorizons parses comma-separated forward-return horizons ("10s,1m")
+// into milliseconds, with the spec's own spelling of each as its label.
+func parseHorizons(spec string) (ms []int64, labels []string, err error) {
+	for _, s := range strings.Split(spec, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return nil, nil, fmt.Errorf("-horizons entry %q", s)
+		}
+		ms = append(ms, d.Milliseconds())
+		labels = append(labels, s)
+	}
+	if len(ms) == 0 {
+		return nil, nil, fmt.Errorf("-horizons: none given")
+	}
+	return ms, labels, nil
+}
+
+// registerSchemas lists the formats this binary reads and writes in the
+// build info (GET /version, the startup banner).
+func registerSchemas() {
+	buildinfo.SetSchema("snapshot_csv", csvlogger.SchemaVersion)
+	buildinfo.SetSchema("wire", model.WireVersion)
+	buildinfo.SetSchema("checkpoint", engine.CheckpointVersion)
+	buildinfo.SetSchema("ring_state", state.PersistVersion)
+	buildinfo.SetSchema("features", features.Version)
+}
+
+// recordBuild appends the running build to logs/builds.jsonl, so a log
+// file can be traced to the build that wrote it by time.
+func recordBuild(dir, symbol string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, "builds.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	rec := struct {
+		Started string `json:"started"`
+		Symbol  string `json:"symbol"`
+		buildinfo.Info
+	}{time.Now().UTC().Format(time.RFC3339), symbol, buildinfo.Get()}
+	if err := json.NewEncoder(f).Encode(rec); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,92 @@
+package account
+
+import (
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// =============================================================================
+// ACCOUNT POSITION — the user's own fills and position (optional)
+// =============================================================================
+//
+// Fed by the authenticated Binance user-data stream (internal/ingest) for the
+// traded symbol only:
+//
+//   ACCOUNT_UPDATE      → Size (signed, + long / − short) and EntryPrice
+//   ORDER_TRADE_UPDATE  → last fill, realized PnL and commission, summed per
+//                         UTC day
+//
+// Unrealized PnL is computed by the engine from the live trade price rather
+// than taken from the exchange (whose figure uses the mark price and only
+// arrives on account updates):
+//
+//   UnrealizedPnL = (price − EntryPrice) · Size
+//
+// State is shared the same way as the OI engine: one writer goroutine, an
+// atomic pointer swap, lock-free reads from the engine goroutine.
+// =============================================================================
+
+// State — the current position.
+type State struct {
+	Size        float64 // signed base-asset quantity
+	EntryPrice  float64
+	RealizedPnL float64 // today (UTC), net of commission
+	Day         int64   // UTC day (unix days) RealizedPnL belongs to
+
+	LastFillTime  int64 // exchange time, unix ms (0 = no fill yet)
+	LastFillPrice float64
+	LastFillQty   float64 // signed: + bought, − sold
+
+	UpdatedAt int64 // wall-clock unix ms of the last event
+}
+
+// UnrealizedPnL — at price.
+func (s *State) UnrealizedPnL(price float64) float64 {
+	if s.Size == 0 || s.EntryPrice == 0 {
+		return 0
+	}
+	return (price - s.EntryPrice) * s.Size
+}
+
+// Tracker — the account state. Written by a SINGLE goroutine (the user-data
+// stream), read lock-free from any goroutine.
+type Tracker struct {
+	state unsafe.Pointer // *State
+}
+
+func NewTracker() *Tracker {
+	t := &Tracker{}
+	atomic.StorePointer(&t.state, unsafe.Pointer(&State{}))
+	return t
+}
+
+// GetState returns the latest state. LOCK-FREE.
+func (t *Tracker) GetState() State {
+	return *(*State)(atomic.LoadPointer(&t.state))
+}
+
+func (t *Tracker) publish(s State) {
+	s.UpdatedAt = time.Now().UnixMilli()
+	atomic.StorePointer(&t.state, unsafe.Pointer(&s))
+}
+
+// SetPosition records the position from an account update or a REST
+// snapshot.
+func (t *Tracker) SetPosition(size, entryPrice float64) {
+	s := t.GetState()
+	s.Size, s.EntryPrice = size, entryPrice
+	t.publish(s)
+}
+
+// AddFill records a fill at timeMs (exchange time): qty signed (+ buy),
+// realized PnL and commission as reported with it.
+func (t *Tracker) AddFill(timeMs int64, price, qty, realized, commission float64) {
+	s := t.GetState()
+	if day := timeMs / 86_400_000; day != s.Day {
+		s.Day, s.RealizedPnL = day, 0
+	}
+	s.RealizedPnL += realized - commission
+	s.LastFillTime, s.LastFillPrice, s.LastFillQty = timeMs, price, qty
+	t.publish(s)
+}
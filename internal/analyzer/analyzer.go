@@ -0,0 +1,180 @@
+package analyzer
+
+import (
+	"fmt"
+	"plugin"
+	"sort"
+	"strings"
+	"sync"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/orderbook"
+)
+
+// =============================================================================
+// CUSTOM ANALYZERS — pluggable metrics outside the engine
+// =============================================================================
+//
+// An Analyzer is a self-contained metric that sees the same event stream as
+// the engine and contributes named scalars to each snapshot, without edits to
+// engine.go. Modules register a Factory under a name from an init():
+//
+//   func init() { analyzer.Register("bigprints", newBigPrints) }
+//
+// and are enabled at startup with -analyzers name[:arg],… (see Parse). A
+// module is linked in either
+//
+//   compiled in  a blank import in cmd/orderflow/analyzers.go
+//   Go plugin    go build -buildmode=plugin, loaded with -analyzer-plugin
+//                (LoadPlugin); its init() registers as above. Plugins must be
+//                built with the same toolchain and module versions as the
+//                binary (Linux/macOS only).
+//
+// CALL CONTRACT — all methods run on the engine goroutine, in order:
+//
+//   OnTrade     every trade, before the engine's own processing of it
+//   OnDepth     the latest orderbook, when it changed since the previous
+//               trade/tick (intermediate depth updates are not replayed)
+//   OnSnapshot  every snapshot, after the engine filled it in; writes exactly
+//               len(Fields()) values into out
+//
+// Calls must not block and should not allocate; an analyzer is on the hot
+// path of every trade. Analyzer state is not checkpointed — modules restart
+// cold.
+//
+// Fields are published as Snapshot.Custom, named "<analyzer>.<field>" in the
+// descriptor frame (model.CustomFields).
+// =============================================================================
+
+// Analyzer — a custom analytics module (see file header).
+type Analyzer interface {
+	// Fields names the scalars OnSnapshot writes. Fixed for the analyzer's
+	// lifetime.
+	Fields() []string
+	OnTrade(t *model.Trade)
+	OnDepth(d *orderbook.Depth)
+	OnSnapshot(s *model.Snapshot, out []float64)
+}
+
+// Factory builds an analyzer from its optional argument (the part after
+// ":" in the -analyzers spec, "" if none).
+type Factory func(arg string) (Analyzer, error)
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a factory available under name. Called from init();
+// panics on a duplicate name.
+func Register(name string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic("analyzer: Register called twice for " + name)
+	}
+	registry[name] = f
+}
+
+// Registered — the registered analyzer names, sorted.
+func Registered() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	out := make([]string, 0, len(registry))
+	for name := range registry {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// LoadPlugin opens a Go plugin; its init() registers its analyzers.
+func LoadPlugin(path string) error {
+	if _, err := plugin.Open(path); err != nil {
+		return fmt.Errorf("analyzer plugin %s: %w", path, err)
+	}
+	return nil
+}
+
+// Set — the enabled analyzers. Owned by the engine goroutine.
+type Set struct {
+	names     []string
+	analyzers []Analyzer
+	offsets   []int // start of each analyzer's fields in the output
+	fields    []string
+	depthAt   int64 // Depth.Time last delivered to OnDepth
+}
+
+// Parse instantiates the analyzers in a comma-separated spec like
+// "bigprints:10,myfeed". The total field count is bounded by
+// model.MaxCustomFields.
+func Parse(spec string) (*Set, error) {
+	s := &Set{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(item, ":")
+		registryMu.Lock()
+		f, ok := registry[name]
+		registryMu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown analyzer %q (registered: %s)", name, strings.Join(Registered(), ", "))
+		}
+		a, err := f(arg)
+		if err != nil {
+			return nil, fmt.Errorf("analyzer %s: %w", name, err)
+		}
+		s.names = append(s.names, name)
+		s.analyzers = append(s.analyzers, a)
+		s.offsets = append(s.offsets, len(s.fields))
+		for _, field := range a.Fields() {
+			full := name + "." + field
+			if len(full) > 31 {
+				return nil, fmt.Errorf("analyzer field %q: name longer than 31 bytes", full)
+			}
+			s.fields = append(s.fields, full)
+		}
+	}
+	if len(s.fields) > model.MaxCustomFields {
+		return nil, fmt.Errorf("%d analyzer fields, max %d", len(s.fields), model.MaxCustomFields)
+	}
+	s.offsets = append(s.offsets, len(s.fields))
+	return s, nil
+}
+
+// Fields — qualified field names in output order (for model.SetCustomFields).
+func (s *Set) Fields() []string { return s.fields }
+
+// Len — number of enabled analyzers.
+func (s *Set) Len() int { return len(s.analyzers) }
+
+// OnTrade forwards a trade to every analyzer.
+func (s *Set) OnTrade(t *model.Trade) {
+	for _, a := range s.analyzers {
+		a.OnTrade(t)
+	}
+}
+
+// OnDepth forwards the book if it changed since the last delivery.
+func (s *Set) OnDepth(book *orderbook.Book) {
+	if len(s.analyzers) == 0 {
+		return
+	}
+	d := book.GetDepth()
+	if d.Time == s.depthAt {
+		return
+	}
+	s.depthAt = d.Time
+	for _, a := range s.analyzers {
+		a.OnDepth(&d)
+	}
+}
+
+// OnSnapshot lets every analyzer write its fields into out.
+func (s *Set) OnSnapshot(snap *model.Snapshot, out []float64) {
+	for i, a := range s.analyzers {
+		a.OnSnapshot(snap, out[s.offsets[i]:s.offsets[i+1]])
+	}
+}
@@ -0,0 +1,98 @@
+package bigprints
+
+import (
+	"fmt"
+	"strconv"
+
+	"market-indikator/internal/analyzer"
+	"market-indikator/internal/model"
+	"market-indikator/internal/orderbook"
+)
+
+// =============================================================================
+// BIG PRINTS — block-trade flow (example analyzer)
+// =============================================================================
+//
+// Counts aggressor prints of at least Threshold base units over the trailing
+// 60 seconds, in one-second buckets:
+//
+//   net    Σ signed quantity of big prints (buy aggressor +, sell −)
+//   count  number of big prints
+//
+// Enabled with -analyzers bigprints[:threshold] (default 10 BTC).
+//
+// TRADING INTERPRETATION:
+//   A final score driven by many small prints with net ≈ 0 is retail flow;
+//   the same score with a large |net| in its direction has size behind it.
+// =============================================================================
+
+const (
+	defaultThreshold = 10.0
+	windowSecs       = 60
+)
+
+func init() {
+	analyzer.Register("bigprints", New)
+}
+
+// BigPrints — trailing-minute big-print tracker.
+type BigPrints struct {
+	Threshold float64
+
+	sec   int64 // newest bucket's second
+	net   [windowSecs]float64
+	count [windowSecs]float64
+}
+
+// New — the analyzer.Factory; arg is the optional size threshold.
+func New(arg string) (analyzer.Analyzer, error) {
+	th := defaultThreshold
+	if arg != "" {
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("threshold %q: want a positive size", arg)
+		}
+		th = v
+	}
+	return &BigPrints{Threshold: th}, nil
+}
+
+func (b *BigPrints) Fields() []string { return []string{"net", "count"} }
+
+func (b *BigPrints) OnTrade(t *model.Trade) {
+	b.advance(t.Time / 1000)
+	if t.Quantity < b.Threshold {
+		return
+	}
+	q := t.SignedQty()
+	i := b.sec % windowSecs
+	b.net[i] += q
+	b.count[i]++
+}
+
+func (b *BigPrints) OnDepth(*orderbook.Depth) {}
+
+func (b *BigPrints) OnSnapshot(s *model.Snapshot, out []float64) {
+	b.advance(s.Time / 1000)
+	var net, count float64
+	for i := range b.net {
+		net += b.net[i]
+		count += b.count[i]
+	}
+	out[0], out[1] = net, count
+}
+
+// advance clears the buckets of the seconds skipped up to sec.
+func (b *BigPrints) advance(sec int64) {
+	if sec <= b.sec {
+		return
+	}
+	gap := sec - b.sec
+	if b.sec == 0 || gap > windowSecs {
+		gap = windowSecs
+	}
+	for s := sec - gap + 1; s <= sec; s++ {
+		b.net[s%windowSecs], b.count[s%windowSecs] = 0, 0
+	}
+	b.sec = sec
+}
@@ -0,0 +1,86 @@
+package ml
+
+import (
+	"fmt"
+	"strconv"
+
+	"market-indikator/internal/analyzer"
+	"market-indikator/internal/features"
+	"market-indikator/internal/model"
+	"market-indikator/internal/onnx"
+	"market-indikator/internal/orderbook"
+)
+
+// =============================================================================
+// ML SCORE — a learned score from an ONNX model, next to FinalScore
+// =============================================================================
+//
+// Evaluates a model trained on the exported feature vectors
+// (export -kind features, see internal/features) on every snapshot and
+// publishes its first output:
+//
+//   score   the model's prediction for the current feature vector
+//
+// Enabled with -analyzers ml:path/to/model.onnx; the field reaches clients
+// as "ml.score" in Snapshot.Custom, next to FinalScore, so both can be
+// charted and compared live. The model must take Len float32 inputs; if its
+// metadata has a feature_version entry it must equal features.Version
+// (onnx.helper.set_model_props(model, {"feature_version": "1"})), else the
+// model is refused at startup.
+//
+// The vector is built from the snapshot as-is, so mid-second snapshots see
+// a partial 1s candle; the snapshot closing each second sees what the
+// export (and so the training set) saw.
+//
+// TRADING INTERPRETATION:
+//   Its scale is whatever the model was trained on — a forward return in bps
+//   for a regression on the label tool's fwd_* targets. Agreement in sign
+//   with FinalScore confirms the hand-weighted composite; a persistent
+//   disagreement is the regime the weights don't capture.
+// =============================================================================
+
+func init() {
+	analyzer.Register("ml", New)
+}
+
+// ML — the model scorer.
+type ML struct {
+	model *onnx.Model
+	fx    features.Extractor
+	vec   [features.Len]float32
+	last  float64
+}
+
+// New — the analyzer.Factory; arg is the model path.
+func New(arg string) (analyzer.Analyzer, error) {
+	if arg == "" {
+		return nil, fmt.Errorf("want ml:path/to/model.onnx")
+	}
+	m, err := onnx.Load(arg)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := m.Metadata["feature_version"]; ok {
+		if n, err := strconv.Atoi(v); err != nil || n != features.Version {
+			return nil, fmt.Errorf("%s: trained on feature version %s, this build has %d", arg, v, features.Version)
+		}
+	}
+	if n := m.InputLen(); n != features.Len && n != -1 {
+		return nil, fmt.Errorf("%s: model takes %d inputs, the feature vector has %d", arg, n, features.Len)
+	}
+	return &ML{model: m}, nil
+}
+
+func (a *ML) Fields() []string { return []string{"score"} }
+
+func (a *ML) OnTrade(*model.Trade) {}
+
+func (a *ML) OnDepth(*orderbook.Depth) {}
+
+func (a *ML) OnSnapshot(s *model.Snapshot, out []float64) {
+	a.fx.Extract(s, a.vec[:])
+	if y, err := a.model.Run(a.vec[:]); err == nil && len(y) > 0 {
+		a.last = float64(y[0])
+	}
+	out[0] = a.last
+}
@@ -0,0 +1,132 @@
+package binance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// USD-M futures REST endpoints.
+const (
+	FuturesBase        = "https://fapi.binance.com"
+	FuturesTestnetBase = "https://testnet.binancefuture.com"
+)
+
+const (
+	recvWindow     = "5000"
+	requestTimeout = 5 * time.Second
+)
+
+// Client — REST access, API-key authenticated (HMAC-SHA256 signed endpoints)
+// when keys are given, within the host's shared weight budget (limiter.go).
+// Safe for concurrent use.
+type Client struct {
+	base    string
+	apiKey  string
+	secret  string
+	http    *http.Client
+	limiter *Limiter
+	maxWait time.Duration // longest wait for rate-limit budget
+}
+
+// NewClient — apiKey "" for public endpoints only.
+func NewClient(base, apiKey, secret string) *Client {
+	return &Client{base: base, apiKey: apiKey, secret: secret, http: HTTPClient(requestTimeout),
+		limiter: LimiterFor(base), maxWait: requestTimeout}
+}
+
+// SetTimeout bounds both the request and the wait for rate-limit budget.
+// Must be called before first use.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.http.Timeout, c.maxWait = d, d
+}
+
+// Do sends a request with the API key header (and timestamp + signature when
+// signed) and decodes the JSON reply into out (nil = discard).
+func (c *Client) Do(method, path string, q url.Values, signed bool, out any) error {
+	// Budget first: a signature's timestamp must not age in the queue
+	weight, ok := endpointWeight[path]
+	if !ok {
+		weight = 1
+	}
+	if err := c.limiter.Acquire(weight, c.maxWait); err != nil {
+		return err
+	}
+	if q == nil {
+		q = url.Values{}
+	}
+	if signed {
+		// Exchange time: a fast local clock gets "timestamp ahead of server"
+		q.Set("timestamp", strconv.FormatInt(exchangeClock.NowMs(), 10))
+		q.Set("recvWindow", recvWindow)
+		mac := hmac.New(sha256.New, []byte(c.secret))
+		mac.Write([]byte(q.Encode()))
+		q.Set("signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+	target := c.base + path
+	if len(q) > 0 {
+		target += "?" + q.Encode()
+	}
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-MBX-APIKEY", c.apiKey)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	c.limiter.Observe(resp.Header, resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, body)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Position — the symbol's net position (one-way mode; hedge-mode legs are
+// netted) and its size-weighted entry price (0 when flat).
+func (c *Client) Position(symbol string) (size, entryPrice float64, err error) {
+	var rows []struct {
+		Symbol      string `json:"symbol"`
+		PositionAmt string `json:"positionAmt"`
+		EntryPrice  string `json:"entryPrice"`
+	}
+	q := url.Values{"symbol": {symbol}}
+	if err := c.Do(http.MethodGet, "/fapi/v2/positionRisk", q, true, &rows); err != nil {
+		return 0, 0, err
+	}
+	var cost float64
+	for _, r := range rows {
+		if r.Symbol != symbol {
+			continue
+		}
+		amt, _ := strconv.ParseFloat(r.PositionAmt, 64)
+		entry, _ := strconv.ParseFloat(r.EntryPrice, 64)
+		size += amt
+		cost += amt * entry
+	}
+	return size, EntryOf(size, cost), nil
+}
+
+// EntryOf — the netted entry price of positions summing to size with
+// Σ amount·entry = cost (0 when flat).
+func EntryOf(size, cost float64) float64 {
+	if size == 0 {
+		return 0
+	}
+	return cost / size
+}
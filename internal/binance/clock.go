@@ -0,0 +1,203 @@
+package binance
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+// EXCHANGE CLOCK — local-vs-Binance clock offset
+// =============================================================================
+//
+// Trades carry exchange time; the heartbeat, request signatures and anything
+// else reading time.Now() use the local clock. A host running 300ms fast
+// rolls its heartbeat candles 300ms before Binance does, and the trades of
+// that last 300ms land in the next bucket — the candle disagrees with
+// Binance's own kline right at the boundary. So everything that buckets or
+// stamps "now" asks the Clock for exchange time instead:
+//
+//   exchange now = local now + offset
+//
+// Two estimates of offset:
+//
+//   - server time (Sync): GET /fapi/v1/time bracketed by local send / receive
+//     stamps t0, t1. offset = server − (t0 + t1)/2, uncertain by ±RTT/2; the
+//     round with the smallest RTT out of syncRounds is kept.
+//
+//   - stream events (ObserveEvent): an event stamped T on the exchange and
+//     received locally at R satisfies T + latency = R + offset, with
+//     latency ≥ 0, so
+//
+//       offset ≥ T − R
+//
+//     The max of T − R over the last eventWindow is a lower bound that
+//     tightens whenever a message arrives with little network delay.
+//
+// Offset takes the server estimate, raised to the event bound when the two
+// disagree (a server round with asymmetric delay can land below what a
+// single event proves). Without a server sample (no REST) the event bound
+// alone is used; with neither, the offset is 0 — the local clock, as before.
+// =============================================================================
+
+const (
+	syncRounds   = 3
+	syncPeriod   = 10 * time.Minute
+	syncMaxAge   = 30 * time.Minute // older server samples are ignored
+	eventWindow  = 5                // minutes the event bound looks back
+	serverTimeEP = "/fapi/v1/time"
+)
+
+const noBound = math.MinInt64
+
+// ClockStats — for /admin/stats.
+type ClockStats struct {
+	Offset     time.Duration // exchange − local, as used
+	Server     time.Duration // last server-time estimate
+	ServerRTT  time.Duration // its round trip (0 = never synced)
+	EventBound time.Duration // lower bound from stream events
+	SyncErrors int64
+}
+
+// Clock — offset estimator. ObserveEvent must be called from one goroutine;
+// everything else is safe for concurrent use.
+type Clock struct {
+	serverOffset int64 // atomic, ns
+	serverRTT    int64 // atomic, ns
+	serverAt     int64 // atomic, local unix ns of the sample (0 = none)
+	eventBound   int64 // atomic, ns (noBound = none)
+	syncErrors   int64 // atomic
+
+	// ObserveEvent goroutine only: max of T − R per minute, ring of eventWindow
+	slots   [eventWindow]int64
+	minutes [eventWindow]int64
+}
+
+func NewClock() *Clock {
+	k := &Clock{eventBound: noBound}
+	for i := range k.slots {
+		k.slots[i] = noBound
+	}
+	return k
+}
+
+var exchangeClock = NewClock()
+
+// ExchangeClock — the process-wide clock request signatures are stamped with.
+func ExchangeClock() *Clock {
+	return exchangeClock
+}
+
+// ObserveEvent folds in an event stamped exchangeMs (exchange time, ms) and
+// received at recvNs (local unix ns).
+func (k *Clock) ObserveEvent(exchangeMs, recvNs int64) {
+	if recvNs == 0 {
+		return
+	}
+	d := exchangeMs*int64(time.Millisecond) - recvNs
+	m := recvNs / int64(time.Minute)
+	i := m % eventWindow
+	if k.minutes[i] != m {
+		k.minutes[i], k.slots[i] = m, noBound
+	}
+	if d <= k.slots[i] {
+		return
+	}
+	k.slots[i] = d
+	bound := int64(noBound)
+	for j, v := range k.slots {
+		if m-k.minutes[j] < eventWindow && v > bound {
+			bound = v
+		}
+	}
+	atomic.StoreInt64(&k.eventBound, bound)
+}
+
+// Sync measures the offset against the server time (syncRounds requests,
+// the tightest round kept).
+func (k *Clock) Sync(c *Client) error {
+	var best struct{ offset, rtt int64 }
+	best.rtt = math.MaxInt64
+	for i := 0; i < syncRounds; i++ {
+		var reply struct {
+			ServerTime int64 `json:"serverTime"`
+		}
+		t0 := time.Now().UnixNano()
+		if err := c.Do(http.MethodGet, serverTimeEP, nil, false, &reply); err != nil {
+			atomic.AddInt64(&k.syncErrors, 1)
+			return err
+		}
+		t1 := time.Now().UnixNano()
+		if rtt := t1 - t0; rtt < best.rtt {
+			best.rtt = rtt
+			best.offset = reply.ServerTime*int64(time.Millisecond) - (t0+t1)/2
+		}
+	}
+	atomic.StoreInt64(&k.serverOffset, best.offset)
+	atomic.StoreInt64(&k.serverRTT, best.rtt)
+	atomic.StoreInt64(&k.serverAt, time.Now().UnixNano())
+	return nil
+}
+
+// Start syncs now and every syncPeriod.
+func (k *Clock) Start(ctx context.Context, c *Client) {
+	go func() {
+		ticker := time.NewTicker(syncPeriod)
+		defer ticker.Stop()
+		for {
+			if err := k.Sync(c); err != nil {
+				log.Printf("Exchange clock sync failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Offset — exchange time minus local time.
+func (k *Clock) Offset() time.Duration {
+	bound := atomic.LoadInt64(&k.eventBound)
+	at := atomic.LoadInt64(&k.serverAt)
+	if at == 0 || time.Now().UnixNano()-at > int64(syncMaxAge) {
+		if bound == noBound {
+			return 0
+		}
+		return time.Duration(bound)
+	}
+	off := atomic.LoadInt64(&k.serverOffset)
+	if bound != noBound && bound > off {
+		off = bound
+	}
+	return time.Duration(off)
+}
+
+// Now — the current exchange time.
+func (k *Clock) Now() time.Time {
+	return time.Now().Add(k.Offset())
+}
+
+// NowMs — the current exchange time, unix ms.
+func (k *Clock) NowMs() int64 {
+	return k.Now().UnixMilli()
+}
+
+func (k *Clock) Stats() ClockStats {
+	st := ClockStats{
+		Offset:     k.Offset(),
+		SyncErrors: atomic.LoadInt64(&k.syncErrors),
+	}
+	if atomic.LoadInt64(&k.serverAt) != 0 {
+		st.Server = time.Duration(atomic.LoadInt64(&k.serverOffset))
+		st.ServerRTT = time.Duration(atomic.LoadInt64(&k.serverRTT))
+	}
+	if b := atomic.LoadInt64(&k.eventBound); b != noBound {
+		st.EventBound = time.Duration(b)
+	}
+	return st
+}
@@ -0,0 +1,168 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// REST RATE LIMIT — shared request-weight budget
+// =============================================================================
+//
+// Binance meters REST usage per IP as request weight per calendar minute
+// (1200 here) and answers overuse with 429, then — if the client keeps
+// going — 418 and an IP ban of minutes to days. Every Client talking to the
+// same host shares one Limiter, so all pollers, the user-data stream and the
+// executor draw from the same budget:
+//
+//   - before a request, Acquire reserves its weight (endpointWeight) and, if
+//     the minute's budget (90% of the limit, the rest is headroom for other
+//     processes on the IP) is spent, queues the caller until the next minute
+//   - after a response, Observe takes X-MBX-USED-WEIGHT-1M as the floor of
+//     the minute's usage — the server's count includes requests this
+//     process did not make
+//   - a 429 or 418 stops every caller until Retry-After (default: the next
+//     minute for 429, 2 minutes for 418)
+//
+// A caller that would have to wait longer than its Client's timeout gets
+// ErrRateLimited instead of blocking, so a poller skips a round and an order
+// fails fast rather than executing late.
+// =============================================================================
+
+// DefaultWeightLimit — request weight per minute per IP.
+const DefaultWeightLimit = 1200
+
+// ErrRateLimited — the request would wait past the Client's timeout.
+var ErrRateLimited = errors.New("binance: REST rate limit")
+
+// endpointWeight — request weight of the endpoints in use (default 1).
+var endpointWeight = map[string]int{
+	"/fapi/v2/positionRisk": 5,
+}
+
+// LimiterStats — counters for /admin/stats.
+type LimiterStats struct {
+	Used        int // weight used in the current minute (reserved or reported)
+	Limit       int
+	Throttled   int64     // requests queued for the next minute
+	Rejected    int64     // requests failed with ErrRateLimited
+	Backoffs    int64     // 429 / 418 responses
+	BannedUntil time.Time // zero = not backing off
+}
+
+// Limiter — one host's weight budget. Safe for concurrent use.
+type Limiter struct {
+	mu          sync.Mutex
+	limit       int
+	minute      int64 // unix minute that used refers to
+	used        int
+	bannedUntil time.Time
+	throttled   int64
+	rejected    int64
+	backoffs    int64
+}
+
+func NewLimiter(limit int) *Limiter {
+	return &Limiter{limit: limit}
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = map[string]*Limiter{}
+)
+
+// LimiterFor — the shared limiter of a REST base URL (one per host, so
+// mainnet and testnet budgets stay separate).
+func LimiterFor(base string) *Limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	l, ok := limiters[base]
+	if !ok {
+		l = NewLimiter(DefaultWeightLimit)
+		limiters[base] = l
+	}
+	return l
+}
+
+// roll starts a new minute's count. Caller holds mu.
+func (l *Limiter) roll(now time.Time) {
+	if m := now.Unix() / 60; m != l.minute {
+		l.minute, l.used = m, 0
+	}
+}
+
+// Acquire reserves weight, waiting up to maxWait for budget.
+func (l *Limiter) Acquire(weight int, maxWait time.Duration) error {
+	deadline := time.Now().Add(maxWait)
+	queued := false
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.roll(now)
+		var until time.Time
+		if now.Before(l.bannedUntil) {
+			until = l.bannedUntil
+		} else if l.used+weight <= l.limit*9/10 {
+			l.used += weight
+			l.mu.Unlock()
+			return nil
+		} else {
+			until = time.Unix((l.minute+1)*60, 0)
+		}
+		if until.After(deadline) {
+			l.rejected++
+			l.mu.Unlock()
+			return fmt.Errorf("%w until %s", ErrRateLimited, until.Format(time.TimeOnly))
+		}
+		if !queued {
+			l.throttled++
+			queued = true
+		}
+		l.mu.Unlock()
+		time.Sleep(time.Until(until))
+	}
+}
+
+// Observe folds a response's weight header and status into the budget.
+func (l *Limiter) Observe(h http.Header, status int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.roll(now)
+	if n, err := strconv.Atoi(h.Get("X-MBX-USED-WEIGHT-1M")); err == nil && n > l.used {
+		l.used = n
+	}
+	if status != http.StatusTooManyRequests && status != http.StatusTeapot {
+		return
+	}
+	wait := time.Until(time.Unix((l.minute+1)*60, 0))
+	if status == http.StatusTeapot {
+		wait = 2 * time.Minute
+	}
+	if s, err := strconv.Atoi(h.Get("Retry-After")); err == nil && s > 0 {
+		wait = time.Duration(s) * time.Second
+	}
+	if until := now.Add(wait); until.After(l.bannedUntil) {
+		l.bannedUntil = until
+	}
+	l.backoffs++
+	log.Printf("Binance REST HTTP %d: all requests paused until %s", status, l.bannedUntil.Format(time.TimeOnly))
+}
+
+// Stats — current usage and counters.
+func (l *Limiter) Stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll(time.Now())
+	st := LimiterStats{Used: l.used, Limit: l.limit, Throttled: l.throttled,
+		Rejected: l.rejected, Backoffs: l.backoffs}
+	if time.Now().Before(l.bannedUntil) {
+		st.BannedUntil = l.bannedUntil
+	}
+	return st
+}
@@ -0,0 +1,100 @@
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// USD-M futures WebSocket hosts: <base>/ws/<stream> for one stream,
+// <base>/stream?streams=a/b for a combined connection.
+const (
+	FuturesWSBase        = "wss://fstream.binance.com"
+	FuturesTestnetWSBase = "wss://stream.binancefuture.com"
+)
+
+// Network — where and how every REST client and stream reaches Binance.
+// Configure it once at startup, before any client or stream is created.
+type Network struct {
+	REST  string   // REST base URL
+	WS    string   // WebSocket base URL
+	Proxy *url.URL // HTTP(S) or SOCKS5 proxy for REST and WS (nil = HTTPS_PROXY etc. from the environment)
+}
+
+// Mainnet and Testnet — the two USD-M futures environments.
+var (
+	Mainnet = Network{REST: FuturesBase, WS: FuturesWSBase}
+	Testnet = Network{REST: FuturesTestnetBase, WS: FuturesTestnetWSBase}
+)
+
+var network = Mainnet
+
+// Configure replaces the network settings. Not safe to call once clients or
+// streams are running.
+func Configure(n Network) {
+	network = n
+}
+
+// Current — the configured network.
+func Current() Network {
+	return network
+}
+
+// ParseProxy validates a -proxy URL: http, https, socks5 or socks5h.
+func ParseProxy(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (want http, https, socks5 or socks5h)", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy %q has no host", s)
+	}
+	return u, nil
+}
+
+func (n Network) proxy() func(*http.Request) (*url.URL, error) {
+	if n.Proxy == nil {
+		return http.ProxyFromEnvironment
+	}
+	return http.ProxyURL(n.Proxy)
+}
+
+// HTTPClient — an http.Client going through the configured proxy.
+func HTTPClient(timeout time.Duration) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = network.proxy()
+	return &http.Client{Timeout: timeout, Transport: t}
+}
+
+// Dialer — a WebSocket dialer going through the configured proxy.
+func Dialer() *websocket.Dialer {
+	d := *websocket.DefaultDialer
+	d.Proxy = network.proxy()
+	return &d
+}
+
+// WSStream — the URL of one raw stream (e.g. "btcusdt@aggTrade", or a
+// listenKey).
+func WSStream(stream string) string {
+	return network.WS + "/ws/" + stream
+}
+
+// WSCombined — the URL of a combined connection carrying streams.
+func WSCombined(streams ...string) string {
+	u := network.WS + "/stream?streams="
+	for i, s := range streams {
+		if i > 0 {
+			u += "/"
+		}
+		u += s
+	}
+	return u
+}
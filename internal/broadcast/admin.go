@@ -0,0 +1,100 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// ADMIN STATS — GET /admin/stats
+// ═══════════════════════════════════════════════════════════════
+//
+// Live view of the fan-out layer for debugging slow clients without
+// rebuilding. The per-client list is collected by the hub goroutine
+// itself (request/reply over statsReq) — or, sharded, by each shard —
+// so the clients maps stay single-owner and lock-free.
+
+// ClientStats describes one connected WebSocket client.
+type ClientStats struct {
+	Addr        string    `json:"addr"`
+	ConnectedAt time.Time `json:"connected_at"`
+	Backlog     int       `json:"backlog"` // queued frames in send
+	BacklogCap  int       `json:"backlog_cap"`
+	Drops       int64     `json:"drops"`
+	DropStreak  int       `json:"drop_streak"` // consecutive drops right now
+}
+
+// Stats is the JSON body returned by /admin/stats.
+type Stats struct {
+	Clients      []ClientStats    `json:"clients"`
+	ClientCount  int              `json:"client_count"`
+	Snapshots    int64            `json:"snapshots_total"`
+	SnapshotsSec int64            `json:"snapshots_per_sec"`
+	BufferSize   int              `json:"buffer_size"`
+	BufferCap    int              `json:"buffer_cap"`
+	DropLimit    int              `json:"drop_limit"`
+	Counters     map[string]int64 `json:"counters"`
+}
+
+// counter is a named external metric (e.g. ingest reconnects).
+type counter struct {
+	name string
+	fn   func() int64
+}
+
+// AddCounter registers an external metric to include in /admin/stats.
+// Must be called before Start.
+func (b *Broadcaster) AddCounter(name string, fn func() int64) {
+	b.counters = append(b.counters, counter{name: name, fn: fn})
+}
+
+// clientStats — called from the goroutine owning clients only.
+func clientStats(clients map[*Client]bool) []ClientStats {
+	out := make([]ClientStats, 0, len(clients))
+	for c := range clients {
+		out = append(out, ClientStats{
+			Addr:        c.conn.RemoteAddr().String(),
+			ConnectedAt: c.connectedAt,
+			Backlog:     len(c.send),
+			BacklogCap:  cap(c.send),
+			Drops:       c.drops,
+			DropStreak:  c.consecutiveDrops,
+		})
+	}
+	return out
+}
+
+func serveStats(hub *Hub, counters []counter, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reply := make(chan []ClientStats, 1)
+	hub.statsReq <- reply
+	clients := <-reply
+
+	st := Stats{
+		Clients:      clients,
+		ClientCount:  len(clients),
+		Snapshots:    atomic.LoadInt64(&hub.snapshots),
+		SnapshotsSec: atomic.LoadInt64(&hub.rate),
+		DropLimit:    hub.opts.MaxConsecutiveDrops,
+		Counters:     make(map[string]int64, len(counters)),
+	}
+	if hub.buffer != nil {
+		st.BufferSize = hub.buffer.Size()
+		st.BufferCap = hub.buffer.Capacity()
+	}
+	for _, c := range counters {
+		st.Counters[c.name] = c.fn()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(st); err != nil {
+		log.Printf("Admin stats encode error: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/state"
+	"market-indikator/internal/vwap"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// ANCHORED VWAP — /admin/anchors
+// ═══════════════════════════════════════════════════════════════
+//
+//   GET    /admin/anchors               → active anchors (from the latest snapshot)
+//   POST   /admin/anchors?from=<unixms> → {"id": N}
+//   DELETE /admin/anchors?id=N
+//
+// The engine only sees trades from the moment an anchor is created, so an
+// anchor in the past is seeded here from candle history: completed 1s
+// candles from the snapshot buffer, and 1m candles from the "1m" history tier
+// for the stretch before the 1s buffer begins. Each candle counts at its
+// typical price (H+L+C)/3, so a seeded σ is slightly understated until live
+// trades dominate. Anchors older than the 1m tier start at its oldest minute.
+
+// AnchorEngine is the engine side of /admin/anchors.
+type AnchorEngine interface {
+	AddAnchor(fromMs int64, seed vwap.Accumulator, seededTo int64) (int64, error)
+	RemoveAnchor(id int64) error
+}
+
+// SetAnchors enables /admin/anchors. Must be called before Start.
+func (b *Broadcaster) SetAnchors(a AnchorEngine) {
+	b.anchors = a
+}
+
+// AnchorInfo is one entry of GET /admin/anchors.
+type AnchorInfo struct {
+	ID     int64   `json:"id"`
+	From   int64   `json:"from"`
+	VWAP   float64 `json:"vwap"`
+	Upper1 float64 `json:"upper1"`
+	Lower1 float64 `json:"lower1"`
+	Upper2 float64 `json:"upper2"`
+	Lower2 float64 `json:"lower2"`
+}
+
+func serveAnchors(b *Broadcaster, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		out := []AnchorInfo{}
+		if snap, ok := b.buffer.Latest(); ok {
+			for i := 0; i < snap.NumAnchors; i++ {
+				a := &snap.Anchors[i]
+				out = append(out, AnchorInfo{
+					ID: a.ID, From: a.From, VWAP: a.Band.VWAP,
+					Upper1: a.Band.Upper1, Lower1: a.Band.Lower1,
+					Upper2: a.Band.Upper2, Lower2: a.Band.Lower2,
+				})
+			}
+		}
+		writeJSON(w, out)
+
+	case http.MethodPost:
+		from, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+		if err != nil || from <= 0 {
+			http.Error(w, "from: want unix ms", http.StatusBadRequest)
+			return
+		}
+		seed, seededTo := seedAnchor(from, b.buffer, b.history["1m"])
+		id, err := b.anchors.AddAnchor(from, seed, seededTo)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("Anchored VWAP %d requested from %d (seeded vol %.3f)", id, from, seed.V)
+		writeJSON(w, map[string]int64{"id": id})
+
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "id: want integer", http.StatusBadRequest)
+			return
+		}
+		if err := b.anchors.RemoveAnchor(id); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// seedAnchor — VWAP sums of the completed candles from fromMs up to the live
+// second, and that second (unix seconds) as the seed's exclusive end.
+func seedAnchor(fromMs int64, sec1, min1 *state.RingBuffer) (vwap.Accumulator, int64) {
+	var acc vwap.Accumulator
+	if sec1 == nil {
+		return acc, 0
+	}
+	latest, ok := sec1.Latest()
+	if !ok {
+		return acc, 0
+	}
+	live := latest.Candle1s.Time
+	fromSec := (fromMs + 999) / 1000
+
+	// 1s candles: the last snapshot of each second holds its closing candle
+	secs := sec1.Range(fromSec*1000, live*1000)
+	firstSec := live
+	if len(secs) > 0 {
+		firstSec = secs[0].Candle1s.Time
+	}
+
+	// 1m candles for whole minutes before the 1s coverage
+	if min1 != nil && fromSec < firstSec {
+		for _, snap := range min1.Range(fromSec*1000, firstSec*1000) {
+			c := &snap.Candle1m
+			if c.Time >= fromSec && c.Time+60 <= firstSec {
+				addCandle(&acc, c)
+			}
+		}
+	}
+
+	for i := range secs {
+		c := &secs[i].Candle1s
+		last := i == len(secs)-1 || secs[i+1].Candle1s.Time != c.Time
+		if last && c.Time >= fromSec && c.Time < live {
+			addCandle(&acc, c)
+		}
+	}
+	return acc, live
+}
+
+func addCandle(acc *vwap.Accumulator, c *model.CandleSnapshot) {
+	if vol := c.BuyVol + c.SellVol; vol > 0 {
+		acc.Add((c.High+c.Low+c.Close)/3, vol)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Admin encode error: %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package broadcast
+
+import (
+	"net/http"
+
+	"market-indikator/internal/evaluation"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// SELF-EVALUATION — GET /admin/eval
+// ═══════════════════════════════════════════════════════════════
+//
+// Rolling score-vs-forward-return statistics (see internal/evaluation):
+// correlation, hit rate and per-score-band mean return for 10s and 60s.
+
+// SetEvaluation enables /admin/eval. Must be called before Start.
+func (b *Broadcaster) SetEvaluation(t *evaluation.Tracker) {
+	b.eval = t
+}
+
+func serveEval(t *evaluation.Tracker, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, t.Report())
+}
@@ -0,0 +1,50 @@
+package broadcast
+
+import (
+	"net/http"
+
+	"market-indikator/internal/execution"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// EXECUTION — /admin/execution
+// ═══════════════════════════════════════════════════════════════
+//
+//   GET  /admin/execution                → executor status (position, PnL, halt)
+//   POST /admin/execution?action=kill    → cancel orders, flatten, halt
+//   POST /admin/execution?action=resume  → re-read the position, trade again
+//
+// Only registered with -exec (see internal/execution). The POSTs need the
+// admin token (guard.go); -exec-live refuses to start without one.
+
+// SetExecution enables /admin/execution. Must be called before Start.
+func (b *Broadcaster) SetExecution(x *execution.Executor) {
+	b.exec = x
+}
+
+func serveExecution(x *execution.Executor, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, x.Status())
+
+	case http.MethodPost:
+		var err error
+		switch r.URL.Query().Get("action") {
+		case "kill":
+			err = x.Kill("kill switch (/admin/execution)")
+		case "resume":
+			err = x.Resume()
+		default:
+			http.Error(w, "action must be kill or resume", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeJSON(w, x.Status())
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
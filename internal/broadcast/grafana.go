@@ -0,0 +1,189 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/state"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// GRAFANA — /grafana/* (JSON / SimpleJSON datasource, Infinity)
+// ═══════════════════════════════════════════════════════════════
+//
+//   GET  /grafana/            → 200 (datasource "Test")
+//   POST /grafana/search      → ["score", "cvd", …]
+//   POST /grafana/metrics     → [{"label": "score", "value": "score"}, …]
+//   POST /grafana/query       → [{"target": "score", "datapoints": [[v, ms], …]}, …]
+//   GET  /grafana/query?target=score[&target=cvd]&from=<ms>&to=<ms>[&points=N]
+//                             → same reply, for the Infinity datasource
+//                               (${__from} / ${__to} are unix ms)
+//
+// A query is served from the finest history tier that reaches back to
+// `from` (1s buffer, then the 1m and 5m tiers); whatever lies before the
+// coarsest tier is read from the 1s CSV logs. Each series is thinned to at
+// most maxDataPoints by keeping the last snapshot of each time bucket.
+// Only metrics the CSV logs carry are offered, so a range reads the same
+// whichever source serves it.
+
+const grafanaMaxPoints = 2000 // when the request gives none
+
+// grafanaMetrics — the queryable series.
+var grafanaMetrics = map[string]func(s *model.Snapshot) float64{
+	"price":     func(s *model.Snapshot) float64 { return s.Price },
+	"score":     func(s *model.Snapshot) float64 { return s.FinalScore },
+	"score_1m":  func(s *model.Snapshot) float64 { return s.Candle1m.AvgScore },
+	"delta_1s":  func(s *model.Snapshot) float64 { return s.Candle1s.Delta },
+	"cvd":       func(s *model.Snapshot) float64 { return s.CVD },
+	"oi":        func(s *model.Snapshot) float64 { return s.OI.OI },
+	"oi_delta":  func(s *model.Snapshot) float64 { return s.OI.OIDelta1m },
+	"imbalance": func(s *model.Snapshot) float64 { return s.Orderbook.Imbalance },
+	"ob_score":  func(s *model.Snapshot) float64 { return float64(s.Orderbook.Score) },
+}
+
+// SetGrafana enables /grafana/*; csvDir holds the snapshot CSV logs read for
+// ranges older than every history tier ("" = tiers only). Must be called
+// before Start.
+func (b *Broadcaster) SetGrafana(csvDir string) {
+	b.grafana = true
+	b.csvDir = csvDir
+}
+
+// grafanaQuery — the parts of a JSON datasource /query body in use.
+type grafanaQuery struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+	Targets       []grafanaTarget `json:"targets"`
+	MaxDataPoints int             `json:"maxDataPoints"`
+}
+
+type grafanaTarget struct {
+	Target string `json:"target"`
+}
+
+// grafanaSeries is one series of a /grafana/query reply.
+type grafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"` // [value, unix ms]
+}
+
+func serveGrafana(b *Broadcaster, w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/grafana", "/grafana/":
+		w.WriteHeader(http.StatusOK)
+
+	case "/grafana/search", "/grafana/metrics":
+		names := make([]string, 0, len(grafanaMetrics))
+		for name := range grafanaMetrics {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if r.URL.Path == "/grafana/search" {
+			writeJSON(w, names)
+			return
+		}
+		out := make([]map[string]string, len(names))
+		for i, name := range names {
+			out[i] = map[string]string{"label": name, "value": name}
+		}
+		writeJSON(w, out)
+
+	case "/grafana/query":
+		var q grafanaQuery
+		switch r.Method {
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+				http.Error(w, "bad query: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		case http.MethodGet:
+			p := r.URL.Query()
+			from, err1 := strconv.ParseInt(p.Get("from"), 10, 64)
+			to, err2 := strconv.ParseInt(p.Get("to"), 10, 64)
+			if err1 != nil || err2 != nil {
+				http.Error(w, "from, to: want unix ms", http.StatusBadRequest)
+				return
+			}
+			q.Range.From, q.Range.To = time.UnixMilli(from), time.UnixMilli(to)
+			q.MaxDataPoints, _ = strconv.Atoi(p.Get("points"))
+			for _, t := range p["target"] {
+				q.Targets = append(q.Targets, grafanaTarget{t})
+			}
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		from, to := q.Range.From.UnixMilli(), q.Range.To.UnixMilli()
+		if to <= from {
+			http.Error(w, "empty range", http.StatusBadRequest)
+			return
+		}
+		for _, t := range q.Targets {
+			if _, ok := grafanaMetrics[t.Target]; !ok && t.Target != "" {
+				http.Error(w, "unknown target "+strconv.Quote(t.Target), http.StatusBadRequest)
+				return
+			}
+		}
+		points := q.MaxDataPoints
+		if points <= 0 {
+			points = grafanaMaxPoints
+		}
+
+		snaps := thin(b.grafanaRange(from, to), from, to, points)
+		out := []grafanaSeries{}
+		for _, t := range q.Targets {
+			get, ok := grafanaMetrics[t.Target]
+			if !ok {
+				continue // Grafana sends an empty target for a fresh panel
+			}
+			s := grafanaSeries{Target: t.Target, Datapoints: make([][2]float64, len(snaps))}
+			for i := range snaps {
+				s.Datapoints[i] = [2]float64{get(&snaps[i]), float64(snaps[i].Time)}
+			}
+			out = append(out, s)
+		}
+		writeJSON(w, out)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// grafanaRange — snapshots with from <= Time < to from the finest tier that
+// reaches back to from, preceded by CSV history for what no tier covers.
+func (b *Broadcaster) grafanaRange(from, to int64) []model.Snapshot {
+	tier, oldest := finestTier([]*state.RingBuffer{b.buffer, b.history["1m"], b.history["5m"]}, from, to)
+
+	var out []model.Snapshot
+	if from < oldest && b.csvDir != "" {
+		out = state.LoadRangeFromCSV(b.csvDir, from, oldest)
+	}
+	if tier != nil {
+		out = append(out, tier.Range(max(from, oldest), to)...)
+	}
+	return out
+}
+
+// thin keeps the last snapshot of each of `points` equal buckets of
+// [from, to). snaps is in time order.
+func thin(snaps []model.Snapshot, from, to int64, points int) []model.Snapshot {
+	if len(snaps) <= points {
+		return snaps
+	}
+	width := (to - from + int64(points) - 1) / int64(points)
+	out := snaps[:0]
+	for i := range snaps {
+		last := i == len(snaps)-1 || (snaps[i+1].Time-from)/width != (snaps[i].Time-from)/width
+		if last {
+			out = append(out, snaps[i])
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package broadcast
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// ADMIN GUARD — state-changing /admin requests
+// ═══════════════════════════════════════════════════════════════
+//
+// The dashboard port is often public (a VPS, ngrok), and a plain POST can be
+// sent by any web page the operator happens to visit. Every request that
+// changes state — any method but GET / HEAD on /admin/execution,
+// /admin/anchors, /admin/latency and /admin/reload — needs the admin token,
+// and so does every request to /ws/replication (the engine state):
+//
+//   X-Admin-Token: <token>        or        Authorization: Bearer <token>
+//
+// A browser cannot attach either header cross-origin without a CORS
+// preflight, which this server never answers, so the token also stops
+// CSRF. Without a token (SetAdminToken "") only local requests pass: a
+// loopback peer with no X-Forwarded-For / Forwarded (a local proxy or ngrok
+// agent relaying someone else) and no Origin (curl and scripts, not a
+// browser page).
+
+// SetAdminToken sets the token state-changing admin requests must carry
+// ("" = local requests only). Must be called before Start.
+func (b *Broadcaster) SetAdminToken(token string) {
+	b.adminToken = token
+}
+
+// authorized reports whether r may change state.
+func (b *Broadcaster) authorized(r *http.Request) bool {
+	if b.adminToken != "" {
+		got := r.Header.Get("X-Admin-Token")
+		if got == "" {
+			got, _ = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		return subtle.ConstantTimeCompare([]byte(got), []byte(b.adminToken)) == 1
+	}
+	if r.Header.Get("Origin") != "" || r.Header.Get("X-Forwarded-For") != "" || r.Header.Get("Forwarded") != "" {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// guard wraps an admin handler: reads pass, anything else must be
+// authorized.
+func (b *Broadcaster) guard(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && !b.authorized(r) {
+			http.Error(w, "admin token required", http.StatusUnauthorized)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// guardAll wraps an endpoint every request to which must be authorized.
+func (b *Broadcaster) guardAll(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !b.authorized(r) {
+			http.Error(w, "admin token required", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,123 @@
+package broadcast
+
+import (
+	"fmt"
+	"net/url"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/state"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// HISTORY AT A CHOSEN RESOLUTION — /ws?res=1m&span=6h
+// ═══════════════════════════════════════════════════════════════
+//
+// By default a new client gets the whole 1s buffer (3600 snapshots) or a
+// whole tier (?history=1m|5m). A client drawing an hourly chart on a phone
+// needs neither: it names the resolution and how far back instead, and the
+// server cuts the history down before streaming it.
+//
+//   res    bucket width (s, m, h, d: 30s, 1m, 15m, 1h, …)
+//   span   how far back from the newest snapshot (default res × 720)
+//
+// The history comes from the finest tier that reaches back span (1s buffer,
+// then the 1m and 5m tiers, as /grafana does), keeping the last snapshot
+// of each res bucket — the bucket's closing state, like the tiers
+// themselves (state.Downsampler). A res finer than that tier's just gets
+// the tier. Live ticks follow at full rate as usual.
+
+const (
+	historyDefaultPoints = 720
+	historyMaxPoints     = 20000
+	historyMaxSpanMs     = 7 * 86400 * 1000 // the 5m tier
+)
+
+// historyRequest — a client's ?res=&span=, in ms (res 0 = not asked).
+type historyRequest struct {
+	resMs, spanMs int64
+}
+
+// parseHistoryRequest reads ?res= and ?span= (span needs res).
+func parseHistoryRequest(q url.Values) (historyRequest, error) {
+	var hr historyRequest
+	res, span := q.Get("res"), q.Get("span")
+	if res == "" {
+		if span != "" {
+			return hr, fmt.Errorf("span needs res")
+		}
+		return hr, nil
+	}
+	var err error
+	if hr.resMs, err = parseHistoryDuration(res); err != nil {
+		return hr, fmt.Errorf("res: %v", err)
+	}
+	hr.spanMs = hr.resMs * historyDefaultPoints
+	if span != "" {
+		if hr.spanMs, err = parseHistoryDuration(span); err != nil {
+			return hr, fmt.Errorf("span: %v", err)
+		}
+	}
+	hr.spanMs = min(hr.spanMs, historyMaxSpanMs)
+	if hr.spanMs/hr.resMs > historyMaxPoints {
+		return hr, fmt.Errorf("span/res is over %d snapshots", historyMaxPoints)
+	}
+	return hr, nil
+}
+
+// parseHistoryDuration — "30s", "1m", "6h", … in ms (timeframe syntax).
+func parseHistoryDuration(s string) (int64, error) {
+	tfs, err := model.ParseTimeframes(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(tfs) != 1 {
+		return 0, fmt.Errorf("%q: want one duration", s)
+	}
+	return tfs[0].Seconds * 1000, nil
+}
+
+// historyAt — the snapshots a historyRequest asks for, oldest first.
+func (h *Hub) historyAt(hr historyRequest) []model.Snapshot {
+	last, ok := h.buffer.Latest()
+	if !ok {
+		return nil
+	}
+	from, to := last.Time-hr.spanMs, last.Time+1
+	tier, oldest := finestTier([]*state.RingBuffer{h.buffer, h.history["1m"], h.history["5m"]}, from, to)
+	if tier == nil {
+		return nil
+	}
+	return downsample(tier.Range(max(from, oldest), to), hr.resMs)
+}
+
+// finestTier — of tiers (finest first, nil entries skipped), the first that
+// reaches back to from, else the one reaching furthest; and its oldest
+// snapshot time (to if none holds anything).
+func finestTier(tiers []*state.RingBuffer, from, to int64) (*state.RingBuffer, int64) {
+	var tier *state.RingBuffer
+	oldest := to
+	for _, rb := range tiers {
+		if rb == nil {
+			continue
+		}
+		if first, ok := rb.Oldest(); ok && first.Time < oldest {
+			tier, oldest = rb, first.Time
+			if oldest <= from {
+				break
+			}
+		}
+	}
+	return tier, oldest
+}
+
+// downsample keeps the last snapshot of each resMs bucket (aligned to the
+// epoch, as the tiers are), in place. snaps is in time order.
+func downsample(snaps []model.Snapshot, resMs int64) []model.Snapshot {
+	out := snaps[:0]
+	for i := range snaps {
+		if i == len(snaps)-1 || snaps[i+1].Time/resMs != snaps[i].Time/resMs {
+			out = append(out, snaps[i])
+		}
+	}
+	return out
+}
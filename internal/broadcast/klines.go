@@ -0,0 +1,196 @@
+package broadcast
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/state"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// CANDLES — GET /klines
+// ═══════════════════════════════════════════════════════════════
+//
+//   GET /klines?tf=1m[&symbol=BTCUSDT][&from=<ms>][&to=<ms>][&limit=N][&format=array]
+//
+// The candles of one timeframe, oldest first: 1s, 1m or any -timeframes
+// label (5m, 15m, 1h, …). Default: the last limit (500, max 5000) candles up
+// to now. Each candle is an object shaped for TradingView lightweight-charts
+// (candlestick setData takes it as is; time is unix seconds):
+//
+//   {"time", "open", "high", "low", "close", "volume", "buy_volume",
+//    "sell_volume", "delta", "avg_score", "trades", "book"}
+//
+// or, with format=array, Binance-style rows for TA libraries:
+//
+//   [open time ms, open, high, low, close, volume, delta, avg score]
+//
+// book (1m and up, objects only) is the order book over the candle:
+// {"avg_imbalance", "min_imbalance", "max_imbalance", "max_bid_wall",
+// "max_ask_wall", "avg_spread"}, see engine/candlebook.go.
+//
+// The candles are read from the snapshot history — the finest tier no
+// coarser than tf that reaches back to from (1s buffer, 1m, 5m tiers) — so
+// the range is limited to what the tiers hold (1h of 1s, 24h of 1m, 7d of
+// 5m and up). The newest candle is the one in progress. symbol is checked
+// against the engine's (orderflow gateway routes it to the right one).
+
+const (
+	klinesDefaultLimit = 500
+	klinesMaxLimit     = 5000
+)
+
+// Kline is one candle of GET /klines.
+type Kline struct {
+	Time       int64   `json:"time"` // bucket start, unix s
+	Open       float64 `json:"open"`
+	High       float64 `json:"high"`
+	Low        float64 `json:"low"`
+	Close      float64 `json:"close"`
+	Volume     float64 `json:"volume"`
+	BuyVolume  float64 `json:"buy_volume"`
+	SellVolume float64 `json:"sell_volume"`
+	Delta      float64 `json:"delta"`
+	AvgScore   float64 `json:"avg_score"`
+	Trades     int64   `json:"trades"`
+
+	Book *KlineBook `json:"book,omitempty"`
+}
+
+// KlineBook — a candle's order book statistics (model.CandleBook).
+type KlineBook struct {
+	AvgImbalance float64 `json:"avg_imbalance"`
+	MinImbalance float64 `json:"min_imbalance"`
+	MaxImbalance float64 `json:"max_imbalance"`
+	MaxBidWall   float64 `json:"max_bid_wall"`
+	MaxAskWall   float64 `json:"max_ask_wall"`
+	AvgSpread    float64 `json:"avg_spread"`
+}
+
+// klineCandle — a timeframe's candle in a snapshot, and its book
+// statistics (nil for 1s).
+type klineCandle func(s *model.Snapshot) (*model.CandleSnapshot, *model.CandleBook)
+
+// klineSource — where a timeframe's candles sit in a snapshot.
+func klineSource(tf string) (seconds int64, candle klineCandle, ok bool) {
+	switch tf {
+	case "1s":
+		return 1, func(s *model.Snapshot) (*model.CandleSnapshot, *model.CandleBook) { return &s.Candle1s, nil }, true
+	case "1m":
+		return 60, func(s *model.Snapshot) (*model.CandleSnapshot, *model.CandleBook) { return &s.Candle1m, &s.Book1m }, true
+	}
+	for i := range model.HTFs[:model.NumHTF] {
+		if model.HTFs[i].Label == tf {
+			return model.HTFs[i].Seconds, func(s *model.Snapshot) (*model.CandleSnapshot, *model.CandleBook) {
+				return &s.HTF[i], &s.HTFBook[i]
+			}, true
+		}
+	}
+	return 0, nil, false
+}
+
+func serveKlines(b *Broadcaster, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := r.URL.Query()
+	if sym := p.Get("symbol"); sym != "" && !strings.EqualFold(sym, model.Symbol) {
+		http.Error(w, "unknown symbol "+sym, http.StatusNotFound)
+		return
+	}
+	seconds, candle, ok := klineSource(p.Get("tf"))
+	if !ok {
+		labels := []string{"1s", "1m"}
+		for i := range model.HTFs[:model.NumHTF] {
+			labels = append(labels, model.HTFs[i].Label)
+		}
+		http.Error(w, "tf must be one of "+strings.Join(labels, ", "), http.StatusBadRequest)
+		return
+	}
+	limit := klinesDefaultLimit
+	if v := p.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > klinesMaxLimit {
+			http.Error(w, "limit: want 1.."+strconv.Itoa(klinesMaxLimit), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	to, from := time.Now().UnixMilli(), int64(-1)
+	for name, v := range map[string]*int64{"from": &from, "to": &to} {
+		if s := p.Get(name); s != "" {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || n < 0 {
+				http.Error(w, name+": want unix ms", http.StatusBadRequest)
+				return
+			}
+			*v = n
+		}
+	}
+	if from < 0 {
+		from = to - int64(limit)*seconds*1000
+	}
+	if to <= from {
+		http.Error(w, "empty range", http.StatusBadRequest)
+		return
+	}
+
+	klines := b.klines(from, to, seconds, candle)
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	if p.Get("format") != "array" {
+		writeJSON(w, klines)
+		return
+	}
+	rows := make([][8]float64, len(klines))
+	for i, k := range klines {
+		rows[i] = [8]float64{float64(k.Time * 1000), k.Open, k.High, k.Low, k.Close, k.Volume, k.Delta, k.AvgScore}
+	}
+	writeJSON(w, rows)
+}
+
+// klines — the candles starting in [from, to) ms: each one's latest state
+// in the history, i.e. its close (or the live candle so far).
+func (b *Broadcaster) klines(from, to, seconds int64, candle klineCandle) []Kline {
+	var tiers []*state.RingBuffer
+	for _, t := range []struct {
+		seconds int64
+		rb      *state.RingBuffer
+	}{{1, b.buffer}, {60, b.history["1m"]}, {300, b.history["5m"]}} {
+		if t.seconds <= seconds {
+			tiers = append(tiers, t.rb)
+		}
+	}
+	// A candle's closing snapshot comes up to one bucket after its start.
+	tier, oldest := finestTier(tiers, from, to+seconds*1000)
+	if tier == nil {
+		return []Kline{}
+	}
+	out := []Kline{}
+	for _, s := range tier.Range(max(from, oldest), to+seconds*1000) {
+		c, book := candle(&s)
+		if c.Time == 0 || c.Time*1000 < from || c.Time*1000 >= to {
+			continue
+		}
+		k := Kline{
+			Time: c.Time, Open: c.Open, High: c.High, Low: c.Low, Close: c.Close,
+			Volume: c.BuyVol + c.SellVol, BuyVolume: c.BuyVol, SellVolume: c.SellVol,
+			Delta: c.Delta, AvgScore: c.AvgScore, Trades: c.BuyCount + c.SellCount,
+		}
+		if book != nil {
+			kb := KlineBook(*book)
+			k.Book = &kb
+		}
+		if n := len(out); n > 0 && out[n-1].Time == k.Time {
+			out[n-1] = k // a later state of the same candle
+		} else {
+			out = append(out, k)
+		}
+	}
+	return out
+}
@@ -0,0 +1,36 @@
+package broadcast
+
+import (
+	"net/http"
+
+	"market-indikator/internal/latency"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// LATENCY — /admin/latency
+// ═══════════════════════════════════════════════════════════════
+//
+//   GET  /admin/latency  → p50/p99/p999 per stage since the last reset
+//   POST /admin/latency  → reset the histograms (new measurement window)
+//
+// The hub's write pumps record the last stage (recv_to_write) once per
+// frame written to each client; the ingest and engine stages are recorded
+// by the caller (see internal/latency).
+
+// SetLatency enables /admin/latency and write-latency recording. Must be
+// called before Start.
+func (b *Broadcaster) SetLatency(r *latency.Recorder) {
+	b.latency = r
+}
+
+func serveLatency(rec *latency.Recorder, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		rec.Reset()
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, rec.Report())
+}
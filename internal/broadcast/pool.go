@@ -0,0 +1,86 @@
+package broadcast
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// FRAME BUFFER POOL
+// ═══════════════════════════════════════════════════════════════
+//
+// Every snapshot is serialized once (full frame, plus the delta in delta
+// mode and the compact encoding while compact clients are connected) and
+// the same bytes are queued on every client. Allocating them per snapshot —
+// and growing them from a small guess to the ~1–2 KB a snapshot takes — is
+// tens of MB/s of garbage at a busy tape. The buffers come from a pool
+// instead, reference-counted across the clients they are queued on:
+//
+//   hub      takes a buffer (1 ref), encodes, +1 per client (or shard) it
+//            is queued on, then drops its own ref
+//   shard    drops the hub's ref once its clients are queued
+//   client   drops its ref once writePump has written the frame
+//
+// The last release returns the buffer to the pool. Frames still queued when
+// a connection dies are never released; their buffers are simply collected
+// by the GC — a missed reuse, not a leak. History batches (written
+// synchronously in serveWs) borrow a buffer for the whole stream.
+
+// maxPooledBuf — larger buffers (a big history batch) go to the GC rather
+// than pinning memory in the pool.
+const maxPooledBuf = 1 << 20
+
+// sharedBuf — a serialized frame and the number of holders.
+type sharedBuf struct {
+	b    []byte
+	refs int32 // atomic
+}
+
+var bufPool = sync.Pool{
+	New: func() any { return &sharedBuf{b: make([]byte, 0, 2048)} },
+}
+
+// getBuf — an empty buffer with one reference, the caller's.
+func getBuf() *sharedBuf {
+	s := bufPool.Get().(*sharedBuf)
+	s.b = s.b[:0]
+	atomic.StoreInt32(&s.refs, 1)
+	return s
+}
+
+// retain adds a holder. Only while the caller still holds a reference.
+func (s *sharedBuf) retain() {
+	atomic.AddInt32(&s.refs, 1)
+}
+
+// retainFrame adds a holder to a frame's buffers (delta and compact may be
+// nil).
+func retainFrame(full, delta, compact *sharedBuf) {
+	full.retain()
+	if delta != nil {
+		delta.retain()
+	}
+	if compact != nil {
+		compact.retain()
+	}
+}
+
+// releaseFrame drops a reference to a frame's buffers (delta and compact
+// may be nil).
+func releaseFrame(full, delta, compact *sharedBuf) {
+	full.release()
+	if delta != nil {
+		delta.release()
+	}
+	if compact != nil {
+		compact.release()
+	}
+}
+
+// release drops a holder; the last one returns the buffer to the pool. The
+// caller must not touch s.b afterwards.
+func (s *sharedBuf) release() {
+	if atomic.AddInt32(&s.refs, -1) == 0 && cap(s.b) <= maxPooledBuf {
+		bufPool.Put(s)
+	}
+}
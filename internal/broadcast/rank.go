@@ -0,0 +1,118 @@
+package broadcast
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"market-indikator/internal/state"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// WATCHLIST RANKING — GET /rank
+// ═══════════════════════════════════════════════════════════════
+//
+//   GET /rank[?by=score|velocity|oi|volume]
+//
+// One entry per tracked symbol, read from each symbol's latest snapshot (no
+// engine work), sorted by the chosen key in descending magnitude:
+//
+//   score     |FinalScore|
+//   velocity  |score velocity| (points/s)
+//   oi        |1-minute OI change| in percent of OI
+//   volume    tape intensity z (live second's trades vs 5-minute baseline)
+//
+// Symbols without a snapshot yet are listed last with zero values.
+
+// RankEntry is one row of GET /rank.
+type RankEntry struct {
+	Symbol          string  `json:"symbol"`
+	Time            int64   `json:"time"`   // snapshot time, unix ms
+	AgeMs           int64   `json:"age_ms"` // wall-clock age of the snapshot (-1 = none)
+	Price           float64 `json:"price"`
+	FinalScore      float64 `json:"final_score"`
+	ScoreVelocity   float64 `json:"score_velocity"`
+	ScorePercentile float64 `json:"score_percentile"`
+	OIDelta1mPct    float64 `json:"oi_delta_1m_pct"`
+	VolumeZ         float64 `json:"volume_z"`
+	TradesPerSec    float64 `json:"trades_per_sec"`
+}
+
+// rankKeys — the sort keys of GET /rank.
+var rankKeys = map[string]func(e *RankEntry) float64{
+	"score":    func(e *RankEntry) float64 { return math.Abs(e.FinalScore) },
+	"velocity": func(e *RankEntry) float64 { return math.Abs(e.ScoreVelocity) },
+	"oi":       func(e *RankEntry) float64 { return math.Abs(e.OIDelta1mPct) },
+	"volume":   func(e *RankEntry) float64 { return e.VolumeZ },
+}
+
+// SortRank orders entries as GET /rank does: by key (score, velocity, oi or
+// volume), symbols without a snapshot (AgeMs < 0) last. False for an unknown
+// key.
+func SortRank(entries []RankEntry, by string) bool {
+	key, ok := rankKeys[by]
+	if !ok {
+		return false
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if mi, mj := entries[i].AgeMs < 0, entries[j].AgeMs < 0; mi != mj {
+			return mj
+		}
+		return key(&entries[i]) > key(&entries[j])
+	})
+	return true
+}
+
+// AddSymbol registers a symbol's live snapshot buffer for /rank. Must be
+// called before Start.
+func (b *Broadcaster) AddSymbol(symbol string, rb *state.RingBuffer) {
+	b.symbols = append(b.symbols, rankedSymbol{symbol, rb})
+}
+
+type rankedSymbol struct {
+	symbol string
+	buffer *state.RingBuffer
+}
+
+func serveRank(symbols []rankedSymbol, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	by := r.URL.Query().Get("by")
+	if by == "" {
+		by = "score"
+	}
+	if _, ok := rankKeys[by]; !ok {
+		http.Error(w, "by must be score, velocity, oi or volume", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UnixMilli()
+	out := make([]RankEntry, 0, len(symbols))
+	for _, s := range symbols {
+		snap, ok := s.buffer.Latest()
+		if !ok {
+			out = append(out, RankEntry{Symbol: s.symbol, AgeMs: -1})
+			continue
+		}
+		e := RankEntry{
+			Symbol:          s.symbol,
+			Time:            snap.Time,
+			AgeMs:           now - snap.Time,
+			Price:           snap.Price,
+			FinalScore:      snap.FinalScore,
+			ScoreVelocity:   snap.ScoreDyn.Velocity,
+			ScorePercentile: snap.ScoreDyn.Percentile,
+			VolumeZ:         snap.Flow.IntensityZ,
+			TradesPerSec:    snap.Flow.TradesPerSec,
+		}
+		if base := snap.OI.OI - snap.OI.OIDelta1m; base > 0 {
+			e.OIDelta1mPct = snap.OI.OIDelta1m / base * 100
+		}
+		out = append(out, e)
+	}
+	SortRank(out, by)
+	writeJSON(w, out)
+}
@@ -0,0 +1,26 @@
+package broadcast
+
+import (
+	"net/http"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// CONFIG RELOAD — POST /admin/reload
+// ═══════════════════════════════════════════════════════════════
+//
+// Re-reads the -config file, like SIGHUP, and answers with what was applied,
+// what needs a restart and what was rejected (see internal/config).
+
+// SetReload enables /admin/reload; fn performs the reload and returns its
+// JSON-able result. Must be called before Start.
+func (b *Broadcaster) SetReload(fn func() any) {
+	b.reload = fn
+}
+
+func serveReload(fn func() any, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, fn())
+}
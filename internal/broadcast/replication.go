@@ -0,0 +1,19 @@
+package broadcast
+
+import (
+	"net/http"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// REPLICATION — GET /ws/replication
+// ═══════════════════════════════════════════════════════════════
+//
+// The engine checkpoint and ring buffers streamed to warm standbys (see
+// internal/replication). Served on the dashboard port, like /ws, but only
+// to authorized requests (guard.go): the standby sends the admin token.
+
+// SetReplication enables /ws/replication with h (a replication.Source).
+// Must be called before Start.
+func (b *Broadcaster) SetReplication(h http.Handler) {
+	b.replicate = h
+}
@@ -0,0 +1,27 @@
+package broadcast
+
+import (
+	"net/http"
+
+	"market-indikator/internal/risk"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// RISK — GET /admin/risk
+// ═══════════════════════════════════════════════════════════════
+//
+// The risk book (see internal/risk): position, exposure, PnL, drawdown,
+// latched breach, veto count and the configured limits.
+
+// SetRisk enables /admin/risk. Must be called before Start.
+func (b *Broadcaster) SetRisk(m *risk.Manager) {
+	b.risk = m
+}
+
+func serveRisk(m *risk.Manager, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, m.GetState())
+}
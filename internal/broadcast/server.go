@@ -3,37 +3,210 @@ package broadcast
 import (
 	"log"
 	"net/http"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"time"
 
+	"market-indikator/internal/evaluation"
+	"market-indikator/internal/execution"
+	"market-indikator/internal/latency"
 	"market-indikator/internal/model"
+	"market-indikator/internal/risk"
 	"market-indikator/internal/state"
+	"market-indikator/internal/summary"
 
 	"github.com/gorilla/websocket"
 )
 
+// Keepalive timing. The server pings every pingPeriod; a client that has not
+// answered (or sent anything) within pongWait is treated as dead. This catches
+// half-open connections (laptop sleep, NAT timeout) in seconds rather than
+// waiting for a TCP error that may never come.
+const (
+	writeWait      = 5 * time.Second
+	pongWait       = 15 * time.Second
+	pingPeriod     = pongWait * 9 / 10
+	maxMessageSize = 4096
+)
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // Allow all origins for now
 	},
 }
 
+// Options tunes the fan-out behavior.
+type Options struct {
+	// MaxConsecutiveDrops disconnects a client after this many ticks in a row
+	// were dropped because its send queue was full. 0 = never disconnect.
+	MaxConsecutiveDrops int
+
+	// Coalesce switches from one frame per trade to one frame per interval
+	// carrying only the latest snapshot (conflation). 0 = per-trade frames.
+	Coalesce time.Duration
+
+	// HistoryBatch is the number of history snapshots packed into one WS
+	// frame for new clients. 1 = one bare snapshot per frame (legacy).
+	HistoryBatch int
+
+	// DeltaKeyframe enables delta-encoded live frames: a full snapshot every
+	// DeltaKeyframe frames and compact diffs (see model.AppendDelta) between.
+	// Only clients that opt in with ?delta=1 get diffs; the rest keep full
+	// snapshots. Clients that dropped a frame or just joined get a full
+	// snapshot on their next tick. 0 = always send full snapshots.
+	DeltaKeyframe int
+
+	// Compression negotiates permessage-deflate with clients that offer it.
+	// History is always compressed when negotiated (3600 snapshots of mostly
+	// repeated floats shrink several-fold); live ticks only with CompressLive,
+	// since per-frame deflate costs CPU per client for ~100-byte payloads.
+	// zstd is not an option: browsers only implement permessage-deflate.
+	Compression      bool
+	CompressionLevel int // flate level 1 (fast) … 9 (small)
+	CompressLive     bool
+
+	// HubShards splits the fan-out over this many goroutines, each owning a
+	// share of the clients (see shard.go). Snapshots are still serialized
+	// once, by the hub. 0 or 1 = the hub goroutine fans out itself.
+	HubShards int
+
+	// PinThreads locks the hub and shard goroutines to their own OS
+	// threads (runtime.LockOSThread).
+	PinThreads bool
+}
+
+// DefaultOptions — a client that misses ~10s of busy-market ticks in a row is
+// not keeping up and is cut loose.
+func DefaultOptions() Options {
+	return Options{
+		MaxConsecutiveDrops: 1000,
+		HistoryBatch:        100,
+		Compression:         true,
+		CompressionLevel:    1,
+	}
+}
+
 // Broadcaster receives Snapshots from the engine and fans them out to WS clients.
 type Broadcaster struct {
-	input  <-chan model.Snapshot
-	buffer *state.RingBuffer
+	input     <-chan model.Snapshot
+	buffer    *state.RingBuffer
+	opts      Options
+	counters  []counter // extra numbers surfaced in /admin/stats
+	history   map[string]*state.RingBuffer
+	anchors   AnchorEngine        // nil = /admin/anchors disabled
+	eval      *evaluation.Tracker // nil = /admin/eval disabled
+	symbols   []rankedSymbol      // empty = /rank disabled
+	exec      *execution.Executor // nil = /admin/execution disabled
+	risk      *risk.Manager       // nil = /admin/risk disabled
+	latency   *latency.Recorder   // nil = /admin/latency disabled
+	signals   <-chan model.Signal // nil = /ws/signals disabled
+	summaries *summary.Tracker    // nil = /summary disabled
+	reload    func() any          // nil = /admin/reload disabled
+	replicate http.Handler        // nil = /ws/replication disabled
+	grafana   bool                // /grafana/* enabled
+	csvDir    string              // CSV history for /grafana/query
+
+	adminToken string // "" = state-changing admin requests from localhost only (guard.go)
 }
 
-func NewBroadcaster(input <-chan model.Snapshot, buffer *state.RingBuffer) *Broadcaster {
-	return &Broadcaster{input: input, buffer: buffer}
+func NewBroadcaster(input <-chan model.Snapshot, buffer *state.RingBuffer, opts Options) *Broadcaster {
+	return &Broadcaster{input: input, buffer: buffer, opts: opts, history: make(map[string]*state.RingBuffer)}
+}
+
+// AddHistory registers an alternate history buffer (e.g. a downsampled
+// long-horizon tier) that clients can ask for with /ws?history=<name>.
+// Must be called before Start.
+func (b *Broadcaster) AddHistory(name string, rb *state.RingBuffer) {
+	b.history[name] = rb
 }
 
 // Start launches the broadcast loop and HTTP server.
 func (b *Broadcaster) Start(addr string) {
-	hub := newHub(b.buffer)
+	upgrader.EnableCompression = b.opts.Compression
+	hub := newHub(b.buffer, b.opts)
+	hub.history = b.history
+	hub.latency = b.latency
 	go hub.run(b.input)
 
+	http.HandleFunc("/admin/stats", func(w http.ResponseWriter, r *http.Request) {
+		serveStats(hub, b.counters, w, r)
+	})
+	http.HandleFunc("/version", serveVersion)
+	http.HandleFunc("/klines", func(w http.ResponseWriter, r *http.Request) {
+		serveKlines(b, w, r)
+	})
+
+	if b.anchors != nil {
+		http.HandleFunc("/admin/anchors", b.guard(func(w http.ResponseWriter, r *http.Request) {
+			serveAnchors(b, w, r)
+		}))
+	}
+
+	if b.eval != nil {
+		http.HandleFunc("/admin/eval", func(w http.ResponseWriter, r *http.Request) {
+			serveEval(b.eval, w, r)
+		})
+	}
+
+	if b.exec != nil {
+		http.HandleFunc("/admin/execution", b.guard(func(w http.ResponseWriter, r *http.Request) {
+			serveExecution(b.exec, w, r)
+		}))
+	}
+
+	if b.risk != nil {
+		http.HandleFunc("/admin/risk", func(w http.ResponseWriter, r *http.Request) {
+			serveRisk(b.risk, w, r)
+		})
+	}
+
+	if b.latency != nil {
+		http.HandleFunc("/admin/latency", b.guard(func(w http.ResponseWriter, r *http.Request) {
+			serveLatency(b.latency, w, r)
+		}))
+	}
+
+	if b.reload != nil {
+		http.HandleFunc("/admin/reload", b.guard(func(w http.ResponseWriter, r *http.Request) {
+			serveReload(b.reload, w, r)
+		}))
+	}
+
+	if b.replicate != nil {
+		http.Handle("/ws/replication", b.guardAll(b.replicate))
+	}
+
+	if b.summaries != nil {
+		http.HandleFunc("/summary", func(w http.ResponseWriter, r *http.Request) {
+			serveSummary(b.summaries, w, r)
+		})
+	}
+
+	if b.grafana {
+		http.HandleFunc("/grafana/", func(w http.ResponseWriter, r *http.Request) {
+			serveGrafana(b, w, r)
+		})
+	}
+
+	if len(b.symbols) > 0 {
+		http.HandleFunc("/rank", func(w http.ResponseWriter, r *http.Request) {
+			serveRank(b.symbols, w, r)
+		})
+	}
+
+	if b.signals != nil {
+		sh := newSignalHub()
+		go sh.run(b.signals)
+		http.HandleFunc("/ws/signals", func(w http.ResponseWriter, r *http.Request) {
+			serveSignals(sh, w, r)
+		})
+	}
+
 	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
 		serveWs(hub, w, r)
 	})
+	http.Handle("/", dashboardHandler())
 
 	log.Printf("Broadcaster listening on %s", addr)
 	if err := http.ListenAndServe(addr, nil); err != nil {
@@ -46,52 +219,262 @@ type Hub struct {
 	clients    map[*Client]bool
 	register   chan *Client
 	unregister chan *Client
+	statsReq   chan chan []ClientStats
 	buffer     *state.RingBuffer
+	history    map[string]*state.RingBuffer // read-only after Start
+	latency    *latency.Recorder            // nil = no write-latency samples; read-only after Start
+	opts       Options
+
+	// Delta encoding state (hub goroutine only).
+	frames *FrameEncoder
+
+	// Clients connected with ?encoding=compact (model/compact.go), hub
+	// goroutine only: while there are any, each snapshot is also encoded
+	// compact.
+	compactClients int
+
+	// Sharded fan-out (HubShards > 1): the shards and each client's shard,
+	// hub goroutine only. clients stays empty.
+	shards []*fanShard
+	owner  map[*Client]*fanShard
+
+	// Throughput — written by run(), read by the stats handler.
+	snapshots int64 // atomic, total snapshots fanned out
+	rate      int64 // atomic, snapshots in the last full second
 }
 
-func newHub(buffer *state.RingBuffer) *Hub {
+func newHub(buffer *state.RingBuffer, opts Options) *Hub {
 	return &Hub{
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		statsReq:   make(chan chan []ClientStats),
 		clients:    make(map[*Client]bool),
 		buffer:     buffer,
+		opts:       opts,
+		frames:     NewFrameEncoder(opts.DeltaKeyframe),
+		owner:      make(map[*Client]*fanShard),
 	}
 }
 
 func (h *Hub) run(input <-chan model.Snapshot) {
+	if h.opts.PinThreads {
+		runtime.LockOSThread()
+	}
+	for i := 0; h.opts.HubShards > 1 && i < h.opts.HubShards; i++ {
+		sh := newFanShard(h)
+		h.shards = append(h.shards, sh)
+		go sh.run()
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	var lastCount int64
+
+	// Coalesced mode: hold only the newest snapshot and flush it on each tick.
+	// tickC stays nil (blocks forever) in per-trade mode.
+	var (
+		tickC   <-chan time.Time
+		latest  model.Snapshot
+		pending bool
+	)
+	if h.opts.Coalesce > 0 {
+		coalesce := time.NewTicker(h.opts.Coalesce)
+		defer coalesce.Stop()
+		tickC = coalesce.C
+	}
+
 	for {
 		select {
+		case <-tickC:
+			if pending {
+				h.broadcast(&latest)
+				pending = false
+			}
+		case <-ticker.C:
+			count := atomic.LoadInt64(&h.snapshots)
+			atomic.StoreInt64(&h.rate, count-lastCount)
+			lastCount = count
+		case reply := <-h.statsReq:
+			if h.shards != nil {
+				go h.shardStats(reply)
+				continue
+			}
+			reply <- clientStats(h.clients)
 		case client := <-h.register:
+			client.needsKey = true
+			if client.encoding == model.EncodingCompact {
+				h.compactClients++
+			}
+			if h.shards != nil {
+				h.assign(client)
+				continue
+			}
 			h.clients[client] = true
 			log.Printf("Client connected (%d total)", len(h.clients))
 		case client := <-h.unregister:
+			if client.encoding == model.EncodingCompact {
+				h.compactClients--
+			}
+			if h.shards != nil {
+				h.release(client)
+				continue
+			}
 			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
+				remove(h.clients, client)
 				log.Printf("Client disconnected (%d total)", len(h.clients))
 			}
 		case snap := <-input:
-			// Serialize ONCE per snapshot.
-			msg := snap.AppendMsgPack(make([]byte, 0, 128))
-
-			// Fan-out to all connected clients.
-			for client := range h.clients {
-				select {
-				case client.send <- msg:
-				default:
-					// Slow client — drop this tick, don't kill.
-					// Client will catch up on next tick.
-					// Dead clients are cleaned up via readPump.
-				}
+			if tickC != nil {
+				latest = snap
+				pending = true
+				continue
 			}
+			h.broadcast(&snap)
 		}
 	}
 }
 
+// broadcast serializes a snapshot once and fans it out to every client,
+// itself or through the shards. Hub goroutine only.
+func (h *Hub) broadcast(snap *model.Snapshot) {
+	// Delta mode: everyone in sync gets the diff against the previous frame;
+	// keyframes and out-of-sync clients get the full snapshot.
+	// Pooled buffers (pool.go), shared by every client they are queued on.
+	full, delta := getBuf(), getBuf()
+	fb, db := h.frames.AppendEncode(full.b, delta.b, snap)
+	full.b = fb
+	if db == nil {
+		delta.release()
+		delta = nil
+	} else {
+		delta.b = db
+	}
+	var compact *sharedBuf
+	if h.compactClients > 0 {
+		compact = getBuf()
+		compact.b = snap.AppendEncoded(compact.b, model.EncodingCompact)
+	}
+	atomic.AddInt64(&h.snapshots, 1)
+
+	if h.shards != nil {
+		for _, sh := range h.shards {
+			retainFrame(full, delta, compact)
+			sh.in <- shardMsg{full: full, delta: delta, compact: compact, recvNs: snap.RecvNs}
+		}
+	} else {
+		h.deliver(h.clients, full, delta, compact, snap.RecvNs)
+	}
+	releaseFrame(full, delta, compact)
+}
+
+// deliver queues a frame on every client in clients. Called by the
+// clients' owner: the hub goroutine, or their shard's, holding a reference
+// to full, delta (nil outside delta mode) and compact (nil without compact
+// clients) until it returns. Only clients that asked for ?delta=1 get
+// deltas, and compact clients never do.
+func (h *Hub) deliver(clients map[*Client]bool, full, delta, compact *sharedBuf, recvNs int64) {
+	for client := range clients {
+		msg := full
+		switch {
+		case client.encoding == model.EncodingCompact:
+			if msg = compact; msg == nil {
+				continue // registered after this frame was encoded
+			}
+		case delta != nil && client.delta && !client.needsKey:
+			msg = delta
+		}
+		msg.retain()
+		select {
+		case client.send <- frame{buf: msg, recvNs: recvNs}:
+			client.consecutiveDrops = 0
+			client.needsKey = false
+		default:
+			msg.release()
+			client.needsKey = true // missed a frame — its delta base is gone
+			// Slow client — drop this tick. A short stall catches up on
+			// the next tick; a client that stays full past the limit is
+			// disconnected so it stops pinning memory.
+			client.drops++
+			client.consecutiveDrops++
+			limit := h.opts.MaxConsecutiveDrops
+			if limit > 0 && client.consecutiveDrops >= limit {
+				client.closeReason = "too slow: send queue full"
+				remove(clients, client)
+				log.Printf("Client %s disconnected after %d consecutive drops",
+					client.conn.RemoteAddr(), client.consecutiveDrops)
+			}
+		}
+	}
+}
+
+// FrameEncoder — the live-frame encoding of the hub, usable on its own (see
+// internal/harness): every snapshot as a full MsgPack frame and, in delta
+// mode, the diff against the previous snapshot. Not safe for concurrent use.
+type FrameEncoder struct {
+	keyframe int // 0 = no deltas
+	prevFlat model.Flat
+	curFlat  model.Flat
+	prevLen  int
+	curLen   int
+	frameNo  int
+}
+
+// NewFrameEncoder — deltaKeyframe as Options.DeltaKeyframe.
+func NewFrameEncoder(deltaKeyframe int) *FrameEncoder {
+	return &FrameEncoder{keyframe: deltaKeyframe}
+}
+
+// Encode returns the snapshot's full frame and its delta frame (nil on
+// keyframes and outside delta mode).
+func (e *FrameEncoder) Encode(snap *model.Snapshot) (full, delta []byte) {
+	return e.AppendEncode(make([]byte, 0, 128), nil, snap)
+}
+
+// AppendEncode — Encode appending to fullBuf and deltaBuf (the hub's pooled
+// buffers). delta is nil when there is no delta frame, whatever deltaBuf.
+func (e *FrameEncoder) AppendEncode(fullBuf, deltaBuf []byte, snap *model.Snapshot) (full, delta []byte) {
+	full = snap.AppendMsgPack(fullBuf)
+	if n := e.keyframe; n > 0 {
+		e.prevFlat, e.prevLen = e.curFlat, e.curLen
+		e.curLen = snap.Flatten(&e.curFlat)
+		// A layout change (anchor added/removed) forces a keyframe
+		if e.frameNo%n != 0 && e.curLen == e.prevLen {
+			delta = model.AppendDelta(deltaBuf, &e.prevFlat, &e.curFlat, e.curLen)
+		}
+		e.frameNo++
+	}
+	return full, delta
+}
+
+// remove — drops a client from its owner's set and closes its send queue,
+// which tells writePump to send a close frame (with closeReason, if set) and
+// exit. The set's owner goroutine only.
+func remove(clients map[*Client]bool, c *Client) {
+	delete(clients, c)
+	close(c.send)
+}
+
+// frame — one queued live frame (a reference to its pooled buffer, released
+// once written) and the receive time of the trade behind it (0 = heartbeat),
+// for the recv_to_write latency stage.
+type frame struct {
+	buf    *sharedBuf
+	recvNs int64
+}
+
 type Client struct {
 	hub  *Hub
 	conn *websocket.Conn
-	send chan []byte
+	send chan frame
+
+	connectedAt time.Time
+	encoding    model.Encoding // ?encoding=, fixed at connect
+	delta       bool           // ?delta=1: accepts delta frames in delta mode
+
+	// Owner goroutine only (the hub's, or the client's shard's).
+	drops            int64  // ticks dropped because send was full
+	consecutiveDrops int    // drops since the last successful enqueue
+	closeReason      string // set before close(send); read by writePump after
+	needsKey         bool   // delta mode: next frame must be a full snapshot
 }
 
 // ═══════════════════════════════════════════════════════════════
@@ -99,50 +482,94 @@ type Client struct {
 // ═══════════════════════════════════════════════════════════════
 //
 // Instead of sending one giant MsgPack array (which blocks JS decode),
-// we stream history as individual small messages:
+// we stream history as small batches:
 //
+//   Message 0: Descriptor {tf: [[label, seconds], ...], ribbon: [periods], ...}
+//              naming the htf entries and EMA ribbon of each snapshot,
+//              plus the server build, wire version and encoding
+//              (see model.AppendDescriptor)
 //   Message 1: MsgPack uint32 = count of history snapshots
-//   Message 2..N+1: Individual FixArray(9) snapshots (~128 bytes each)
-//   After: Client registered for live FixArray(9) ticks
+//   Message 2..: Array of up to HistoryBatch snapshots (see model.Snapshot)
+//                (HistoryBatch=1 sends bare snapshots, one per message)
+//   After: Client registered for live snapshot ticks
 //
 // Frontend detects the header (typeof decoded === 'number') and
 // shows a loading progress bar until all history snapshots arrive.
-// Each individual message decodes in <0.1ms — zero main thread blocking.
+// A batch is told apart from a live tick by its first element being an
+// array. 100 snapshots ≈ 13KB per frame — still well under 1ms to decode,
+// but ~100x fewer WriteMessage calls (and round-trips through the TCP
+// stack) than one frame per snapshot.
 
 func serveWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
+	enc, err := model.ParseEncoding(r.URL.Query().Get("encoding"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// ?delta=1 opts in to delta frames (Options.DeltaKeyframe); legacy
+	// readers that expect every tick as a full snapshot leave it off.
+	var delta bool
+	if v := r.URL.Query().Get("delta"); v != "" {
+		if delta, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "invalid delta: "+v, http.StatusBadRequest)
+			return
+		}
+	}
+	hr, err := parseHistoryRequest(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	client := &Client{hub: hub, conn: conn, send: make(chan []byte, 4096)}
+	client := &Client{hub: hub, conn: conn, send: make(chan frame, 4096), connectedAt: time.Now(), encoding: enc, delta: delta}
 
-	// Send full history BEFORE registering for live ticks
-	if hub.buffer != nil {
-		snapshots := hub.buffer.GetAll()
+	// Compression only takes effect if the client negotiated the extension.
+	if hub.opts.Compression {
+		if err := conn.SetCompressionLevel(hub.opts.CompressionLevel); err != nil {
+			log.Printf("Invalid compression level %d: %v", hub.opts.CompressionLevel, err)
+		}
+		conn.EnableWriteCompression(true)
+	}
+
+	// Descriptor first, so the client can label htf candles and ribbon EMAs.
+	if err := conn.WriteMessage(websocket.BinaryMessage, model.AppendDescriptor(nil, client.encoding)); err != nil {
+		log.Printf("Descriptor write failed: %v", err)
+		conn.Close()
+		return
+	}
+
+	// Send full history BEFORE registering for live ticks.
+	// ?history=<name> selects a long-horizon tier instead of the 1s buffer;
+	// ?res=&span= a resolution and range (history.go).
+	source := hub.buffer
+	if name := r.URL.Query().Get("history"); name != "" {
+		if rb, ok := hub.history[name]; ok {
+			source = rb
+		}
+	}
+	if source != nil {
+		var snapshots []model.Snapshot
+		if hr.resMs > 0 {
+			snapshots = hub.historyAt(hr)
+		} else {
+			snapshots = source.GetAll()
+		}
 		if len(snapshots) > 0 {
-			// 1. Send count header (MsgPack uint32: 0xce + 4 bytes big-endian)
-			n := uint32(len(snapshots))
-			header := []byte{0xce, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
-			if err := conn.WriteMessage(websocket.BinaryMessage, header); err != nil {
-				log.Printf("Failed to send history header: %v", err)
+			if err := streamHistory(conn, snapshots, hub.opts.HistoryBatch, client.encoding); err != nil {
+				log.Printf("History stream interrupted: %v", err)
 				conn.Close()
 				return
 			}
-
-			// 2. Stream each snapshot as individual message
-			for _, snap := range snapshots {
-				msg := snap.AppendMsgPack(make([]byte, 0, 128))
-				if err := conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
-					log.Printf("History stream interrupted after %d snapshots: %v", n, err)
-					conn.Close()
-					return
-				}
-			}
 			log.Printf("Streamed %d history snapshots to new client", len(snapshots))
 		}
 	}
 
+	conn.EnableWriteCompression(hub.opts.Compression && hub.opts.CompressLive)
+
 	// Register for live ticks
 	client.hub.register <- client
 
@@ -150,38 +577,100 @@ func serveWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
 	go client.readPump()
 }
 
+// streamHistory writes the count header followed by the snapshots in
+// batches of up to batch per frame, in the client's encoding.
+func streamHistory(conn *websocket.Conn, snapshots []model.Snapshot, batch int, enc model.Encoding) error {
+	// 1. Count header (MsgPack uint32: 0xce + 4 bytes big-endian)
+	n := uint32(len(snapshots))
+	header := []byte{0xce, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+	if err := conn.WriteMessage(websocket.BinaryMessage, header); err != nil {
+		return err
+	}
+
+	// 2. Snapshots, batched, in one pooled buffer (WriteMessage copies)
+	if batch < 1 {
+		batch = 1
+	}
+	buf := getBuf()
+	defer buf.release()
+	for start := 0; start < len(snapshots); start += batch {
+		end := start + batch
+		if end > len(snapshots) {
+			end = len(snapshots)
+		}
+
+		buf.b = buf.b[:0]
+		if batch > 1 {
+			buf.b = model.AppendArrayHeader(buf.b, end-start)
+		}
+		for i := start; i < end; i++ {
+			buf.b = snapshots[i].AppendEncoded(buf.b, enc)
+		}
+		if err := conn.WriteMessage(websocket.BinaryMessage, buf.b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Client) readPump() {
 	defer func() {
 		c.hub.unregister <- c
 		c.conn.Close()
 	}()
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
 	for {
 		_, _, err := c.conn.ReadMessage()
 		if err != nil {
 			break
 		}
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	}
 }
 
 func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
 	defer func() {
+		ticker.Stop()
 		c.conn.Close()
 	}()
 	for {
-		message, ok := <-c.send
-		if !ok {
-			c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-			return
-		}
+		select {
+		case f, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				msg := []byte{}
+				if c.closeReason != "" {
+					msg = websocket.FormatCloseMessage(websocket.ClosePolicyViolation, c.closeReason)
+				}
+				c.conn.WriteMessage(websocket.CloseMessage, msg)
+				return
+			}
 
-		w, err := c.conn.NextWriter(websocket.BinaryMessage)
-		if err != nil {
-			return
-		}
-		w.Write(message)
+			w, err := c.conn.NextWriter(websocket.BinaryMessage)
+			if err != nil {
+				return
+			}
+			w.Write(f.buf.b)
 
-		if err := w.Close(); err != nil {
-			return
+			err = w.Close()
+			f.buf.release()
+			if err != nil {
+				return
+			}
+			if f.recvNs != 0 && c.hub.latency != nil {
+				c.hub.latency.Observe(latency.RecvToWrite, time.Now().UnixNano()-f.recvNs)
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		}
 	}
 }
@@ -0,0 +1,108 @@
+package broadcast
+
+import (
+	"log"
+	"runtime"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// SHARDED FAN-OUT — Options.HubShards
+// ═══════════════════════════════════════════════════════════════
+//
+// The hub serializes each snapshot once and then walks every client's send
+// queue itself, so a burst of connecting clients or one huge audience adds
+// latency to the next snapshot for all of them. With HubShards > 1 the walk
+// is split: each shard goroutine owns a share of the clients, and the hub
+// only hands the encoded frames (full, delta, compact) to every shard.
+//
+//   hub     serialize, delta-encode, throttle counters; assigns each new
+//           client to the shard with the fewest
+//   shard   the per-client enqueue, drop accounting and slow-client
+//           disconnects, exactly as the unsharded hub does them
+//
+// Registration, frames and removals reach a shard over one channel, in
+// order: a client never sees a frame encoded before it was registered. The
+// process serves one symbol (orderflow gateway spreads symbols over
+// processes), so shards split clients, not symbols.
+
+// shardMsg — one message to a shard: a frame (full != nil), a client to add
+// or drop, or a stats request.
+type shardMsg struct {
+	full, delta *sharedBuf // one reference each, released by the shard
+	compact     *sharedBuf // likewise; nil without compact clients
+	recvNs      int64
+	add, drop   *Client
+	stats       chan<- []ClientStats
+}
+
+type fanShard struct {
+	hub     *Hub
+	in      chan shardMsg
+	clients map[*Client]bool // shard goroutine only
+	load    int              // clients assigned, hub goroutine only
+}
+
+func newFanShard(h *Hub) *fanShard {
+	return &fanShard{hub: h, in: make(chan shardMsg, 256), clients: make(map[*Client]bool)}
+}
+
+func (s *fanShard) run() {
+	if s.hub.opts.PinThreads {
+		runtime.LockOSThread()
+	}
+	for m := range s.in {
+		switch {
+		case m.full != nil:
+			s.hub.deliver(s.clients, m.full, m.delta, m.compact, m.recvNs)
+			releaseFrame(m.full, m.delta, m.compact)
+		case m.add != nil:
+			s.clients[m.add] = true
+		case m.drop != nil:
+			if s.clients[m.drop] {
+				remove(s.clients, m.drop)
+			}
+		case m.stats != nil:
+			m.stats <- clientStats(s.clients)
+		}
+	}
+}
+
+// assign hands a new client to the least loaded shard. Hub goroutine only.
+func (h *Hub) assign(c *Client) {
+	best := h.shards[0]
+	for _, sh := range h.shards[1:] {
+		if sh.load < best.load {
+			best = sh
+		}
+	}
+	best.load++
+	h.owner[c] = best
+	best.in <- shardMsg{add: c}
+	log.Printf("Client connected (%d total)", len(h.owner))
+}
+
+// release drops a disconnected client from its shard. Hub goroutine only.
+func (h *Hub) release(c *Client) {
+	sh, ok := h.owner[c]
+	if !ok {
+		return
+	}
+	delete(h.owner, c)
+	sh.load--
+	sh.in <- shardMsg{drop: c}
+	log.Printf("Client disconnected (%d total)", len(h.owner))
+}
+
+// shardStats collects every shard's clients for /admin/stats. Off the hub
+// goroutine, so a busy shard never stalls it.
+func (h *Hub) shardStats(reply chan<- []ClientStats) {
+	parts := make(chan []ClientStats, len(h.shards))
+	for _, sh := range h.shards {
+		sh.in <- shardMsg{stats: parts}
+	}
+	out := []ClientStats{}
+	for range h.shards {
+		out = append(out, <-parts...)
+	}
+	reply <- out
+}
@@ -0,0 +1,158 @@
+package broadcast
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"market-indikator/internal/model"
+
+	"github.com/gorilla/websocket"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// SCORE SIGNALS — /ws/signals
+// ═══════════════════════════════════════════════════════════════
+//
+// A channel of its own next to /ws: one JSON text frame per signal state
+// change (see internal/signals), so automation can act on discrete events
+// without decoding snapshots. A new client first gets the last
+// signalReplay state changes, oldest first. A client whose queue fills is
+// disconnected — signals must not be skipped silently.
+
+const signalReplay = 100
+
+// SignalInfo is one /ws/signals frame.
+type SignalInfo struct {
+	ID          int64   `json:"id"`
+	Side        string  `json:"side"`  // long / short
+	State       string  `json:"state"` // armed / triggered / completed / invalidated
+	Reason      string  `json:"reason"`
+	Time        int64   `json:"time"` // this state change, unix ms
+	ArmedAt     int64   `json:"armed_at"`
+	TriggeredAt int64   `json:"triggered_at,omitempty"`
+	ClosedAt    int64   `json:"closed_at,omitempty"`
+	ArmPrice    float64 `json:"arm_price"`
+	EntryPrice  float64 `json:"entry_price,omitempty"`
+	ExitPrice   float64 `json:"exit_price,omitempty"`
+	Score       float64 `json:"score"`
+	ReturnBps   float64 `json:"return_bps,omitempty"`
+}
+
+// SetSignals enables /ws/signals, fed from ch (typically a bus.Signals
+// subscription). Must be called before Start.
+func (b *Broadcaster) SetSignals(ch <-chan model.Signal) {
+	b.signals = ch
+}
+
+// signalHub — the /ws/signals clients and the replay buffer.
+type signalHub struct {
+	mu      sync.Mutex
+	clients map[chan []byte]bool
+	recent  [][]byte // encoded frames, oldest first
+}
+
+func newSignalHub() *signalHub {
+	return &signalHub{clients: make(map[chan []byte]bool)}
+}
+
+// run fans out every signal until ch closes.
+func (h *signalHub) run(ch <-chan model.Signal) {
+	for s := range ch {
+		at, _ := s.Last()
+		msg, err := json.Marshal(SignalInfo{
+			ID: s.ID, Side: s.SideName(), State: s.StateName(), Reason: s.Reason, Time: at,
+			ArmedAt: s.ArmedAt, TriggeredAt: s.TriggeredAt, ClosedAt: s.ClosedAt,
+			ArmPrice: s.ArmPrice, EntryPrice: s.EntryPrice, ExitPrice: s.ExitPrice,
+			Score: s.Score, ReturnBps: s.ReturnBps,
+		})
+		if err != nil {
+			log.Printf("Signal encode error: %v", err)
+			continue
+		}
+
+		h.mu.Lock()
+		h.recent = append(h.recent, msg)
+		if len(h.recent) > signalReplay {
+			h.recent = h.recent[len(h.recent)-signalReplay:]
+		}
+		for c := range h.clients {
+			select {
+			case c <- msg:
+			default:
+				delete(h.clients, c)
+				close(c)
+			}
+		}
+		h.mu.Unlock()
+	}
+}
+
+func serveSignals(h *signalHub, w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	send := make(chan []byte, signalReplay+64) // replay fits without blocking
+
+	// Replay and register under one lock: no state change is missed or sent twice
+	h.mu.Lock()
+	for _, msg := range h.recent {
+		send <- msg
+	}
+	h.clients[send] = true
+	h.mu.Unlock()
+
+	// Reader: keeps the pong deadline fresh, notices the client leaving
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn.SetReadLimit(maxMessageSize)
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			conn.SetReadDeadline(time.Now().Add(pongWait))
+			return nil
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		h.mu.Lock()
+		if h.clients[send] {
+			delete(h.clients, send)
+			close(send)
+		}
+		h.mu.Unlock()
+		conn.Close()
+	}()
+	for {
+		select {
+		case msg, ok := <-send:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "too slow: send queue full"))
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
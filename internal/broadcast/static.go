@@ -0,0 +1,43 @@
+package broadcast
+
+import (
+	"embed"
+	"io/fs"
+	"log"
+	"net/http"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// EMBEDDED DASHBOARD
+// ═══════════════════════════════════════════════════════════════
+//
+// The React frontend (web/) can be compiled straight into the binary:
+//
+//   cd web && npm run build:embed   → writes internal/broadcast/static/dist
+//   go build ./cmd/orderflow        → bundle is embedded via go:embed
+//
+// The broadcaster then serves it at "/" next to the /ws feed, so a single
+// process provides both data and UI. If the bundle was not built, "/"
+// answers 404 with a hint and /ws keeps working as before.
+
+//go:embed all:static
+var staticFS embed.FS
+
+// dashboardHandler returns the file server for the embedded bundle.
+func dashboardHandler() http.Handler {
+	dist, err := fs.Sub(staticFS, "static/dist")
+	if err != nil {
+		log.Printf("Dashboard: embedded bundle unavailable: %v", err)
+		return http.NotFoundHandler()
+	}
+
+	if _, err := fs.Stat(dist, "index.html"); err != nil {
+		log.Println("Dashboard: no embedded bundle (run `npm run build:embed` in web/)")
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "dashboard not built into this binary", http.StatusNotFound)
+		})
+	}
+
+	log.Println("Dashboard: serving embedded bundle at /")
+	return http.FileServer(http.FS(dist))
+}
@@ -0,0 +1,62 @@
+package broadcast
+
+import (
+	"net/http"
+	"strconv"
+
+	"market-indikator/internal/summary"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// PERFORMANCE SUMMARIES — GET /summary
+// ═══════════════════════════════════════════════════════════════
+//
+//   GET /summary[?kind=day|session][&last=N]
+//
+// {"running": [...], "finished": [...]}: the day and session in progress
+// and the last N finished ones (default all kept), newest first. See
+// internal/summary for the fields.
+
+// SetSummaries enables /summary. Must be called before Start.
+func (b *Broadcaster) SetSummaries(t *summary.Tracker) {
+	b.summaries = t
+}
+
+func serveSummary(t *summary.Tracker, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	kind := r.URL.Query().Get("kind")
+	if kind != "" && kind != "day" && kind != "session" {
+		http.Error(w, "kind must be day or session", http.StatusBadRequest)
+		return
+	}
+	last := -1
+	if v := r.URL.Query().Get("last"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "last: want a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		last = n
+	}
+
+	running, finished := t.Snapshot()
+	out := struct {
+		Running  []summary.Summary `json:"running"`
+		Finished []summary.Summary `json:"finished"`
+	}{Running: []summary.Summary{}, Finished: []summary.Summary{}}
+	for _, s := range running {
+		if kind == "" || s.Kind == kind {
+			out.Running = append(out.Running, s)
+		}
+	}
+	for i := len(finished) - 1; i >= 0 && last != 0; i-- {
+		if kind == "" || finished[i].Kind == kind {
+			out.Finished = append(out.Finished, finished[i])
+			last--
+		}
+	}
+	writeJSON(w, out)
+}
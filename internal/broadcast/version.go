@@ -0,0 +1,23 @@
+package broadcast
+
+import (
+	"net/http"
+
+	"market-indikator/internal/buildinfo"
+)
+
+// ═══════════════════════════════════════════════════════════════
+// VERSION — GET /version
+// ═══════════════════════════════════════════════════════════════
+//
+// The server's commit, build time, Go version and schema versions (see
+// internal/buildinfo). WS clients get the commit and wire version in the
+// descriptor frame too.
+
+func serveVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, buildinfo.Get())
+}
@@ -0,0 +1,120 @@
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// BUILD INFO — which build produced a log file or a stream
+// =============================================================================
+//
+// Commit and BuildTime are stamped at link time:
+//
+//   go build -ldflags "-X market-indikator/internal/buildinfo.Commit=$(git describe --always --dirty) \
+//     -X market-indikator/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/orderflow
+//
+// Without them the commit falls back to the VCS stamp the go tool embeds
+// when building inside the git checkout (vcs.revision, vcs.modified), so a
+// plain go build still says which commit it is; only a build from a source
+// copy without .git is "unknown".
+//
+// Schemas are the versions of every persisted or wire format (snapshot CSV,
+// WebSocket snapshot, checkpoint, ...), registered at startup by the binary
+// that links those packages, so /version and the startup banner list them
+// without this package importing the rest of the tree.
+// =============================================================================
+
+// Set with -ldflags -X (see above).
+var (
+	Commit    string
+	BuildTime string
+)
+
+// Info — the build and its schema versions (GET /version).
+type Info struct {
+	Commit     string         `json:"commit"`                // "unknown" if not stamped
+	Modified   bool           `json:"modified"`              // built from a tree with local changes
+	CommitTime string         `json:"commit_time,omitempty"` // from the VCS stamp
+	BuildTime  string         `json:"build_time,omitempty"`
+	GoVersion  string         `json:"go_version"`
+	Schemas    map[string]int `json:"schemas"`
+}
+
+var (
+	mu      sync.Mutex
+	schemas = make(map[string]int)
+)
+
+// SetSchema registers a format's version under name.
+func SetSchema(name string, version int) {
+	mu.Lock()
+	schemas[name] = version
+	mu.Unlock()
+}
+
+// Get — the current build.
+func Get() Info {
+	info := Info{
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+		Schemas:   make(map[string]int),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value[:min(len(s.Value), 12)]
+				}
+			case "vcs.time":
+				info.CommitTime = s.Value
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	if strings.HasSuffix(info.Commit, "-dirty") {
+		info.Modified = true
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	mu.Lock()
+	for name, v := range schemas {
+		info.Schemas[name] = v
+	}
+	mu.Unlock()
+	return info
+}
+
+// String — a one-line banner: commit, build time, toolchain and schemas.
+func (i Info) String() string {
+	var b strings.Builder
+	b.WriteString("commit " + i.Commit)
+	if i.Modified && !strings.HasSuffix(i.Commit, "-dirty") {
+		b.WriteString(" (modified)")
+	}
+	if i.BuildTime != "" {
+		b.WriteString(", built " + i.BuildTime)
+	}
+	b.WriteString(", " + i.GoVersion)
+	names := make([]string, 0, len(i.Schemas))
+	for name := range i.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for n, name := range names {
+		sep := " "
+		if n == 0 {
+			sep = "; schemas "
+		}
+		fmt.Fprintf(&b, "%s%s=%d", sep, name, i.Schemas[name])
+	}
+	return b.String()
+}
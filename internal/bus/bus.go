@@ -3,41 +3,178 @@ package bus
 import (
 	"market-indikator/internal/model"
 	"sync"
+	"sync/atomic"
 )
 
 // Bus handles internal pub/sub.
+//
+// Two kinds of subscriber:
+//   - Ring (SubscribeRing) — lock-free SPSC queue for the engine hot path.
+//     Registered at startup, read by Publish without taking the mutex.
+//   - Channel (Subscribe) — for everything else (loggers, dynamic consumers).
+//
+// Lifecycle: subscribers can leave with Unsubscribe (their channel is closed),
+// and Close shuts the whole bus down, closing every subscriber channel so
+// `for t := range ch` consumers exit cleanly. Publishing after Close is a no-op.
 type Bus struct {
+	rings atomic.Pointer[[]*Ring] // copy-on-write, read lock-free by Publish
+
 	mu          sync.RWMutex
-	subscribers []chan model.Trade
+	subscribers []*subscriber
+	closed      bool
+}
+
+type subscriber struct {
+	name  string
+	ch    chan model.Trade
+	drops int64 // atomic — trades dropped because ch was full
+}
+
+// SubscriberStats — per-subscriber delivery metrics.
+type SubscriberStats struct {
+	Name    string
+	Backlog int
+	Cap     int
+	Drops   int64
 }
 
 func NewBus() *Bus {
-	return &Bus{
-		subscribers: make([]chan model.Trade, 0),
+	b := &Bus{
+		subscribers: make([]*subscriber, 0),
+	}
+	b.rings.Store(&[]*Ring{})
+	return b
+}
+
+// SubscribeRing registers a lock-free SPSC ring subscriber. Publish must be
+// called from a single goroutine for rings to be safe (it is: one ingester).
+func (b *Bus) SubscribeRing(name string, size int) *Ring {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r := NewRing(name, size)
+	if b.closed {
+		r.Close()
+		return r
 	}
+	old := *b.rings.Load()
+	rings := make([]*Ring, len(old), len(old)+1)
+	copy(rings, old)
+	rings = append(rings, r)
+	b.rings.Store(&rings)
+	return r
 }
 
 // Subscribe returns a read-only channel for trades.
-func (b *Bus) Subscribe(bufferSize int) <-chan model.Trade {
+// name identifies the subscriber in Stats.
+func (b *Bus) Subscribe(name string, bufferSize int) <-chan model.Trade {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	ch := make(chan model.Trade, bufferSize)
-	b.subscribers = append(b.subscribers, ch)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	b.subscribers = append(b.subscribers, &subscriber{name: name, ch: ch})
 	return ch
 }
 
+// Unsubscribe removes the subscriber owning ch and closes its channel.
+// Unknown channels are ignored.
+func (b *Bus) Unsubscribe(ch <-chan model.Trade) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, s := range b.subscribers {
+		if s.ch == ch {
+			close(s.ch)
+			b.subscribers = append(b.subscribers[:i], b.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Close closes every subscriber channel. Idempotent.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, s := range b.subscribers {
+		close(s.ch)
+	}
+	b.subscribers = nil
+	for _, r := range *b.rings.Load() {
+		r.Close()
+	}
+}
+
+// Stats returns delivery metrics for every current subscriber.
+func (b *Bus) Stats() []SubscriberStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	rings := *b.rings.Load()
+	out := make([]SubscriberStats, 0, len(rings)+len(b.subscribers))
+	for _, r := range rings {
+		out = append(out, SubscriberStats{
+			Name:    r.name,
+			Backlog: r.Backlog(),
+			Cap:     len(r.buf),
+			Drops:   r.Drops(),
+		})
+	}
+	for _, s := range b.subscribers {
+		out = append(out, SubscriberStats{
+			Name:    s.name,
+			Backlog: len(s.ch),
+			Cap:     cap(s.ch),
+			Drops:   atomic.LoadInt64(&s.drops),
+		})
+	}
+	return out
+}
+
+// Drops returns the drop counter of the named subscriber (0 if unknown).
+func (b *Bus) Drops(name string) int64 {
+	for _, r := range *b.rings.Load() {
+		if r.name == name {
+			return r.Drops()
+		}
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subscribers {
+		if s.name == name {
+			return atomic.LoadInt64(&s.drops)
+		}
+	}
+	return 0
+}
+
 // Publish broadcasts the trade to all subscribers.
 // Non-blocking publish: if a subscriber is slow/full, we drop the message.
+// Rings are served first, lock-free; after Close they drop everything.
 func (b *Bus) Publish(t model.Trade) {
+	for _, r := range *b.rings.Load() {
+		r.Publish(t)
+	}
+
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	for _, ch := range b.subscribers {
+	for _, s := range b.subscribers {
 		select {
-		case ch <- t:
+		case s.ch <- t:
 		default:
 			// Slow consumer, dropping to maintain low latency
+			atomic.AddInt64(&s.drops, 1)
 		}
 	}
 }
@@ -0,0 +1,175 @@
+package bus
+
+import (
+	"errors"
+	"market-indikator/internal/model"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+// SPSC RING — lock-free single-producer/single-consumer trade queue
+// =============================================================================
+//
+// Disruptor-style bounded ring for the hot path (ingest → engine):
+//
+//   producer (ingest goroutine):  write buf[tail & mask], then tail++  (release)
+//   consumer (engine goroutine):  read  buf[head & mask], then head++  (release)
+//
+// No mutex, no channel select: Publish is two atomic loads, one copy and one
+// atomic store (~10ns). head and tail sit on separate cache lines so producer
+// and consumer don't false-share.
+//
+// Waiting: the consumer spins briefly (yielding), then parks on a 1-slot
+// notify channel. The producer only touches the channel when the consumer has
+// announced it is parked, so under load the channel is never used. Lost
+// wake-ups are impossible: the consumer sets `waiting` BEFORE re-checking
+// tail, the producer publishes tail BEFORE checking `waiting`.
+//
+// Full ring → the trade is dropped and counted (same policy as the channel
+// bus: never block ingest).
+// =============================================================================
+
+const spinBeforePark = 64
+
+// Errors returned by NextTimeout.
+var (
+	ErrClosed  = errors.New("bus: ring closed")
+	ErrTimeout = errors.New("bus: ring wait timed out")
+)
+
+type cacheLinePad [64]byte
+
+// Ring is a bounded SPSC queue of trades. Exactly one goroutine may call
+// Publish and exactly one may call Next.
+type Ring struct {
+	_    cacheLinePad
+	head uint64 // next slot to read (consumer-owned)
+	_    cacheLinePad
+	tail uint64 // next slot to write (producer-owned)
+	_    cacheLinePad
+
+	mask    uint64
+	buf     []model.Trade
+	name    string
+	drops   int64 // atomic
+	waiting int32 // atomic, 1 = consumer parked on notify
+	closed  int32 // atomic
+	notify  chan struct{}
+}
+
+// NewRing — size is rounded up to a power of two.
+func NewRing(name string, size int) *Ring {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+	return &Ring{
+		mask:   uint64(n - 1),
+		buf:    make([]model.Trade, n),
+		name:   name,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// Publish enqueues t, or drops it if the ring is full. Producer only.
+func (r *Ring) Publish(t model.Trade) {
+	if atomic.LoadInt32(&r.closed) == 1 {
+		return
+	}
+	tail := atomic.LoadUint64(&r.tail)
+	if tail-atomic.LoadUint64(&r.head) > r.mask {
+		atomic.AddInt64(&r.drops, 1)
+		return
+	}
+	r.buf[tail&r.mask] = t
+	atomic.StoreUint64(&r.tail, tail+1)
+
+	if atomic.LoadInt32(&r.waiting) == 1 {
+		r.wake()
+	}
+}
+
+// Next blocks until a trade is available. Returns false once the ring is
+// closed and drained. Consumer only.
+func (r *Ring) Next() (model.Trade, bool) {
+	t, err := r.next(time.Time{})
+	return t, err == nil
+}
+
+// NextTimeout is Next with a deadline: ErrTimeout if nothing arrived within d,
+// ErrClosed once the ring is closed and drained. Consumer only.
+func (r *Ring) NextTimeout(d time.Duration) (model.Trade, error) {
+	return r.next(time.Now().Add(d))
+}
+
+// next — shared wait loop; zero deadline = wait forever. The timer is only
+// created when the consumer actually parks, so the busy path stays
+// allocation-free.
+func (r *Ring) next(deadline time.Time) (model.Trade, error) {
+	var timeout <-chan time.Time
+	head := atomic.LoadUint64(&r.head)
+	for spins := 0; ; spins++ {
+		if head < atomic.LoadUint64(&r.tail) {
+			t := r.buf[head&r.mask]
+			atomic.StoreUint64(&r.head, head+1)
+			return t, nil
+		}
+		if atomic.LoadInt32(&r.closed) == 1 {
+			return model.Trade{}, ErrClosed
+		}
+
+		if spins < spinBeforePark {
+			runtime.Gosched()
+			continue
+		}
+
+		// Park
+		if !deadline.IsZero() && timeout == nil {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return model.Trade{}, ErrTimeout
+			}
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		atomic.StoreInt32(&r.waiting, 1)
+		if head < atomic.LoadUint64(&r.tail) || atomic.LoadInt32(&r.closed) == 1 {
+			atomic.StoreInt32(&r.waiting, 0)
+			continue
+		}
+		select {
+		case <-r.notify:
+		case <-timeout:
+			atomic.StoreInt32(&r.waiting, 0)
+			return model.Trade{}, ErrTimeout
+		}
+		atomic.StoreInt32(&r.waiting, 0)
+		spins = 0
+	}
+}
+
+// Close wakes the consumer; Next returns false after the remaining trades.
+func (r *Ring) Close() {
+	atomic.StoreInt32(&r.closed, 1)
+	r.wake()
+}
+
+// Drops returns the number of trades dropped because the ring was full.
+func (r *Ring) Drops() int64 {
+	return atomic.LoadInt64(&r.drops)
+}
+
+// Backlog returns the number of queued trades.
+func (r *Ring) Backlog() int {
+	return int(atomic.LoadUint64(&r.tail) - atomic.LoadUint64(&r.head))
+}
+
+func (r *Ring) wake() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
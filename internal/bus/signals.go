@@ -0,0 +1,82 @@
+package bus
+
+import (
+	"market-indikator/internal/model"
+	"sync"
+	"sync/atomic"
+)
+
+// Signals — pub/sub for score signal state changes (see internal/signals).
+// A few per hour, so channel subscribers only; same non-blocking,
+// drop-when-full delivery as Bus.Subscribe.
+type Signals struct {
+	mu          sync.RWMutex
+	subscribers []*signalSub
+	closed      bool
+}
+
+type signalSub struct {
+	name  string
+	ch    chan model.Signal
+	drops int64 // atomic
+}
+
+func NewSignals() *Signals {
+	return &Signals{}
+}
+
+// Subscribe returns a read-only channel of signal state changes.
+func (b *Signals) Subscribe(name string, bufferSize int) <-chan model.Signal {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan model.Signal, bufferSize)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	b.subscribers = append(b.subscribers, &signalSub{name: name, ch: ch})
+	return ch
+}
+
+// Publish delivers s to every subscriber, dropping it for full ones.
+func (b *Signals) Publish(s model.Signal) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		select {
+		case sub.ch <- s:
+		default:
+			atomic.AddInt64(&sub.drops, 1)
+		}
+	}
+}
+
+// Drops returns the drop counter of the named subscriber (0 if unknown).
+func (b *Signals) Drops(name string) int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subscribers {
+		if s.name == name {
+			return atomic.LoadInt64(&s.drops)
+		}
+	}
+	return 0
+}
+
+// Close closes every subscriber channel. Idempotent.
+func (b *Signals) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, s := range b.subscribers {
+		close(s.ch)
+	}
+	b.subscribers = nil
+}
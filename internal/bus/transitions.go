@@ -0,0 +1,83 @@
+package bus
+
+import (
+	"market-indikator/internal/model"
+	"sync"
+	"sync/atomic"
+)
+
+// Transitions — pub/sub for decision-layer transitions (HTF bias flips,
+// market-state changes; see logger.TransitionTracker). A handful per hour,
+// so channel subscribers only; same non-blocking, drop-when-full delivery as
+// Bus.Subscribe.
+type Transitions struct {
+	mu          sync.RWMutex
+	subscribers []*transitionSub
+	closed      bool
+}
+
+type transitionSub struct {
+	name  string
+	ch    chan model.Transition
+	drops int64 // atomic
+}
+
+func NewTransitions() *Transitions {
+	return &Transitions{}
+}
+
+// Subscribe returns a read-only channel of transitions.
+func (b *Transitions) Subscribe(name string, bufferSize int) <-chan model.Transition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan model.Transition, bufferSize)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+	b.subscribers = append(b.subscribers, &transitionSub{name: name, ch: ch})
+	return ch
+}
+
+// Publish delivers t to every subscriber, dropping it for full ones.
+func (b *Transitions) Publish(t model.Transition) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subscribers {
+		select {
+		case s.ch <- t:
+		default:
+			atomic.AddInt64(&s.drops, 1)
+		}
+	}
+}
+
+// Drops returns the drop counter of the named subscriber (0 if unknown).
+func (b *Transitions) Drops(name string) int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subscribers {
+		if s.name == name {
+			return atomic.LoadInt64(&s.drops)
+		}
+	}
+	return 0
+}
+
+// Close closes every subscriber channel. Idempotent.
+func (b *Transitions) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, s := range b.subscribers {
+		close(s.ch)
+	}
+	b.subscribers = nil
+}
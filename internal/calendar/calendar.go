@@ -0,0 +1,323 @@
+package calendar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"market-indikator/internal/binance"
+)
+
+// =============================================================================
+// ECONOMIC CALENDAR — event-risk windows (QualityEventRisk)
+// =============================================================================
+//
+// Scheduled releases (FOMC, CPI, NFP, …) move price on the headline, not on
+// order flow: the book is pulled seconds before, the first prints are
+// one-sided sweeps, and the score during the print is noise. A calendar of
+// such events is loaded from a URL or file and every snapshot inside
+//
+//   [event − Before, event + After]
+//
+// is flagged QualityEventRisk; the decision layer then opens no position
+// (logger.EntriesSuppressed; the hint reads NO_TRADE while flat) and, like
+// any quality flag, the executor does not enter. A position held into the
+// window is still managed by the hint.
+//
+// Two formats, told apart by content:
+//
+//   ICS    VEVENTs: DTSTART (UTC "…Z", TZID=… local, or an all-day DATE at
+//          00:00 UTC) and SUMMARY
+//   JSON   an array of objects: "time" / "date" / "datetime" (unix s or ms,
+//          or RFC 3339) and "title" / "name" / "event"; e.g. the weekly
+//          ForexFactory export
+//
+// Match (optional) keeps only titles matching a regexp, e.g. FOMC|CPI|NFP
+// out of a full calendar. The source is re-read every RefreshEvery; a
+// failed refresh keeps the previous list.
+//
+// TRADING INTERPRETATION: sit out the window. The minutes after it (when
+// the flag clears) are where flow reads mean something again — the first
+// post-release trend is usually the one the score catches.
+// =============================================================================
+
+// RefreshEvery — how often the source is re-read.
+const RefreshEvery = time.Hour
+
+// Event — one scheduled release.
+type Event struct {
+	Time  int64  `json:"time"` // unix ms
+	Title string `json:"title"`
+}
+
+// Calendar — the loaded events and their risk windows. Active is lock-free
+// and safe from any goroutine.
+type Calendar struct {
+	src           string
+	before, after int64 // ms
+	match         *regexp.Regexp
+	http          *http.Client
+
+	events atomic.Pointer[[]Event] // sorted by time
+	errors int64                   // atomic — failed loads
+}
+
+// New — a calendar read from src (http(s) URL or file path), flagging
+// before..after around each event; match filters titles (nil = all).
+func New(src string, before, after time.Duration, match *regexp.Regexp) *Calendar {
+	c := &Calendar{src: src, before: before.Milliseconds(), after: after.Milliseconds(), match: match,
+		http: binance.HTTPClient(10 * time.Second)}
+	c.events.Store(&[]Event{})
+	return c
+}
+
+// Load reads the source once (call it at startup to fail fast).
+func (c *Calendar) Load() error {
+	data, err := c.read()
+	if err != nil {
+		atomic.AddInt64(&c.errors, 1)
+		return err
+	}
+	events, err := Parse(data)
+	if err != nil {
+		atomic.AddInt64(&c.errors, 1)
+		return err
+	}
+	if c.match != nil {
+		kept := events[:0]
+		for _, ev := range events {
+			if c.match.MatchString(ev.Title) {
+				kept = append(kept, ev)
+			}
+		}
+		events = kept
+	}
+	c.events.Store(&events)
+	if next, ok := c.Next(time.Now().UnixMilli()); ok {
+		log.Printf("Calendar: %d events, next %q at %s", len(events), next.Title,
+			time.UnixMilli(next.Time).UTC().Format(time.RFC3339))
+	} else {
+		log.Printf("Calendar: %d events, none upcoming", len(events))
+	}
+	return nil
+}
+
+// Start re-reads the source every RefreshEvery until ctx ends.
+func (c *Calendar) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(RefreshEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.Load(); err != nil {
+					log.Printf("Calendar refresh failed (keeping %d events): %v", len(*c.events.Load()), err)
+				}
+			}
+		}
+	}()
+}
+
+// Errors returns the number of failed loads since start.
+func (c *Calendar) Errors() int64 {
+	return atomic.LoadInt64(&c.errors)
+}
+
+// Active — the event whose risk window covers nowMs (unix ms), if any.
+func (c *Calendar) Active(nowMs int64) (Event, bool) {
+	events := *c.events.Load()
+	// First event not yet past its window
+	i := sort.Search(len(events), func(i int) bool { return events[i].Time+c.after >= nowMs })
+	if i < len(events) && events[i].Time-c.before <= nowMs {
+		return events[i], true
+	}
+	return Event{}, false
+}
+
+// Next — the first event at or after nowMs.
+func (c *Calendar) Next(nowMs int64) (Event, bool) {
+	events := *c.events.Load()
+	i := sort.Search(len(events), func(i int) bool { return events[i].Time >= nowMs })
+	if i < len(events) {
+		return events[i], true
+	}
+	return Event{}, false
+}
+
+func (c *Calendar) read() ([]byte, error) {
+	if !strings.HasPrefix(c.src, "http://") && !strings.HasPrefix(c.src, "https://") {
+		return os.ReadFile(c.src)
+	}
+	resp, err := c.http.Get(c.src)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, body)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+}
+
+// Parse reads an ICS or JSON calendar (see file header), sorted by time.
+func Parse(data []byte) ([]Event, error) {
+	trimmed := bytes.TrimSpace(data)
+	var (
+		events []Event
+		err    error
+	)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("BEGIN:VCALENDAR")):
+		events, err = parseICS(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		events, err = parseJSON(trimmed)
+	default:
+		return nil, fmt.Errorf("calendar: neither ICS nor a JSON array")
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time < events[j].Time })
+	return events, nil
+}
+
+func parseJSON(data []byte) ([]Event, error) {
+	var rows []map[string]any
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("calendar JSON: %v", err)
+	}
+	var events []Event
+	for i, r := range rows {
+		var ev Event
+		for _, k := range []string{"title", "name", "event"} {
+			if s, ok := r[k].(string); ok && s != "" {
+				ev.Title = s
+				break
+			}
+		}
+		for _, k := range []string{"time", "date", "datetime"} {
+			if v, ok := r[k]; ok {
+				ms, err := jsonTime(v)
+				if err != nil {
+					return nil, fmt.Errorf("calendar JSON entry %d: %v", i, err)
+				}
+				ev.Time = ms
+				break
+			}
+		}
+		if ev.Time == 0 {
+			return nil, fmt.Errorf("calendar JSON entry %d: no time", i)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// jsonTime — unix s or ms (a number or numeric string), or RFC 3339, in ms.
+func jsonTime(v any) (int64, error) {
+	var n float64
+	switch t := v.(type) {
+	case float64:
+		n = t
+	case string:
+		if ts, err := time.Parse(time.RFC3339, t); err == nil {
+			return ts.UnixMilli(), nil
+		}
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return 0, fmt.Errorf("time %q: want unix s/ms or RFC 3339", t)
+		}
+		n = f
+	default:
+		return 0, fmt.Errorf("time %v: want unix s/ms or RFC 3339", v)
+	}
+	if n < 1e11 { // seconds (ms would be before 1973)
+		n *= 1000
+	}
+	return int64(n), nil
+}
+
+func parseICS(data []byte) ([]Event, error) {
+	// Unfold: a line starting with a space or tab continues the previous one
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("calendar ICS: %v", err)
+	}
+
+	var (
+		events []Event
+		ev     Event
+		in     bool
+	)
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			ev, in = Event{}, true
+		case name == "END" && value == "VEVENT" && in:
+			if ev.Time == 0 {
+				return nil, fmt.Errorf("calendar ICS: VEVENT %q without DTSTART", ev.Title)
+			}
+			events = append(events, ev)
+			in = false
+		case name == "SUMMARY" && in:
+			ev.Title = strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\\`, `\`).Replace(value)
+		case name == "DTSTART" && in:
+			t, err := icsTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("calendar ICS: %v", err)
+			}
+			ev.Time = t.UnixMilli()
+		}
+	}
+	return events, nil
+}
+
+// icsTime parses a DTSTART value with its parameters (TZID=…, VALUE=DATE).
+func icsTime(value, params string) (time.Time, error) {
+	loc := time.UTC
+	for _, p := range strings.Split(params, ";") {
+		if tz, ok := strings.CutPrefix(p, "TZID="); ok {
+			l, err := time.LoadLocation(strings.Trim(tz, `"`))
+			if err != nil {
+				return time.Time{}, fmt.Errorf("DTSTART TZID %q: %v", tz, err)
+			}
+			loc = l
+		}
+	}
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("DTSTART %q", value)
+}
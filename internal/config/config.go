@@ -0,0 +1,264 @@
+package config
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// CONFIG FILE — flag values from a file, reloadable at runtime
+// =============================================================================
+//
+// A -config file sets the same options as the command line, one per line:
+//
+//   # comments and blank lines are ignored
+//   score-weights  = aggressive=0.5,passive=0.3,positioning=0.2
+//   signal-trigger = 45
+//   log-keep-days  = 30
+//
+// Names are flag names without the dash. A flag given on the command line
+// wins over the file, at startup and on every reload. A repeatable flag
+// (-formula) may appear on several lines.
+//
+// Reload (SIGHUP or POST /admin/reload) re-reads the file and compares it
+// with what was applied last; a line removed from the file reverts to the
+// flag's default. Each changed option is then:
+//
+//   reloadable   set, and the hook registered for its group (OnChange) runs;
+//                if the hook rejects the values the group is reverted
+//   otherwise    left alone and reported as requiring a restart (symbol,
+//                ports, streams, anything wired once at startup)
+//
+// The hooks hand the new values to their owners — the engine goroutine, for
+// score weights and alert thresholds — so the scorer's normalizers and
+// smoother keep their warm state across a weight tweak.
+// =============================================================================
+
+// Result — the outcome of a reload, by flag name.
+type Result struct {
+	Applied []string `json:"applied"`           // changed and in effect
+	Restart []string `json:"requires_restart"`  // changed, needs a restart
+	Ignored []string `json:"ignored,omitempty"` // set on the command line
+	Errors  []string `json:"errors,omitempty"`  // rejected (old value kept)
+}
+
+// String — a one-line summary for the log.
+func (r Result) String() string {
+	if len(r.Applied)+len(r.Restart)+len(r.Ignored)+len(r.Errors) == 0 {
+		return "no changes"
+	}
+	var parts []string
+	add := func(label string, list []string) {
+		if len(list) > 0 {
+			parts = append(parts, label+" "+strings.Join(list, ", "))
+		}
+	}
+	add("applied:", r.Applied)
+	add("requires restart:", r.Restart)
+	add("ignored (command line):", r.Ignored)
+	add("errors:", r.Errors)
+	return strings.Join(parts, "; ")
+}
+
+type group struct {
+	names []string
+	apply func() error
+}
+
+// Reloader — the config file bound to a flag set.
+type Reloader struct {
+	mu      sync.Mutex
+	path    string
+	fs      *flag.FlagSet
+	cli     map[string]bool   // set on the command line
+	applied map[string]string // values taken from the file (joined by \n)
+	groups  map[string]*group // reloadable flag → its group
+}
+
+// New applies the file at path to fs, which must already be parsed. Unknown
+// names and invalid values are errors.
+func New(path string, fs *flag.FlagSet) (*Reloader, error) {
+	r := &Reloader{
+		path:    path,
+		fs:      fs,
+		cli:     make(map[string]bool),
+		applied: make(map[string]string),
+		groups:  make(map[string]*group),
+	}
+	fs.Visit(func(f *flag.Flag) { r.cli[f.Name] = true })
+	lines, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lines {
+		if r.cli[l.name] {
+			continue
+		}
+		if err := fs.Set(l.name, l.value); err != nil {
+			return nil, fmt.Errorf("%s:%d: %s: %v", path, l.num, l.name, err)
+		}
+	}
+	r.applied = join(lines)
+	return r, nil
+}
+
+// Path — the config file.
+func (r *Reloader) Path() string { return r.path }
+
+// OnChange makes the named flags reloadable: when any of them changes, all
+// are set first and then apply runs once. apply validates and hands the new
+// values on; an error reverts the group. Register before the first Reload.
+func (r *Reloader) OnChange(names []string, apply func() error) {
+	g := &group{names: names, apply: apply}
+	for _, name := range names {
+		if r.fs.Lookup(name) == nil {
+			panic("config: OnChange: unknown flag " + name)
+		}
+		r.groups[name] = g
+	}
+}
+
+// Reload re-reads the file and applies what changed. Safe from any
+// goroutine; reloads are serialized.
+func (r *Reloader) Reload() Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := Result{Applied: []string{}, Restart: []string{}}
+	lines, err := r.read()
+	if err != nil {
+		res.Errors = append(res.Errors, err.Error())
+		return res
+	}
+	next := join(lines)
+
+	names := make([]string, 0, len(next)+len(r.applied))
+	for name := range next {
+		names = append(names, name)
+	}
+	for name := range r.applied {
+		if _, ok := next[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	type change struct {
+		name, old string
+		values    []string
+	}
+	var (
+		dirty   []*group
+		changes = make(map[*group][]change)
+	)
+	for _, name := range names {
+		val, inFile := next[name]
+		f := r.fs.Lookup(name)
+		if !inFile {
+			val = f.DefValue
+		}
+		prev, ok := r.applied[name]
+		if !ok {
+			prev = f.DefValue
+		}
+		if val == prev {
+			continue
+		}
+		switch g := r.groups[name]; {
+		case r.cli[name]:
+			res.Ignored = append(res.Ignored, name)
+		case g == nil:
+			res.Restart = append(res.Restart, name)
+		default:
+			if _, seen := changes[g]; !seen {
+				dirty = append(dirty, g)
+			}
+			changes[g] = append(changes[g], change{name, f.Value.String(), strings.Split(val, "\n")})
+		}
+	}
+
+	for _, g := range dirty {
+		cs := changes[g]
+		err := func() error {
+			for _, c := range cs {
+				for _, v := range c.values {
+					if err := r.fs.Set(c.name, v); err != nil {
+						return fmt.Errorf("%s: %v", c.name, err)
+					}
+				}
+			}
+			return g.apply()
+		}()
+		if err != nil {
+			for _, c := range cs {
+				r.fs.Set(c.name, c.old)
+			}
+			res.Errors = append(res.Errors, err.Error())
+			continue
+		}
+		for _, c := range cs {
+			if v, ok := next[c.name]; ok {
+				r.applied[c.name] = v
+			} else {
+				delete(r.applied, c.name)
+			}
+			res.Applied = append(res.Applied, c.name)
+		}
+	}
+	return res
+}
+
+type line struct {
+	num         int
+	name, value string
+}
+
+// read parses the file: name = value lines, # comments.
+func (r *Reloader) read() ([]line, error) {
+	f, err := os.Open(r.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []line
+	sc := bufio.NewScanner(f)
+	for num := 1; sc.Scan(); num++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(text, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		name = strings.TrimLeft(name, "-")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%s:%d: want name = value", r.path, num)
+		}
+		if r.fs.Lookup(name) == nil || name == "config" {
+			return nil, fmt.Errorf("%s:%d: unknown option %q", r.path, num, name)
+		}
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		lines = append(lines, line{num, name, value})
+	}
+	return lines, sc.Err()
+}
+
+// join — the file's values by name, repeated lines joined by \n.
+func join(lines []line) map[string]string {
+	m := make(map[string]string, len(lines))
+	for _, l := range lines {
+		if v, ok := m[l.name]; ok {
+			m[l.name] = v + "\n" + l.value
+		} else {
+			m[l.name] = l.value
+		}
+	}
+	return m
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"errors"
+	"log"
+	"sync/atomic"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/vwap"
+)
+
+// =============================================================================
+// ANCHORED VWAP — user-chosen start times
+// =============================================================================
+//
+// Clients create anchors through /admin/anchors (any goroutine); the request
+// is queued on anchorCmds and applied by the engine goroutine at the start of
+// the next trade or heartbeat, so the anchor list stays single-owner.
+//
+// An anchor in the past is pre-seeded by the caller from candle history
+// (see broadcast/anchors.go) up to seededTo; the engine then adds the current
+// 1s candle if it lies at or after seededTo, and every trade from there on.
+// =============================================================================
+
+// ErrAnchorsFull — MaxAnchors anchored VWAPs already exist (or are queued).
+var ErrAnchorsFull = errors.New("too many anchored VWAPs")
+
+// ErrAnchorQueueFull — the engine hasn't drained earlier anchor requests yet.
+var ErrAnchorQueueFull = errors.New("anchor request queue full")
+
+type anchorCmd struct {
+	remove   bool
+	anchor   vwap.Anchor
+	seededTo int64 // unix seconds, exclusive
+}
+
+// AddAnchor queues a new anchored VWAP starting at fromMs, pre-seeded with
+// the history accumulated up to seededTo (unix seconds, exclusive). Safe to
+// call from any goroutine; returns the anchor's ID.
+func (e *Engine) AddAnchor(fromMs int64, seed vwap.Accumulator, seededTo int64) (int64, error) {
+	if atomic.AddInt32(&e.anchorCount, 1) > model.MaxAnchors {
+		atomic.AddInt32(&e.anchorCount, -1)
+		return 0, ErrAnchorsFull
+	}
+	id := atomic.AddInt64(&e.nextAnchorID, 1)
+	cmd := anchorCmd{anchor: vwap.Anchor{ID: id, From: fromMs, Acc: seed}, seededTo: seededTo}
+	select {
+	case e.anchorCmds <- cmd:
+		return id, nil
+	default:
+		atomic.AddInt32(&e.anchorCount, -1)
+		return 0, ErrAnchorQueueFull
+	}
+}
+
+// RemoveAnchor queues removal of an anchored VWAP. Unknown IDs are ignored.
+// Safe to call from any goroutine.
+func (e *Engine) RemoveAnchor(id int64) error {
+	select {
+	case e.anchorCmds <- anchorCmd{remove: true, anchor: vwap.Anchor{ID: id}}:
+		return nil
+	default:
+		return ErrAnchorQueueFull
+	}
+}
+
+// applyAnchorCmds — drains queued anchor changes. Engine goroutine only.
+func (e *Engine) applyAnchorCmds() {
+	for {
+		select {
+		case cmd := <-e.anchorCmds:
+			if cmd.remove {
+				e.removeAnchor(cmd.anchor.ID)
+				continue
+			}
+			a := cmd.anchor
+			// The live second counts only if seeding stopped short of it and
+			// it is not before the anchor (seconds, as in seedAnchor)
+			if e.Candle1s.Time >= cmd.seededTo && e.Candle1s.Time*1000 >= a.From {
+				addCandleVWAP(&a.Acc, &e.Candle1s)
+			}
+			e.anchors[e.numAnchors] = a
+			e.numAnchors++
+			log.Printf("Anchored VWAP %d added from %d", a.ID, a.From)
+		default:
+			return
+		}
+	}
+}
+
+func (e *Engine) removeAnchor(id int64) {
+	for i := 0; i < e.numAnchors; i++ {
+		if e.anchors[i].ID != id {
+			continue
+		}
+		copy(e.anchors[i:e.numAnchors], e.anchors[i+1:e.numAnchors])
+		e.numAnchors--
+		e.anchors[e.numAnchors] = vwap.Anchor{}
+		atomic.AddInt32(&e.anchorCount, -1)
+		log.Printf("Anchored VWAP %d removed", id)
+		return
+	}
+}
+
+// addCandleVWAP — folds a candle into an accumulator at its typical price
+// (H+L+C)/3. Used for seeding, where individual trades are gone.
+func addCandleVWAP(a *vwap.Accumulator, c *CandleDelta) {
+	if vol := c.BuyVol + c.SellVol; vol > 0 {
+		a.Add((c.High+c.Low+c.Close)/3, vol)
+	}
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"market-indikator/internal/model"
+	"market-indikator/internal/orderbook"
+)
+
+// =============================================================================
+// PER-CANDLE ORDER BOOK STATISTICS — Snapshot.Book1m / HTFBook
+// =============================================================================
+//
+// The snapshot carries the book as of the last update only; a post-trade
+// review wants what the book looked like over the whole candle. Each 1m and
+// HTF candle accumulates, from the engine's book reads (every trade and
+// heartbeat):
+//
+//   AvgImbalance  time-weighted top-level imbalance
+//   Min/Max       its extremes
+//   MaxBidWall    largest single bid level seen (any kept level)
+//   MaxAskWall    largest single ask level seen
+//   AvgSpread     time-weighted spread
+//
+// The book is sampled, not streamed: a reading holds until the next one
+// (piecewise constant), and the reading before a candle opens covers the
+// candle's start. Reads while the book is stale or suspect are skipped.
+//
+// TRADING INTERPRETATION: a 1m candle that fell with AvgImbalance > 0 sold
+// into resting bids (absorbed or pulled — compare the bid wall); a wide
+// AvgSpread marks a thin, gappy minute whose prints are less trustworthy.
+// =============================================================================
+
+// candleBook — one candle's accumulators (checkpointed). Engine goroutine
+// only.
+type candleBook struct {
+	Bucket     int64 // candle bucket start, unix s
+	LastMs     int64 // time of the reading in force (0 = none yet)
+	LastImb    float64
+	LastSpread float64
+	ImbSum     float64 // Σ imbalance × ms
+	SpreadSum  float64 // Σ spread × ms
+	Weight     float64 // Σ ms
+	Samples    int
+	Stats      model.CandleBook
+}
+
+// update — feeds the book read at nowMs (exchange ms) into the candle at
+// bucket; usable = the book is neither stale nor suspect.
+func (c *candleBook) update(bucket, nowMs int64, p *orderbook.Pressure, usable bool) {
+	if bucket != c.Bucket {
+		prev := *c
+		*c = candleBook{Bucket: bucket}
+		if prev.LastMs > 0 {
+			// The reading in force at the open covers the candle's start
+			c.LastMs, c.LastImb, c.LastSpread = max(bucket*1000, prev.LastMs), prev.LastImb, prev.LastSpread
+		}
+	}
+	if !usable || p.BidVol+p.AskVol == 0 {
+		c.LastMs = 0 // an unusable stretch counts for nothing
+		return
+	}
+	if c.LastMs > 0 && nowMs > c.LastMs {
+		dt := float64(nowMs - c.LastMs)
+		c.ImbSum += c.LastImb * dt
+		c.SpreadSum += c.LastSpread * dt
+		c.Weight += dt
+	}
+	c.LastMs, c.LastImb, c.LastSpread = nowMs, p.Imbalance, p.Spread
+
+	s := &c.Stats
+	if c.Samples == 0 {
+		s.MinImbalance, s.MaxImbalance = p.Imbalance, p.Imbalance
+	}
+	c.Samples++
+	s.MinImbalance = min(s.MinImbalance, p.Imbalance)
+	s.MaxImbalance = max(s.MaxImbalance, p.Imbalance)
+	s.MaxBidWall = max(s.MaxBidWall, p.MaxBidQty)
+	s.MaxAskWall = max(s.MaxAskWall, p.MaxAskQty)
+	if c.Weight > 0 {
+		s.AvgImbalance, s.AvgSpread = c.ImbSum/c.Weight, c.SpreadSum/c.Weight
+	} else {
+		s.AvgImbalance, s.AvgSpread = p.Imbalance, p.Spread
+	}
+}
+
+// updateCandleBooks — the 1m and HTF candles' book statistics, after the
+// candles themselves have been updated or rolled.
+func (e *Engine) updateCandleBooks(nowMs int64, p *orderbook.Pressure, quality model.QualitySnapshot) {
+	usable := quality.Flags&bookUnusable == 0
+	e.book1m.update(e.Candle1m.Time, nowMs, p, usable)
+	for i := 0; i < e.numHTF; i++ {
+		e.htfBook[i].update(e.HTF[i].Time, nowMs, p, usable)
+	}
+}
@@ -0,0 +1,324 @@
+package engine
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"market-indikator/internal/flow"
+	"market-indikator/internal/indicators"
+	"market-indikator/internal/positioning"
+	"market-indikator/internal/pressure"
+	"market-indikator/internal/profile"
+	"market-indikator/internal/session"
+	"market-indikator/internal/state"
+	"market-indikator/internal/tpo"
+	"market-indikator/internal/vwap"
+)
+
+// =============================================================================
+// ENGINE CHECKPOINTING — warm restarts
+// =============================================================================
+//
+// A cold engine starts with CVD=0, empty 4h/1d candles and σ estimates at
+// their 1.0 defaults, so scores are off for hours after a restart. The
+// checkpoint captures everything ProcessTrade accumulates:
+//
+//   CVD, LastPrice, every CandleDelta bucket (1s, 1m, HTF) and the 1m/HTF
+//   candles' book statistics, the scorer's EMA/σ state and score-percentile
+//   window, the per-timeframe scorers, the session tracker (today's opens,
+//   previous-day range, session/day VWAP sums), the rolling VWAP window,
+//   anchored VWAPs and the tape-speed and effort-vs-result baselines, the
+//   VPIN buckets and the price-impact regression, the RSI/MACD/EMA-ribbon
+//   state, the squeeze detectors, the day's volume profile and the
+//   session's market profile, the positioning tracker's OI history, the
+//   cold-start warmup counters, the time-of-day profile, the thin-book
+//   depth window, the rolling 1m range and swing points and the session's
+//   opening range.
+//
+// Checkpoint/Restore must be called from the engine goroutine (the engine is
+// single-owner and lock-free). Candles whose bucket has already ended by the
+// time trading resumes simply roll over on the next trade, as usual.
+//
+// HTF buckets are saved with their bucket length and restored by matching it,
+// so changing -timeframes between runs keeps the buckets both sets share and
+// starts the new ones cold.
+// =============================================================================
+
+// CheckpointVersion — bumped whenever Checkpoint changes incompatibly.
+const CheckpointVersion = 16
+
+// CandleState — exported copy of a CandleDelta (the per-timeframe EMA alpha
+// is configuration, not state, and is not saved).
+type CandleState struct {
+	Time      int64
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	BuyVol    float64
+	SellVol   float64
+	Delta     float64
+	AvgScore  float64
+	BuyCount  int64 // zero in checkpoints saved before trade counts
+	SellCount int64
+	DeltaHigh float64 // zero in checkpoints saved before intrabar delta
+	DeltaLow  float64
+}
+
+// Checkpoint — serializable engine state.
+type Checkpoint struct {
+	Version    int
+	SavedAt    int64 // unix ms
+	CVD        float64
+	CVDAnchor  CVDAnchorState
+	LastPrice  float64
+	LastTrade  int64
+	Candle1s   CandleState
+	Candle1m   CandleState
+	HTF        []CandleState
+	HTFSecs    []int64 // bucket length of each HTF entry
+	Scorer     pressure.ScorerState
+	ScoreDyn   pressure.Dynamics
+	TFScorers  []TFScorerState    // per-HTF scorers, same order as HTF
+	Book1m     candleBook         // zero in checkpoints saved before candle book stats
+	HTFBook    []candleBook       // same order as HTF
+	Warmup     []warmup           // score, then HTF order; nil in checkpoints saved before warmup (= warm)
+	Seasonal   *pressure.Seasonal // nil = off, or saved before time-of-day normalization
+	ThinBook   thinBook           // zero in checkpoints saved before the thin-book flag
+	Structure  structure          // zero in checkpoints saved before the structure tracker
+	OpenRange  openRange          // zero in checkpoints saved before opening ranges
+	Session    session.Tracker
+	Rolling    vwap.Rolling
+	Anchors    []vwap.Anchor
+	Tape       flow.Tape
+	Effort1s   flow.EffortResult
+	Effort1m   flow.EffortResult
+	VPIN       flow.VPIN
+	Lambda     flow.Lambda
+	Indicators []indicators.Set
+	Squeeze    []indicators.Squeeze
+	Profile    profile.Profile
+	TPO        *tpo.Profile // nil in checkpoints saved before the market profile
+	Position   positioning.Tracker
+}
+
+// TFScorerState — a per-timeframe scorer (see tfscore.go).
+type TFScorerState struct {
+	Scorer pressure.ScorerState
+	Candle tfScorerState
+}
+
+// Checkpoint captures the current state. Engine goroutine only.
+func (e *Engine) Checkpoint() Checkpoint {
+	cp := Checkpoint{
+		Version:   CheckpointVersion,
+		SavedAt:   time.Now().UnixMilli(),
+		CVD:       e.CVD,
+		CVDAnchor: e.saveCVD(),
+		LastPrice: e.LastPrice,
+		LastTrade: e.lastTradeTime,
+		Candle1s:  saveCandle(&e.Candle1s),
+		Candle1m:  saveCandle(&e.Candle1m),
+		Book1m:    e.book1m,
+		Scorer:    e.scorer.State(),
+		Session:   e.sessions,
+		Tape:      e.tape,
+		Effort1s:  e.effort1s,
+		Effort1m:  e.effort1m,
+		VPIN:      *e.vpin,
+		Lambda:    e.lambda,
+		Rolling: vwap.Rolling{
+			BucketSec: e.rolling.BucketSec,
+			Buckets:   append([]vwap.Accumulator(nil), e.rolling.Buckets...),
+			Times:     append([]int64(nil), e.rolling.Times...),
+		},
+	}
+	cp.Anchors = append(cp.Anchors, e.anchors[:e.numAnchors]...)
+	cp.VPIN.Imbalance = append([]float64(nil), e.vpin.Imbalance...)
+	cp.ScoreDyn = *e.scoreDyn
+	cp.ScoreDyn.Window = append([]int8(nil), e.scoreDyn.Window...)
+	cp.Profile = *e.profile
+	cp.Profile.Vol = append([]float64(nil), e.profile.Vol...)
+	cp.Profile.Levels = append([]profile.Level(nil), e.profile.Levels...)
+	t := *e.tpo
+	t.Count = append([]int32(nil), e.tpo.Count...)
+	cp.TPO = &t
+	cp.Position = *e.position
+	cp.Position.OIs = append([]float64(nil), e.position.OIs...)
+	cp.Position.Prices = append([]float64(nil), e.position.Prices...)
+	for _, ind := range e.indicators {
+		c := *ind
+		c.Ribbon = append([]indicators.EMA(nil), ind.Ribbon...)
+		cp.Indicators = append(cp.Indicators, c)
+	}
+	for _, sq := range e.squeeze {
+		cp.Squeeze = append(cp.Squeeze, *sq)
+	}
+	for i := 0; i < e.numHTF; i++ {
+		cp.HTF = append(cp.HTF, saveCandle(&e.HTF[i]))
+		cp.HTFSecs = append(cp.HTFSecs, e.htfs[i].Seconds)
+		t := e.tfScorers[i]
+		cp.TFScorers = append(cp.TFScorers, TFScorerState{Scorer: t.scorer.State(), Candle: t.tfScorerState})
+		cp.HTFBook = append(cp.HTFBook, e.htfBook[i])
+	}
+	cp.Warmup = append([]warmup{e.scoreWarmup}, e.htfWarmup[:e.numHTF]...)
+	if e.seasonal != nil {
+		s := *e.seasonal
+		cp.Seasonal = &s
+	}
+	cp.ThinBook = e.thin
+	cp.ThinBook.Depth = append([]float64(nil), e.thin.Depth...)
+	cp.Structure = e.structure
+	cp.Structure.Highs = append([]float64(nil), e.structure.Highs...)
+	cp.Structure.Lows = append([]float64(nil), e.structure.Lows...)
+	cp.OpenRange = e.openRange
+	return cp
+}
+
+// Restore loads a checkpoint. Engine goroutine only, before the first trade.
+func (e *Engine) Restore(cp Checkpoint) {
+	e.CVD = cp.CVD
+	e.restoreCVD(cp.CVDAnchor)
+	e.LastPrice = cp.LastPrice
+	e.lastTradeTime = cp.LastTrade
+	restoreCandle(&e.Candle1s, cp.Candle1s)
+	restoreCandle(&e.Candle1m, cp.Candle1m)
+	e.book1m = cp.Book1m
+	// Before warmup was saved, a checkpoint means a warm engine
+	e.scoreWarmup.N = e.scoreWarmup.Need
+	if len(cp.Warmup) > 0 {
+		e.scoreWarmup = cp.Warmup[0]
+	}
+	for j := range cp.HTF {
+		for i := 0; i < e.numHTF; i++ {
+			if j < len(cp.HTFSecs) && e.htfs[i].Seconds == cp.HTFSecs[j] {
+				restoreCandle(&e.HTF[i], cp.HTF[j])
+				if j < len(cp.TFScorers) {
+					e.tfScorers[i].scorer.Restore(cp.TFScorers[j].Scorer)
+					e.tfScorers[i].tfScorerState = cp.TFScorers[j].Candle
+				}
+				if j < len(cp.HTFBook) {
+					e.htfBook[i] = cp.HTFBook[j]
+				}
+				if len(cp.Warmup) == 0 {
+					e.htfWarmup[i].N = e.htfWarmup[i].Need
+				} else if j+1 < len(cp.Warmup) {
+					e.htfWarmup[i] = cp.Warmup[j+1]
+				}
+			}
+		}
+	}
+	e.scorer.Restore(cp.Scorer)
+	if e.seasonal != nil && cp.Seasonal != nil {
+		*e.seasonal = *cp.Seasonal
+	}
+	if len(cp.ThinBook.Depth) <= thinBookWindow && cp.ThinBook.Next < max(len(cp.ThinBook.Depth), 1) {
+		pct := e.thin.Pct // configuration
+		e.thin = cp.ThinBook
+		e.thin.Pct = pct
+	}
+	// A different range length starts fresh
+	if s := &cp.Structure; s.Window == e.structure.Window && len(s.Highs) == len(s.Lows) &&
+		len(s.Highs) <= s.Window && s.Next < max(len(s.Highs), 1) {
+		e.structure = *s
+	}
+	if cp.OpenRange.Lengths == e.openRange.Lengths {
+		e.openRange = cp.OpenRange
+	}
+	if len(cp.ScoreDyn.Window) == len(e.scoreDyn.Window) {
+		*e.scoreDyn = cp.ScoreDyn
+	}
+	e.sessions = cp.Session
+	e.tape = cp.Tape
+	e.effort1s = cp.Effort1s
+	e.effort1m = cp.Effort1m
+	e.lambda = cp.Lambda
+	for _, saved := range cp.Indicators {
+		for _, ind := range e.indicators {
+			if ind.Seconds == saved.Seconds {
+				ind.Restore(saved)
+			}
+		}
+	}
+	for _, saved := range cp.Squeeze {
+		for _, sq := range e.squeeze {
+			if sq.Seconds == saved.Seconds {
+				*sq = saved
+			}
+		}
+	}
+	// Buckets of a different size or count aren't comparable — start fresh
+	if cp.VPIN.BucketVol == e.vpin.BucketVol && len(cp.VPIN.Imbalance) == len(e.vpin.Imbalance) {
+		*e.vpin = cp.VPIN
+	}
+	if cp.Profile.Tick == e.profile.Tick {
+		*e.profile = cp.Profile
+	}
+	if cp.TPO != nil && cp.TPO.Tick == e.tpo.Tick {
+		*e.tpo = *cp.TPO
+	}
+	if len(cp.Position.OIs) == len(e.position.OIs) && len(cp.Position.Prices) == len(e.position.Prices) {
+		*e.position = cp.Position
+	}
+	if cp.Rolling.BucketSec == e.rolling.BucketSec && len(cp.Rolling.Buckets) == len(e.rolling.Buckets) {
+		*e.rolling = cp.Rolling
+	}
+	e.numAnchors = copy(e.anchors[:], cp.Anchors)
+	atomic.StoreInt32(&e.anchorCount, int32(e.numAnchors))
+	for i := 0; i < e.numAnchors; i++ {
+		if e.anchors[i].ID > atomic.LoadInt64(&e.nextAnchorID) {
+			atomic.StoreInt64(&e.nextAnchorID, e.anchors[i].ID)
+		}
+	}
+	e.publishPrice(cp.LastPrice)
+}
+
+// SaveCheckpoint writes a checkpoint to path.
+func SaveCheckpoint(path string, cp Checkpoint) error {
+	return state.WriteGob(path, cp)
+}
+
+// LoadCheckpoint reads a checkpoint; ok=false if none exists.
+func LoadCheckpoint(path string) (cp Checkpoint, ok bool, err error) {
+	ok, err = state.ReadGob(path, &cp)
+	if ok && cp.Version != CheckpointVersion {
+		return cp, false, fmt.Errorf("%s: version %d, want %d", path, cp.Version, CheckpointVersion)
+	}
+	return cp, ok, err
+}
+
+func saveCandle(c *CandleDelta) CandleState {
+	return CandleState{
+		Time:      c.Time,
+		Open:      c.Open,
+		High:      c.High,
+		Low:       c.Low,
+		Close:     c.Close,
+		BuyVol:    c.BuyVol,
+		SellVol:   c.SellVol,
+		Delta:     c.Delta,
+		AvgScore:  c.AvgScore,
+		BuyCount:  c.BuyCount,
+		SellCount: c.SellCount,
+		DeltaHigh: c.DeltaHigh,
+		DeltaLow:  c.DeltaLow,
+	}
+}
+
+// restoreCandle — copies the saved fields, keeping c.scoreAlpha.
+func restoreCandle(c *CandleDelta, s CandleState) {
+	c.Time = s.Time
+	c.Open = s.Open
+	c.High = s.High
+	c.Low = s.Low
+	c.Close = s.Close
+	c.BuyVol = s.BuyVol
+	c.SellVol = s.SellVol
+	c.Delta = s.Delta
+	c.AvgScore = s.AvgScore
+	c.BuyCount = s.BuyCount
+	c.SellCount = s.SellCount
+	c.DeltaHigh = s.DeltaHigh
+	c.DeltaLow = s.DeltaLow
+}
@@ -0,0 +1,187 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/session"
+)
+
+// =============================================================================
+// ANCHORED CVD — reset policies
+// =============================================================================
+//
+// Engine.CVD is the lifetime sum since the process (or its checkpoint chain)
+// started, so its level says more about uptime than about the market. The
+// anchored CVD restarts from zero at a fixed point:
+//
+//   none       anchored = lifetime
+//   daily      since 00:00 UTC
+//   session    since the current Asia / London / NY open (internal/session)
+//   rolling:W  over the trailing window W:
+//                CVD_W(t) = CVD(t) − CVD(t − W)
+//              with CVD(t − W) read from marks taken at the end of each of
+//              cvdRollingSlots buckets of W / cvdRollingSlots (≥ 1s), so the
+//              window is exact to one bucket
+//
+// Both values go out in every snapshot (Snapshot.CVD = lifetime,
+// Snapshot.CVDAnchor = anchored). The scorers, tfScorers and lead-lag feed
+// keep using the lifetime CVD: they difference it, and a reset would read as
+// a burst of selling.
+//
+// TRADING INTERPRETATION:
+//   Daily CVD above zero with price below the day open = buyers absorbing a
+//   sell-off; session CVD flipping sign at the London open = the new
+//   session's flow disagreeing with Asia's. Rolling CVD is the uptime-free
+//   level to compare across restarts and symbols.
+// =============================================================================
+
+// CVD reset modes.
+const (
+	CVDNone = iota
+	CVDDaily
+	CVDSession
+	CVDRolling
+)
+
+const (
+	cvdRollingSlots = 600
+	maxCVDWindow    = 7 * 24 * time.Hour
+)
+
+// CVDReset — anchored CVD policy.
+type CVDReset struct {
+	Mode   int
+	Window time.Duration // CVDRolling only
+}
+
+// ParseCVDReset parses "none", "daily", "session" or "rolling:<duration>"
+// (e.g. "rolling:4h").
+func ParseCVDReset(spec string) (CVDReset, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	switch spec {
+	case "", "none":
+		return CVDReset{Mode: CVDNone}, nil
+	case "daily":
+		return CVDReset{Mode: CVDDaily}, nil
+	case "session":
+		return CVDReset{Mode: CVDSession}, nil
+	}
+	if w, ok := strings.CutPrefix(spec, "rolling:"); ok {
+		d, err := time.ParseDuration(w)
+		if err != nil || d < time.Second || d > maxCVDWindow || d%time.Second != 0 {
+			return CVDReset{}, fmt.Errorf("cvd reset %q: rolling window must be whole seconds in 1s..%v", spec, maxCVDWindow)
+		}
+		return CVDReset{Mode: CVDRolling, Window: d}, nil
+	}
+	return CVDReset{}, fmt.Errorf("cvd reset %q: want none, daily, session or rolling:<duration>", spec)
+}
+
+func (r CVDReset) String() string {
+	switch r.Mode {
+	case CVDDaily:
+		return "daily"
+	case CVDSession:
+		return "session"
+	case CVDRolling:
+		return "rolling:" + r.Window.String()
+	}
+	return "none"
+}
+
+// CVDAnchorState — anchored CVD state (checkpointed).
+type CVDAnchorState struct {
+	Reset string    // policy (CVDReset.String); restored only under the same one
+	Start int64     // unix seconds the anchor began (0 = no trade yet)
+	Base  float64   // lifetime CVD at Start
+	Marks []float64 // rolling: lifetime CVD at the end of each bucket, by bucket % len
+	Last  int64     // rolling: latest bucket
+}
+
+// cvdAnchor — the policy and its state. Engine goroutine only.
+type cvdAnchor struct {
+	reset  CVDReset
+	bucket int64 // rolling: seconds per mark
+	slots  int64 // rolling: buckets per window
+	CVDAnchorState
+}
+
+// SetCVDReset selects the anchored CVD policy. Call before the first trade
+// (and before Restore, which keeps the saved anchor only under the same
+// policy).
+func (e *Engine) SetCVDReset(r CVDReset) {
+	a := cvdAnchor{reset: r}
+	a.Reset = r.String()
+	if r.Mode == CVDRolling {
+		w := int64(r.Window / time.Second)
+		a.bucket = max(1, w/cvdRollingSlots)
+		a.slots = w / a.bucket
+		a.Marks = make([]float64, a.slots+1)
+	}
+	e.cvd = a
+}
+
+// addCVD — adds delta to the lifetime CVD, first moving the anchor to the
+// period containing sec. Heartbeats pass delta = 0.
+func (e *Engine) addCVD(sec int64, delta float64) {
+	a := &e.cvd
+	switch a.reset.Mode {
+	case CVDDaily, CVDSession:
+		start := sec / 86400 * 86400
+		if a.reset.Mode == CVDSession {
+			start = session.StartOf(sec)
+		}
+		if start > a.Start {
+			a.Start, a.Base = start, e.CVD
+		}
+	case CVDRolling:
+		b, n := sec/a.bucket, int64(len(a.Marks))
+		if a.Start == 0 {
+			a.Start, a.Base, a.Last = b*a.bucket, e.CVD, b
+		}
+		if b > a.Last {
+			// Buckets without a trade end at the CVD they started with
+			for k := max(a.Last+1, b-n+1); k < b; k++ {
+				a.Marks[k%n] = e.CVD
+			}
+			a.Last = b
+		}
+	}
+	e.CVD += delta
+	if a.reset.Mode == CVDRolling {
+		a.Marks[a.Last%int64(len(a.Marks))] = e.CVD
+	}
+}
+
+// snapshotCVD — the anchored CVD and when its anchor began (unix ms; 0 =
+// lifetime).
+func (e *Engine) snapshotCVD() model.CVDSnapshot {
+	a := &e.cvd
+	switch a.reset.Mode {
+	case CVDDaily, CVDSession:
+		return model.CVDSnapshot{Anchored: e.CVD - a.Base, Since: a.Start * 1000}
+	case CVDRolling:
+		from := a.Last - a.slots // bucket whose end opens the window
+		if from*a.bucket < a.Start {
+			return model.CVDSnapshot{Anchored: e.CVD - a.Base, Since: a.Start * 1000}
+		}
+		return model.CVDSnapshot{Anchored: e.CVD - a.Marks[from%int64(len(a.Marks))], Since: (from + 1) * a.bucket * 1000}
+	}
+	return model.CVDSnapshot{Anchored: e.CVD}
+}
+
+// saveCVD / restoreCVD — checkpoint copies.
+func (e *Engine) saveCVD() CVDAnchorState {
+	s := e.cvd.CVDAnchorState
+	s.Marks = append([]float64(nil), s.Marks...)
+	return s
+}
+
+func (e *Engine) restoreCVD(s CVDAnchorState) {
+	if s.Reset != e.cvd.Reset || len(s.Marks) != len(e.cvd.Marks) {
+		return // different policy: the anchor restarts with the next trade
+	}
+	e.cvd.CVDAnchorState = s
+}
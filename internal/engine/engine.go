@@ -1,11 +1,24 @@
 package engine
 
 import (
+	"market-indikator/internal/account"
+	"market-indikator/internal/analyzer"
+	"market-indikator/internal/calendar"
+	"market-indikator/internal/flow"
+	"market-indikator/internal/formula"
+	"market-indikator/internal/indicators"
+	"market-indikator/internal/leadlag"
 	"market-indikator/internal/model"
 	oi "market-indikator/internal/oi"
 	"market-indikator/internal/orderbook"
+	"market-indikator/internal/positioning"
 	"market-indikator/internal/pressure"
+	"market-indikator/internal/profile"
+	"market-indikator/internal/session"
+	"market-indikator/internal/tpo"
+	"market-indikator/internal/vwap"
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -18,6 +31,13 @@ import (
 //   BuyVol:  Σ qty where aggressive buy
 //   SellVol: Σ qty where aggressive sell
 //   Delta:   BuyVol - SellVol
+//   BuyCount / SellCount: number of aggressive buy / sell trades
+//     (count imbalance vs volume imbalance: many small buyers against one
+//     large seller shows +count, −delta)
+//   DeltaHigh / DeltaLow: running max / min of Delta within the bucket
+//   DeltaPct: 100 × Delta / (BuyVol + SellVol)
+//     (a bar closing at Delta ≈ 0 with DeltaHigh ≫ 0 had buyers pushing
+//     first and getting absorbed — trapped longs the net Delta hides)
 //   AvgScore: EMA of per-tick finalScore within the bucket
 //
 // TIMEFRAME PRESSURE AGGREGATION:
@@ -30,6 +50,10 @@ import (
 //     4h:  N=500  (α≈0.004)  — very heavy
 //     1d:  N=1000 (α≈0.002)  — structural trend
 //
+//   The HTF set is configurable (model.ParseTimeframes, e.g. adding 30m/1w or
+//   a 15s scalping bucket); timeframes outside the tuned set get
+//   N = 50·√(seconds/300). Weekly buckets start Monday 00:00 UTC.
+//
 //   This gives each timeframe its own responsiveness profile:
 //     - 5m score changes quickly → short-term momentum
 //     - 1d score changes slowly → structural bias
@@ -57,26 +81,42 @@ type CandleDelta struct {
 	SellVol  float64
 	Delta    float64
 	AvgScore float64 // EMA of per-tick finalScore within this bucket
+	BuyCount   int64
+	SellCount  int64
+	DeltaHigh  float64 // running max of Delta within the bucket
+	DeltaLow   float64 // running min of Delta within the bucket
 	scoreAlpha float64 // EMA alpha for this timeframe
 }
 
-// Timeframe definitions: label, bucket duration in seconds, EMA alpha for score
-type tfDef struct {
-	Seconds int64
-	Alpha   float64
-}
+// Staleness windows for the data-quality flags.
+// Depth arrives every 100ms and OI every 3s, so these allow for a few missed
+// updates before the input is considered frozen.
+const (
+	depthStaleMs = 2000
+	oiStaleMs    = 10000
+	tradeGapMs   = 5000
+)
 
-// We maintain 7 timeframe buckets beyond 1s/1m:
-// Index: 0=5m, 1=15m, 2=1h, 3=4h, 4=1d
-const NumHTF = 5
+// bookUnusable — quality flags under which the scorer drops the passive
+// (orderbook) domain: a frozen book, or one whose latest frame was garbage.
+const bookUnusable = model.QualityDepthStale | model.QualityDepthSuspect
 
-var htfDefs = [NumHTF]tfDef{
-	{300, 0.039},    // 5m:  N≈50
-	{900, 0.020},    // 15m: N≈100
-	{3600, 0.010},   // 1h:  N≈200
-	{14400, 0.004},  // 4h:  N≈500
-	{86400, 0.002},  // 1d:  N≈1000
-}
+// VPIN defaults: 100 BTC buckets (≈ a minute of BTCUSDT perp volume) over
+// 50 buckets.
+const (
+	DefaultVPINBucket  = 100.0
+	DefaultVPINBuckets = 50
+)
+
+// DefaultProfileTick — volume-profile bin width (price units; 10 USDT on
+// BTCUSDT).
+const DefaultProfileTick = 10.0
+
+// Rolling VWAP window: 60 × 1m buckets = trailing hour.
+const (
+	rollingVWAPBucket  = 60
+	rollingVWAPBuckets = 60
+)
 
 // Engine — integrates all analytics + multi-timeframe candles.
 type Engine struct {
@@ -85,11 +125,68 @@ type Engine struct {
 
 	Candle1s CandleDelta
 	Candle1m CandleDelta
-	HTF      [NumHTF]CandleDelta // 5m, 15m, 1h, 4h, 1d
+	HTF      [model.MaxHTF]CandleDelta // one per model.HTFs entry
+
+	htfs      [model.MaxHTF]model.Timeframe
+	numHTF    int
+	tfScorers [model.MaxHTF]*tfScorer // per-HTF independent scores (tfscore.go)
+	book1m    candleBook              // per-candle book statistics (candlebook.go)
+	htfBook   [model.MaxHTF]candleBook
+
+	scoreWarmup warmup // cold-start warmup (warmup.go)
+	htfWarmup   [model.MaxHTF]warmup
+	thin        thinBook // thin-book regime (thinbook.go)
 
 	book     *orderbook.Book
 	oiEngine *oi.Engine
 	scorer   *pressure.Scorer
+	scoreDyn *pressure.Dynamics
+	seasonal *pressure.Seasonal // nil = no time-of-day normalization (seasonal.go)
+	sessions session.Tracker
+	rolling  *vwap.Rolling
+	tape     flow.Tape
+	effort1s flow.EffortResult
+	effort1m flow.EffortResult
+	vpin     *flow.VPIN
+	lambda   flow.Lambda
+	profile  *profile.Profile
+	tpo      *tpo.Profile
+	position *positioning.Tracker
+	leader   *leadlag.Tracker     // nil = no leader feed
+	derivs   *positioning.Feed    // nil = no markPrice / forceOrder feed
+	l1       *orderbook.L1Tracker // nil = no bookTicker feed (l1.go)
+	l1Flow   l1Flow
+	cvd      cvdAnchor        // anchored CVD policy (cvd.go)
+	account  *account.Tracker // nil = no user-data stream
+	formulas *formula.Set  // nil = none
+	custom   *analyzer.Set // nil = none
+
+	// Scheduled-event windows (nil = no calendar)
+	calendar *calendar.Calendar
+
+	// Rolling range, swing points and breakouts (structure.go)
+	structure structure
+
+	// Session opening range and breakout (openrange.go)
+	openRange openRange
+
+	indicators [model.NumIndicatorTFs]*indicators.Set   // 1m, 5m, 1h
+	squeeze    [model.NumSqueezeTFs]*indicators.Squeeze // 5m, 15m
+
+	// Latest effort-vs-result readings (updated with the candles)
+	er1s, er1m float64
+	events     int
+
+	// Anchored VWAPs (anchors.go). anchors/numAnchors are engine-goroutine
+	// only; the rest is shared with AddAnchor/RemoveAnchor callers.
+	anchors      [model.MaxAnchors]vwap.Anchor
+	numAnchors   int
+	anchorCmds   chan anchorCmd
+	anchorCount  int32 // atomic, active + queued
+	nextAnchorID int64 // atomic
+
+	lastTradeTime int64        // exchange time (ms) of the previous trade
+	clock         func() int64 // wall clock, unix ms (nil = time.Now)
 
 	pricePtr unsafe.Pointer
 }
@@ -100,20 +197,136 @@ func NewEngine(book *orderbook.Book, oiEngine *oi.Engine) *Engine {
 		book:     book,
 		oiEngine: oiEngine,
 		scorer:   pressure.NewScorer(),
+		scoreDyn: pressure.NewDynamics(),
+		rolling:  vwap.NewRolling(rollingVWAPBucket, rollingVWAPBuckets),
+		vpin:     flow.NewVPIN(DefaultVPINBucket, DefaultVPINBuckets),
+		profile:  profile.New(DefaultProfileTick),
+		tpo:      tpo.New(DefaultProfileTick),
+		position: positioning.New(),
+
+		structure: structure{Window: DefaultStructureWindow},
+
+		anchorCmds: make(chan anchorCmd, 2*model.MaxAnchors),
 	}
 	atomic.StorePointer(&e.pricePtr, unsafe.Pointer(&initial))
 
-	// Initialize EMA alphas for HTF buckets
-	for i := 0; i < NumHTF; i++ {
-		e.HTF[i].scoreAlpha = htfDefs[i].Alpha
+	for i, sec := range model.IndicatorTFs {
+		e.indicators[i] = indicators.NewSet(sec, model.RibbonPeriods)
+	}
+	for i, sec := range model.SqueezeTFs {
+		e.squeeze[i] = indicators.NewSqueeze(sec)
+	}
+
+	// HTF buckets follow the configured timeframe set
+	e.numHTF = copy(e.htfs[:], model.HTFs)
+	for i := 0; i < e.numHTF; i++ {
+		e.HTF[i].scoreAlpha = e.htfs[i].Alpha
+		e.tfScorers[i] = newTFScorer()
 	}
 	// 1s and 1m use faster alphas
 	e.Candle1s.scoreAlpha = 0.333 // N≈5
 	e.Candle1m.scoreAlpha = 0.065 // N≈30
+	e.initWarmup()
 
 	return e
 }
 
+// SetClock replaces the wall clock input ages are measured against (replays
+// and deterministic test harnesses). Call before the first trade.
+func (e *Engine) SetClock(now func() int64) {
+	e.clock = now
+}
+
+// SetIntensityGain enables tape-intensity scaling of the aggressive pressure
+// domain (see pressure.Scorer.IntensityGain). Call before the first trade.
+func (e *Engine) SetIntensityGain(gain float64) {
+	e.scorer.IntensityGain = gain
+}
+
+// SetScoreWeights replaces the composite's weights (see
+// pressure.ParseWeights), for the final and the per-HTF scores. Engine
+// goroutine only; unlike the smoother and normalizer it may change between
+// trades — the EMA state is kept.
+func (e *Engine) SetScoreWeights(w pressure.Weights) {
+	e.scorer.Weights = w
+	for i := 0; i < e.numHTF; i++ {
+		e.tfScorers[i].scorer.Weights = w
+	}
+}
+
+// SetScoreSmoother replaces the final score's smoothing stage (see
+// pressure.ParseSmoother). Call before the first trade (and before Restore).
+func (e *Engine) SetScoreSmoother(sm pressure.Smoother) {
+	e.scorer.SetSmoother(sm)
+}
+
+// SetScoreNormalizer replaces the scorer's input normalization (see
+// pressure.ParseNormalizer). Call before the first trade (and before Restore).
+func (e *Engine) SetScoreNormalizer(f pressure.NormalizerFactory) {
+	e.scorer.SetNormalizer(f)
+}
+
+// SetVPIN replaces the VPIN estimator with bucketVol-sized buckets over a
+// window of n. Call before the first trade (and before Restore).
+func (e *Engine) SetVPIN(bucketVol float64, n int) {
+	e.vpin = flow.NewVPIN(bucketVol, n)
+}
+
+// SetProfileTick replaces the volume and market (TPO) profiles with ones
+// binned at tick price units. Call before the first trade (and before Restore).
+func (e *Engine) SetProfileTick(tick float64) {
+	e.profile = profile.New(tick)
+	e.tpo = tpo.New(tick)
+}
+
+// SetLeader pairs the traded symbol with a leader symbol's feed for the
+// lead-lag statistics (see internal/leadlag). Call before the first trade.
+func (e *Engine) SetLeader(feed *leadlag.Feed) {
+	e.leader = leadlag.NewTracker(feed)
+}
+
+// SetDerivs feeds funding, basis and liquidations (see
+// ingest.DerivsIngester) into the positioning context. Call before the
+// first trade.
+func (e *Engine) SetDerivs(feed *positioning.Feed) {
+	e.derivs = feed
+}
+
+// updatePosition feeds the positioning tracker, with the derivatives
+// reading when there is a feed.
+func (e *Engine) updatePosition(sec int64, price float64, st *oi.State) {
+	if e.derivs == nil {
+		e.position.Update(sec, price, st, nil)
+		return
+	}
+	d := e.derivs.Get()
+	e.position.Update(sec, price, st, &d)
+}
+
+// SetAccount overlays the user's own position (see internal/account) on
+// every snapshot. Call before the first trade.
+func (e *Engine) SetAccount(acct *account.Tracker) {
+	e.account = acct
+}
+
+// SetAnalyzers installs the custom analyzers feeding Snapshot.Custom (see
+// internal/analyzer). Call before the first trade.
+func (e *Engine) SetAnalyzers(set *analyzer.Set) {
+	e.custom = set
+}
+
+// SetCalendar flags snapshots inside the calendar's event windows with
+// QualityEventRisk. Call before the first trade.
+func (e *Engine) SetCalendar(c *calendar.Calendar) {
+	e.calendar = c
+}
+
+// SetFormulas installs the user-defined scores evaluated into
+// Snapshot.Formulas (see internal/formula). Call before the first trade.
+func (e *Engine) SetFormulas(set *formula.Set) {
+	e.formulas = set
+}
+
 func (e *Engine) GetPrice() float64 {
 	p := (*float64)(atomic.LoadPointer(&e.pricePtr))
 	if p == nil {
@@ -122,58 +335,220 @@ func (e *Engine) GetPrice() float64 {
 	return *p
 }
 
+// publishPrice makes price visible to GetPrice readers (OI poller).
+func (e *Engine) publishPrice(price float64) {
+	priceCopy := price
+	atomic.StorePointer(&e.pricePtr, unsafe.Pointer(&priceCopy))
+}
+
 // ProcessTrade — HOT PATH.
-// ~250ns total: CVD + 7 candle updates + 2 atomic reads + scorer + snapshot.
+// ~250ns total: CVD + 2+NumHTF candle updates + 2 atomic reads + scorer + snapshot.
 func (e *Engine) ProcessTrade(t model.Trade) model.Snapshot {
 	price := t.Price
 	qty := t.Quantity
 	tradeTimeSec := t.Time / 1000
 	tradeTimeMin := tradeTimeSec / 60 * 60
 
-	// ─── CVD ───
-	var delta float64
-	if t.IsBuyer {
-		// IsBuyer=true means "Maker was Buyer". This means the taker (aggressor) was a Seller.
-		delta = -qty
-	} else {
-		// IsBuyer=false means "Maker was Seller". This means the taker (aggressor) was a Buyer.
-		delta = qty
+	e.applyAnchorCmds()
+
+	// ─── CUSTOM ANALYZERS ───
+	if e.custom != nil {
+		e.custom.OnTrade(&t)
+		e.custom.OnDepth(e.book)
 	}
-	e.CVD += delta
+
+	// ─── CVD ───
+	// Aggressor side per the venue's convention (model.Side)
+	delta := t.SignedQty()
+	e.addCVD(tradeTimeSec, delta)
 	e.LastPrice = price
 
 	// ─── PRICE PUBLISH ───
-	priceCopy := price
-	atomic.StorePointer(&e.pricePtr, unsafe.Pointer(&priceCopy))
+	e.publishPrice(price)
 
 	// ─── ORDERBOOK + OI (atomic reads, ~2ns) ───
 	press := e.book.GetPressure()
 	oiState := e.oiEngine.GetState()
 
+	// ─── DATA QUALITY ───
+	quality := e.computeQuality(t.Time, &press, oiState.UpdatedAt, true)
+
+	// ─── TAPE SPEED + VPIN ───
+	e.tape.Add(tradeTimeSec, qty)
+	e.vpin.Add(qty, delta > 0)
+
 	// ─── COMPOSITE SCORE (~30ns) ───
-	finalScore := e.scorer.Update(pressure.Input{
+	e.observeSecond(tradeTimeSec, oiState.OIDelta1m)
+	in := pressure.Input{
 		CVD:        e.CVD,
 		Delta1s:    e.Candle1s.Delta,
 		OBScore:    press.Score,
 		OIDelta1m:  oiState.OIDelta1m,
 		OIBehavior: oiState.Behavior,
-	})
+		BookStale:  quality.Flags&bookUnusable != 0,
+		OIStale:    quality.Flags&model.QualityOIStale != 0,
+		ThinBook:   quality.Flags&model.QualityThinBook != 0,
+		Intensity:  e.tape.IntensityZ(),
+	}
+	e.seasonalInput(tradeTimeSec, &in)
+	finalScore := e.scorer.Update(in)
+	e.scoreDyn.Update(tradeTimeSec, finalScore)
+	e.updateWarmup(t.Time, true)
 
 	// ─── CANDLE UPDATES ───
 	// 1s and 1m
 	updateCandle(&e.Candle1s, tradeTimeSec, price, qty, delta, finalScore)
 	updateCandle(&e.Candle1m, tradeTimeMin, price, qty, delta, finalScore)
 
-	// HTF: 5m, 15m, 1h, 4h, 1d
-	for i := 0; i < NumHTF; i++ {
-		bucketTime := tradeTimeSec / htfDefs[i].Seconds * htfDefs[i].Seconds
-		updateCandle(&e.HTF[i], bucketTime, price, qty, delta, finalScore)
+	// HTF: configured timeframes (default 5m, 15m, 1h, 4h, 1d)
+	for i := 0; i < e.numHTF; i++ {
+		updateCandle(&e.HTF[i], e.htfs[i].Bucket(tradeTimeSec), price, qty, delta, finalScore)
+		e.tfScorers[i].update(e.HTF[i].Time, e.CVD, press.Score, &oiState, quality)
 	}
+	e.updateCandleBooks(t.Time, &press, quality)
+
+	e.updateCandleFlow()
 
-	// ─── BUILD SNAPSHOT ───
+	// ─── SESSION (Asia/London/NY open, range, VWAP) + ROLLING VWAP ───
+	e.sessions.Update(tradeTimeSec, price, qty)
+	e.rolling.Add(tradeTimeSec, price, qty)
+	e.profile.Update(tradeTimeSec, price, qty)
+	e.tpo.Update(tradeTimeSec, price)
+
+	// ─── INDICATORS (RSI / MACD / EMA ribbon on 1m, 5m, 1h) ───
+	for _, ind := range e.indicators {
+		ind.Update(tradeTimeSec, price)
+	}
+	e.updateSqueeze(tradeTimeSec, price)
+	e.updateStructure(tradeTimeSec, price, true)
+	e.updateOpenRange(tradeTimeSec, price, oiState.OIDelta1m, true)
+	e.updatePosition(tradeTimeSec, price, &oiState)
+	if e.leader != nil {
+		e.leader.Update(tradeTimeSec, price, e.CVD)
+	}
+	if e.l1 != nil {
+		e.l1Flow.touch.consume(delta)
+	}
+	e.updateL1(tradeTimeSec)
+	for i := 0; i < e.numAnchors; i++ {
+		if t.Time >= e.anchors[i].From {
+			e.anchors[i].Acc.Add(price, qty)
+		}
+	}
+
+	return e.buildSnapshot(t.Time, price, &press, &oiState, finalScore, quality)
+}
+
+// Tick — WALL-CLOCK HEARTBEAT, called by the engine goroutine when no trade
+// has arrived by the next second boundary.
+//
+// Candles otherwise only roll when a trade lands in a new bucket, so in a quiet
+// second the old 1s bucket stays "current" and keeps being reported (and its
+// delta keeps feeding the scorer). Tick advances every bucket whose period has
+// ended to a fresh, flat bucket at the last price (O=H=L=C, zero volume),
+// re-runs the scorer with zero trade flow so the score decays, and returns a
+// heartbeat snapshot flagged with QualityHeartbeat.
+//
+// nowMs is exchange time (local time + binance.Clock offset), the clock trades
+// are bucketed by; local time would roll buckets early or late by the skew.
+//
+// Returns false if nothing rolled (still inside the current second) or no
+// trade has been seen yet.
+func (e *Engine) Tick(nowMs int64) (model.Snapshot, bool) {
+	e.applyAnchorCmds()
+
+	nowSec := nowMs / 1000
+	if e.LastPrice == 0 || nowSec <= e.Candle1s.Time {
+		return model.Snapshot{}, false
+	}
+	price := e.LastPrice
+
+	press := e.book.GetPressure()
+	oiState := e.oiEngine.GetState()
+	quality := e.computeQuality(nowMs, &press, oiState.UpdatedAt, false)
+	if e.custom != nil {
+		e.custom.OnDepth(e.book)
+	}
+	e.tape.Advance(nowSec)
+	e.addCVD(nowSec, 0) // roll the CVD anchor
+
+	e.observeSecond(nowSec, oiState.OIDelta1m)
+	in := pressure.Input{
+		CVD:        e.CVD,
+		Delta1s:    0, // the 1s bucket being opened has no trades
+		OBScore:    press.Score,
+		OIDelta1m:  oiState.OIDelta1m,
+		OIBehavior: oiState.Behavior,
+		BookStale:  quality.Flags&bookUnusable != 0,
+		OIStale:    quality.Flags&model.QualityOIStale != 0,
+		ThinBook:   quality.Flags&model.QualityThinBook != 0,
+		Intensity:  e.tape.IntensityZ(),
+	}
+	e.seasonalInput(nowSec, &in)
+	finalScore := e.scorer.Update(in)
+	e.scoreDyn.Update(nowSec, finalScore)
+	e.updateWarmup(nowMs, false)
+
+	rollCandle(&e.Candle1s, nowSec, price, finalScore)
+	rollCandle(&e.Candle1m, nowSec/60*60, price, finalScore)
+	for i := 0; i < e.numHTF; i++ {
+		rollCandle(&e.HTF[i], e.htfs[i].Bucket(nowSec), price, finalScore)
+		e.tfScorers[i].update(e.HTF[i].Time, e.CVD, press.Score, &oiState, quality)
+	}
+	e.updateCandleBooks(nowMs, &press, quality)
+	e.updateCandleFlow()
+	e.sessions.Update(nowSec, price, 0)
+	e.profile.Update(nowSec, price, 0)
+	e.tpo.Update(nowSec, price)
+	for _, ind := range e.indicators {
+		ind.Update(nowSec, price)
+	}
+	e.updateSqueeze(nowSec, price)
+	e.updateStructure(nowSec, price, false)
+	e.updateOpenRange(nowSec, price, oiState.OIDelta1m, false)
+	e.updatePosition(nowSec, price, &oiState)
+	if e.leader != nil {
+		e.leader.Update(nowSec, price, e.CVD)
+	}
+	e.updateL1(nowSec)
+
+	return e.buildSnapshot(nowMs, price, &press, &oiState, finalScore, quality), true
+}
+
+// updateCandleFlow — candle-driven flow analytics: effort-vs-result on the
+// live 1s and 1m candles and the per-second price-impact sample.
+func (e *Engine) updateCandleFlow() {
+	c := &e.Candle1s
+	var ev1s, ev1m int
+	e.er1s, ev1s = e.effort1s.Update(c.Time, c.Delta, c.Open, c.High, c.Low, c.Close)
+	e.lambda.Update(c.Time, c.Delta, c.Close)
+	c = &e.Candle1m
+	e.er1m, ev1m = e.effort1m.Update(c.Time, c.Delta, c.Open, c.High, c.Low, c.Close)
+	e.events = ev1s | ev1m<<4
+}
+
+// updateSqueeze — feeds the squeeze detectors and ORs a fire in this second
+// into the event flags (after updateCandleFlow, which resets them).
+func (e *Engine) updateSqueeze(sec int64, price float64) {
+	for i, sq := range e.squeeze {
+		sq.Update(sec, price)
+		if !sq.Fired(sec) {
+			continue
+		}
+		ev := model.EventSqueezeUp5m
+		if sq.Dir < 0 {
+			ev = model.EventSqueezeDown5m
+		}
+		e.events |= ev << (2 * i)
+	}
+}
+
+// buildSnapshot — copies the current engine state into a Snapshot.
+func (e *Engine) buildSnapshot(timeMs int64, price float64, press *orderbook.Pressure,
+	oiState *oi.State, finalScore float64, quality model.QualitySnapshot) model.Snapshot {
 	snap := model.Snapshot{
 		Price:    price,
-		Time:     t.Time,
+		Time:     timeMs,
 		CVD:      e.CVD,
 		Candle1s: snapshotCandle(&e.Candle1s),
 		Candle1m: snapshotCandle(&e.Candle1m),
@@ -183,28 +558,177 @@ func (e *Engine) ProcessTrade(t model.Trade) model.Snapshot {
 			Spread:    press.Spread,
 			Imbalance: press.Imbalance,
 			Score:     press.Score,
+
+			BidCoG:  press.BidCoG,
+			AskCoG:  press.AskCoG,
+			CoGSkew: press.CoGSkew,
 		},
 		OI: model.OISnapshot{
 			OI:        oiState.OI,
 			OIDelta1s: oiState.OIDelta1s,
 			OIDelta1m: oiState.OIDelta1m,
 			Behavior:  oiState.Behavior,
+
+			Notional:        oiState.Notional,
+			NotionalDelta1s: oiState.NotionalDelta1s,
+			NotionalDelta1m: oiState.NotionalDelta1m,
+			Venues:          oiState.Venues,
 		},
 		FinalScore: finalScore,
+		Confidence: e.scorer.Confidence,
+		Quality:    quality,
+		Session:    snapshotSession(&e.sessions),
+		VWAP: model.VWAPSnapshot{
+			Session: snapshotBand(&e.sessions.Current.VWAP),
+			Day:     snapshotBand(&e.sessions.DayVWAP),
+		},
+	}
+	snap.CVDAnchor = e.snapshotCVD()
+	rolling := e.rolling.Window(timeMs / 1000)
+	snap.VWAP.Rolling = snapshotBand(&rolling)
+	for i := 0; i < e.numAnchors; i++ {
+		a := &e.anchors[i]
+		snap.Anchors[i] = model.AnchorSnapshot{ID: a.ID, From: a.From, Band: snapshotBand(&a.Acc)}
+	}
+	snap.NumAnchors = e.numAnchors
+	snap.Flow = model.FlowSnapshot{
+		TradesPerSec:   e.tape.Rate,
+		AvgTradeSize:   e.tape.AvgSize(),
+		IntensityZ:     e.tape.IntensityZ(),
+		EffortResult1s: e.er1s,
+		EffortResult1m: e.er1m,
+		Events:         e.events,
+		VPIN:           e.vpin.Value(),
+		Lambda:         e.lambda.Value(),
+	}
+	for i, ind := range e.indicators {
+		v := ind.Live()
+		out := &snap.Indicators[i]
+		out.RSI, out.MACD, out.Signal, out.Hist = v.RSI, v.MACD, v.Signal, v.Hist
+		copy(out.Ribbon[:], v.Ribbon)
+	}
+	vel, acc := e.scoreDyn.Live()
+	snap.ScoreDyn = model.ScoreDynamicsSnapshot{Velocity: vel, Accel: acc, Percentile: e.scoreDyn.Percentile()}
+	for i, l := range e.profile.Levels {
+		if i == model.MaxLevels {
+			break
+		}
+		snap.Levels[i] = model.LevelSnapshot{Price: l.Price, Kind: l.Kind, Strength: l.Strength}
+		snap.NumLevels++
+	}
+	squeezeOn := false
+	for i, sq := range e.squeeze {
+		out := &snap.Squeeze[i]
+		out.Bars, out.Ratio, out.Dir = sq.Bars, sq.Ratio, sq.Dir
+		if sq.On {
+			out.On = 1
+			squeezeOn = true
+		}
+	}
+	ctx := e.position.Live(squeezeOn)
+	snap.Context = model.ContextSnapshot{Label: ctx.Label, Side: ctx.Side, OIZ: ctx.OIZ, OIRange: ctx.OIRange,
+		Funding: ctx.Funding, BasisBps: ctx.BasisBps, Liq1m: ctx.Liq1m, LiqZ: ctx.LiqZ}
+	if e.leader != nil {
+		r := e.leader.Live()
+		snap.Leader = model.LeaderSnapshot{Lag: r.Lag, Corr: r.Corr, CVDCorr: r.CVDCorr,
+			Expected: r.Expected, Ret10s: r.Ret10s, CVD10s: r.CVD10s}
+	}
+	if q := &e.l1Flow.quote; q.UpdatedAt > 0 {
+		if q.UpdatedAt >= press.UpdatedAt {
+			snap.Orderbook.BestBid, snap.Orderbook.BestAsk, snap.Orderbook.Spread = q.Bid, q.Ask, q.Spread
+		}
+		snap.L1 = e.l1Flow.live
+	}
+	if e.account != nil {
+		a := e.account.GetState()
+		snap.Position = model.PositionSnapshot{Size: a.Size, EntryPrice: a.EntryPrice,
+			UnrealizedPnL: a.UnrealizedPnL(price), RealizedPnL: a.RealizedPnL,
+			LastFillTime: a.LastFillTime, LastFillPrice: a.LastFillPrice, LastFillQty: a.LastFillQty}
 	}
 
-	for i := 0; i < NumHTF; i++ {
+	for i := 0; i < e.numHTF; i++ {
 		snap.HTF[i] = snapshotCandle(&e.HTF[i])
+		snap.TFScore[i] = e.tfScorers[i].Score
+		snap.HTFBook[i] = e.htfBook[i].Stats
+	}
+	snap.Book1m = e.book1m.Stats
+	snap.Warmup = e.snapshotWarmup(timeMs)
+	snap.Structure = e.snapshotStructure(price)
+	snap.TPO = snapshotTPO(&e.tpo.Live)
+	snap.OpenRange = e.snapshotOpenRange()
+	if e.custom != nil {
+		e.custom.OnSnapshot(&snap, snap.Custom[:])
+	}
+	if e.formulas != nil {
+		e.formulas.Eval(&snap, snap.Formulas[:])
 	}
 
 	return snap
 }
 
+// computeQuality — input freshness for the current trade (or heartbeat).
+// Depth/OI ages are measured against the wall clock (their UpdatedAt stamps are
+// local); the trade gap uses exchange time so replays flag the same gaps. A
+// book holding a dropped (suspect) frame is flagged QualityDepthSuspect, a
+// thin one (thinbook.go) QualityThinBook; a scheduled event's window
+// (internal/calendar) QualityEventRisk.
+func (e *Engine) computeQuality(eventTime int64, press *orderbook.Pressure, oiAt int64, isTrade bool) model.QualitySnapshot {
+	now := time.Now().UnixMilli()
+	if e.clock != nil {
+		now = e.clock()
+	}
+	q := model.QualitySnapshot{DepthAgeMs: -1, OIAgeMs: -1}
+
+	if press.UpdatedAt > 0 {
+		q.DepthAgeMs = now - press.UpdatedAt
+	}
+	if q.DepthAgeMs < 0 || q.DepthAgeMs > depthStaleMs {
+		q.Flags |= model.QualityDepthStale
+	}
+	if press.Suspect != 0 {
+		q.Flags |= model.QualityDepthSuspect
+	}
+	if e.thin.observe(eventTime/1000, press.BidVol+press.AskVol, q.Flags&bookUnusable == 0) {
+		q.Flags |= model.QualityThinBook
+	}
+	if e.calendar != nil {
+		if _, ok := e.calendar.Active(eventTime); ok {
+			q.Flags |= model.QualityEventRisk
+		}
+	}
+
+	if oiAt > 0 {
+		q.OIAgeMs = now - oiAt
+	}
+	if q.OIAgeMs < 0 || q.OIAgeMs > oiStaleMs {
+		q.Flags |= model.QualityOIStale
+	}
+
+	if e.lastTradeTime > 0 {
+		q.TradeGapMs = eventTime - e.lastTradeTime
+		if q.TradeGapMs > tradeGapMs {
+			q.Flags |= model.QualityTradeGap
+		}
+	}
+	if isTrade {
+		e.lastTradeTime = eventTime
+	} else {
+		q.Flags |= model.QualityHeartbeat
+	}
+
+	return q
+}
+
 // updateCandle — updates a single candle bucket in-place.
 // Includes EMA of finalScore for multi-timeframe pressure tracking.
+//
+// A trade stamped before the current bucket (exchange time slightly behind a
+// rollover from Tick, whose exchange clock is an estimate) is folded into the
+// current bucket rather than rolling it backwards. A trade that opens a new
+// bucket is counted in it like any other.
 func updateCandle(c *CandleDelta, bucketTime int64, price, qty, delta, score float64) {
-	if c.Time != bucketTime {
-		// New bucket
+	if bucketTime > c.Time {
+		// New bucket, then accumulate its opening trade below
 		c.Time = bucketTime
 		c.Open = price
 		c.High = price
@@ -213,8 +737,11 @@ func updateCandle(c *CandleDelta, bucketTime int64, price, qty, delta, score flo
 		c.BuyVol = 0
 		c.SellVol = 0
 		c.Delta = 0
+		c.BuyCount = 0
+		c.SellCount = 0
+		c.DeltaHigh = 0
+		c.DeltaLow = 0
 		c.AvgScore = score // Initialize EMA with first score
-		return
 	}
 
 	if price > c.High {
@@ -227,25 +754,96 @@ func updateCandle(c *CandleDelta, bucketTime int64, price, qty, delta, score flo
 
 	if delta > 0 {
 		c.BuyVol += qty
+		c.BuyCount++
 	} else {
 		c.SellVol += qty
+		c.SellCount++
 	}
 	c.Delta += delta
+	if c.Delta > c.DeltaHigh {
+		c.DeltaHigh = c.Delta
+	}
+	if c.Delta < c.DeltaLow {
+		c.DeltaLow = c.Delta
+	}
 
 	// EMA of finalScore within this bucket
 	c.AvgScore = c.scoreAlpha*score + (1.0-c.scoreAlpha)*c.AvgScore
 }
 
+// rollCandle — opens an empty bucket at bucketTime if the current one has
+// ended (heartbeat path, no trade).
+func rollCandle(c *CandleDelta, bucketTime int64, price, score float64) {
+	if bucketTime <= c.Time {
+		return
+	}
+	c.Time = bucketTime
+	c.Open = price
+	c.High = price
+	c.Low = price
+	c.Close = price
+	c.BuyVol = 0
+	c.SellVol = 0
+	c.Delta = 0
+	c.BuyCount = 0
+	c.SellCount = 0
+	c.DeltaHigh = 0
+	c.DeltaLow = 0
+	c.AvgScore = score
+}
+
+func snapshotSession(t *session.Tracker) model.SessionSnapshot {
+	return model.SessionSnapshot{
+		ID:          t.ID,
+		Start:       t.Current.Start,
+		Open:        t.Current.Open,
+		High:        t.Current.High,
+		Low:         t.Current.Low,
+		VWAP:        t.Current.VWAP.VWAP(),
+		AsiaOpen:    t.Opens[session.Asia],
+		LondonOpen:  t.Opens[session.London],
+		NYOpen:      t.Opens[session.NY],
+		PrevDayHigh: t.PrevHigh,
+		PrevDayLow:  t.PrevLow,
+	}
+}
+
+func snapshotTPO(t *tpo.Stats) model.TPOSnapshot {
+	ts := model.TPOSnapshot{POC: t.POC, VAH: t.VAH, VAL: t.VAL, Brackets: t.Brackets, Singles: t.Singles}
+	if t.PoorHigh {
+		ts.PoorHigh = 1
+	}
+	if t.PoorLow {
+		ts.PoorLow = 1
+	}
+	return ts
+}
+
+func snapshotBand(a *vwap.Accumulator) model.BandSnapshot {
+	return model.NewBand(a.VWAP(), a.Sigma())
+}
+
 func snapshotCandle(c *CandleDelta) model.CandleSnapshot {
-	return model.CandleSnapshot{
-		Time:     c.Time,
-		Open:     c.Open,
-		High:     c.High,
-		Low:      c.Low,
-		Close:    c.Close,
-		BuyVol:   c.BuyVol,
-		SellVol:  c.SellVol,
-		Delta:    c.Delta,
-		AvgScore: c.AvgScore,
+	s := model.CandleSnapshot{
+		Time:      c.Time,
+		Open:      c.Open,
+		High:      c.High,
+		Low:       c.Low,
+		Close:     c.Close,
+		BuyVol:    c.BuyVol,
+		SellVol:   c.SellVol,
+		Delta:     c.Delta,
+		AvgScore:  c.AvgScore,
+		BuyCount:  c.BuyCount,
+		SellCount: c.SellCount,
+		DeltaHigh: c.DeltaHigh,
+		DeltaLow:  c.DeltaLow,
+	}
+	if n := c.BuyCount + c.SellCount; n > 0 {
+		s.AvgSize = (c.BuyVol + c.SellVol) / float64(n)
+	}
+	if vol := c.BuyVol + c.SellVol; vol > 0 {
+		s.DeltaPct = 100 * c.Delta / vol
 	}
+	return s
 }
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"market-indikator/internal/model"
+	"market-indikator/internal/orderbook"
+)
+
+// =============================================================================
+// L1 OVERLAY — bookTicker quote and windowed OFI
+// =============================================================================
+//
+// With a bookTicker feed (SetL1) the snapshot's best bid / ask / spread come
+// from whichever is newer, the last quote or the last depth frame — between
+// 100ms depth snapshots the touch can move several ticks. The tracker's OFI
+// is cumulative; when a read falls in a new second, the value seen at the
+// previous read becomes that second's mark (quote updates between the two
+// reads count toward the new second), and
+//
+//   OFI1s  = OFI(now) − OFI(mark of the current second)
+//   OFI10s = OFI(now) − OFI(oldest mark within the last ofiWindow seconds)
+//
+// Trades and heartbeats read at least once a second; seconds with no read
+// leave no mark, so after a gap the 10s window is the span actually covered.
+// Each read also advances the touch replenishment (replenish.go).
+// =============================================================================
+
+const ofiWindow = 10 // seconds
+
+// l1Flow — per-second marks of the cumulative OFI. Engine goroutine only.
+type l1Flow struct {
+	marks [ofiWindow + 1]float64
+	secs  [ofiWindow + 1]int64
+	last  float64 // OFI at the previous read
+	live  model.L1Snapshot
+	quote orderbook.L1
+	touch replenish
+}
+
+// SetL1 enables the bookTicker overlay (see orderbook.L1Tracker). Call
+// before the first trade.
+func (e *Engine) SetL1(t *orderbook.L1Tracker) {
+	e.l1 = t
+}
+
+// updateL1 reads the latest quote and advances the OFI windows to sec.
+func (e *Engine) updateL1(sec int64) {
+	if e.l1 == nil {
+		return
+	}
+	f := &e.l1Flow
+	q := e.l1.Get()
+	f.quote = q
+	if q.UpdatedAt == 0 {
+		return
+	}
+
+	i := sec % (ofiWindow + 1)
+	if f.secs[i] != sec {
+		f.secs[i], f.marks[i] = sec, f.last
+	}
+	f.last = q.OFI
+	oldestSec, oldest := sec, f.marks[i]
+	for j, s := range f.secs {
+		if s >= sec-ofiWindow && s < oldestSec {
+			oldestSec, oldest = s, f.marks[j]
+		}
+	}
+	f.touch.update(sec, &q)
+	f.live = model.L1Snapshot{
+		BidQty:      q.BidQty,
+		AskQty:      q.AskQty,
+		Micro:       q.Micro,
+		SpreadRatio: q.SpreadRatio,
+		OFI1s:       q.OFI - f.marks[i],
+		OFI10s:      q.OFI - oldest,
+
+		BidRefill:      f.touch.bid.rate(),
+		AskRefill:      f.touch.ask.rate(),
+		BidRefillRatio: f.touch.bid.ratio(),
+		AskRefillRatio: f.touch.ask.ratio(),
+	}
+}
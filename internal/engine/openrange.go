@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/session"
+)
+
+// =============================================================================
+// OPENING-RANGE BREAKOUT — per session (internal/session)
+// =============================================================================
+//
+// The first minutes of a session set its opening range; the session's
+// direction is often decided by which side of it gives way first. For each
+// configured session (ParseOpenRange, e.g. london:30m,ny:15m):
+//
+//   range     High / Low of every price in [open, open + length); a
+//             session joined more than orJoinSlack after its open gets no
+//             range (a partial range is not an opening range)
+//   breakout  the first trade beyond High (Low) once the range is set:
+//             Dir = +1 (−1), EventORBreakUp / Down; the context at that
+//             trade is kept in Confirm:
+//               ORConfirmDelta  live 1m delta in the breakout direction
+//               ORConfirmOI     1m OI change > 0 (new positions, not
+//                               covering / liquidation)
+//   failure   a 1m close back inside the range after a breakout: Failed =
+//             the failed direction, Dir = 0, EventORFailed; the opposite
+//             side (or the same one again) can still break afterwards
+//
+// The state resets at each session open; sessions without a length have
+// none (zero snapshot).
+//
+// TRADING INTERPRETATION: a breakout with both confirmations is the setup
+// — aggressors pushing and new positions behind it. A break on delta alone
+// with OI falling is short covering (or long liquidation) running stops
+// and fades more often; a failed breakout is the reversal setup towards
+// the other side of the range.
+// =============================================================================
+
+// Opening-range confirmation bits (model.OpenRangeSnapshot.Confirm).
+const (
+	ORConfirmDelta = 1 << 0
+	ORConfirmOI    = 1 << 1
+)
+
+const orJoinSlack = 60 // seconds after the open a range may still start
+
+// OpenRange — opening-range length per session ID, seconds (0 = off).
+type OpenRange [session.NumSessions]int64
+
+// ParseOpenRange parses a comma-separated list of SESSION:DURATION
+// (asia / london / ny, whole minutes up to the session's length), e.g.
+// "london:30m,ny:15m"; "" or "none" = off.
+func ParseOpenRange(spec string) (OpenRange, error) {
+	var or OpenRange
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" || spec == "none" {
+		return or, nil
+	}
+	for _, f := range strings.Split(spec, ",") {
+		name, dur, ok := strings.Cut(strings.TrimSpace(f), ":")
+		id := -1
+		for i, n := range session.Names {
+			if strings.EqualFold(n, name) {
+				id = i
+			}
+		}
+		if !ok || id < 0 {
+			return or, fmt.Errorf("open range %q: want SESSION:DURATION, session asia, london or ny", f)
+		}
+		d, err := time.ParseDuration(dur)
+		probe := sessionProbe(id)
+		length := session.EndOf(probe) - session.StartOf(probe)
+		if err != nil || d < time.Minute || d%time.Minute != 0 || int64(d/time.Second) > length {
+			return or, fmt.Errorf("open range %q: duration must be whole minutes within the session", f)
+		}
+		or[id] = int64(d / time.Second)
+	}
+	return or, nil
+}
+
+// sessionProbe — a unix time inside session id on day 0.
+func sessionProbe(id int) int64 {
+	for sec := int64(0); sec < 86400; sec += 3600 {
+		if session.At(sec) == id {
+			return sec
+		}
+	}
+	return 0
+}
+
+// openRange — the current session's range and breakout (checkpointed).
+// Engine goroutine only.
+type openRange struct {
+	Lengths OpenRange
+
+	Start     int64 // session open, unix seconds (0 = none yet)
+	End       int64 // range end (0 = no range this session)
+	High, Low float64
+	Dir       int // +1 / −1 = broken out, 0 = inside or not yet
+	Confirm   int // ORConfirm* of the current breakout
+	Failed    int // direction of the last failed breakout (0 = none)
+
+	Min   int64   // live 1m bucket
+	Close float64 // last price in it
+}
+
+// SetOpenRange sets the opening-range length per session (all off by
+// default). Call before the first trade (and before Restore).
+func (e *Engine) SetOpenRange(or OpenRange) {
+	e.openRange = openRange{Lengths: or}
+}
+
+// update advances to sec with price and returns the event bits; trade =
+// price is a trade (not a heartbeat).
+func (o *openRange) update(sec int64, price, delta1m, oiDelta1m float64, trade bool) int {
+	if price <= 0 {
+		return 0
+	}
+	if start := session.StartOf(sec); start != o.Start {
+		*o = openRange{Lengths: o.Lengths, Start: start}
+		if n := o.Lengths[session.At(sec)]; n > 0 && sec-start <= orJoinSlack {
+			o.End = start + n
+		}
+	}
+	if o.End == 0 {
+		return 0
+	}
+	if sec < o.End {
+		if o.High == 0 {
+			o.High, o.Low = price, price
+		}
+		o.High = max(o.High, price)
+		o.Low = min(o.Low, price)
+		return 0
+	}
+
+	// A closed 1m candle back inside the range fails the breakout
+	ev := 0
+	if m := sec / 60 * 60; m != o.Min {
+		if o.Dir != 0 && o.Min >= o.End && o.Close <= o.High && o.Close >= o.Low {
+			o.Failed, o.Dir, o.Confirm = o.Dir, 0, 0
+			ev |= model.EventORFailed
+		}
+		o.Min = m
+	}
+	o.Close = price
+
+	if !trade || o.Dir != 0 {
+		return ev
+	}
+	switch {
+	case price > o.High:
+		o.Dir = 1
+	case price < o.Low:
+		o.Dir = -1
+	default:
+		return ev
+	}
+	o.Confirm = 0
+	if delta1m*float64(o.Dir) > 0 {
+		o.Confirm |= ORConfirmDelta
+	}
+	if oiDelta1m > 0 {
+		o.Confirm |= ORConfirmOI
+	}
+	if o.Dir > 0 {
+		return ev | model.EventORBreakUp
+	}
+	return ev | model.EventORBreakDown
+}
+
+// updateOpenRange feeds the opening-range tracker and ORs its events into
+// the event flags (after updateCandleFlow, which resets them).
+func (e *Engine) updateOpenRange(sec int64, price, oiDelta1m float64, trade bool) {
+	e.events |= e.openRange.update(sec, price, e.Candle1m.Delta, oiDelta1m, trade)
+}
+
+func (e *Engine) snapshotOpenRange() model.OpenRangeSnapshot {
+	o := &e.openRange
+	if o.End == 0 || o.High == 0 {
+		return model.OpenRangeSnapshot{}
+	}
+	return model.OpenRangeSnapshot{High: o.High, Low: o.Low, End: o.End * 1000,
+		Dir: o.Dir, Confirm: o.Confirm, Failed: o.Failed}
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"math"
+
+	"market-indikator/internal/orderbook"
+)
+
+// =============================================================================
+// TOUCH REPLENISHMENT — trade × L1 fusion
+// =============================================================================
+//
+// The bookTicker quote says how much sits at the touch; the tape says how
+// much was taken from it. Neither alone tells a level that is being eaten
+// and refilled from one that is simply pulled. Per side, between two reads
+// of the quote at the same touch price:
+//
+//   consumed  Σ aggressive qty into that side since the previous read
+//             (sells hit the bid, buys lift the ask)
+//   refill    ΔQty + consumed   — size that appeared beyond what the trades
+//                                removed (cancels make it negative)
+//
+// A read at a different touch price breaks the chain: the level was taken
+// out or stepped over, and what sits at the new price is not a refill (the
+// consumption still counts, so a break drives the ratio down). Both sums
+// decay with time constant replenishWindow; per side
+//
+//   Rate   = Σ refill / replenishWindow     base asset per second
+//   Ratio  = clamp(Σ refill / Σ consumed, 0, 2)
+//
+// Because ΔQty telescopes over a chain, a quote that lags the trade by a
+// read only shifts refill between reads, not its sum. Zero without a
+// bookTicker feed.
+//
+// TRADING INTERPRETATION:
+//   Ratio ≈ 1 with the touch price holding = the level refills as fast as
+//   it is hit — iceberg / absorption, the aggressor is not getting through.
+//   Ratio → 0 while the side is being hit = nobody is reloading; the touch
+//   usually breaks next. Ratio > 1 = makers stacking into the flow.
+// =============================================================================
+
+const replenishWindow = 10.0 // seconds
+
+// replenishSide — one side's chain and decayed sums. Engine goroutine only.
+type replenishSide struct {
+	price    float64 // touch price at the previous read (0 = no chain)
+	qty      float64
+	pending  float64 // consumed since the previous read
+	refill   float64 // decayed Σ refill
+	consumed float64 // decayed Σ consumed
+}
+
+// replenish — bid and ask sides, decayed per second.
+type replenish struct {
+	bid, ask replenishSide
+	sec      int64
+}
+
+// consume adds a trade's signed qty (+ aggressive buy) to the side it took
+// liquidity from.
+func (r *replenish) consume(signedQty float64) {
+	if signedQty > 0 {
+		r.ask.pending += signedQty
+	} else {
+		r.bid.pending -= signedQty
+	}
+}
+
+// update folds the quote read at sec into both sides.
+func (r *replenish) update(sec int64, q *orderbook.L1) {
+	if r.sec > 0 && sec > r.sec {
+		d := math.Exp(-float64(sec-r.sec) / replenishWindow)
+		for _, s := range []*replenishSide{&r.bid, &r.ask} {
+			s.refill *= d
+			s.consumed *= d
+		}
+	}
+	if sec > r.sec {
+		r.sec = sec
+	}
+	r.bid.update(q.Bid, q.BidQty)
+	r.ask.update(q.Ask, q.AskQty)
+}
+
+func (s *replenishSide) update(price, qty float64) {
+	s.consumed += s.pending
+	if price == s.price {
+		s.refill += qty - s.qty + s.pending
+	}
+	s.price, s.qty, s.pending = price, qty, 0
+}
+
+// rate — refilled base asset per second.
+func (s *replenishSide) rate() float64 {
+	return s.refill / replenishWindow
+}
+
+// ratio — refilled per consumed (0 with nothing consumed).
+func (s *replenishSide) ratio() float64 {
+	if s.consumed <= 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(s.refill/s.consumed, 2))
+}
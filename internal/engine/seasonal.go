@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"time"
+
+	"market-indikator/internal/pressure"
+	"market-indikator/internal/state"
+)
+
+// Time-of-day normalization (pressure/seasonal.go): the engine feeds the
+// profile one sample per closed 1s candle and rescales the scorer's inputs
+// by the hour-of-week's baselines. The per-HTF scorers run on whole candles
+// and are not rescaled.
+
+// SetSeasonal enables hour-of-week normalization of the score's inputs with
+// profile s (empty, or seeded from the logs; nil = off). Call before the
+// first trade (and before Restore, whose saved profile replaces s).
+func (e *Engine) SetSeasonal(s *pressure.Seasonal) {
+	e.seasonal = s
+}
+
+// observeSecond feeds the 1s candle that is closing (sec is the new
+// second) to the seasonal profile.
+func (e *Engine) observeSecond(sec int64, oiDelta1m float64) {
+	c := &e.Candle1s
+	if e.seasonal == nil || c.Time == 0 || sec <= c.Time {
+		return
+	}
+	e.seasonal.Add(c.Time, [pressure.NumSeasonSeries]float64{
+		pressure.SeasonIntensity: float64(c.BuyCount + c.SellCount),
+		pressure.SeasonDelta:     c.Delta,
+		pressure.SeasonOI:        oiDelta1m,
+	})
+}
+
+// seasonalInput sets the scorer input's hour-of-week scales and rebases
+// its tape intensity on the hour's typical trade count.
+func (e *Engine) seasonalInput(sec int64, in *pressure.Input) {
+	s := e.seasonal
+	if s == nil {
+		return
+	}
+	in.FlowScale = s.Scale(pressure.SeasonDelta, sec)
+	in.OIScale = s.Scale(pressure.SeasonOI, sec)
+	if base := s.Baseline(pressure.SeasonIntensity, sec); base > 0 {
+		in.Intensity = e.tape.IntensityZAgainst(base)
+	}
+}
+
+// SeedSeasonal learns s from the snapshot log's 1s rows with from <= time
+// < to (unix ms), a day in memory at a time, and returns the hour-of-week
+// buckets learned.
+func SeedSeasonal(s *pressure.Seasonal, logDir string, from, to int64) int {
+	for day := time.UnixMilli(from).UTC().Truncate(24 * time.Hour); day.UnixMilli() < to; day = day.AddDate(0, 0, 1) {
+		lo, hi := max(from, day.UnixMilli()), min(to, day.AddDate(0, 0, 1).UnixMilli())
+		for _, snap := range state.LoadRangeFromCSV(logDir, lo, hi) {
+			c := &snap.Candle1s
+			s.Add(snap.Time/1000, [pressure.NumSeasonSeries]float64{
+				pressure.SeasonIntensity: float64(c.BuyCount + c.SellCount),
+				pressure.SeasonDelta:     c.Delta,
+				pressure.SeasonOI:        snap.OI.OIDelta1m,
+			})
+		}
+	}
+	return s.Learned()
+}
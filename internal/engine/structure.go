@@ -0,0 +1,156 @@
+package engine
+
+import (
+	"market-indikator/internal/model"
+)
+
+// =============================================================================
+// MARKET STRUCTURE — rolling range, swing points, breakouts
+// =============================================================================
+//
+// A +60 in the middle of the range and a +60 pressing the hour's high are
+// different trades. The engine keeps the structural levels price trades
+// against, from completed 1m bars:
+//
+//   High / Low            max high / min low of the last Window bars (the
+//                         live minute excluded, so it can take them out)
+//   SwingHigh / SwingLow  the latest fractal pivot: a bar whose high (low)
+//                         is above (below) the swingStrength bars on each
+//                         side — confirmed swingStrength minutes late
+//   ToHigh / ToLow        distance to the range edges in bp of price,
+//                         (High − price) and (price − Low); negative once
+//                         the edge is taken out
+//
+// A trade through High with the live 1m delta positive fires
+// EventBreakoutUp (through Low with it negative, EventBreakoutDown), once
+// per level: a breakout on negative delta waits for delta to turn, and the
+// same level does not fire twice. The bars are counted, not the clock — a
+// feed gap shortens nothing, it just skips minutes.
+//
+// TRADING INTERPRETATION: pressure at a level is information, pressure in
+// the middle is mostly noise. A breakout with confirming delta and a strong
+// score is continuation; price through the level on opposing delta (no
+// event) is the classic failed break / stop run.
+// =============================================================================
+
+const (
+	// DefaultStructureWindow — rolling range length, 1m bars.
+	DefaultStructureWindow = 60
+
+	// MaxStructureWindow — one day of 1m bars.
+	MaxStructureWindow = 1440
+
+	swingStrength = 3 // bars on each side of a pivot
+)
+
+// structure — see above (checkpointed). Engine goroutine only.
+type structure struct {
+	Window int       // bars
+	Highs  []float64 // ring of completed bar highs
+	Lows   []float64
+	Next   int // ring write position
+
+	Min               int64 // live bar (unix s of the minute)
+	LiveHigh, LiveLow float64
+
+	High, Low           float64 // 0 = no completed bar yet
+	SwingHigh, SwingLow float64 // 0 = none yet
+	BrokeHigh, BrokeLow float64 // last level a breakout fired on
+}
+
+// SetStructure sets the rolling range length in 1m bars (default 60). Call
+// before the first trade (and before Restore).
+func (e *Engine) SetStructure(window int) {
+	e.structure = structure{Window: window}
+}
+
+// bar returns the i-th newest completed bar.
+func (s *structure) bar(i int) (high, low float64) {
+	n := len(s.Highs)
+	j := (s.Next - 1 - i + 2*n) % n
+	return s.Highs[j], s.Lows[j]
+}
+
+// close pushes the live bar and recomputes the levels.
+func (s *structure) close() {
+	if len(s.Highs) < s.Window {
+		s.Highs = append(s.Highs, s.LiveHigh)
+		s.Lows = append(s.Lows, s.LiveLow)
+	} else {
+		s.Highs[s.Next], s.Lows[s.Next] = s.LiveHigh, s.LiveLow
+	}
+	s.Next = (s.Next + 1) % s.Window
+
+	s.High, s.Low = s.Highs[0], s.Lows[0]
+	for i := range s.Highs {
+		s.High = max(s.High, s.Highs[i])
+		s.Low = min(s.Low, s.Lows[i])
+	}
+
+	// Pivot candidate: swingStrength bars back, with as many on each side
+	if len(s.Highs) < 2*swingStrength+1 {
+		return
+	}
+	ph, pl := s.bar(swingStrength)
+	isHigh, isLow := true, true
+	for i := 0; i <= 2*swingStrength; i++ {
+		if i == swingStrength {
+			continue
+		}
+		h, l := s.bar(i)
+		isHigh = isHigh && ph > h
+		isLow = isLow && pl < l
+	}
+	if isHigh {
+		s.SwingHigh = ph
+	}
+	if isLow {
+		s.SwingLow = pl
+	}
+}
+
+// update advances to sec with price and returns the breakout event bits;
+// delta1m = the live 1m delta, trade = price is a trade (not a heartbeat).
+func (s *structure) update(sec int64, price, delta1m float64, trade bool) int {
+	if s.Window <= 0 || price <= 0 {
+		return 0
+	}
+	if m := sec / 60 * 60; m != s.Min {
+		if s.LiveHigh > 0 {
+			s.close()
+		}
+		s.Min, s.LiveHigh, s.LiveLow = m, price, price
+	}
+	s.LiveHigh = max(s.LiveHigh, price)
+	s.LiveLow = min(s.LiveLow, price)
+
+	if !trade || s.High == 0 {
+		return 0
+	}
+	ev := 0
+	if price > s.High && delta1m > 0 && s.BrokeHigh != s.High {
+		s.BrokeHigh = s.High
+		ev |= model.EventBreakoutUp
+	}
+	if price < s.Low && delta1m < 0 && s.BrokeLow != s.Low {
+		s.BrokeLow = s.Low
+		ev |= model.EventBreakoutDown
+	}
+	return ev
+}
+
+// updateStructure feeds the structure tracker and ORs a breakout into the
+// event flags (after updateCandleFlow, which resets them).
+func (e *Engine) updateStructure(sec int64, price float64, trade bool) {
+	e.events |= e.structure.update(sec, price, e.Candle1m.Delta, trade)
+}
+
+func (e *Engine) snapshotStructure(price float64) model.StructureSnapshot {
+	s := &e.structure
+	ss := model.StructureSnapshot{High: s.High, Low: s.Low, SwingHigh: s.SwingHigh, SwingLow: s.SwingLow}
+	if s.High > 0 && price > 0 {
+		ss.ToHighBps = (s.High - price) / price * 1e4
+		ss.ToLowBps = (price - s.Low) / price * 1e4
+	}
+	return ss
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"math"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/oi"
+	"market-indikator/internal/pressure"
+)
+
+// =============================================================================
+// PER-TIMEFRAME SCORERS — genuine HTF-horizon pressure
+// =============================================================================
+//
+// The legacy HTF score (CandleDelta.AvgScore) is an EMA of the tick-level
+// final score within the bucket: a heavily smoothed 1s signal. Here each HTF
+// runs its own pressure.Scorer, updated once per CLOSED candle from inputs
+// aggregated over that candle:
+//
+//   CVD      running sum of closed-candle deltas (so CVD velocity = delta)
+//   Delta    the candle's net aggressor volume
+//   OBScore  mean orderbook score over the candle's updates
+//   ΔOI      OI(close) − OI(open)
+//   Behavior OI behavior at the close
+//
+// so σ normalization and EMA smoothing run in units of that timeframe's bars
+// (N=5 bars ≈ 5h on 1h). The score only moves on a close; between closes
+// the snapshot carries the last closed value. The first candle seen after
+// a start is partial but still scored.
+//
+// Both scores are published during the transition: candle avgScore (legacy)
+// and Snapshot.TFScore (this).
+// =============================================================================
+
+// tfScorer — independent scorer on one HTF's closed candles. Engine
+// goroutine only.
+type tfScorer struct {
+	scorer *pressure.Scorer
+	tfScorerState
+}
+
+// tfScorerState — tfScorer's candle accumulators (checkpointed alongside
+// the scorer's own state).
+type tfScorerState struct {
+	Bucket    int64   // live candle's bucket start
+	CVD       float64 // Σ closed-candle deltas
+	CVDOpen   float64 // engine CVD at the live candle's open
+	CVDLast   float64 // engine CVD at the last update
+	OIOpen    float64
+	OILast    float64
+	OBSum     float64
+	OBN       int
+	Behavior  int // OI behavior at the last update
+	BookStale bool
+	OIStale   bool
+	Score     float64 // as of the last close
+}
+
+func newTFScorer() *tfScorer {
+	return &tfScorer{scorer: pressure.NewScorer()}
+}
+
+// update — feeds the live state; a new bucket scores the candle that closed.
+func (t *tfScorer) update(bucket int64, cvd float64, obScore int, oiState *oi.State,
+	quality model.QualitySnapshot) {
+	if bucket != t.Bucket {
+		if t.Bucket == 0 {
+			t.CVDLast, t.OILast = cvd, oiState.OI
+		} else if t.OBN > 0 {
+			t.close()
+		}
+		// The previous update's state is this candle's open
+		t.Bucket = bucket
+		t.CVDOpen, t.OIOpen = t.CVDLast, t.OILast
+		t.OBSum, t.OBN = 0, 0
+	}
+	t.CVDLast, t.OILast = cvd, oiState.OI
+	t.OBSum += float64(obScore)
+	t.OBN++
+	t.Behavior = oiState.Behavior
+	t.BookStale = quality.Flags&bookUnusable != 0
+	t.OIStale = quality.Flags&model.QualityOIStale != 0
+}
+
+func (t *tfScorer) close() {
+	delta := t.CVDLast - t.CVDOpen
+	t.CVD += delta
+	oiDelta := 0.0
+	if t.OIOpen > 0 && t.OILast > 0 {
+		oiDelta = t.OILast - t.OIOpen
+	}
+	t.Score = t.scorer.Update(pressure.Input{
+		CVD:        t.CVD,
+		Delta1s:    delta,
+		OBScore:    int(math.Round(t.OBSum / float64(t.OBN))),
+		OIDelta1m:  oiDelta,
+		OIBehavior: t.Behavior,
+		BookStale:  t.BookStale,
+		OIStale:    t.OIStale,
+	})
+}
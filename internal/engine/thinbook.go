@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"sort"
+)
+
+// =============================================================================
+// THIN-BOOK REGIME — QualityThinBook
+// =============================================================================
+//
+// Around funding, news and the daily roll the book can empty out: makers
+// pull, the top-N depth collapses, and the few remaining lots swing the
+// imbalance (and the passive domain with it) from one frame to the next.
+// The score then measures a vanished book, not pressure.
+//
+// Once per second the top-N depth (bid + ask volume of the scored levels)
+// is sampled into a rolling window (thinBookWindow seconds, usable books
+// only). The window's pct-th percentile is recomputed with each sample;
+// a snapshot whose live depth is below it is flagged QualityThinBook. No
+// flag until thinBookMinSamples seconds are in the window.
+//
+// With the flag set, optionally:
+//
+//   the scorer dampens the passive domain (pressure.Scorer.ThinBookDamp)
+//   the decision layer widens its thresholds (logger.SetThinBookWiden)
+//
+// and, like any quality flag, the executor does not enter.
+//
+// TRADING INTERPRETATION: a ±60 on a thin book is mostly the absence of the
+// other side — expect slippage and a snap-back when the makers return; wait
+// for depth to refill before trusting the passive read.
+// =============================================================================
+
+const (
+	// DefaultThinBookPct — depth percentile under which the book is thin.
+	DefaultThinBookPct = 5.0
+
+	thinBookWindow     = 3600 // s
+	thinBookMinSamples = 300
+)
+
+// thinBook — rolling top-N depth percentile (checkpointed). Engine
+// goroutine only.
+type thinBook struct {
+	Pct       float64   // 0 = off
+	Depth     []float64 // ring of per-second samples
+	Next      int
+	Sec       int64 // last sampled second
+	Threshold float64
+	sorted    []float64 // scratch
+}
+
+// observe samples depth at sec (once per second) and reports whether it is
+// below the threshold; usable = the book is neither stale nor suspect.
+func (t *thinBook) observe(sec int64, depth float64, usable bool) bool {
+	if t.Pct <= 0 || !usable {
+		return false
+	}
+	if sec != t.Sec {
+		t.Sec = sec
+		if len(t.Depth) < thinBookWindow {
+			t.Depth = append(t.Depth, depth)
+		} else {
+			t.Depth[t.Next] = depth
+			t.Next = (t.Next + 1) % thinBookWindow
+		}
+		t.Threshold = 0
+		if len(t.Depth) >= thinBookMinSamples {
+			t.sorted = append(t.sorted[:0], t.Depth...)
+			sort.Float64s(t.sorted)
+			t.Threshold = t.sorted[int(t.Pct/100*float64(len(t.sorted)-1))]
+		}
+	}
+	return depth < t.Threshold
+}
+
+// SetThinBook flags snapshots whose top-N depth is below its rolling pct-th
+// percentile (0 = off, the default) and has the scorer dampen the passive
+// domain by damp (0..1) while flagged. Call before the first trade (and
+// before Restore).
+func (e *Engine) SetThinBook(pct, damp float64) {
+	e.thin.Pct = pct
+	e.scorer.ThinBookDamp = damp
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"time"
+
+	"market-indikator/internal/model"
+)
+
+// =============================================================================
+// OUTPUT THROTTLE — at most one published snapshot per interval
+// =============================================================================
+//
+// The engine builds a snapshot per trade; at hundreds of trades a second
+// that is far more than a chart or the history ring needs. The throttle
+// sits between the engine and its publishers (ring buffer, history tiers,
+// broadcast channel):
+//
+//   - the first snapshot after a quiet interval is published at once
+//   - later ones within the interval replace a single pending snapshot
+//     (conflated to the latest)
+//   - the pending one is published when the interval since the last publish
+//     has passed — by the next Offer, or by Flush when no trade comes
+//
+// So the published stream lags the engine by at most one interval and never
+// drops the final state of a burst. Consumers that must see every state (the
+// executor, the once-per-second CSV row) read the engine output directly.
+// =============================================================================
+
+// Throttle — see above. Engine goroutine only.
+type Throttle struct {
+	every   time.Duration
+	last    time.Time // last publish
+	pending model.Snapshot
+	has     bool
+}
+
+// NewThrottle — every ≤ 0 publishes every snapshot.
+func NewThrottle(every time.Duration) *Throttle {
+	return &Throttle{every: every}
+}
+
+// SetInterval changes the interval (config reload); a pending snapshot is
+// due on the new one.
+func (t *Throttle) SetInterval(every time.Duration) {
+	t.every = every
+}
+
+// Offer hands over a new snapshot; true means publish s now, otherwise it is
+// held as pending.
+func (t *Throttle) Offer(s *model.Snapshot, now time.Time) bool {
+	if t.every <= 0 || now.Sub(t.last) >= t.every {
+		t.last, t.has = now, false
+		return true
+	}
+	t.pending, t.has = *s, true
+	return false
+}
+
+// Flush returns the pending snapshot once its interval has passed.
+func (t *Throttle) Flush(now time.Time) (model.Snapshot, bool) {
+	if !t.has || now.Sub(t.last) < t.every {
+		return model.Snapshot{}, false
+	}
+	t.last, t.has = now, false
+	return t.pending, true
+}
+
+// Due — when the pending snapshot is to be flushed (zero = nothing pending).
+func (t *Throttle) Due() time.Time {
+	if !t.has {
+		return time.Time{}
+	}
+	return t.last.Add(t.every)
+}
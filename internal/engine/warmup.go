@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"market-indikator/internal/model"
+	"market-indikator/internal/pressure"
+)
+
+// =============================================================================
+// COLD-START WARMUP — Snapshot.Warmup
+// =============================================================================
+//
+// A cold engine (no checkpoint) publishes numbers that look real but aren't:
+// the scorer's σ estimates start at 1.0, and each HTF AvgScore EMA starts
+// from a single tick of that unsettled score. Each component counts the
+// samples it has absorbed since the start and is warm once it has enough:
+//
+//   score    pressure.ConfidenceWarmup scorer updates (≈ 3 σ time constants)
+//   HTF i    3/α trade samples of the warm score (α = the timeframe's EMA
+//            alpha: ≈ 150 on 5m … 1500 on 1d), counted only once the score
+//            itself is warm, so the EMA no longer remembers the cold part
+//
+// Until then the snapshot carries the component's estimated readiness time
+// — now + remaining samples × the mean sample interval so far (1 s per
+// sample before there is one) — and 0 once warm. The counters are
+// checkpointed: a warm restart is warm immediately.
+//
+// TRADING INTERPRETATION: while the score or an HTF feeding the bias (1h, 4h,
+// 1d) is warming, the decision layer opens no position whatever the numbers
+// show (logger.EntriesSuppressed; the hint reads NO_TRADE while flat). A
+// position already held is still managed by the hint.
+// =============================================================================
+
+// warmup — one component's sample count (checkpointed).
+type warmup struct {
+	Need    int64 // samples to be warm
+	N       int64 // samples so far, capped at Need
+	FirstMs int64 // time of the first sample, unix ms
+}
+
+func (w *warmup) add(nowMs int64) {
+	if w.N >= w.Need {
+		return
+	}
+	if w.N == 0 {
+		w.FirstMs = nowMs
+	}
+	w.N++
+}
+
+func (w *warmup) warm() bool {
+	return w.N >= w.Need
+}
+
+// readyMs — the estimated time the component is warm (0 = warm).
+func (w *warmup) readyMs(nowMs int64) int64 {
+	if w.warm() {
+		return 0
+	}
+	perSample := int64(1000)
+	if w.N > 1 && nowMs > w.FirstMs {
+		perSample = (nowMs - w.FirstMs) / (w.N - 1)
+	}
+	return nowMs + (w.Need-w.N)*perSample
+}
+
+// initWarmup sizes the counters (the HTF set is configured by then).
+func (e *Engine) initWarmup() {
+	e.scoreWarmup = warmup{Need: pressure.ConfidenceWarmup}
+	for i := 0; i < e.numHTF; i++ {
+		e.htfWarmup[i] = warmup{Need: int64(3/e.htfs[i].Alpha + 0.5)}
+	}
+}
+
+// updateWarmup — one scorer update at nowMs; trade = the HTF EMAs took the
+// score too (heartbeats only reset them at a bucket open).
+func (e *Engine) updateWarmup(nowMs int64, trade bool) {
+	e.scoreWarmup.add(nowMs)
+	if !trade || !e.scoreWarmup.warm() {
+		return
+	}
+	for i := 0; i < e.numHTF; i++ {
+		e.htfWarmup[i].add(nowMs)
+	}
+}
+
+func (e *Engine) snapshotWarmup(nowMs int64) model.WarmupSnapshot {
+	ws := model.WarmupSnapshot{Score: e.scoreWarmup.readyMs(nowMs)}
+	for i := 0; i < e.numHTF; i++ {
+		ws.HTF[i] = e.htfWarmup[i].readyMs(nowMs)
+	}
+	return ws
+}
@@ -0,0 +1,218 @@
+package evaluation
+
+import (
+	"math"
+	"sync"
+)
+
+// =============================================================================
+// SELF-EVALUATION — online score vs forward-return tracking
+// =============================================================================
+//
+// Automates the calibration guidance in pressure/score.go: does the score
+// currently predict where price goes next?
+//
+// The final score and price are sampled once per second (the last snapshot of
+// each second). When a sample is h seconds old (h = 10, 60) its realized
+// forward return is known:
+//
+//   r_h(t) = (p(t+h) − p(t)) / p(t)          reported in basis points
+//
+// and (score(t), r_h(t)) is folded into exponentially-weighted statistics
+// (N = 3600 samples, ≈ the last hour), per horizon:
+//
+//   correlation  Pearson ρ(score, r_h)
+//   hit rate     share of samples with |score| ≥ 10 whose sign matched r_h
+//                (flat returns count as misses)
+//   buckets      per score band (the interpretation bands in score.go):
+//                weight, mean r_h and hit rate
+//
+// TRADING INTERPRETATION:
+//   ρ > 0 and hit rate > 0.5 — the score has edge right now; the outer
+//   buckets' mean return should grow with |score|. ρ ≈ 0 or a hit rate at or
+//   below 0.5 — the current regime does not reward the signal; trade it
+//   smaller or not at all.
+// =============================================================================
+
+// Horizons — forward-return horizons in seconds.
+var Horizons = [NumHorizons]int64{10, 60}
+
+// NumHorizons — number of forward-return horizons.
+const NumHorizons = 2
+
+const (
+	decayAlpha  = 2.0 / (3600 + 1)
+	hitMinScore = 10
+	historySecs = 64 // ≥ the longest horizon + 1, power of two
+)
+
+// bucketEdges — score band boundaries (lower edges of bands 1..6).
+var bucketEdges = [...]float64{-80, -40, -10, 10, 40, 80}
+
+const numBuckets = len(bucketEdges) + 1
+
+// Tracker — rolling score-vs-return statistics. Add is called from the engine
+// goroutine; Report may be called from any goroutine.
+type Tracker struct {
+	mu sync.Mutex
+
+	// Per-second samples (engine side)
+	sec   int64 // second being sampled
+	price float64
+	score float64
+	hist  [historySecs]sample // indexed by sec % historySecs
+
+	stats [NumHorizons]horizonStats
+}
+
+type sample struct {
+	sec   int64
+	price float64
+	score float64
+}
+
+// horizonStats — exponentially-weighted moments for one horizon.
+type horizonStats struct {
+	samples          int64
+	mx, my           float64 // weighted means (score, return)
+	vx, vy, cxy      float64 // weighted (co)variance
+	hitW, hits       float64 // weight of |score| ≥ 10 samples, and of hits
+	bucketW, bucketR [numBuckets]float64
+	bucketHits       [numBuckets]float64
+}
+
+// NewTracker — an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Add — offers a snapshot's time (unix ms), price and final score. O(1).
+func (t *Tracker) Add(timeMs int64, price, score float64) {
+	sec := timeMs / 1000
+	if sec != t.sec && t.sec != 0 {
+		t.commit()
+	}
+	t.sec = sec
+	t.price = price
+	t.score = score
+}
+
+// commit — closes the sampled second and resolves the samples whose
+// horizon it completes.
+func (t *Tracker) commit() {
+	now := sample{sec: t.sec, price: t.price, score: t.score}
+	t.mu.Lock()
+	for i, h := range Horizons {
+		if now.sec < h {
+			continue
+		}
+		past := t.hist[(now.sec-h)%historySecs]
+		if past.sec == now.sec-h && past.price > 0 {
+			t.stats[i].add(past.score, (now.price-past.price)/past.price*1e4)
+		}
+	}
+	t.mu.Unlock()
+	t.hist[now.sec%historySecs] = now
+}
+
+func (s *horizonStats) add(score, ret float64) {
+	s.samples++
+	a := decayAlpha
+	if s.samples == 1 {
+		a = 1
+	}
+	dx := score - s.mx
+	dy := ret - s.my
+	s.mx += a * dx
+	s.my += a * dy
+	s.vx = (1 - a) * (s.vx + a*dx*dx)
+	s.vy = (1 - a) * (s.vy + a*dy*dy)
+	s.cxy = (1 - a) * (s.cxy + a*dx*dy)
+
+	hit := 0.0
+	if score*ret > 0 {
+		hit = 1
+	}
+	if math.Abs(score) >= hitMinScore {
+		s.hitW = (1-decayAlpha)*s.hitW + 1
+		s.hits = (1-decayAlpha)*s.hits + hit
+	} else {
+		s.hitW *= 1 - decayAlpha
+		s.hits *= 1 - decayAlpha
+	}
+
+	b := bucketOf(score)
+	for i := range s.bucketW {
+		s.bucketW[i] *= 1 - decayAlpha
+		s.bucketR[i] *= 1 - decayAlpha
+		s.bucketHits[i] *= 1 - decayAlpha
+	}
+	s.bucketW[b]++
+	s.bucketR[b] += ret
+	s.bucketHits[b] += hit
+}
+
+func bucketOf(score float64) int {
+	b := 0
+	for b < len(bucketEdges) && score >= bucketEdges[b] {
+		b++
+	}
+	return b
+}
+
+// Report is the JSON body of /admin/eval.
+type Report struct {
+	Horizons []HorizonReport `json:"horizons"`
+}
+
+// HorizonReport — statistics for one forward-return horizon.
+type HorizonReport struct {
+	HorizonSec  int64          `json:"horizon_sec"`
+	Samples     int64          `json:"samples"`
+	Correlation float64        `json:"correlation"`
+	HitRate     float64        `json:"hit_rate"` // |score| ≥ 10 only
+	Buckets     []BucketReport `json:"buckets"`
+}
+
+// BucketReport — statistics for one score band [Lo, Hi).
+type BucketReport struct {
+	Lo            float64 `json:"lo"`
+	Hi            float64 `json:"hi"`
+	Weight        float64 `json:"weight"` // decayed sample count
+	MeanReturnBps float64 `json:"mean_return_bps"`
+	HitRate       float64 `json:"hit_rate"`
+}
+
+// Report — a consistent copy of the current statistics.
+func (t *Tracker) Report() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rep := Report{Horizons: make([]HorizonReport, 0, NumHorizons)}
+	for i, h := range Horizons {
+		s := &t.stats[i]
+		hr := HorizonReport{HorizonSec: h, Samples: s.samples}
+		if s.vx > 0 && s.vy > 0 {
+			hr.Correlation = s.cxy / math.Sqrt(s.vx*s.vy)
+		}
+		if s.hitW > 0 {
+			hr.HitRate = s.hits / s.hitW
+		}
+		for b := 0; b < numBuckets; b++ {
+			br := BucketReport{Lo: -100, Hi: 100, Weight: s.bucketW[b]}
+			if b > 0 {
+				br.Lo = bucketEdges[b-1]
+			}
+			if b < len(bucketEdges) {
+				br.Hi = bucketEdges[b]
+			}
+			if s.bucketW[b] > 0 {
+				br.MeanReturnBps = s.bucketR[b] / s.bucketW[b]
+				br.HitRate = s.bucketHits[b] / s.bucketW[b]
+			}
+			hr.Buckets = append(hr.Buckets, br)
+		}
+		rep.Horizons = append(rep.Horizons, hr)
+	}
+	return rep
+}
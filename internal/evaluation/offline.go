@@ -0,0 +1,69 @@
+package evaluation
+
+import "math"
+
+// Calibrate — the statistics of a HorizonReport over a finished history,
+// every sample weighted equally (offline calibration from the logs; see
+// orderflow calibrate). scores[i] is a sample's final score and rets[i] its
+// realized forward return over horizonSec, in bps; NaN returns (unknown)
+// are skipped. Bucket weights are sample counts.
+func Calibrate(horizonSec int64, scores, rets []float64) HorizonReport {
+	var (
+		n                  float64
+		sx, sy, sxx, syy   float64
+		sxy, hitN, hitsSum float64
+		bucketN, bucketR   [numBuckets]float64
+		bucketHits         [numBuckets]float64
+	)
+	for i, score := range scores {
+		ret := rets[i]
+		if math.IsNaN(ret) || math.IsNaN(score) {
+			continue
+		}
+		n++
+		sx += score
+		sy += ret
+		sxx += score * score
+		syy += ret * ret
+		sxy += score * ret
+
+		hit := 0.0
+		if score*ret > 0 {
+			hit = 1
+		}
+		if math.Abs(score) >= hitMinScore {
+			hitN++
+			hitsSum += hit
+		}
+		b := bucketOf(score)
+		bucketN[b]++
+		bucketR[b] += ret
+		bucketHits[b] += hit
+	}
+
+	hr := HorizonReport{HorizonSec: horizonSec, Samples: int64(n)}
+	if n > 1 {
+		vx, vy := sxx-sx*sx/n, syy-sy*sy/n
+		if vx > 0 && vy > 0 {
+			hr.Correlation = (sxy - sx*sy/n) / math.Sqrt(vx*vy)
+		}
+	}
+	if hitN > 0 {
+		hr.HitRate = hitsSum / hitN
+	}
+	for b := 0; b < numBuckets; b++ {
+		br := BucketReport{Lo: -100, Hi: 100, Weight: bucketN[b]}
+		if b > 0 {
+			br.Lo = bucketEdges[b-1]
+		}
+		if b < len(bucketEdges) {
+			br.Hi = bucketEdges[b]
+		}
+		if bucketN[b] > 0 {
+			br.MeanReturnBps = bucketR[b] / bucketN[b]
+			br.HitRate = bucketHits[b] / bucketN[b]
+		}
+		hr.Buckets = append(hr.Buckets, br)
+	}
+	return hr
+}
@@ -0,0 +1,439 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"market-indikator/internal/binance"
+	csvlogger "market-indikator/internal/logger"
+	"market-indikator/internal/model"
+	"market-indikator/internal/risk"
+)
+
+// =============================================================================
+// EXECUTION — orders driven by the decision layer (optional, testnet first)
+// =============================================================================
+//
+// Each snapshot is classified with the same ActionHint the CSV log records
+// (logger.ComputeActionHint: HTF bias × FinalScore × book imbalance). The
+// target position follows it:
+//
+//   ENTER long   hint WATCH_LONG  and FinalScore ≥ +EnterScore, not long yet
+//   ENTER short  hint WATCH_SHORT and FinalScore ≤ −EnterScore, not short yet
+//   EXIT long    hint turns WATCH_SHORT, WAIT_RALLY or NO_TRADE
+//   EXIT short   hint turns WATCH_LONG, WAIT_DIP or NO_TRADE
+//
+// Entries are Qty (no pyramiding); an entry against an open position reverses
+// it in one order. All orders are MARKET; exits are reduce-only.
+//
+// RAILS:
+//   every order passes risk.Manager.Check (position / notional limits, and
+//   no growing orders while a loss limit is breached)
+//   a daily-loss or drawdown breach (risk.Manager.Mark) → kill
+//   one order in flight and Cooldown between orders
+//   an order that errors (or times out) may still have filled: the book is
+//   marked stale and nothing new is sent until it has been re-read from
+//   positionRisk (every flattenRetry until that succeeds)
+//   snapshots with any quality flag (stale depth / OI, trade gap, thin book,
+//   event risk) never enter
+//   after a cold start nothing is entered until the score and the 1h/4h/1d
+//   EMAs have warmed up (logger.EntriesSuppressed); a position seeded from
+//   positionRisk is still exited by the hint meanwhile
+//
+// KILL SWITCH (POST /admin/execution?action=kill, or a loss-limit breach):
+// cancels every open order on the symbol, closes the position reduce-only
+// and halts until an explicit resume — a new UTC day does not resume. If the
+// cancel or the close fails, both are retried on the following snapshots,
+// every flattenRetry and from a fresh positionRisk, until the position is
+// flat (Status.FlattenPending meanwhile). Resume clears the breach and
+// re-reads the position from the exchange.
+//
+// The risk book is fed the executor's own fills, seeded from positionRisk at
+// start; trades placed by hand on the same account are picked up on resume
+// only. Quantities must respect the symbol's lot step (Qty is sent as given).
+//
+// Orders go to the Binance Futures testnet unless Live is set.
+// =============================================================================
+
+// Action hints acted on (see logger.ComputeActionHint).
+const (
+	hintWatchLong  = "WATCH_LONG"
+	hintWatchShort = "WATCH_SHORT"
+	hintWaitDip    = "WAIT_DIP"
+	hintWaitRally  = "WAIT_RALLY"
+	hintNoTrade    = "NO_TRADE"
+)
+
+const (
+	qtyEpsilon   = 1e-9
+	flattenRetry = 5 * time.Second
+)
+
+var errStopped = errors.New("executor stopped")
+
+// Config — strategy parameters (limits live in risk.Limits).
+type Config struct {
+	Symbol     string
+	Live       bool    // false = testnet
+	Qty        float64 // base asset per entry
+	EnterScore float64 // |FinalScore| required to enter
+	Cooldown   time.Duration
+}
+
+// Validate — the config is usable.
+func (c *Config) Validate() error {
+	switch {
+	case c.Qty <= 0:
+		return fmt.Errorf("qty must be > 0")
+	case c.EnterScore < 0:
+		return fmt.Errorf("enter score must be >= 0")
+	}
+	return nil
+}
+
+// Status is the JSON body of GET /admin/execution.
+type Status struct {
+	Venue          string     `json:"venue"` // "testnet" or "live"
+	Symbol         string     `json:"symbol"`
+	Halted         bool       `json:"halted"`
+	HaltReason     string     `json:"halt_reason,omitempty"`
+	FlattenPending bool       `json:"flatten_pending"` // halted, close not done yet
+	BookStale      bool       `json:"book_stale"`      // an order failed, position not re-read yet
+	Hint           string     `json:"hint"`
+	PositionHint   string     `json:"position_hint,omitempty"` // -position-hints, for the book's position
+	Orders         int64      `json:"orders"`
+	LastOrderTime  int64      `json:"last_order_time"` // unix ms
+	LastError      string     `json:"last_error,omitempty"`
+	Risk           risk.State `json:"risk"`
+}
+
+type control struct {
+	kill   bool // false = resume
+	reason string
+	done   chan error
+}
+
+// Executor — runs on its own goroutine; fed snapshots by the engine goroutine
+// through Submit (latest wins, never blocks).
+type Executor struct {
+	cfg    Config
+	client *binance.Client
+	risk   *risk.Manager
+	snaps  chan model.Snapshot
+	ctrl   chan control
+	status unsafe.Pointer // *Status
+	done   chan struct{}  // closed when run exits
+
+	// Owned by the run goroutine
+	hint      string
+	posHint   string // hint made position-aware (logger.PositionHintFor)
+	halted    bool
+	reason    string
+	pending   bool      // kill's cancel / close still to be done
+	flattenAt time.Time // last attempt at it
+	stale     bool      // an order failed: the book may miss its fill
+	syncAt    time.Time // last positionRisk attempt to clear stale
+	vetoed    string    // last veto logged
+	orders    int64
+	lastOrder time.Time
+	lastErr   string
+}
+
+// New — an executor booking its fills into rm.
+func New(cfg Config, rm *risk.Manager, apiKey, secret string) *Executor {
+	base := binance.FuturesTestnetBase
+	if cfg.Live {
+		base = binance.Current().REST
+	}
+	x := &Executor{
+		cfg:    cfg,
+		client: binance.NewClient(base, apiKey, secret),
+		risk:   rm,
+		snaps:  make(chan model.Snapshot, 1),
+		ctrl:   make(chan control),
+		done:   make(chan struct{}),
+	}
+	x.publish()
+	return x
+}
+
+// Start seeds the position from the exchange and starts the executor.
+func (x *Executor) Start(ctx context.Context) error {
+	pos, entry, err := x.client.Position(x.cfg.Symbol)
+	if err != nil {
+		return fmt.Errorf("positionRisk: %w", err)
+	}
+	x.risk.SetPosition(pos, entry)
+	x.publish()
+	log.Printf("Execution enabled on %s %s (position %g, qty %g, limits %+v)",
+		x.venue(), x.cfg.Symbol, pos, x.cfg.Qty, x.risk.Limits())
+	go x.run(ctx)
+	return nil
+}
+
+// Submit hands the latest snapshot to the executor. Called from the engine
+// goroutine; drops the pending one if the executor is behind.
+func (x *Executor) Submit(snap *model.Snapshot) {
+	select {
+	case x.snaps <- *snap:
+		return
+	default:
+	}
+	select {
+	case <-x.snaps:
+	default:
+	}
+	select {
+	case x.snaps <- *snap:
+	default:
+	}
+}
+
+// Kill halts trading: cancels open orders and flattens the position.
+func (x *Executor) Kill(reason string) error {
+	return x.send(control{kill: true, reason: reason})
+}
+
+// Resume re-reads the position and re-enables trading after a kill.
+func (x *Executor) Resume() error {
+	return x.send(control{})
+}
+
+func (x *Executor) send(c control) error {
+	c.done = make(chan error, 1)
+	select {
+	case x.ctrl <- c:
+	case <-x.done:
+		return errStopped
+	}
+	return <-c.done
+}
+
+// Status — the latest state. LOCK-FREE.
+func (x *Executor) Status() Status {
+	return *(*Status)(atomic.LoadPointer(&x.status))
+}
+
+func (x *Executor) run(ctx context.Context) {
+	defer close(x.done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case c := <-x.ctrl:
+			if c.kill {
+				c.done <- x.kill(c.reason)
+			} else {
+				c.done <- x.resume()
+			}
+		case snap := <-x.snaps:
+			x.onSnapshot(&snap)
+		}
+		x.publish()
+	}
+}
+
+func (x *Executor) onSnapshot(snap *model.Snapshot) {
+	breach := x.risk.Mark(snap.Time, snap.Price)
+	_, _, x.hint = csvlogger.DecisionFor(snap)
+	size, entry := x.risk.Position()
+	x.posHint = csvlogger.PositionHintFor(snap, x.hint, size, entry)
+	if x.halted {
+		if x.pending && time.Since(x.flattenAt) >= flattenRetry {
+			x.retryFlatten()
+		}
+		return
+	}
+	if breach != "" {
+		x.kill(breach)
+		return
+	}
+	if x.stale && (time.Since(x.syncAt) < flattenRetry || !x.resync()) {
+		return
+	}
+	if time.Since(x.lastOrder) < x.cfg.Cooldown {
+		return
+	}
+
+	pos, _ := x.risk.Position()
+	target := pos
+	fresh := snap.Quality.Flags == 0 && !csvlogger.EntriesSuppressed(snap)
+	switch {
+	case fresh && x.hint == hintWatchLong && snap.FinalScore >= x.cfg.EnterScore && pos <= 0:
+		target = x.cfg.Qty
+	case fresh && x.hint == hintWatchShort && snap.FinalScore <= -x.cfg.EnterScore && pos >= 0:
+		target = -x.cfg.Qty
+	case pos > 0 && (x.hint == hintWatchShort || x.hint == hintWaitRally || x.hint == hintNoTrade):
+		target = 0
+	case pos < 0 && (x.hint == hintWatchLong || x.hint == hintWaitDip || x.hint == hintNoTrade):
+		target = 0
+	}
+	d := target - pos
+	if math.Abs(d) <= qtyEpsilon {
+		return
+	}
+	if err := x.risk.Check(d); err != nil {
+		if msg := err.Error(); msg != x.vetoed {
+			log.Printf("Execution: %g skipped: %v", d, err)
+			x.vetoed = msg
+		}
+		return
+	}
+	x.vetoed = ""
+	x.order(d, target == 0)
+}
+
+// order sends a MARKET order for qty (signed, + buy) and books the fill.
+func (x *Executor) order(qty float64, reduceOnly bool) error {
+	side := "BUY"
+	if qty < 0 {
+		side = "SELL"
+	}
+	q := url.Values{
+		"symbol":           {x.cfg.Symbol},
+		"side":             {side},
+		"type":             {"MARKET"},
+		"quantity":         {formatQty(math.Abs(qty))},
+		"newOrderRespType": {"RESULT"},
+	}
+	if reduceOnly {
+		q.Set("reduceOnly", "true")
+	}
+	var resp struct {
+		ExecutedQty string `json:"executedQty"`
+		AvgPrice    string `json:"avgPrice"`
+	}
+	x.lastOrder = time.Now()
+	x.orders++
+	if err := x.client.Do(http.MethodPost, "/fapi/v1/order", q, true, &resp); err != nil {
+		x.lastErr = err.Error()
+		x.stale = true
+		log.Printf("Execution: %s %s failed: %v", side, q.Get("quantity"), err)
+		return err
+	}
+	filled, _ := strconv.ParseFloat(resp.ExecutedQty, 64)
+	price, _ := strconv.ParseFloat(resp.AvgPrice, 64)
+	if side == "SELL" {
+		filled = -filled
+	}
+	x.risk.Fill(time.Now().UnixMilli(), filled, price)
+	x.lastErr = ""
+	pos, _ := x.risk.Position()
+	log.Printf("Execution: %s %s filled %g @ %.2f (hint %s, position %g)",
+		side, q.Get("quantity"), math.Abs(filled), price, x.hint, pos)
+	return nil
+}
+
+func (x *Executor) kill(reason string) error {
+	x.halted, x.reason = true, reason
+	log.Printf("Execution HALTED: %s", reason)
+	return x.flatten()
+}
+
+// flatten cancels the open orders and closes the booked position
+// reduce-only; pending until both have succeeded and the book is flat.
+func (x *Executor) flatten() error {
+	x.flattenAt = time.Now()
+	var firstErr error
+	q := url.Values{"symbol": {x.cfg.Symbol}}
+	if err := x.client.Do(http.MethodDelete, "/fapi/v1/allOpenOrders", q, true, nil); err != nil {
+		x.lastErr = err.Error()
+		log.Printf("Execution: cancel open orders failed: %v", err)
+		firstErr = err
+	}
+	if pos, _ := x.risk.Position(); math.Abs(pos) > qtyEpsilon {
+		if err := x.order(-pos, true); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	pos, _ := x.risk.Position()
+	x.pending = firstErr != nil || math.Abs(pos) > qtyEpsilon
+	if x.pending {
+		log.Printf("Execution: kill not complete (position %g), retrying every %v", pos, flattenRetry)
+	}
+	return firstErr
+}
+
+// retryFlatten re-reads the position from the exchange (a failed close may
+// have filled, or been closed by hand) and flattens what is left.
+func (x *Executor) retryFlatten() {
+	pos, entry, err := x.client.Position(x.cfg.Symbol)
+	if err != nil {
+		x.flattenAt, x.lastErr = time.Now(), err.Error()
+		log.Printf("Execution: kill retry: positionRisk: %v", err)
+		return
+	}
+	x.risk.SetPosition(pos, entry)
+	x.stale = false
+	x.flatten()
+}
+
+// resync re-reads the position after a failed order (which may have filled
+// anyway) so the next one is checked against the real book.
+func (x *Executor) resync() bool {
+	x.syncAt = time.Now()
+	pos, entry, err := x.client.Position(x.cfg.Symbol)
+	if err != nil {
+		x.lastErr = err.Error()
+		log.Printf("Execution: resync after failed order: positionRisk: %v", err)
+		return false
+	}
+	x.risk.SetPosition(pos, entry)
+	x.stale = false
+	log.Printf("Execution: position resynced after failed order (position %g)", pos)
+	return true
+}
+
+func (x *Executor) resume() error {
+	pos, entry, err := x.client.Position(x.cfg.Symbol)
+	if err != nil {
+		x.lastErr = err.Error()
+		return fmt.Errorf("positionRisk: %w", err)
+	}
+	x.risk.SetPosition(pos, entry)
+	x.risk.Clear()
+	x.halted, x.reason, x.pending, x.stale, x.lastErr = false, "", false, false, ""
+	log.Printf("Execution resumed (position %g)", pos)
+	return nil
+}
+
+func (x *Executor) venue() string {
+	if x.cfg.Live {
+		return "live"
+	}
+	return "testnet"
+}
+
+func (x *Executor) publish() {
+	s := &Status{
+		Venue:          x.venue(),
+		Symbol:         x.cfg.Symbol,
+		Halted:         x.halted,
+		HaltReason:     x.reason,
+		FlattenPending: x.pending,
+		BookStale:      x.stale,
+		Hint:           x.hint,
+		PositionHint:   x.posHint,
+		Orders:         x.orders,
+		LastError:      x.lastErr,
+		Risk:           x.risk.GetState(),
+	}
+	if !x.lastOrder.IsZero() {
+		s.LastOrderTime = x.lastOrder.UnixMilli()
+	}
+	atomic.StorePointer(&x.status, unsafe.Pointer(s))
+}
+
+// formatQty — qty without float noise (8 decimals at most).
+func formatQty(q float64) string {
+	return strconv.FormatFloat(math.Round(q*1e8)/1e8, 'f', -1, 64)
+}
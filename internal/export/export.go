@@ -0,0 +1,260 @@
+package export
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/state"
+)
+
+// =============================================================================
+// LOG EXPORT — the CSV logs as standard OHLCV candles and tick tables
+// =============================================================================
+//
+// The engine's logs are shaped for restart recovery, not for research tools.
+// This package reads them back and produces the two tables every backtester
+// and notebook understands:
+//
+//   ticks    time, price, qty, side, agg_id     — logs/trades (time & sales)
+//   candles  time, open, high, low, close, volume, buy_vol, sell_vol, trades
+//
+// Candles are built from the trade log, which is exact: every aggTrade is
+// there. Days without a trade log (trade logging was off) fall back to the
+// snapshot log's 1s candles (v2+ rows; v1 rows only carry the price, so
+// O = H = L = C and volume 0). A candle's time is its bucket start, unix ms,
+// buckets as model.Timeframe.Bucket (weeks start Monday 00:00 UTC).
+//
+// Both readers accept the retention janitor's gzipped days (.csv.gz) and
+// rotated parts of one day, and restore time order across them.
+// =============================================================================
+
+const tradeDir = "trades"
+
+// Tick — one logged aggTrade.
+type Tick struct {
+	AggID int64
+	Time  int64 // unix ms
+	Price float64
+	Qty   float64
+	Buy   bool // taker side
+}
+
+// Side — "BUY" or "SELL", as logged.
+func (t *Tick) Side() string {
+	if t.Buy {
+		return "BUY"
+	}
+	return "SELL"
+}
+
+// Candle — one OHLCV bar.
+type Candle struct {
+	Time    int64 // bucket start, unix ms
+	Open    float64
+	High    float64
+	Low     float64
+	Close   float64
+	Volume  float64
+	BuyVol  float64
+	SellVol float64
+	Trades  int64
+}
+
+// dayFiles returns the log files in dir for the UTC days from..to touches,
+// grouped by day, oldest first.
+func dayFiles(dir string, from, to int64) map[string][]string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.csv"))
+	gzFiles, _ := filepath.Glob(filepath.Join(dir, "*.csv.gz"))
+	files = append(files, gzFiles...)
+	sort.Strings(files)
+
+	first := time.UnixMilli(from).UTC().Format("2006-01-02")
+	last := time.UnixMilli(to - 1).UTC().Format("2006-01-02")
+	out := make(map[string][]string)
+	for _, path := range files {
+		name := filepath.Base(path)
+		if len(name) < 10 || name[:10] < first || name[:10] > last {
+			continue
+		}
+		out[name[:10]] = append(out[name[:10]], path)
+	}
+	return out
+}
+
+// days lists the UTC days from..to touches.
+func days(from, to int64) []string {
+	var out []string
+	for d := time.UnixMilli(from).UTC().Truncate(24 * time.Hour); d.UnixMilli() < to; d = d.AddDate(0, 0, 1) {
+		out = append(out, d.Format("2006-01-02"))
+	}
+	return out
+}
+
+// ScanTicks calls fn for every logged trade with from <= Time < to, in time
+// order, one day in memory at a time.
+func ScanTicks(logDir string, from, to int64, fn func(*Tick) error) error {
+	files := dayFiles(filepath.Join(logDir, tradeDir), from, to)
+	for _, day := range days(from, to) {
+		ticks, err := readTicks(files[day], from, to)
+		if err != nil {
+			return err
+		}
+		for i := range ticks {
+			if err := fn(&ticks[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readTicks reads one day's trade files.
+func readTicks(paths []string, from, to int64) ([]Tick, error) {
+	var out []Tick
+	for _, path := range paths {
+		err := readCSV(path, func(row []string) {
+			if len(row) < 5 {
+				return
+			}
+			var t Tick
+			var err error
+			if t.AggID, err = strconv.ParseInt(row[0], 10, 64); err != nil {
+				return // header or malformed
+			}
+			t.Time, _ = strconv.ParseInt(row[1], 10, 64)
+			t.Price, _ = strconv.ParseFloat(row[2], 64)
+			t.Qty, _ = strconv.ParseFloat(row[3], 64)
+			t.Buy = row[4] == "BUY"
+			if t.Time >= from && t.Time < to {
+				out = append(out, t)
+			}
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].AggID < out[j].AggID })
+	return out, nil
+}
+
+// readCSV streams the rows of one (optionally gzipped) CSV file.
+func readCSV(path string, fn func([]string)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	reader := csv.NewReader(bufio.NewReaderSize(r, 1<<20))
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			continue // skip malformed (a torn last line after a crash)
+		}
+		fn(row)
+	}
+}
+
+// ScanCandles calls fn for every candle of each timeframe with a bucket
+// start in from..to, per timeframe in time order (fn's index is the
+// timeframe's position in tfs). Buckets without trades are skipped, as
+// exchanges do.
+func ScanCandles(logDir string, from, to int64, tfs []model.Timeframe, fn func(int, *Candle) error) error {
+	bars := make([]Candle, len(tfs))
+	add := func(sec int64, c *Candle) error {
+		for i := range tfs {
+			start := tfs[i].Bucket(sec) * 1000
+			b := &bars[i]
+			if b.Time != start || b.Open == 0 {
+				if b.Open != 0 { // bucket finished
+					if err := fn(i, b); err != nil {
+						return err
+					}
+				}
+				*b = Candle{Time: start, Open: c.Open, High: c.High, Low: c.Low}
+			}
+			b.High = max(b.High, c.High)
+			b.Low = min(b.Low, c.Low)
+			b.Close = c.Close
+			b.Volume += c.Volume
+			b.BuyVol += c.BuyVol
+			b.SellVol += c.SellVol
+			b.Trades += c.Trades
+		}
+		return nil
+	}
+
+	tradeFiles := dayFiles(filepath.Join(logDir, tradeDir), from, to)
+	for _, day := range days(from, to) {
+		if len(tradeFiles[day]) > 0 {
+			ticks, err := readTicks(tradeFiles[day], from, to)
+			if err != nil {
+				return err
+			}
+			for i := range ticks {
+				t := &ticks[i]
+				c := Candle{Open: t.Price, High: t.Price, Low: t.Price, Close: t.Price, Volume: t.Qty, Trades: 1}
+				if t.Buy {
+					c.BuyVol = t.Qty
+				} else {
+					c.SellVol = t.Qty
+				}
+				if err := add(t.Time/1000, &c); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// No trade log: the snapshot log's 1s candles
+		start, _ := time.Parse("2006-01-02", day)
+		lo, hi := max(from, start.UnixMilli()), min(to, start.AddDate(0, 0, 1).UnixMilli())
+		for _, snap := range state.LoadRangeFromCSV(logDir, lo, hi) {
+			k := &snap.Candle1s
+			if k.Close == 0 {
+				continue
+			}
+			c := Candle{
+				Open: k.Open, High: k.High, Low: k.Low, Close: k.Close,
+				Volume: k.BuyVol + k.SellVol, BuyVol: k.BuyVol, SellVol: k.SellVol,
+				Trades: k.BuyCount + k.SellCount,
+			}
+			if err := add(snap.Time/1000, &c); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range bars {
+		if bars[i].Open != 0 {
+			if err := fn(i, &bars[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
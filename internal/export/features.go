@@ -0,0 +1,29 @@
+package export
+
+import (
+	"time"
+
+	"market-indikator/internal/features"
+	"market-indikator/internal/state"
+)
+
+// ScanFeatures calls fn with the feature vector (internal/features) of every
+// logged snapshot with from <= Time < to, in time order, one day in memory
+// at a time. v is reused between calls.
+func ScanFeatures(logDir string, from, to int64, fn func(ms int64, v []float32) error) error {
+	var (
+		fx  features.Extractor
+		vec [features.Len]float32
+	)
+	for _, day := range days(from, to) {
+		start, _ := time.Parse("2006-01-02", day)
+		lo, hi := max(from, start.UnixMilli()), min(to, start.AddDate(0, 0, 1).UnixMilli())
+		for _, snap := range state.LoadRangeFromCSV(logDir, lo, hi) {
+			fx.Extract(&snap, vec[:])
+			if err := fn(snap.Time, vec[:]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
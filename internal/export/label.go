@@ -0,0 +1,142 @@
+package export
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// FORWARD-RETURN LABELS — supervised targets for the snapshot log
+// =============================================================================
+//
+// Every snapshot row gets the return that was realized h later, per horizon:
+//
+//   fwd_h(t) = 10⁴ · (p(t+h) − p(t)) / p(t)        basis points
+//
+// p(t+h) is the price of the last row at or before t+h. A label is missing
+// (NaN; an empty CSV cell) when that row is more than maxGap older than t+h
+// — the collector was down across the horizon — or when the log ends before
+// t+h. Missing labels are left out rather than guessed, so a model never
+// trains on a return that didn't happen.
+//
+// These are the same forward returns the online self-evaluation
+// (internal/evaluation) scores live, so offline calibration and the live
+// report agree on what "the score was right" means.
+// =============================================================================
+
+// SnapshotTable — logged snapshot rows under one header.
+type SnapshotTable struct {
+	Header []string
+	Rows   [][]string
+	Times  []int64   // timestamp column, unix ms
+	Prices []float64 // price column
+}
+
+// ReadSnapshots reads the snapshot log rows with from <= timestamp < to,
+// oldest first. Days logged under an older schema (v1 / v2) are mapped onto
+// the newest header found; their missing columns are empty.
+func ReadSnapshots(logDir string, from, to int64) (*SnapshotTable, error) {
+	files := dayFiles(logDir, from, to)
+	var names []string
+	for day := range files {
+		names = append(names, day)
+	}
+	sort.Strings(names)
+
+	type row struct {
+		ms    int64
+		cells []string
+	}
+	var (
+		header []string
+		col    map[string]int
+		rows   []row
+	)
+	for _, day := range names {
+		for _, path := range files[day] {
+			var idx []int // file column → table column (−1 = dropped)
+			err := readCSV(path, func(r []string) {
+				if idx == nil {
+					if len(header) < len(r) {
+						// Newer (wider) schema: widen the table
+						header = append([]string(nil), r...)
+						col = make(map[string]int, len(header))
+						for i, h := range header {
+							col[strings.TrimSpace(h)] = i
+						}
+						for i := range rows {
+							rows[i].cells = append(rows[i].cells, make([]string, len(header)-len(rows[i].cells))...)
+						}
+					}
+					idx = make([]int, len(r))
+					for i, h := range r {
+						if c, ok := col[strings.TrimSpace(h)]; ok {
+							idx[i] = c
+						} else {
+							idx[i] = -1
+						}
+					}
+					return
+				}
+				ms, err := strconv.ParseInt(r[0], 10, 64)
+				if err != nil || ms < from || ms >= to {
+					return
+				}
+				cells := make([]string, len(header))
+				for i, v := range r {
+					if i < len(idx) && idx[i] >= 0 {
+						cells[idx[i]] = v
+					}
+				}
+				rows = append(rows, row{ms, cells})
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	// Rows of a day split across _v3 / rotated parts: restore time order
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].ms < rows[j].ms })
+
+	t := &SnapshotTable{Header: header}
+	priceCol, ok := col["price"]
+	for _, r := range rows {
+		var price float64
+		if ok {
+			price, _ = strconv.ParseFloat(r.cells[priceCol], 64)
+		}
+		t.Rows = append(t.Rows, r.cells)
+		t.Times = append(t.Times, r.ms)
+		t.Prices = append(t.Prices, price)
+	}
+	return t, nil
+}
+
+// ForwardReturns returns fwd_h in bps for every row of times/prices (sorted
+// by time) and every horizon (ms), NaN where missing — see above.
+func ForwardReturns(times []int64, prices []float64, horizons []int64, maxGap int64) [][]float64 {
+	n := len(times)
+	out := make([][]float64, len(horizons))
+	for k, h := range horizons {
+		ret := make([]float64, n)
+		j := 0
+		for i := 0; i < n; i++ {
+			target := times[i] + h
+			for j+1 < n && times[j+1] <= target {
+				j++
+			}
+			switch {
+			case n == 0 || target > times[n-1], // log ends first
+				times[j] < target-maxGap, // gap across the horizon
+				prices[i] <= 0 || prices[j] <= 0:
+				ret[i] = math.NaN()
+			default:
+				ret[i] = 1e4 * (prices[j] - prices[i]) / prices[i]
+			}
+		}
+		out[k] = ret
+	}
+	return out
+}
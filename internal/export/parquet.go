@@ -0,0 +1,315 @@
+package export
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// =============================================================================
+// PARQUET WRITER — flat tables, no third-party module
+// =============================================================================
+//
+// Just enough of Apache Parquet for the export tables (every column
+// REQUIRED, no nesting, no nulls), so pandas / polars / DuckDB / Spark read
+// the files directly:
+//
+//   file       "PAR1" | row group … | FileMetaData | footer length (4 B LE) | "PAR1"
+//   row group  one column chunk per column, each a single v1 data page
+//   page       PageHeader | values, PLAIN encoded, UNCOMPRESSED
+//              (REQUIRED flat columns have no repetition/definition levels)
+//   PLAIN      INT64 / DOUBLE 8 B LE; BYTE_ARRAY 4 B LE length + bytes
+//
+// PageHeader and FileMetaData are Thrift structs in the compact protocol
+// (field header = id delta << 4 | type, zigzag varints, lists with the
+// element type in the header). Rows are buffered column-wise and written as
+// a row group every rowGroupRows rows, so memory stays bounded on long
+// exports. Uncompressed is deliberate: the readers all support it, and a
+// zstd/snappy codec would be a second hand-written format.
+// =============================================================================
+
+const rowGroupRows = 1 << 17
+
+// Column kinds.
+const (
+	Int64 = iota
+	Double
+	String
+	TimestampMillis // INT64, unix ms
+)
+
+// Column — one Parquet column.
+type Column struct {
+	Name string
+	Kind int
+}
+
+// Parquet physical / converted types and enums used below.
+const (
+	ptInt64     = 2
+	ptDouble    = 5
+	ptByteArray = 6
+
+	ctUTF8            = 0
+	ctTimestampMillis = 9
+
+	repRequired  = 0
+	encPlain     = 0
+	encRLE       = 3
+	codecNone    = 0
+	pageTypeData = 0
+)
+
+// ParquetWriter — see above. Not safe for concurrent use.
+type ParquetWriter struct {
+	w    io.Writer
+	off  int64
+	cols []Column
+
+	rows   int
+	values [][]byte // PLAIN-encoded values per column, current row group
+	groups []rowGroup
+	total  int64
+}
+
+type rowGroup struct {
+	rows   int64
+	chunks []columnChunk
+}
+
+type columnChunk struct {
+	offset int64 // the data page header
+	size   int64 // page header + values
+}
+
+// NewParquetWriter writes the file magic and returns a writer for cols.
+func NewParquetWriter(w io.Writer, cols []Column) (*ParquetWriter, error) {
+	p := &ParquetWriter{w: w, cols: cols, values: make([][]byte, len(cols))}
+	return p, p.write([]byte("PAR1"))
+}
+
+// Write appends one row: int64 for Int64 / TimestampMillis, float64 (or
+// float32) for Double, string for String, in column order.
+func (p *ParquetWriter) Write(row ...any) error {
+	if len(row) != len(p.cols) {
+		return fmt.Errorf("parquet: %d values for %d columns", len(row), len(p.cols))
+	}
+	for i, v := range row {
+		b := p.values[i]
+		switch p.cols[i].Kind {
+		case Int64, TimestampMillis:
+			n, ok := v.(int64)
+			if !ok {
+				return fmt.Errorf("parquet: column %s wants int64, got %T", p.cols[i].Name, v)
+			}
+			b = binary.LittleEndian.AppendUint64(b, uint64(n))
+		case Double:
+			f, ok := v.(float64)
+			if f32, is32 := v.(float32); is32 {
+				f, ok = float64(f32), true
+			}
+			if !ok {
+				return fmt.Errorf("parquet: column %s wants float64, got %T", p.cols[i].Name, v)
+			}
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(f))
+		case String:
+			s, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("parquet: column %s wants string, got %T", p.cols[i].Name, v)
+			}
+			b = binary.LittleEndian.AppendUint32(b, uint32(len(s)))
+			b = append(b, s...)
+		}
+		p.values[i] = b
+	}
+	p.rows++
+	if p.rows == rowGroupRows {
+		return p.flush()
+	}
+	return nil
+}
+
+// Close writes the last row group and the footer. It does not close the
+// underlying writer.
+func (p *ParquetWriter) Close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+	meta := p.fileMetaData()
+	footer := binary.LittleEndian.AppendUint32(meta, uint32(len(meta)))
+	return p.write(append(footer, "PAR1"...))
+}
+
+// flush writes the buffered rows as one row group.
+func (p *ParquetWriter) flush() error {
+	if p.rows == 0 {
+		return nil
+	}
+	g := rowGroup{rows: int64(p.rows)}
+	for i, values := range p.values {
+		var t thrift
+		t.begin()
+		t.field(1, tI32).i32(pageTypeData)
+		t.field(2, tI32).i32(int32(len(values))) // uncompressed size
+		t.field(3, tI32).i32(int32(len(values))) // compressed size
+		t.field(5, tStruct).begin()              // DataPageHeader
+		t.field(1, tI32).i32(int32(p.rows))
+		t.field(2, tI32).i32(encPlain)
+		t.field(3, tI32).i32(encRLE)
+		t.field(4, tI32).i32(encRLE)
+		t.end()
+		t.end()
+
+		chunk := columnChunk{offset: p.off, size: int64(len(t.b) + len(values))}
+		if err := p.write(t.b); err != nil {
+			return err
+		}
+		if err := p.write(values); err != nil {
+			return err
+		}
+		g.chunks = append(g.chunks, chunk)
+		p.values[i] = values[:0]
+	}
+	p.groups = append(p.groups, g)
+	p.total += int64(p.rows)
+	p.rows = 0
+	return nil
+}
+
+func (p *ParquetWriter) fileMetaData() []byte {
+	var t thrift
+	t.begin()
+	t.field(1, tI32).i32(1) // version
+
+	t.field(2, tList).list(tStruct, 1+len(p.cols)) // schema: root, then leaves
+	t.begin()
+	t.field(4, tBinary).str("schema")
+	t.field(5, tI32).i32(int32(len(p.cols)))
+	t.end()
+	for _, c := range p.cols {
+		t.begin()
+		t.field(1, tI32).i32(physicalType(c.Kind))
+		t.field(3, tI32).i32(repRequired)
+		t.field(4, tBinary).str(c.Name)
+		switch c.Kind {
+		case String:
+			t.field(6, tI32).i32(ctUTF8)
+		case TimestampMillis:
+			t.field(6, tI32).i32(ctTimestampMillis)
+		}
+		t.end()
+	}
+
+	t.field(3, tI64).i64(p.total)
+	t.field(4, tList).list(tStruct, len(p.groups))
+	for _, g := range p.groups {
+		t.begin()
+		t.field(1, tList).list(tStruct, len(g.chunks))
+		var bytes int64
+		for i, ch := range g.chunks {
+			bytes += ch.size
+			t.begin() // ColumnChunk
+			t.field(2, tI64).i64(ch.offset)
+			t.field(3, tStruct).begin() // ColumnMetaData
+			t.field(1, tI32).i32(physicalType(p.cols[i].Kind))
+			t.field(2, tList).list(tI32, 1)
+			t.i32(encPlain)
+			t.field(3, tList).list(tBinary, 1)
+			t.str(p.cols[i].Name)
+			t.field(4, tI32).i32(codecNone)
+			t.field(5, tI64).i64(g.rows)
+			t.field(6, tI64).i64(ch.size)
+			t.field(7, tI64).i64(ch.size)
+			t.field(9, tI64).i64(ch.offset)
+			t.end()
+			t.end()
+		}
+		t.field(2, tI64).i64(bytes)
+		t.field(3, tI64).i64(g.rows)
+		t.end()
+	}
+	t.field(6, tBinary).str("market-indikator export")
+	t.end()
+	return t.b
+}
+
+func physicalType(kind int) int32 {
+	switch kind {
+	case Double:
+		return ptDouble
+	case String:
+		return ptByteArray
+	}
+	return ptInt64
+}
+
+func (p *ParquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.off += int64(n)
+	return err
+}
+
+// =============================================================================
+// Thrift compact protocol — the writing half, structs of scalars and lists
+// =============================================================================
+
+const (
+	tI32    = 5
+	tI64    = 6
+	tBinary = 8
+	tList   = 9
+	tStruct = 12
+)
+
+type thrift struct {
+	b    []byte
+	last []int16 // previous field id, per open struct
+}
+
+func (t *thrift) begin() {
+	t.last = append(t.last, 0)
+}
+
+func (t *thrift) end() {
+	t.b = append(t.b, 0) // STOP
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *thrift) field(id int16, typ byte) *thrift {
+	top := &t.last[len(t.last)-1]
+	if d := id - *top; d > 0 && d <= 15 {
+		t.b = append(t.b, byte(d)<<4|typ)
+	} else {
+		t.b = append(t.b, typ)
+		t.varint(uint64(uint16(id<<1 ^ id>>15)))
+	}
+	*top = id
+	return t
+}
+
+func (t *thrift) list(elem byte, n int) {
+	if n < 15 {
+		t.b = append(t.b, byte(n)<<4|elem)
+		return
+	}
+	t.b = append(t.b, 0xF0|elem)
+	t.varint(uint64(n))
+}
+
+func (t *thrift) i32(v int32) {
+	t.varint(uint64(uint32(v<<1 ^ v>>31)))
+}
+
+func (t *thrift) i64(v int64) {
+	t.varint(uint64(v<<1 ^ v>>63))
+}
+
+func (t *thrift) str(s string) {
+	t.varint(uint64(len(s)))
+	t.b = append(t.b, s...)
+}
+
+func (t *thrift) varint(v uint64) {
+	t.b = binary.AppendUvarint(t.b, v)
+}
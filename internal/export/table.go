@@ -0,0 +1,117 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Table — one output file, CSV or Parquet, for the command-line tools.
+type Table struct {
+	f   *os.File
+	buf *bufio.Writer
+	pq  *ParquetWriter
+}
+
+// CreateTable creates path ("" = stdout, CSV only) in format "csv" or
+// "parquet" and writes the header.
+func CreateTable(path, format string, cols []Column) (*Table, error) {
+	if format != "csv" && format != "parquet" {
+		return nil, fmt.Errorf("format %q: want csv or parquet", format)
+	}
+	if path == "" && format == "parquet" {
+		return nil, fmt.Errorf("-format parquet needs -out")
+	}
+	t := &Table{f: os.Stdout}
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, err
+		}
+		t.f = f
+	}
+	t.buf = bufio.NewWriterSize(t.f, 1<<20)
+	if format == "parquet" {
+		pq, err := NewParquetWriter(t.buf, cols)
+		if err != nil {
+			return nil, err
+		}
+		t.pq = pq
+		return t, nil
+	}
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	fmt.Fprintln(t.buf, strings.Join(names, ","))
+	return t, nil
+}
+
+// Write appends one row, typed as for ParquetWriter.Write. In CSV a NaN is
+// an empty cell.
+func (t *Table) Write(row ...any) error {
+	if t.pq != nil {
+		return t.pq.Write(row...)
+	}
+	for i, v := range row {
+		if i > 0 {
+			t.buf.WriteByte(',')
+		}
+		switch v := v.(type) {
+		case float64:
+			if !math.IsNaN(v) {
+				t.buf.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+			}
+		case float32:
+			if !math.IsNaN(float64(v)) {
+				t.buf.WriteString(strconv.FormatFloat(float64(v), 'f', -1, 32))
+			}
+		case string:
+			t.buf.WriteString(v)
+		default:
+			fmt.Fprint(t.buf, v)
+		}
+	}
+	return t.buf.WriteByte('\n')
+}
+
+// Close finishes the file.
+func (t *Table) Close() error {
+	if t.pq != nil {
+		if err := t.pq.Close(); err != nil {
+			return err
+		}
+	}
+	err := t.buf.Flush()
+	if t.f != os.Stdout {
+		if cerr := t.f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// ParseTime parses a command-line time: YYYY-MM-DD (UTC; as an end, the
+// whole day is included), RFC 3339 or unix ms. "" is def.
+func ParseTime(s string, def int64, end bool) (int64, error) {
+	if s == "" {
+		return def, nil
+	}
+	if d, err := time.Parse("2006-01-02", s); err == nil {
+		if end {
+			d = d.AddDate(0, 0, 1)
+		}
+		return d.UnixMilli(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UnixMilli(), nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ms, nil
+	}
+	return 0, fmt.Errorf("%q: want YYYY-MM-DD, RFC 3339 or unix ms", s)
+}
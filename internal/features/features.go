@@ -0,0 +1,125 @@
+package features
+
+import (
+	"math"
+
+	"market-indikator/internal/model"
+)
+
+// =============================================================================
+// FEATURE VECTOR — a fixed, versioned model input per snapshot
+// =============================================================================
+//
+// One definition serves both sides of a learned score: the export tool
+// writes it for every logged snapshot (training), the ml analyzer computes
+// it on every live snapshot (inference). Only inputs the snapshot log
+// records are used, so a vector rebuilt from the CSV log equals the one the
+// live engine saw at the close of that second — no train/serve skew.
+//
+// Version 1 (Len = 18), float32, in this order:
+//
+//   0  final_score      FinalScore / 100
+//   1  score_1m         1m candle average score / 100
+//   2  score_5m … 6 score_1d   HTF average scores / 100 (0 if not configured)
+//   7  delta_share_1s   1s delta / 1s volume                  ∈ [−1, 1]
+//   8  ob_score         orderbook score / 100
+//   9  imbalance        orderbook imbalance
+//   10 oi_delta_pct     100 · OI change over 1m / OI
+//   11 range_1s_bps     10⁴ · (high − low) / close, 1s candle
+//   12 body_1s_bps      10⁴ · (close − open) / open
+//   13 trades_1s        ln(1 + trades in the 1s candle)
+//   14 avg_size_1s      ln(1 + average trade size)
+//   15 ret_10s          10⁴ · (price − close h ago) / close h ago, h = 10s
+//   16 ret_1m           … h = 1m
+//   17 ret_5m           … h = 5m
+//
+// Features 15–17 need history: an Extractor keeps the close of each second
+// of the last minutes; "close h ago" is that of the last second with a
+// snapshot at most 5s before t − h, and the feature is 0 without one. Missing
+// inputs (older log schemas, no trades) are 0, never NaN.
+//
+// The list only grows by bumping Version; a model records the version it
+// was trained on (see internal/onnx) and is refused on a mismatch.
+// =============================================================================
+
+// Version — the feature vector's version.
+const Version = 1
+
+// Len — features per vector.
+const Len = 18
+
+// Names — the features in vector order.
+var Names = [Len]string{
+	"final_score", "score_1m", "score_5m", "score_15m", "score_1h", "score_4h", "score_1d",
+	"delta_share_1s", "ob_score", "imbalance", "oi_delta_pct",
+	"range_1s_bps", "body_1s_bps", "trades_1s", "avg_size_1s",
+	"ret_10s", "ret_1m", "ret_5m",
+}
+
+var (
+	htfSeconds = [...]int64{300, 900, 3600, 14400, 86400}
+	retSeconds = [...]int64{10, 60, 300}
+)
+
+const (
+	historySecs = 512 // ≥ the longest return horizon + maxGapSecs + 1, power of two
+	maxGapSecs  = 5   // quiet seconds (no snapshot) bridged when looking back
+)
+
+// Extractor — builds vectors from snapshots in time order. One per stream;
+// not safe for concurrent use.
+type Extractor struct {
+	sec   int64 // second of the latest snapshot
+	price float64
+	close [historySecs]float64 // close of each second, by sec % historySecs
+	at    [historySecs]int64   // the second each slot holds
+}
+
+// Extract fills out (len ≥ Len) with the vector for s.
+func (e *Extractor) Extract(s *model.Snapshot, out []float32) {
+	sec := s.Time / 1000
+	if sec != e.sec && e.sec != 0 {
+		i := e.sec % historySecs
+		e.close[i], e.at[i] = e.price, e.sec
+	}
+	e.sec, e.price = sec, s.Price
+
+	out[0] = float32(s.FinalScore / 100)
+	out[1] = float32(s.Candle1m.AvgScore / 100)
+	for i, tf := range htfSeconds {
+		out[2+i] = float32(s.HTFScore(tf) / 100)
+	}
+
+	k := &s.Candle1s
+	vol := k.BuyVol + k.SellVol
+	out[7] = float32(ratio(k.Delta, vol))
+	out[8] = float32(float64(s.Orderbook.Score) / 100)
+	out[9] = float32(s.Orderbook.Imbalance)
+	out[10] = float32(100 * ratio(s.OI.OIDelta1m, s.OI.OI))
+	out[11] = float32(1e4 * ratio(k.High-k.Low, k.Close))
+	out[12] = float32(1e4 * ratio(k.Close-k.Open, k.Open))
+	out[13] = float32(math.Log1p(float64(max(k.BuyCount+k.SellCount, 0))))
+	out[14] = float32(math.Log1p(max(k.AvgSize, 0)))
+
+	for i, h := range retSeconds {
+		out[15+i] = 0
+		for back := sec - h; back >= sec-h-maxGapSecs; back-- {
+			if j := back % historySecs; e.at[j] == back && e.close[j] > 0 {
+				out[15+i] = float32(1e4 * (s.Price - e.close[j]) / e.close[j])
+				break
+			}
+		}
+	}
+}
+
+// ratio — a / b, 0 if b is 0 (or the result isn't finite).
+func ratio(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	r := a / b
+	if math.IsNaN(r) || math.IsInf(r, 0) {
+		return 0
+	}
+	return r
+}
@@ -0,0 +1,121 @@
+package flow
+
+// =============================================================================
+// EFFORT VS RESULT — absorption / exhaustion and liquidity vacuums
+// =============================================================================
+//
+// Wyckoff's effort-vs-result on one candle timeframe (1s, 1m):
+//
+//   effort = |Delta| / σ_Δ        how hard the aggressor pushed
+//   result = (High-Low) / σ_R     how far price actually moved
+//
+// σ_Δ and σ_R are EMAs of |Delta| and range over completed candles
+// (N=60 candles), so both are "multiples of a typical candle". The live
+// candle is compared against them as it forms.
+//
+//   EffortResult = clamp(effort, 0, 5) - clamp(result, 0, 5)
+//
+//   ≫ 0  high effort, no result → ABSORPTION: passive liquidity is eating the
+//        aggressor; the push is exhausting (buy absorption is bearish).
+//   ≪ 0  low effort, big result → LIQUIDITY VACUUM: the book is thin, price
+//        travels on little volume (moves are fragile and overshoot).
+//
+// Events fire when effort ≥ 2 and result ≤ 0.5 (absorption) or effort ≤ 0.5
+// and result ≥ 2 (vacuum), after a warm-up of effortWarmup candles.
+// =============================================================================
+
+const (
+	effortAlpha  = 2.0 / (60 + 1)
+	effortWarmup = 30
+
+	effortHigh = 2.0
+	effortLow  = 0.5
+)
+
+// Event flags for one timeframe (shifted per timeframe by the caller).
+const (
+	EventAbsorbBuy  = 1 << 0 // heavy buying, no upside progress
+	EventAbsorbSell = 1 << 1 // heavy selling, no downside progress
+	EventVacuumUp   = 1 << 2 // price lifted on little net buying
+	EventVacuumDown = 1 << 3 // price dropped on little net selling
+)
+
+// EffortResult — effort-vs-result analyzer for one candle timeframe.
+// Owned by the engine goroutine.
+type EffortResult struct {
+	Bucket int64 // live candle's bucket start
+	Delta  float64
+	Range  float64
+
+	SigmaDelta float64 // EMA |Delta| of completed candles
+	SigmaRange float64 // EMA range of completed candles
+	Closed     int     // completed candles seen (warm-up)
+}
+
+// Update — feeds the live candle (bucket start, running delta, OHLC).
+// Returns the EffortResult value and event flags for the live candle.
+func (a *EffortResult) Update(bucket int64, delta, open, high, low, close float64) (float64, int) {
+	if bucket != a.Bucket {
+		if a.Bucket != 0 {
+			a.close()
+		}
+		a.Bucket = bucket
+	}
+	a.Delta = delta
+	a.Range = high - low
+	return a.evaluate(close >= open)
+}
+
+func (a *EffortResult) close() {
+	d := a.Delta
+	if d < 0 {
+		d = -d
+	}
+	if a.Closed == 0 {
+		a.SigmaDelta, a.SigmaRange = d, a.Range
+	} else {
+		a.SigmaDelta += effortAlpha * (d - a.SigmaDelta)
+		a.SigmaRange += effortAlpha * (a.Range - a.SigmaRange)
+	}
+	a.Closed++
+}
+
+func (a *EffortResult) evaluate(up bool) (float64, int) {
+	if a.Closed < effortWarmup || a.SigmaDelta <= 0 || a.SigmaRange <= 0 {
+		return 0, 0
+	}
+	d := a.Delta
+	if d < 0 {
+		d = -d
+	}
+	effort := d / a.SigmaDelta
+	result := a.Range / a.SigmaRange
+	er := clamp(effort, 0, 5) - clamp(result, 0, 5)
+
+	var events int
+	switch {
+	case effort >= effortHigh && result <= effortLow:
+		if a.Delta > 0 {
+			events |= EventAbsorbBuy
+		} else {
+			events |= EventAbsorbSell
+		}
+	case effort <= effortLow && result >= effortHigh:
+		if up {
+			events |= EventVacuumUp
+		} else {
+			events |= EventVacuumDown
+		}
+	}
+	return er, events
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
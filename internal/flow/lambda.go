@@ -0,0 +1,81 @@
+package flow
+
+// =============================================================================
+// KYLE'S LAMBDA — price impact per unit of signed volume
+// =============================================================================
+//
+// Kyle (1985): price moves linearly in net order flow,
+//
+//   Δp_t = λ · x_t + ε_t
+//
+// with x_t the signed (aggressor) volume and Δp_t the price change over the
+// same interval. Sampled per completed 1s candle (x = Delta, Δp = close −
+// previous close), λ is the rolling OLS slope:
+//
+//   λ = Cov(x, Δp) / Var(x)
+//
+// with means, variance and covariance kept as EMAs (N=300 seconds), so the
+// estimate tracks the current liquidity regime in O(1) per second.
+//
+// TRADING INTERPRETATION:
+//   High λ → the book is thin, the market is "cheap to push": a given delta
+//            moves price a lot (and a CVD surge is meaningful).
+//   Low λ  → deep book, flow is absorbed; large deltas with little movement
+//            (see EffortResult).
+//   λ ≤ 0  → flow and price disagree over the window (mean reversion /
+//            absorption regime); the slope carries little information.
+// =============================================================================
+
+const (
+	lambdaAlpha  = 2.0 / (300 + 1)
+	lambdaWarmup = 60
+)
+
+// Lambda — rolling price-impact estimator. Owned by the engine goroutine.
+type Lambda struct {
+	Bucket    int64 // live 1s candle's bucket
+	Delta     float64
+	Close     float64
+	PrevClose float64 // close of the last completed second (0 = none)
+
+	MeanX, MeanY float64
+	VarX, CovXY  float64
+	Samples      int
+}
+
+// Update — feeds the live 1s candle; a new bucket completes the previous
+// second's sample.
+func (l *Lambda) Update(bucket int64, delta, close float64) {
+	if bucket != l.Bucket {
+		if l.Bucket != 0 {
+			l.sample()
+		}
+		l.Bucket = bucket
+	}
+	l.Delta = delta
+	l.Close = close
+}
+
+func (l *Lambda) sample() {
+	prev := l.PrevClose
+	l.PrevClose = l.Close
+	if prev == 0 {
+		return
+	}
+	x, y := l.Delta, l.Close-prev
+	dx := x - l.MeanX
+	dy := y - l.MeanY
+	l.MeanX += lambdaAlpha * dx
+	l.MeanY += lambdaAlpha * dy
+	l.VarX = (1 - lambdaAlpha) * (l.VarX + lambdaAlpha*dx*dx)
+	l.CovXY = (1 - lambdaAlpha) * (l.CovXY + lambdaAlpha*dx*dy)
+	l.Samples++
+}
+
+// Value — λ in price units per unit of signed volume (0 during warm-up).
+func (l *Lambda) Value() float64 {
+	if l.Samples < lambdaWarmup || l.VarX < 1e-12 {
+		return 0
+	}
+	return l.CovXY / l.VarX
+}
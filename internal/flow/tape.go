@@ -0,0 +1,113 @@
+package flow
+
+import (
+	"math"
+)
+
+// =============================================================================
+// TAPE SPEED — trade intensity
+// =============================================================================
+//
+// The pressure score is normalized flow, so it reads the same on 3 trades/s
+// as on 300 trades/s. Tape speed puts it in context.
+//
+// Per completed second s with n_s trades and volume v_s:
+//
+//   Rate    = EMA_fast(n_s)                  trades/sec, N=10s
+//   AvgSize = EMA_fast(v_s) / EMA_fast(n_s)  mean trade size
+//   μ, σ²   = EMA_slow(n_s), EMA_slow((n_s-μ)²)   baseline, N=300s (5 min)
+//
+// Burst intensity compares the LIVE (partial) second against the baseline,
+// so a burst shows up on the trade that starts it:
+//
+//   IntensityZ = (n_live - μ) / σ
+//
+// Seconds without trades count as n=0 (applied when the next trade or
+// heartbeat arrives), so a market that goes quiet decays properly.
+//
+// TRADING INTERPRETATION:
+//   Score ±80 with IntensityZ > +2 → real aggression, participation behind it.
+//   Score ±80 with IntensityZ < 0  → thin tape, one or two prints moving it.
+//   AvgSize jump without Rate jump → large players active (block prints).
+// =============================================================================
+
+const (
+	tapeFastAlpha = 2.0 / (10 + 1)
+	tapeSlowAlpha = 2.0 / (300 + 1)
+
+	// maxCatchUp bounds the empty seconds folded in after a gap; beyond
+	// ~5 slow half-lives the EMAs are at their zero-flow values anyway.
+	maxCatchUp = 1500
+)
+
+// Tape — tape-speed tracker. Owned by the engine goroutine.
+type Tape struct {
+	Sec    int64   // current (live) second, unix seconds
+	Count  float64 // trades in the live second
+	Volume float64 // volume in the live second
+
+	Rate     float64 // EMA_fast trades/sec
+	VolRate  float64 // EMA_fast volume/sec
+	Mean     float64 // EMA_slow trades/sec
+	Variance float64 // EMA_slow squared deviation
+}
+
+// Add — folds one trade at sec (unix seconds). O(1) except after a gap.
+func (t *Tape) Add(sec int64, qty float64) {
+	t.Advance(sec)
+	t.Count++
+	t.Volume += qty
+}
+
+// Advance — closes every second before sec (heartbeat path).
+func (t *Tape) Advance(sec int64) {
+	if t.Sec == 0 {
+		t.Sec = sec
+		return
+	}
+	if sec <= t.Sec {
+		return
+	}
+	t.closeSecond(t.Count, t.Volume)
+	empty := sec - t.Sec - 1
+	if empty > maxCatchUp {
+		empty = maxCatchUp
+	}
+	for i := int64(0); i < empty; i++ {
+		t.closeSecond(0, 0)
+	}
+	t.Sec = sec
+	t.Count = 0
+	t.Volume = 0
+}
+
+func (t *Tape) closeSecond(n, v float64) {
+	t.Rate += tapeFastAlpha * (n - t.Rate)
+	t.VolRate += tapeFastAlpha * (v - t.VolRate)
+	d := n - t.Mean
+	t.Mean += tapeSlowAlpha * d
+	t.Variance = (1 - tapeSlowAlpha) * (t.Variance + tapeSlowAlpha*d*d)
+}
+
+// AvgSize — mean trade size over the fast window.
+func (t *Tape) AvgSize() float64 {
+	if t.Rate < 1e-9 {
+		return 0
+	}
+	return t.VolRate / t.Rate
+}
+
+// IntensityZ — live second's trade count vs the 5-minute baseline.
+func (t *Tape) IntensityZ() float64 {
+	return t.IntensityZAgainst(t.Mean)
+}
+
+// IntensityZAgainst — IntensityZ against another baseline rate (e.g. the
+// hour-of-week's, pressure.Seasonal), with the 5-minute σ.
+func (t *Tape) IntensityZAgainst(mean float64) float64 {
+	sigma := math.Sqrt(t.Variance)
+	if sigma < 1 {
+		sigma = 1 // a baseline of ~0 trades/s shouldn't turn 2 prints into z=50
+	}
+	return (t.Count - mean) / sigma
+}
@@ -0,0 +1,102 @@
+package flow
+
+// =============================================================================
+// VPIN — Volume-synchronized Probability of INformed trading
+// =============================================================================
+//
+// Easley, López de Prado & O'Hara (2012). Trades are grouped into buckets of
+// equal VOLUME V (a volume clock, so busy periods get more buckets than
+// quiet ones). Per bucket τ, with aggressor-classified volume:
+//
+//   OI_τ = |V_buy,τ − V_sell,τ|            order imbalance
+//
+// and over the last n complete buckets:
+//
+//   VPIN = Σ OI_τ / (n · V)                 ∈ [0, 1]
+//
+// The aggTrade feed carries the aggressor side, so no bulk-volume
+// classification is needed. A trade that overflows the current bucket is
+// split across as many buckets as it fills.
+//
+// TRADING INTERPRETATION:
+//   VPIN measures flow TOXICITY — how one-sided the volume is. Market makers
+//   widen / pull quotes when it is high, so it precedes liquidity-driven
+//   volatility (it peaked hours before the 2010 flash crash).
+//     < 0.2  balanced two-way flow
+//     > 0.4  one-sided, informed/forced flow — expect range expansion
+//
+// Bucket size trades responsiveness for noise: V ≈ a minute of volume and
+// n = 50 gives a ~1h horizon on BTCUSDT perps.
+// =============================================================================
+
+// VPIN — volume-bucket VPIN estimator. Owned by the engine goroutine.
+type VPIN struct {
+	BucketVol float64 // V
+	Imbalance []float64
+	Next      int  // ring slot for the next completed bucket
+	Full      bool // n buckets completed
+	SumOI     float64
+
+	Buy  float64 // current (filling) bucket
+	Sell float64
+}
+
+// NewVPIN — buckets of bucketVol over a window of n buckets.
+func NewVPIN(bucketVol float64, n int) *VPIN {
+	return &VPIN{BucketVol: bucketVol, Imbalance: make([]float64, n)}
+}
+
+// Add — folds in one trade (buy = aggressive buyer). O(1) per bucket filled.
+func (v *VPIN) Add(qty float64, buy bool) {
+	if v.BucketVol <= 0 {
+		return
+	}
+	for qty > 0 {
+		room := v.BucketVol - v.Buy - v.Sell
+		fill := qty
+		if fill > room {
+			fill = room
+		}
+		if buy {
+			v.Buy += fill
+		} else {
+			v.Sell += fill
+		}
+		qty -= fill
+		if v.Buy+v.Sell >= v.BucketVol*(1-1e-12) { // tolerate rounding at the edge
+			v.completeBucket()
+		}
+	}
+}
+
+func (v *VPIN) completeBucket() {
+	oi := v.Buy - v.Sell
+	if oi < 0 {
+		oi = -oi
+	}
+	v.SumOI += oi - v.Imbalance[v.Next]
+	v.Imbalance[v.Next] = oi
+	v.Next++
+	if v.Next == len(v.Imbalance) {
+		v.Next = 0
+		v.Full = true
+		// Re-sum once per lap so the running total can't drift
+		v.SumOI = 0
+		for _, x := range v.Imbalance {
+			v.SumOI += x
+		}
+	}
+	v.Buy, v.Sell = 0, 0
+}
+
+// Value — VPIN over the completed buckets (0 until the first completes).
+func (v *VPIN) Value() float64 {
+	n := v.Next
+	if v.Full {
+		n = len(v.Imbalance)
+	}
+	if n == 0 {
+		return 0
+	}
+	return clamp(v.SumOI/(float64(n)*v.BucketVol), 0, 1)
+}
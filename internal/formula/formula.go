@@ -0,0 +1,403 @@
+package formula
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/pressure"
+)
+
+// =============================================================================
+// USER-DEFINED SCORES — a small expression language over snapshot signals
+// =============================================================================
+//
+// Research scores are defined at startup as name=expression and evaluated by
+// the engine on every snapshot, next to FinalScore:
+//
+//   -formula 'flow=0.5*norm(cvd_vel) + 0.3*imbalance - 0.2*norm(oi_delta_1m)'
+//
+// Grammar (usual precedence, left-associative):
+//
+//   expr    = term { ("+" | "-") term }
+//   term    = unary { ("*" | "/") unary }
+//   unary   = "-" unary | primary
+//   primary = number | signal | func "(" expr { "," expr } ")" | "(" expr ")"
+//
+// Signals are the names in the signals table (signals.go), read from the
+// snapshot being built. Functions:
+//
+//   norm(x)          adaptive x / EMA(|x|) in [-1, +1] — the scorer's own
+//                    normalization, with state per call site
+//   abs(x)  sign(x)  tanh(x)
+//   min(a, b)  max(a, b)  clamp(x, lo, hi)
+//
+// Division by zero and non-finite results evaluate to 0. Expressions are
+// compiled once into a tree; evaluation is allocation-free. norm() state is
+// not checkpointed (it re-adapts within ~50 snapshots).
+// =============================================================================
+
+// node — a compiled expression.
+type node interface {
+	eval(s *model.Snapshot, env *env) float64
+}
+
+// Formula — one compiled, named expression.
+type Formula struct {
+	Name string
+	Expr string
+	root node
+}
+
+// Set — the configured formulas. Owned by the engine goroutine.
+type Set struct {
+	Formulas []*Formula
+	env      env
+}
+
+// env — per-snapshot derived signals shared by all formulas.
+type env struct {
+	prevCVD float64
+	cvdVel  float64
+	hasPrev bool
+}
+
+// ParseSpec parses "name=expression".
+func ParseSpec(spec string) (*Formula, error) {
+	name, expr, ok := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("formula %q: want name=expression", spec)
+	}
+	if len(name) > 31 {
+		return nil, fmt.Errorf("formula %q: name longer than 31 bytes", name)
+	}
+	for _, r := range name {
+		if !isIdent(r) {
+			return nil, fmt.Errorf("formula %q: name must be letters, digits and _", name)
+		}
+	}
+	return Compile(name, expr)
+}
+
+// Compile parses expr into a Formula.
+func Compile(name, expr string) (*Formula, error) {
+	p := &parser{src: expr}
+	p.next()
+	root, err := p.expr()
+	if err == nil && p.tok.kind != tokEOF {
+		err = p.errorf("unexpected %q", p.tok.text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("formula %s: %w", name, err)
+	}
+	return &Formula{Name: name, Expr: expr, root: root}, nil
+}
+
+// NewSet — a set of formulas (at most model.MaxFormulas, unique names).
+func NewSet(formulas []*Formula) (*Set, error) {
+	if len(formulas) > model.MaxFormulas {
+		return nil, fmt.Errorf("%d formulas, max %d", len(formulas), model.MaxFormulas)
+	}
+	seen := make(map[string]bool)
+	for _, f := range formulas {
+		if seen[f.Name] {
+			return nil, fmt.Errorf("duplicate formula %q", f.Name)
+		}
+		seen[f.Name] = true
+	}
+	return &Set{Formulas: formulas}, nil
+}
+
+// Names — formula names in order (for model.SetFormulas).
+func (s *Set) Names() []string {
+	out := make([]string, len(s.Formulas))
+	for i, f := range s.Formulas {
+		out[i] = f.Name
+	}
+	return out
+}
+
+// Eval evaluates every formula against snap into out (len ≥ len(Formulas)).
+func (s *Set) Eval(snap *model.Snapshot, out []float64) {
+	e := &s.env
+	if e.hasPrev {
+		e.cvdVel = snap.CVD - e.prevCVD
+	}
+	e.prevCVD, e.hasPrev = snap.CVD, true
+
+	for i, f := range s.Formulas {
+		v := f.root.eval(snap, e)
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			v = 0
+		}
+		out[i] = v
+	}
+}
+
+// ─── AST ───
+
+type number float64
+
+func (n number) eval(*model.Snapshot, *env) float64 { return float64(n) }
+
+type signal struct {
+	get func(*model.Snapshot, *env) float64
+}
+
+func (n signal) eval(s *model.Snapshot, e *env) float64 { return n.get(s, e) }
+
+type neg struct{ x node }
+
+func (n neg) eval(s *model.Snapshot, e *env) float64 { return -n.x.eval(s, e) }
+
+type binary struct {
+	op   byte
+	l, r node
+}
+
+func (n binary) eval(s *model.Snapshot, e *env) float64 {
+	l, r := n.l.eval(s, e), n.r.eval(s, e)
+	switch n.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	}
+	if r == 0 {
+		return 0
+	}
+	return l / r
+}
+
+type call1 struct {
+	fn func(float64) float64
+	x  node
+}
+
+func (n call1) eval(s *model.Snapshot, e *env) float64 { return n.fn(n.x.eval(s, e)) }
+
+type call2 struct {
+	fn   func(a, b float64) float64
+	a, b node
+}
+
+func (n call2) eval(s *model.Snapshot, e *env) float64 { return n.fn(n.a.eval(s, e), n.b.eval(s, e)) }
+
+type clampNode struct{ x, lo, hi node }
+
+func (n clampNode) eval(s *model.Snapshot, e *env) float64 {
+	return math.Max(n.lo.eval(s, e), math.Min(n.hi.eval(s, e), n.x.eval(s, e)))
+}
+
+// normNode — norm(x); the normalizer is this call site's own.
+type normNode struct {
+	x    node
+	norm *pressure.EMANorm
+}
+
+func (n normNode) eval(s *model.Snapshot, e *env) float64 { return n.norm.Norm(n.x.eval(s, e)) }
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return 0
+}
+
+// ─── LEXER / PARSER ───
+
+const (
+	tokEOF = iota
+	tokNum
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind int
+	text string
+	pos  int
+}
+
+type parser struct {
+	src string
+	pos int
+	tok token
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s at offset %d", fmt.Sprintf(format, args...), p.tok.pos)
+}
+
+func isIdent(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (p *parser) next() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos == len(p.src) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return
+	}
+	c := p.src[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.src) && (p.src[p.pos] >= '0' && p.src[p.pos] <= '9' || p.src[p.pos] == '.' ||
+			p.src[p.pos] == 'e' || p.src[p.pos] == 'E' ||
+			(p.src[p.pos] == '-' || p.src[p.pos] == '+') && (p.src[p.pos-1] == 'e' || p.src[p.pos-1] == 'E')) {
+			p.pos++
+		}
+		p.tok = token{kind: tokNum, text: p.src[start:p.pos], pos: start}
+	case isIdent(rune(c)):
+		for p.pos < len(p.src) && isIdent(rune(p.src[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: p.src[start:p.pos], pos: start}
+	default:
+		p.pos++
+		p.tok = token{kind: tokOp, text: p.src[start:p.pos], pos: start}
+	}
+}
+
+func (p *parser) isOp(op string) bool {
+	return p.tok.kind == tokOp && p.tok.text == op
+}
+
+func (p *parser) expect(op string) error {
+	if !p.isOp(op) {
+		if p.tok.kind == tokEOF {
+			return p.errorf("missing %q", op)
+		}
+		return p.errorf("want %q, got %q", op, p.tok.text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) expr() (node, error) {
+	l, err := p.term()
+	for err == nil && (p.isOp("+") || p.isOp("-")) {
+		op := p.tok.text[0]
+		p.next()
+		var r node
+		if r, err = p.term(); err == nil {
+			l = binary{op: op, l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) term() (node, error) {
+	l, err := p.unary()
+	for err == nil && (p.isOp("*") || p.isOp("/")) {
+		op := p.tok.text[0]
+		p.next()
+		var r node
+		if r, err = p.unary(); err == nil {
+			l = binary{op: op, l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) unary() (node, error) {
+	if p.isOp("-") {
+		p.next()
+		x, err := p.unary()
+		return neg{x}, err
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	switch p.tok.kind {
+	case tokNum:
+		v, err := strconv.ParseFloat(p.tok.text, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %q", p.tok.text)
+		}
+		p.next()
+		return number(v), nil
+
+	case tokIdent:
+		name, pos := p.tok.text, p.tok.pos
+		p.next()
+		if p.isOp("(") {
+			return p.call(name, pos)
+		}
+		get, ok := signals[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown signal %q at offset %d", name, pos)
+		}
+		return signal{get}, nil
+
+	case tokOp:
+		if p.isOp("(") {
+			p.next()
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+	return nil, p.errorf("unexpected end of expression")
+}
+
+// arity — argument count of each function.
+var arity = map[string]int{"norm": 1, "abs": 1, "sign": 1, "tanh": 1, "min": 2, "max": 2, "clamp": 3}
+
+func (p *parser) call(name string, pos int) (node, error) {
+	p.next() // "("
+	var args []node
+	for {
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, x)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	n, ok := arity[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at offset %d", name, pos)
+	}
+	if len(args) != n {
+		return nil, fmt.Errorf("%s takes %d argument(s), got %d at offset %d", name, n, len(args), pos)
+	}
+	switch name {
+	case "norm":
+		return normNode{x: args[0], norm: pressure.NewEMANorm()}, nil
+	case "abs":
+		return call1{math.Abs, args[0]}, nil
+	case "sign":
+		return call1{sign, args[0]}, nil
+	case "tanh":
+		return call1{math.Tanh, args[0]}, nil
+	case "min":
+		return call2{math.Min, args[0], args[1]}, nil
+	case "max":
+		return call2{math.Max, args[0], args[1]}, nil
+	}
+	return clampNode{args[0], args[1], args[2]}, nil
+}
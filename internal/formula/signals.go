@@ -0,0 +1,65 @@
+package formula
+
+import (
+	"sort"
+
+	"market-indikator/internal/model"
+)
+
+// signals — the names a formula can read. Each getter reads the snapshot
+// being built (FinalScore, ScoreDyn and the indicators are already filled in)
+// or the set's derived state.
+var signals = map[string]func(s *model.Snapshot, e *env) float64{
+	"price":   func(s *model.Snapshot, _ *env) float64 { return s.Price },
+	"cvd":     func(s *model.Snapshot, _ *env) float64 { return s.CVD },
+	"cvd_vel": func(_ *model.Snapshot, e *env) float64 { return e.cvdVel }, // ΔCVD since the previous snapshot
+
+	"delta_1s": func(s *model.Snapshot, _ *env) float64 { return s.Candle1s.Delta },
+	"delta_1m": func(s *model.Snapshot, _ *env) float64 { return s.Candle1m.Delta },
+
+	"ob_score":  func(s *model.Snapshot, _ *env) float64 { return float64(s.Orderbook.Score) },
+	"imbalance": func(s *model.Snapshot, _ *env) float64 { return s.Orderbook.Imbalance },
+	"spread":    func(s *model.Snapshot, _ *env) float64 { return s.Orderbook.Spread },
+
+	"oi_delta_1s": func(s *model.Snapshot, _ *env) float64 { return s.OI.OIDelta1s },
+	"oi_delta_1m": func(s *model.Snapshot, _ *env) float64 { return s.OI.OIDelta1m },
+	"oi_behavior": func(s *model.Snapshot, _ *env) float64 { return float64(s.OI.Behavior) },
+
+	"final_score": func(s *model.Snapshot, _ *env) float64 { return s.FinalScore },
+	"score_vel":   func(s *model.Snapshot, _ *env) float64 { return s.ScoreDyn.Velocity },
+	"score_accel": func(s *model.Snapshot, _ *env) float64 { return s.ScoreDyn.Accel },
+	"score_pct":   func(s *model.Snapshot, _ *env) float64 { return s.ScoreDyn.Percentile },
+
+	"tape_rate":   func(s *model.Snapshot, _ *env) float64 { return s.Flow.TradesPerSec },
+	"trade_size":  func(s *model.Snapshot, _ *env) float64 { return s.Flow.AvgTradeSize },
+	"intensity_z": func(s *model.Snapshot, _ *env) float64 { return s.Flow.IntensityZ },
+	"er_1s":       func(s *model.Snapshot, _ *env) float64 { return s.Flow.EffortResult1s },
+	"er_1m":       func(s *model.Snapshot, _ *env) float64 { return s.Flow.EffortResult1m },
+	"vpin":        func(s *model.Snapshot, _ *env) float64 { return s.Flow.VPIN },
+	"lambda":      func(s *model.Snapshot, _ *env) float64 { return s.Flow.Lambda },
+
+	"rsi_1m":       func(s *model.Snapshot, _ *env) float64 { return s.Indicators[0].RSI },
+	"rsi_5m":       func(s *model.Snapshot, _ *env) float64 { return s.Indicators[1].RSI },
+	"rsi_1h":       func(s *model.Snapshot, _ *env) float64 { return s.Indicators[2].RSI },
+	"macd_hist_1m": func(s *model.Snapshot, _ *env) float64 { return s.Indicators[0].Hist },
+	"macd_hist_5m": func(s *model.Snapshot, _ *env) float64 { return s.Indicators[1].Hist },
+	"macd_hist_1h": func(s *model.Snapshot, _ *env) float64 { return s.Indicators[2].Hist },
+
+	"vwap_session": func(s *model.Snapshot, _ *env) float64 { return s.VWAP.Session.VWAP },
+	"vwap_day":     func(s *model.Snapshot, _ *env) float64 { return s.VWAP.Day.VWAP },
+
+	"leader_expected": func(s *model.Snapshot, _ *env) float64 { return s.Leader.Expected },
+	"leader_corr":     func(s *model.Snapshot, _ *env) float64 { return s.Leader.Corr },
+	"leader_ret":      func(s *model.Snapshot, _ *env) float64 { return s.Leader.Ret10s },
+	"leader_cvd":      func(s *model.Snapshot, _ *env) float64 { return s.Leader.CVD10s },
+}
+
+// Signals — the available signal names, sorted (for -help and errors).
+func Signals() []string {
+	out := make([]string, 0, len(signals))
+	for name := range signals {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
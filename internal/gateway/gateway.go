@@ -0,0 +1,206 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"market-indikator/internal/broadcast"
+	"market-indikator/internal/buildinfo"
+)
+
+// =============================================================================
+// SHARD GATEWAY — one endpoint in front of per-symbol engine processes
+// =============================================================================
+//
+// An engine process tracks one symbol, and its broadcaster fans every
+// snapshot out from a single hub goroutine. Past a few dozen symbols one
+// process per symbol (a shard) is the way to spread the work over cores; the
+// gateway puts them back behind one address:
+//
+//   /ws, REST, dashboard   proxied to the shard named by ?symbol= (the first
+//                          shard without one); WebSocket upgrades pass through
+//   GET /rank              every shard's /rank merged and re-sorted
+//   GET /shards            the shards, whether they answer, and their builds
+//
+// The gateway holds no state and decodes nothing on the snapshot path: a
+// client's frames are copied from its shard's socket as they come. Shards
+// can be restarted behind it; clients reconnect as they would to a direct
+// engine.
+// =============================================================================
+
+const probeTimeout = 2 * time.Second
+
+// Shard — one engine process.
+type Shard struct {
+	Symbol string
+	URL    *url.URL
+	proxy  *httputil.ReverseProxy
+}
+
+// Gateway — the shards by symbol.
+type Gateway struct {
+	shards []*Shard
+	bySym  map[string]*Shard
+	client *http.Client
+}
+
+// New — a gateway with no shards yet.
+func New() *Gateway {
+	return &Gateway{bySym: make(map[string]*Shard), client: &http.Client{Timeout: probeTimeout}}
+}
+
+// Add routes symbol to the engine at rawURL (http://host:port). Must be
+// called before serving.
+func (g *Gateway) Add(symbol, rawURL string) error {
+	symbol = strings.ToUpper(symbol)
+	if symbol == "" {
+		return fmt.Errorf("empty symbol")
+	}
+	if _, dup := g.bySym[symbol]; dup {
+		return fmt.Errorf("%s: duplicate shard", symbol)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s: shard URL %q: want http://host:port", symbol, rawURL)
+	}
+	sh := &Shard{Symbol: symbol, URL: u}
+	sh.proxy = &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(u)
+			r.SetXForwarded()
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("Gateway: %s shard: %v", symbol, err)
+			http.Error(w, symbol+" shard unavailable", http.StatusBadGateway)
+		},
+	}
+	g.shards = append(g.shards, sh)
+	g.bySym[symbol] = sh
+	return nil
+}
+
+// Shards — in the order added.
+func (g *Gateway) Shards() []*Shard {
+	return g.shards
+}
+
+// ServeHTTP routes a request to its shard, or answers /rank and /shards.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/rank":
+		g.serveRank(w, r)
+		return
+	case "/shards":
+		g.serveShards(w, r)
+		return
+	}
+	if len(g.shards) == 0 {
+		http.Error(w, "no shards", http.StatusServiceUnavailable)
+		return
+	}
+	sh := g.shards[0]
+	if sym := r.URL.Query().Get("symbol"); sym != "" {
+		if sh = g.bySym[strings.ToUpper(sym)]; sh == nil {
+			http.Error(w, "unknown symbol "+sym, http.StatusNotFound)
+			return
+		}
+	}
+	sh.proxy.ServeHTTP(w, r)
+}
+
+// fanOut runs fn for every shard concurrently and waits.
+func (g *Gateway) fanOut(fn func(i int, sh *Shard)) {
+	var wg sync.WaitGroup
+	for i, sh := range g.shards {
+		wg.Add(1)
+		go func(i int, sh *Shard) {
+			defer wg.Done()
+			fn(i, sh)
+		}(i, sh)
+	}
+	wg.Wait()
+}
+
+// getJSON decodes GET <shard><path> into v.
+func (g *Gateway) getJSON(sh *Shard, path string, v any) error {
+	resp, err := g.client.Get(sh.URL.JoinPath(path).String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// serveRank — GET /rank[?by=…] over all shards. A shard that doesn't answer
+// is listed like a symbol without a snapshot.
+func (g *Gateway) serveRank(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	by := r.URL.Query().Get("by")
+	if by == "" {
+		by = "score"
+	}
+	if !broadcast.SortRank(nil, by) {
+		http.Error(w, "by must be score, velocity, oi or volume", http.StatusBadRequest)
+		return
+	}
+	parts := make([][]broadcast.RankEntry, len(g.shards))
+	g.fanOut(func(i int, sh *Shard) {
+		if err := g.getJSON(sh, "/rank", &parts[i]); err != nil {
+			parts[i] = []broadcast.RankEntry{{Symbol: sh.Symbol, AgeMs: -1}}
+		}
+	})
+	var out []broadcast.RankEntry
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	broadcast.SortRank(out, by)
+	writeJSON(w, out)
+}
+
+// ShardStatus is one entry of GET /shards.
+type ShardStatus struct {
+	Symbol string          `json:"symbol"`
+	URL    string          `json:"url"`
+	Up     bool            `json:"up"`
+	Error  string          `json:"error,omitempty"`
+	Build  *buildinfo.Info `json:"build,omitempty"`
+}
+
+func (g *Gateway) serveShards(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	out := make([]ShardStatus, len(g.shards))
+	g.fanOut(func(i int, sh *Shard) {
+		st := ShardStatus{Symbol: sh.Symbol, URL: sh.URL.String()}
+		var info buildinfo.Info
+		if err := g.getJSON(sh, "/version", &info); err != nil {
+			st.Error = err.Error()
+		} else {
+			st.Up, st.Build = true, &info
+		}
+		out[i] = st
+	})
+	writeJSON(w, out)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Gateway encode error: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package harness
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"market-indikator/internal/ingest"
+	"market-indikator/internal/model"
+	"market-indikator/internal/orderbook"
+)
+
+// Fixture format: one JSON event per line, in feed order.
+//
+//   {"kind":"trade","time":…,"id":…,"price":…,"qty":…,"buyer_maker":true}
+//   {"kind":"depth","time":…,"bids":[[price,qty],…],"asks":[[price,qty],…]}
+//   {"kind":"oi","time":…,"oi":…,"price":…}
+//   {"kind":"tick","time":…}
+//
+// Trade time is exchange time; depth / oi / tick time stands in for the wall
+// clock at which the live process would have seen them.
+
+// Event kinds.
+const (
+	KindTrade = "trade"
+	KindDepth = "depth"
+	KindOI    = "oi"
+	KindTick  = "tick"
+)
+
+// Fixture schedule for RecordSynthetic — the synthetic ingester's depth and
+// OI cadence and the orderflow heartbeat lag.
+const (
+	depthEveryMs   = 250
+	oiEveryMs      = 3000
+	heartbeatLagMs = 250
+)
+
+// Event — one fixture line.
+type Event struct {
+	Kind       string       `json:"kind"`
+	Time       int64        `json:"time"` // unix ms
+	ID         int64        `json:"id,omitempty"`
+	Price      float64      `json:"price,omitempty"` // trade price / price at the OI poll
+	Qty        float64      `json:"qty,omitempty"`
+	BuyerMaker bool         `json:"buyer_maker,omitempty"` // aggressive sell
+	Bids       [][2]float64 `json:"bids,omitempty"`
+	Asks       [][2]float64 `json:"asks,omitempty"`
+	OI         float64      `json:"oi,omitempty"`
+}
+
+// Trade — the event as a model.Trade.
+func (ev *Event) Trade() model.Trade {
+	return model.Trade{ID: ev.ID, Price: ev.Price, Quantity: ev.Qty, Time: ev.Time, IsBuyer: ev.BuyerMaker}
+}
+
+// ReadFixture parses a JSON-lines fixture.
+func ReadFixture(r io.Reader) ([]Event, error) {
+	var events []Event
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var ev Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, ev)
+	}
+	return events, sc.Err()
+}
+
+// WriteFixture writes events as JSON lines.
+func WriteFixture(w io.Writer, events []Event) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for i := range events {
+		if err := enc.Encode(&events[i]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// RecordSynthetic records durationMs of the synthetic market (cfg.StartMs
+// must be set for a reproducible fixture): trades, depth every 250 ms, OI
+// every 3 s, and the heartbeat ticks the orderflow loop would run in seconds
+// without trades.
+func RecordSynthetic(cfg ingest.SyntheticConfig, durationMs int64) []Event {
+	g := ingest.NewGenerator(cfg)
+	start := g.Now()
+	nextDepth, nextOI := start, start
+	lastSec := int64(-1)
+	var price float64
+	var events []Event
+	var bids, asks []orderbook.PriceLevel
+
+	for {
+		t := g.Trade()
+		if t.Time > start+durationMs {
+			break
+		}
+		// Heartbeats: the loop wakes heartbeatLag after each second boundary
+		// that passed without a trade
+		if lastSec >= 0 {
+			for s := lastSec + 1; s*1000+heartbeatLagMs < t.Time; s++ {
+				events = append(events, Event{Kind: KindTick, Time: s*1000 + heartbeatLagMs})
+			}
+		}
+		for ; nextDepth <= t.Time; nextDepth += depthEveryMs {
+			bids, asks = g.Depth(bids[:0], asks[:0])
+			events = append(events, Event{Kind: KindDepth, Time: nextDepth, Bids: levelPairs(bids), Asks: levelPairs(asks)})
+		}
+		for ; nextOI <= t.Time; nextOI += oiEveryMs {
+			events = append(events, Event{Kind: KindOI, Time: nextOI, OI: g.OI(), Price: price})
+		}
+		events = append(events, Event{Kind: KindTrade, Time: t.Time, ID: t.ID, Price: t.Price,
+			Qty: t.Quantity, BuyerMaker: t.IsBuyer})
+		price, lastSec = t.Price, t.Time/1000
+	}
+	return events
+}
+
+func levelPairs(levels []orderbook.PriceLevel) [][2]float64 {
+	out := make([][2]float64, len(levels))
+	for i, l := range levels {
+		out[i] = [2]float64{l.Price, l.Quantity}
+	}
+	return out
+}
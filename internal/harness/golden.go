@@ -0,0 +1,94 @@
+package harness
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Golden format: one JSON line per frame,
+//
+//   {"time":…,"full":"<base64 MsgPack>","delta":"<base64>"}
+//
+// VerifyGolden reports the first frame whose bytes differ, with the byte
+// offset, so a change is traced to one snapshot field without a decoder.
+
+type goldenFrame struct {
+	Time  int64  `json:"time"`
+	Full  string `json:"full"`
+	Delta string `json:"delta,omitempty"`
+}
+
+// WriteGolden records frames as the expected output.
+func WriteGolden(w io.Writer, frames []Frame) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for i := range frames {
+		g := goldenFrame{
+			Time: frames[i].Snapshot.Time,
+			Full: base64.StdEncoding.EncodeToString(frames[i].Full),
+		}
+		if frames[i].Delta != nil {
+			g.Delta = base64.StdEncoding.EncodeToString(frames[i].Delta)
+		}
+		if err := enc.Encode(&g); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// VerifyGolden compares frames with a golden file: nil if identical.
+func VerifyGolden(r io.Reader, frames []Frame) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	i := 0
+	for ; sc.Scan(); i++ {
+		var g goldenFrame
+		if err := json.Unmarshal(sc.Bytes(), &g); err != nil {
+			return fmt.Errorf("golden line %d: %w", i+1, err)
+		}
+		if i >= len(frames) {
+			return fmt.Errorf("got %d frames, golden has more", len(frames))
+		}
+		f := &frames[i]
+		if f.Snapshot.Time != g.Time {
+			return fmt.Errorf("frame %d: time %d, want %d", i, f.Snapshot.Time, g.Time)
+		}
+		if err := compare("full", f.Full, g.Full); err != nil {
+			return fmt.Errorf("frame %d (time %d): %w", i, g.Time, err)
+		}
+		if err := compare("delta", f.Delta, g.Delta); err != nil {
+			return fmt.Errorf("frame %d (time %d): %w", i, g.Time, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if i != len(frames) {
+		return fmt.Errorf("got %d frames, golden has %d", len(frames), i)
+	}
+	return nil
+}
+
+func compare(name string, got []byte, wantB64 string) error {
+	want, err := base64.StdEncoding.DecodeString(wantB64)
+	if err != nil {
+		return fmt.Errorf("%s: bad base64: %w", name, err)
+	}
+	if bytes.Equal(got, want) {
+		return nil
+	}
+	n := min(len(got), len(want))
+	at := n
+	for j := 0; j < n; j++ {
+		if got[j] != want[j] {
+			at = j
+			break
+		}
+	}
+	return fmt.Errorf("%s frame differs at byte %d (got %d bytes, want %d)", name, at, len(got), len(want))
+}
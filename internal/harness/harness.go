@@ -0,0 +1,143 @@
+package harness
+
+import (
+	"fmt"
+
+	"market-indikator/internal/broadcast"
+	"market-indikator/internal/bus"
+	"market-indikator/internal/engine"
+	"market-indikator/internal/model"
+	oi "market-indikator/internal/oi"
+	"market-indikator/internal/orderbook"
+	"market-indikator/internal/state"
+)
+
+// =============================================================================
+// END-TO-END HARNESS — deterministic pipeline replay
+// =============================================================================
+//
+// Runs a recorded fixture through the live wiring on ONE goroutine:
+//
+//   trade  → bus → SPSC ring → engine.ProcessTrade ─┐
+//   tick   → engine.Tick (heartbeat)  ──────────────┤→ ring buffer → FrameEncoder
+//   depth  → orderbook.Book.UpdateDepthAt           │   (full + delta wire bytes)
+//   oi     → oi.Engine.UpdateAt                     │
+//
+// Every wall-clock read is replaced by the event's time (engine.SetClock,
+// UpdateDepthAt, UpdateAt), so the same fixture yields byte-identical frames
+// on every run and machine. A golden file (golden.go) pins the frame
+// sequence; a new analytics module that changes the wire output shows up as
+// the first differing frame and byte.
+//
+// Fixtures are JSON lines (fixture.go), recorded from the synthetic generator
+// or written by hand. orderflow replay records and verifies them.
+// =============================================================================
+
+// Options — pipeline configuration.
+type Options struct {
+	DeltaKeyframe int                  // broadcast.Options.DeltaKeyframe (0 = full frames only)
+	BufferSize    int                  // snapshot ring buffer capacity (0 = 3600)
+	Setup         func(*engine.Engine) // extra engine configuration before the first event
+}
+
+// Frame — one emitted snapshot and its wire encoding.
+type Frame struct {
+	Snapshot model.Snapshot
+	Full     []byte // MsgPack snapshot
+	Delta    []byte // delta frame (nil on keyframes / without delta mode)
+}
+
+// Pipeline — bus, engine, ring buffer and frame encoder wired as in
+// cmd/orderflow.
+type Pipeline struct {
+	Bus     *bus.Bus
+	Book    *orderbook.Book
+	OI      *oi.Engine
+	Engine  *engine.Engine
+	Buffer  *state.RingBuffer
+	Encoder *broadcast.FrameEncoder
+
+	ring   *bus.Ring
+	now    int64 // time of the event being fed, unix ms
+	levels [2][]orderbook.PriceLevel
+}
+
+func NewPipeline(opts Options) *Pipeline {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 3600
+	}
+	p := &Pipeline{
+		Bus:     bus.NewBus(),
+		Book:    orderbook.NewBook(),
+		OI:      oi.NewEngine(),
+		Buffer:  state.NewRingBuffer(opts.BufferSize),
+		Encoder: broadcast.NewFrameEncoder(opts.DeltaKeyframe),
+	}
+	p.Engine = engine.NewEngine(p.Book, p.OI)
+	p.Engine.SetClock(func() int64 { return p.now })
+	if opts.Setup != nil {
+		opts.Setup(p.Engine)
+	}
+	p.ring = p.Bus.SubscribeRing("engine", 16)
+	return p
+}
+
+// Feed applies one event; ok reports whether it emitted a frame.
+func (p *Pipeline) Feed(ev *Event) (f Frame, ok bool, err error) {
+	p.now = ev.Time
+	var snap model.Snapshot
+	switch ev.Kind {
+	case KindTrade:
+		p.Bus.Publish(ev.Trade())
+		t, ok := p.ring.Next()
+		if !ok {
+			return f, false, fmt.Errorf("bus closed")
+		}
+		snap = p.Engine.ProcessTrade(t)
+	case KindTick:
+		var rolled bool
+		if snap, rolled = p.Engine.Tick(ev.Time); !rolled {
+			return f, false, nil
+		}
+	case KindDepth:
+		p.levels[0] = appendLevels(p.levels[0][:0], ev.Bids)
+		p.levels[1] = appendLevels(p.levels[1][:0], ev.Asks)
+		// ErrResync has no stream to reconnect here; the Suspect flag it
+		// leaves on the book is what the replay reproduces.
+		_ = p.Book.UpdateDepthAt(p.levels[0], p.levels[1], ev.Time, ev.Time)
+		return f, false, nil
+	case KindOI:
+		p.OI.UpdateAt(ev.OI, ev.Price, ev.Time)
+		return f, false, nil
+	default:
+		return f, false, fmt.Errorf("unknown event kind %q", ev.Kind)
+	}
+
+	p.Buffer.Add(snap)
+	f.Snapshot, _ = p.Buffer.Latest()
+	f.Full, f.Delta = p.Encoder.Encode(&f.Snapshot)
+	return f, true, nil
+}
+
+// Run feeds every event through a new pipeline and returns the frames.
+func Run(events []Event, opts Options) ([]Frame, error) {
+	p := NewPipeline(opts)
+	var frames []Frame
+	for i := range events {
+		f, ok, err := p.Feed(&events[i])
+		if err != nil {
+			return frames, fmt.Errorf("event %d: %w", i, err)
+		}
+		if ok {
+			frames = append(frames, f)
+		}
+	}
+	return frames, nil
+}
+
+func appendLevels(dst []orderbook.PriceLevel, src [][2]float64) []orderbook.PriceLevel {
+	for _, l := range src {
+		dst = append(dst, orderbook.PriceLevel{Price: l[0], Quantity: l[1]})
+	}
+	return dst
+}
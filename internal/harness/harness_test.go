@@ -0,0 +1,80 @@
+package harness
+
+import (
+	"compress/gzip"
+	"flag"
+	"os"
+	"testing"
+)
+
+// testdata/fixture.jsonl.gz — one minute of the mixed synthetic market at
+// one trade per second, recorded with
+//
+//   orderflow replay -record fixture.jsonl -minutes 1 -rate 1
+//
+// testdata/golden.jsonl.gz — its frames; after an intended wire change:
+//
+//   go test ./internal/harness -run TestReplayGolden -update
+
+var update = flag.Bool("update", false, "rewrite testdata/golden.jsonl.gz")
+
+const (
+	fixturePath = "testdata/fixture.jsonl.gz"
+	goldenPath  = "testdata/golden.jsonl.gz"
+)
+
+func TestReplayGolden(t *testing.T) {
+	f, err := os.Open(fixturePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := ReadFixture(zr)
+	if err != nil {
+		t.Fatalf("%s: %v", fixturePath, err)
+	}
+
+	frames, err := Run(events, Options{DeltaKeyframe: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) == 0 {
+		t.Fatal("no frames")
+	}
+
+	if *update {
+		g, err := os.Create(goldenPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zw := gzip.NewWriter(g)
+		if err := WriteGolden(zw, frames); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Close(); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("%d frames written to %s", len(frames), goldenPath)
+		return
+	}
+
+	g, err := os.Open(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	zg, err := gzip.NewReader(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyGolden(zg, frames); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,193 @@
+package indicators
+
+// =============================================================================
+// CLASSIC INDICATORS — RSI, MACD, EMA ribbon on internal candles
+// =============================================================================
+//
+// Computed on the engine's own trade stream, per candle timeframe (1m, 5m,
+// 1h), so no second data pipeline is needed:
+//
+//   RSI(14)  — Wilder:  AG_t = (13·AG_{t-1} + gain_t) / 14   (same for loss)
+//              RSI = 100 − 100 / (1 + AG/AL); the first 14 changes seed AG/AL
+//              with a simple average.
+//   MACD(12, 26, 9) — MACD = EMA12 − EMA26, Signal = EMA9(MACD),
+//              Hist = MACD − Signal.
+//   EMA ribbon — EMAs of the closes for each configured period
+//              (default 8, 13, 21, 34, 55).
+//
+// State is committed on candle CLOSE; the values reported with each snapshot
+// are the live ones, i.e. what the indicators would be if the current candle
+// closed at the last price (the way charting platforms draw the last bar).
+//
+// A value is 0 until its warm-up is complete (14 changes for RSI, 26 closes
+// for MACD, `period` closes for a ribbon EMA).
+// =============================================================================
+
+const (
+	rsiPeriod  = 14
+	macdFast   = 12
+	macdSlow   = 26
+	macdSignal = 9
+)
+
+// EMA — exponential moving average seeded with its first input.
+type EMA struct {
+	Period int
+	Value  float64
+	N      int // inputs committed
+}
+
+func (e *EMA) alpha() float64 {
+	return 2 / float64(e.Period+1)
+}
+
+// Commit — folds in a closed value.
+func (e *EMA) Commit(x float64) {
+	if e.N == 0 {
+		e.Value = x
+	} else {
+		e.Value += e.alpha() * (x - e.Value)
+	}
+	e.N++
+}
+
+// Live — the EMA if x were committed now (state unchanged).
+func (e *EMA) Live(x float64) float64 {
+	if e.N == 0 {
+		return x
+	}
+	return e.Value + e.alpha()*(x-e.Value)
+}
+
+// Values — live indicator readings for one timeframe.
+type Values struct {
+	RSI    float64
+	MACD   float64
+	Signal float64
+	Hist   float64
+	Ribbon []float64 // aliases Set's scratch; copy before keeping
+}
+
+// Set — indicators on one candle timeframe. Owned by the engine goroutine.
+type Set struct {
+	Seconds   int64
+	Bucket    int64   // live candle's bucket start
+	Close     float64 // live candle's last price
+	PrevClose float64 // last committed close (0 = none)
+
+	AvgGain, AvgLoss float64
+	RSIChanges       int
+
+	Fast, Slow, Signal EMA
+	Ribbon             []EMA
+
+	scratch []float64
+}
+
+// NewSet — indicators on seconds-long candles with the given EMA ribbon.
+func NewSet(seconds int64, ribbon []int) *Set {
+	s := &Set{
+		Seconds: seconds,
+		Fast:    EMA{Period: macdFast},
+		Slow:    EMA{Period: macdSlow},
+		Signal:  EMA{Period: macdSignal},
+	}
+	for _, p := range ribbon {
+		s.Ribbon = append(s.Ribbon, EMA{Period: p})
+	}
+	return s
+}
+
+// Update — feeds a price at sec (unix seconds); a new bucket commits the
+// previous candle's close. O(ribbon).
+func (s *Set) Update(sec int64, price float64) {
+	bucket := sec / s.Seconds * s.Seconds
+	if bucket > s.Bucket {
+		if s.Bucket != 0 {
+			s.commit(s.Close)
+		}
+		s.Bucket = bucket
+	}
+	s.Close = price
+}
+
+func (s *Set) commit(close float64) {
+	if s.PrevClose != 0 {
+		gain, loss := changeParts(close - s.PrevClose)
+		if s.RSIChanges < rsiPeriod {
+			s.AvgGain += gain / rsiPeriod
+			s.AvgLoss += loss / rsiPeriod
+		} else {
+			s.AvgGain = (s.AvgGain*(rsiPeriod-1) + gain) / rsiPeriod
+			s.AvgLoss = (s.AvgLoss*(rsiPeriod-1) + loss) / rsiPeriod
+		}
+		s.RSIChanges++
+	}
+	s.PrevClose = close
+
+	s.Fast.Commit(close)
+	s.Slow.Commit(close)
+	s.Signal.Commit(s.Fast.Value - s.Slow.Value)
+	for i := range s.Ribbon {
+		s.Ribbon[i].Commit(close)
+	}
+}
+
+// Restore — loads saved state. A ribbon saved with different periods starts
+// cold; RSI and MACD are kept either way.
+func (s *Set) Restore(saved Set) {
+	ribbon := s.Ribbon
+	same := len(saved.Ribbon) == len(ribbon)
+	for i := 0; same && i < len(ribbon); i++ {
+		same = saved.Ribbon[i].Period == ribbon[i].Period
+	}
+	*s = saved
+	s.Ribbon = ribbon
+	if same {
+		copy(s.Ribbon, saved.Ribbon)
+	}
+}
+
+// Live — indicator values with the live candle treated as closed.
+func (s *Set) Live() Values {
+	var v Values
+	if s.Close == 0 {
+		return v
+	}
+
+	if s.RSIChanges >= rsiPeriod {
+		gain, loss := changeParts(s.Close - s.PrevClose)
+		ag := (s.AvgGain*(rsiPeriod-1) + gain) / rsiPeriod
+		al := (s.AvgLoss*(rsiPeriod-1) + loss) / rsiPeriod
+		if al == 0 {
+			v.RSI = 100
+		} else {
+			v.RSI = 100 - 100/(1+ag/al)
+		}
+	}
+
+	if s.Slow.N >= macdSlow {
+		v.MACD = s.Fast.Live(s.Close) - s.Slow.Live(s.Close)
+		v.Signal = s.Signal.Live(v.MACD)
+		v.Hist = v.MACD - v.Signal
+	}
+
+	if cap(s.scratch) < len(s.Ribbon) {
+		s.scratch = make([]float64, len(s.Ribbon))
+	}
+	v.Ribbon = s.scratch[:len(s.Ribbon)]
+	for i := range s.Ribbon {
+		v.Ribbon[i] = 0
+		if s.Ribbon[i].N >= s.Ribbon[i].Period {
+			v.Ribbon[i] = s.Ribbon[i].Live(s.Close)
+		}
+	}
+	return v
+}
+
+func changeParts(d float64) (gain, loss float64) {
+	if d > 0 {
+		return d, 0
+	}
+	return 0, -d
+}
@@ -0,0 +1,137 @@
+package indicators
+
+import (
+	"math"
+)
+
+// =============================================================================
+// SQUEEZE — Bollinger Bands inside Keltner Channels
+// =============================================================================
+//
+// On closed candles (5m, 15m):
+//
+//   Bollinger: SMA20 ± 2·σ20               (σ of the last 20 closes)
+//   Keltner:   EMA20 ± 1.5·ATR20           (ATR: Wilder-smoothed true range)
+//
+//   Squeeze ON  ⇔ BB_upper < KC_upper  and  BB_lower > KC_lower
+//
+// Volatility (σ) has contracted below the candles' typical range — energy is
+// being stored. The squeeze FIRES on the first close where it turns off;
+// breakout direction is the side of SMA20 that close is on.
+//
+//   Ratio = (BB_upper − BB_lower) / (KC_upper − KC_lower)   (< 1 = squeeze)
+//
+// TRADING INTERPRETATION:
+//   A fire in the direction of the pressure score (and HTF bias) is the
+//   classic compression-breakout entry; a fire against it is usually a fake.
+// =============================================================================
+
+const (
+	squeezeLen = 20
+	bbMult     = 2.0
+	kcMult     = 1.5
+)
+
+// Squeeze — squeeze detector on one candle timeframe. Owned by the engine
+// goroutine.
+type Squeeze struct {
+	Seconds int64
+	Bucket  int64
+	High    float64 // live candle
+	Low     float64
+	Close   float64
+
+	PrevClose float64
+	Closes    [squeezeLen]float64 // ring of committed closes
+	N         int                 // committed closes
+	ATR       float64
+	KCMid     EMA
+
+	On      bool
+	Bars    int     // consecutive closes in squeeze
+	Ratio   float64 // BB width / KC width of the last close
+	Dir     int     // direction of the last fire: +1 up, -1 down
+	FiredAt int64   // second (unix) in which the last fire was detected
+}
+
+// NewSqueeze — detector on seconds-long candles.
+func NewSqueeze(seconds int64) *Squeeze {
+	return &Squeeze{Seconds: seconds, KCMid: EMA{Period: squeezeLen}}
+}
+
+// Update — feeds a price at sec; a new bucket closes the previous candle.
+func (s *Squeeze) Update(sec int64, price float64) {
+	bucket := sec / s.Seconds * s.Seconds
+	if bucket > s.Bucket {
+		if s.Bucket != 0 {
+			s.commit(sec)
+		}
+		s.Bucket = bucket
+		s.High, s.Low = price, price
+	}
+	if price > s.High {
+		s.High = price
+	}
+	if price < s.Low {
+		s.Low = price
+	}
+	s.Close = price
+}
+
+// Fired reports whether the squeeze fired during second sec (the second
+// whose first trade or heartbeat closed the candle), so each fire is one
+// event.
+func (s *Squeeze) Fired(sec int64) bool {
+	return s.FiredAt != 0 && sec == s.FiredAt
+}
+
+// commit closes the live candle; now is the second that rolled it.
+func (s *Squeeze) commit(now int64) {
+	tr := s.High - s.Low
+	if s.PrevClose != 0 {
+		tr = math.Max(tr, math.Max(math.Abs(s.High-s.PrevClose), math.Abs(s.Low-s.PrevClose)))
+	}
+	if s.N < squeezeLen {
+		s.ATR += tr / squeezeLen
+	} else {
+		s.ATR = (s.ATR*(squeezeLen-1) + tr) / squeezeLen
+	}
+	s.PrevClose = s.Close
+	s.Closes[s.N%squeezeLen] = s.Close
+	s.N++
+	s.KCMid.Commit(s.Close)
+
+	if s.N < squeezeLen {
+		return
+	}
+	var sum, sq float64
+	for _, c := range s.Closes {
+		sum += c
+	}
+	sma := sum / squeezeLen
+	for _, c := range s.Closes {
+		sq += (c - sma) * (c - sma)
+	}
+	sd := math.Sqrt(sq / squeezeLen)
+
+	bbW := 2 * bbMult * sd
+	kcW := 2 * kcMult * s.ATR
+	on := kcW > 0 && sma+bbMult*sd < s.KCMid.Value+kcMult*s.ATR && sma-bbMult*sd > s.KCMid.Value-kcMult*s.ATR
+	if kcW > 0 {
+		s.Ratio = bbW / kcW
+	}
+
+	if s.On && !on {
+		s.Dir = 1
+		if s.Close < sma {
+			s.Dir = -1
+		}
+		s.FiredAt = now
+	}
+	s.On = on
+	if on {
+		s.Bars++
+	} else {
+		s.Bars = 0
+	}
+}
@@ -0,0 +1,144 @@
+package ingest
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"market-indikator/internal/binance"
+	"market-indikator/internal/orderbook"
+
+	"github.com/gorilla/websocket"
+)
+
+// bookTicker is quiet while the touch does not move: on a thin symbol a
+// minute without a frame is normal, so only a longer silence means dead.
+const bookTickerIdle = 2 * time.Minute
+
+// bookTickerEvent matches the bookTicker stream payload (documentation only:
+// parseBookTicker decodes it in place).
+// Example: {"e":"bookTicker","u":400900217,"E":1568014460893,"T":1568014460891,"s":"BTCUSDT","b":"25.35190000","B":"31.21000000","a":"25.36520000","A":"40.66000000"}
+type bookTickerEvent struct {
+	EventType string `json:"e"` // Event type (always "bookTicker")
+	U         int64  `json:"u"` // Order book update ID
+	E         int64  `json:"E"` // Event time
+	T         int64  `json:"T"` // Transaction time
+	Symbol    string `json:"s"` // Symbol
+	Bid       string `json:"b"` // Best bid price
+	BidQty    string `json:"B"` // Best bid qty
+	Ask       string `json:"a"` // Best ask price
+	AskQty    string `json:"A"` // Best ask qty
+}
+
+// bookTicker — the decoded fields of a bookTickerEvent.
+type bookTicker struct {
+	updateID    int64
+	bid, bidQty float64
+	ask, askQty float64
+}
+
+// apply feeds the quote to the tracker.
+func (q *bookTicker) apply(l1 *orderbook.L1Tracker) {
+	l1.Update(q.bid, q.bidQty, q.ask, q.askQty, q.updateID)
+}
+
+// BookTickerIngester streams <symbol>@bookTicker into an L1Tracker on its
+// own socket (-combined-stream=false; MarketStream.SetBookTicker otherwise).
+type BookTickerIngester struct {
+	l1     *orderbook.L1Tracker
+	symbol string
+
+	reconnects int64 // atomic
+}
+
+func NewBookTickerIngester(l1 *orderbook.L1Tracker, symbol string) *BookTickerIngester {
+	return &BookTickerIngester{l1: l1, symbol: symbol}
+}
+
+func (k *BookTickerIngester) Start(ctx context.Context) {
+	go k.loop(ctx)
+}
+
+// Reconnects returns how many times the bookTicker stream has dropped and redialed.
+func (k *BookTickerIngester) Reconnects() int64 {
+	return atomic.LoadInt64(&k.reconnects)
+}
+
+func (k *BookTickerIngester) loop(ctx context.Context) {
+	delay := reconnectDelay
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		start := time.Now()
+		err := k.connectAndConsume(ctx)
+		if err != nil {
+			atomic.AddInt64(&k.reconnects, 1)
+			var wait time.Duration
+			wait, delay = redialDelay(start, delay, reconnectDelay, maxReconnectDelay)
+			log.Printf("BookTicker ingest error: %v. Reconnecting in %v...", err, wait)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		} else {
+			delay = reconnectDelay
+		}
+	}
+}
+
+func (k *BookTickerIngester) connectAndConsume(ctx context.Context) error {
+	url := binance.WSStream(strings.ToLower(k.symbol) + "@bookTicker")
+	c, _, err := binance.Dialer().Dial(url, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Connected to Binance BookTicker Stream (%s)", k.symbol)
+	keepAlive(c, "BookTicker stream", bookTickerIdle)
+
+	// Rotation: every frame is the full quote and the tracker drops update
+	// ids it has seen, so the replacement simply takes over (as for depth).
+	rotateAt := time.Now().Add(connMaxAge)
+	var next <-chan *websocket.Conn
+	defer func() { c.Close(); discardDial(next) }()
+
+	buf := make([]byte, 0, 256)
+	var q bookTicker
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case nc := <-next:
+			next, rotateAt = nil, time.Now().Add(rotateRetry)
+			if nc != nil {
+				c.Close()
+				c, rotateAt = nc, time.Now().Add(connMaxAge)
+				log.Printf("BookTicker stream: rotated connection")
+			}
+		default:
+		}
+		if next == nil && time.Now().After(rotateAt) {
+			next = dialAsync(url, "BookTicker stream", bookTickerIdle)
+		}
+
+		buf, err = readFrame(c, buf)
+		if err != nil {
+			return err
+		}
+		touch(c, bookTickerIdle)
+
+		if err := parseBookTicker(buf, &q); err != nil {
+			return err
+		}
+		q.apply(k.l1)
+	}
+}
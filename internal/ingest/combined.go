@@ -0,0 +1,246 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"market-indikator/internal/binance"
+	"market-indikator/internal/bus"
+	"market-indikator/internal/model"
+	"market-indikator/internal/orderbook"
+	"market-indikator/internal/positioning"
+
+	"github.com/gorilla/websocket"
+)
+
+// =============================================================================
+// COMBINED MARKET STREAM — trades and depth on one socket
+// =============================================================================
+//
+// Binance's combined endpoint multiplexes several streams over one
+// connection:
+//
+//   wss://fstream.binance.com/stream?streams=btcusdt@aggTrade/btcusdt@depth20@100ms
+//
+// Every frame is wrapped as {"stream":"<name>","data":<payload>}; the
+// envelope is split in place (parseCombined) and the payload dispatched by
+// name to the same decoders the single-stream ingesters use. One socket per
+// symbol instead of two, and one reconnect loop: trades and depth always
+// drop and recover together, so the book is never fresh while trades are
+// stale or the other way round.
+//
+// Liveness: depth arrives every 100-500ms, so the idle deadline is depthIdle;
+// a connection still delivering depth but no trades for tradeIdleTimeout is
+// treated as dead too.
+//
+// Only the streams the engine consumes are subscribed; another is one more
+// name in the URL plus a case in read — as @bookTicker is, with
+// SetBookTicker, and @markPrice@1s + @forceOrder with SetDerivs.
+// =============================================================================
+
+// MarketStream replaces Ingester + DepthIngester for one symbol.
+type MarketStream struct {
+	book   *orderbook.Book
+	symbol string
+	seq    tradeSeq
+
+	tradeStream  string // e.g. "btcusdt@aggTrade"
+	depthStream  string // e.g. "btcusdt@depth20@100ms" (see DepthStream)
+	tickerStream string // e.g. "btcusdt@bookTicker" ("" = not subscribed)
+	l1           *orderbook.L1Tracker
+	markStream   string // e.g. "btcusdt@markPrice@1s" ("" = not subscribed)
+	liqStream    string // e.g. "btcusdt@forceOrder"
+	derivs       *positioning.Feed
+
+	// Ingest goroutine only — reused across messages (see parse.go)
+	buf        []byte
+	bids, asks []orderbook.PriceLevel
+	trade      model.Trade
+	quote      bookTicker
+	mark       markPrice
+	liq        forceOrder
+	lastTrade  time.Time
+
+	reconnects int64 // atomic — connection attempts after the first
+}
+
+func NewMarketStream(b *bus.Bus, book *orderbook.Book, symbol string) *MarketStream {
+	s := strings.ToLower(symbol)
+	return &MarketStream{
+		book:        book,
+		symbol:      symbol,
+		seq:         tradeSeq{bus: b},
+		tradeStream: s + "@aggTrade",
+		depthStream: s + DefaultDepthStream.suffix(),
+		buf:         make([]byte, 0, 4096),
+		bids:        make([]orderbook.PriceLevel, 0, orderbook.MaxDepthLevels),
+		asks:        make([]orderbook.PriceLevel, 0, orderbook.MaxDepthLevels),
+	}
+}
+
+func (m *MarketStream) Start(ctx context.Context) {
+	go m.loop(ctx)
+}
+
+// SetDepthStream selects the depth stream (default DefaultDepthStream). Must
+// be called before Start.
+func (m *MarketStream) SetDepthStream(ds DepthStream) {
+	m.depthStream = strings.ToLower(m.symbol) + ds.suffix()
+}
+
+// SetBookTicker also subscribes @bookTicker and feeds it to l1. Must be
+// called before Start.
+func (m *MarketStream) SetBookTicker(l1 *orderbook.L1Tracker) {
+	m.l1 = l1
+	m.tickerStream = strings.ToLower(m.symbol) + "@bookTicker"
+}
+
+// SetDerivs also subscribes @markPrice@1s and @forceOrder and feeds them
+// to feed. Must be called before Start.
+func (m *MarketStream) SetDerivs(feed *positioning.Feed) {
+	m.derivs = feed
+	m.markStream, m.liqStream = derivsStreams(m.symbol)
+}
+
+// Reconnects returns how many times the combined stream has dropped and redialed.
+func (m *MarketStream) Reconnects() int64 {
+	return atomic.LoadInt64(&m.reconnects)
+}
+
+func (m *MarketStream) loop(ctx context.Context) {
+	delay := reconnectDelay
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		start := time.Now()
+		err := m.connectAndConsume(ctx)
+		if err != nil {
+			atomic.AddInt64(&m.reconnects, 1)
+			var wait time.Duration
+			wait, delay = redialDelay(start, delay, reconnectDelay, maxReconnectDelay)
+			log.Printf("Market stream error (%s): %v. Reconnecting in %v...", m.symbol, err, wait)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		} else {
+			delay = reconnectDelay
+		}
+	}
+}
+
+func (m *MarketStream) connectAndConsume(ctx context.Context) error {
+	streams := []string{m.tradeStream, m.depthStream}
+	if m.l1 != nil {
+		streams = append(streams, m.tickerStream)
+	}
+	if m.derivs != nil {
+		streams = append(streams, m.markStream, m.liqStream)
+	}
+	url := binance.WSCombined(streams...)
+	c, _, err := binance.Dialer().Dial(url, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Connected to Binance combined stream (%s: aggTrade + depth)", m.symbol)
+	keepAlive(c, "Market stream", depthIdle)
+	m.lastTrade = time.Now()
+
+	rotateAt := time.Now().Add(connMaxAge)
+	var next <-chan *websocket.Conn
+	defer func() { c.Close(); discardDial(next) }() // c changes on rotation
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case nc := <-next: // nil channel (never ready) outside a rotation
+			next, rotateAt = nil, time.Now().Add(rotateRetry)
+			if nc != nil {
+				if c = handover("Market stream", c, nc, &m.seq, m.read); c == nc {
+					rotateAt = time.Now().Add(connMaxAge)
+				}
+			}
+		default:
+		}
+		if next == nil && time.Now().After(rotateAt) {
+			next = dialAsync(url, "Market stream", depthIdle)
+		}
+
+		trade, ok, err := m.read(c, true)
+		if err != nil {
+			return err
+		}
+		if ok {
+			m.seq.publish(&trade)
+		}
+		if time.Since(m.lastTrade) > tradeIdleTimeout {
+			return fmt.Errorf("no trades for %v", tradeIdleTimeout)
+		}
+	}
+}
+
+// read reads one combined frame (frameReader): the trade, if it was one;
+// depth goes to the book when applyDepth.
+func (m *MarketStream) read(c *websocket.Conn, applyDepth bool) (model.Trade, bool, error) {
+	var err error
+	if m.buf, err = readFrame(c, m.buf); err != nil {
+		return model.Trade{}, false, err
+	}
+	recv := time.Now()
+	touch(c, depthIdle)
+
+	stream, data, err := parseCombined(m.buf)
+	if err != nil {
+		return model.Trade{}, false, err
+	}
+	switch string(stream) {
+	case m.tradeStream:
+		if err := parseAggTrade(data, &m.trade); err != nil {
+			return model.Trade{}, false, err
+		}
+		m.trade.RecvTime = recv.UnixNano()
+		m.lastTrade = recv
+		return m.trade, true, nil
+	case m.depthStream:
+		if !applyDepth {
+			break
+		}
+		var eventMs int64
+		if m.bids, m.asks, eventMs, err = parseDepth(data, m.bids[:0], m.asks[:0]); err != nil {
+			return model.Trade{}, false, err
+		}
+		if err := m.book.UpdateDepth(m.bids, m.asks, eventMs); err != nil {
+			return model.Trade{}, false, err
+		}
+	case m.tickerStream:
+		// Both connections during a handover: the tracker drops seen ids
+		if err := parseBookTicker(data, &m.quote); err != nil {
+			return model.Trade{}, false, err
+		}
+		m.quote.apply(m.l1)
+	case m.markStream:
+		if err := parseMarkPrice(data, &m.mark); err != nil {
+			return model.Trade{}, false, err
+		}
+		m.derivs.UpdateMark(m.mark.mark, m.mark.index, m.mark.funding, m.mark.eventMs)
+	case m.liqStream:
+		// Both connections during a handover: the feed drops counted times
+		if err := parseForceOrder(data, &m.liq); err != nil {
+			return model.Trade{}, false, err
+		}
+		m.liq.apply(m.derivs)
+	}
+	return model.Trade{}, false, nil
+}
@@ -2,43 +2,116 @@ package ingest
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"market-indikator/internal/binance"
 	"market-indikator/internal/orderbook"
 
 	"github.com/gorilla/websocket"
 )
 
 const (
-	// Partial book depth stream: top 20 levels, 100ms updates
-	// This gives us a full snapshot every 100ms — no need for diff management.
-	depthWSURL      = "wss://fstream.binance.com/ws/btcusdt@depth20@100ms"
-	depthReconnect  = 1 * time.Second
-	depthMaxReconn  = 30 * time.Second
+	depthReconnect = 1 * time.Second
+	depthMaxReconn = 30 * time.Second
+	depthIdle      = 10 * time.Second // ≤500ms stream — 10s of silence means it's dead
 )
 
-// depthEvent matches Binance partial depth stream JSON.
-// Example: {"lastUpdateId":123456,"E":1672515782136,"T":1672515782100,"bids":[["16850.00","1.5"],...],"asks":[["16851.00","0.8"],...]}
-type depthEvent struct {
-	Bids [][]string `json:"bids"`
-	Asks [][]string `json:"asks"`
+// DepthStream — which partial book depth stream to subscribe: the top Levels
+// (5, 10 or 20) every Speed (100ms, 250ms or 500ms). Every frame is a full
+// snapshot of those levels — no diff management. depth20@100ms is the
+// densest and costs the most bandwidth and parsing; a thin alt book is
+// usually served as well by depth5 or depth10 at 250ms/500ms.
+type DepthStream struct {
+	Levels int
+	Speed  time.Duration
 }
 
+// DefaultDepthStream — depth20@100ms.
+var DefaultDepthStream = DepthStream{Levels: 20, Speed: 100 * time.Millisecond}
+
+// ParseDepthStream parses "depth<levels>[@<speed>]", e.g. "depth10@500ms"
+// (speed defaults to 250ms, as on Binance).
+func ParseDepthStream(s string) (DepthStream, error) {
+	ds := DepthStream{Speed: 250 * time.Millisecond}
+	name, speed, hasSpeed := strings.Cut(strings.TrimPrefix(s, "depth"), "@")
+	n, err := strconv.Atoi(name)
+	if err != nil || !strings.HasPrefix(s, "depth") {
+		return ds, fmt.Errorf("depth stream %q: want depth<levels>[@<speed>], e.g. depth20@100ms", s)
+	}
+	ds.Levels = n
+	if hasSpeed {
+		if ds.Speed, err = time.ParseDuration(speed); err != nil {
+			return ds, fmt.Errorf("depth stream %q: %v", s, err)
+		}
+	}
+	return ds, ds.Validate()
+}
+
+// Validate — a stream Binance offers.
+func (ds DepthStream) Validate() error {
+	switch ds.Levels {
+	case 5, 10, 20:
+	default:
+		return fmt.Errorf("depth levels %d: want 5, 10 or 20", ds.Levels)
+	}
+	switch ds.Speed {
+	case 100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond:
+	default:
+		return fmt.Errorf("depth speed %v: want 100ms, 250ms or 500ms", ds.Speed)
+	}
+	return nil
+}
+
+// suffix — the stream name after the symbol, e.g. "@depth20@100ms" (250ms is
+// Binance's default and has no suffix).
+func (ds DepthStream) suffix() string {
+	s := "@depth" + strconv.Itoa(ds.Levels)
+	if ds.Speed != 250*time.Millisecond {
+		s += "@" + strconv.Itoa(int(ds.Speed.Milliseconds())) + "ms"
+	}
+	return s
+}
+
+func (ds DepthStream) String() string {
+	return fmt.Sprintf("depth%d@%dms", ds.Levels, ds.Speed.Milliseconds())
+}
+
+// Partial depth stream payload, decoded in place by parseDepth (parse.go).
+// Futures example: {"e":"depthUpdate","E":1672515782136,"T":1672515782100,"s":"BTCUSDT","U":…,"u":…,"pu":…,"b":[["16850.00","1.5"],...],"a":[["16851.00","0.8"],...]}
+
 // DepthIngester connects to Binance depth stream and updates the orderbook.
 type DepthIngester struct {
-	book *orderbook.Book
+	book   *orderbook.Book
+	symbol string
+	stream DepthStream
+
+	reconnects int64 // atomic
+}
+
+func NewDepthIngester(book *orderbook.Book, symbol string) *DepthIngester {
+	return &DepthIngester{book: book, symbol: symbol, stream: DefaultDepthStream}
 }
 
-func NewDepthIngester(book *orderbook.Book) *DepthIngester {
-	return &DepthIngester{book: book}
+// SetStream selects the depth stream (default DefaultDepthStream). Must be
+// called before Start.
+func (d *DepthIngester) SetStream(ds DepthStream) {
+	d.stream = ds
 }
 
 func (d *DepthIngester) Start(ctx context.Context) {
 	go d.loop(ctx)
 }
 
+// Reconnects returns how many times the depth stream has dropped and redialed.
+func (d *DepthIngester) Reconnects() int64 {
+	return atomic.LoadInt64(&d.reconnects)
+}
+
 func (d *DepthIngester) loop(ctx context.Context) {
 	delay := depthReconnect
 
@@ -49,17 +122,17 @@ func (d *DepthIngester) loop(ctx context.Context) {
 		default:
 		}
 
+		start := time.Now()
 		err := d.connectAndConsume(ctx)
 		if err != nil {
-			log.Printf("Depth ingest error: %v. Reconnecting in %v...", err, delay)
+			atomic.AddInt64(&d.reconnects, 1)
+			var wait time.Duration
+			wait, delay = redialDelay(start, delay, depthReconnect, depthMaxReconn)
+			log.Printf("Depth ingest error: %v. Reconnecting in %v...", err, wait)
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(delay):
-			}
-			delay *= 2
-			if delay > depthMaxReconn {
-				delay = depthMaxReconn
+			case <-time.After(wait):
 			}
 		} else {
 			delay = depthReconnect
@@ -68,59 +141,61 @@ func (d *DepthIngester) loop(ctx context.Context) {
 }
 
 func (d *DepthIngester) connectAndConsume(ctx context.Context) error {
-	c, _, err := websocket.DefaultDialer.Dial(depthWSURL, nil)
+	url := binance.WSStream(strings.ToLower(d.symbol) + d.stream.suffix())
+	c, _, err := binance.Dialer().Dial(url, nil)
 	if err != nil {
 		return err
 	}
-	defer c.Close()
 
-	log.Println("Connected to Binance Depth Stream")
+	log.Printf("Connected to Binance Depth Stream (%s, %s)", d.symbol, d.stream)
+	keepAlive(c, "Depth stream", depthIdle)
+
+	// Rotation before Binance's 24h close: every frame is a full snapshot,
+	// so the replacement simply takes over (see ws.go).
+	rotateAt := time.Now().Add(connMaxAge)
+	var next <-chan *websocket.Conn
+	defer func() { c.Close(); discardDial(next) }()
 
 	// Pre-allocate parsing buffers to avoid per-message allocations.
 	// These slices are reused across messages.
 	bids := make([]orderbook.PriceLevel, 0, orderbook.MaxDepthLevels)
 	asks := make([]orderbook.PriceLevel, 0, orderbook.MaxDepthLevels)
-	var event depthEvent
+	buf := make([]byte, 0, 4096)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case nc := <-next:
+			next, rotateAt = nil, time.Now().Add(rotateRetry)
+			if nc != nil {
+				c.Close()
+				c, rotateAt = nc, time.Now().Add(connMaxAge)
+				log.Printf("Depth stream: rotated connection")
+			}
 		default:
 		}
+		if next == nil && time.Now().After(rotateAt) {
+			next = dialAsync(url, "Depth stream", depthIdle)
+		}
 
-		err := c.ReadJSON(&event)
+		buf, err = readFrame(c, buf)
 		if err != nil {
 			return err
 		}
+		touch(c, depthIdle)
 
-		// Parse string pairs into PriceLevel structs.
-		// Reuse slices to minimize allocations.
-		bids = bids[:0]
-		for _, lvl := range event.Bids {
-			if len(lvl) < 2 {
-				continue
-			}
-			price, _ := strconv.ParseFloat(lvl[0], 64)
-			qty, _ := strconv.ParseFloat(lvl[1], 64)
-			if qty > 0 {
-				bids = append(bids, orderbook.PriceLevel{Price: price, Quantity: qty})
-			}
-		}
-
-		asks = asks[:0]
-		for _, lvl := range event.Asks {
-			if len(lvl) < 2 {
-				continue
-			}
-			price, _ := strconv.ParseFloat(lvl[0], 64)
-			qty, _ := strconv.ParseFloat(lvl[1], 64)
-			if qty > 0 {
-				asks = append(asks, orderbook.PriceLevel{Price: price, Quantity: qty})
-			}
+		// Decode string pairs straight into the reused PriceLevel slices.
+		var eventMs int64
+		bids, asks, eventMs, err = parseDepth(buf, bids[:0], asks[:0])
+		if err != nil {
+			return err
 		}
 
 		// Update book — this computes all pressure metrics and publishes atomically.
-		d.book.UpdateDepth(bids, asks)
+		// ErrResync (suspect frames in a row) reconnects for a fresh snapshot.
+		if err := d.book.UpdateDepth(bids, asks, eventMs); err != nil {
+			return err
+		}
 	}
 }
@@ -0,0 +1,191 @@
+package ingest
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"market-indikator/internal/binance"
+	"market-indikator/internal/positioning"
+
+	"github.com/gorilla/websocket"
+)
+
+// markPrice arrives every second; a longer silence means dead.
+const derivsIdle = 30 * time.Second
+
+// markPriceEvent matches the markPrice@1s stream payload (documentation
+// only: parseMarkPrice decodes it in place).
+// Example: {"e":"markPriceUpdate","E":1562305380000,"s":"BTCUSDT","p":"11794.15000000","i":"11784.62659091","P":"11784.25641265","r":"0.00038167","T":1562306400000}
+type markPriceEvent struct {
+	EventType   string `json:"e"` // Event type (always "markPriceUpdate")
+	E           int64  `json:"E"` // Event time
+	Symbol      string `json:"s"` // Symbol
+	Mark        string `json:"p"` // Mark price
+	Index       string `json:"i"` // Index price
+	Settle      string `json:"P"` // Estimated settle price
+	Funding     string `json:"r"` // Funding rate
+	NextFunding int64  `json:"T"` // Next funding time
+}
+
+// forceOrderEvent matches the forceOrder stream payload (documentation only:
+// parseForceOrder decodes it in place). Binance pushes at most the latest
+// liquidation per second per symbol.
+// Example: {"e":"forceOrder","E":1568014460893,"o":{"s":"BTCUSDT","S":"SELL","o":"LIMIT","f":"IOC","q":"0.014","p":"9910","ap":"9910","X":"FILLED","l":"0.014","z":"0.014","T":1568014460893}}
+type forceOrderEvent struct {
+	EventType string `json:"e"` // Event type (always "forceOrder")
+	E         int64  `json:"E"` // Event time
+	Order     struct {
+		Symbol   string `json:"s"`  // Symbol
+		Side     string `json:"S"`  // SELL = a long liquidated
+		Price    string `json:"p"`  // Order price
+		AvgPrice string `json:"ap"` // Average fill price
+		Filled   string `json:"z"`  // Accumulated filled qty
+		T        int64  `json:"T"`  // Trade time
+	} `json:"o"`
+}
+
+// markPrice — the decoded fields of a markPriceEvent.
+type markPrice struct {
+	eventMs              int64
+	mark, index, funding float64
+}
+
+// forceOrder — the decoded fields of a forceOrderEvent.
+type forceOrder struct {
+	sell                    bool
+	price, avgPrice, filled float64
+	timeMs                  int64
+}
+
+// apply feeds the liquidation's filled notional to the feed.
+func (l *forceOrder) apply(f *positioning.Feed) {
+	price := l.avgPrice
+	if price <= 0 {
+		price = l.price
+	}
+	f.AddLiquidation(l.sell, l.filled*price, l.timeMs)
+}
+
+// derivsStreams — the stream names of symbol's derivatives feed.
+func derivsStreams(symbol string) (mark, liq string) {
+	s := strings.ToLower(symbol)
+	return s + "@markPrice@1s", s + "@forceOrder"
+}
+
+// DerivsIngester streams <symbol>@markPrice@1s (funding, mark and index →
+// basis) and <symbol>@forceOrder (liquidations) into a positioning.Feed on
+// its own combined socket (-combined-stream=false; MarketStream.SetDerivs
+// otherwise).
+type DerivsIngester struct {
+	feed   *positioning.Feed
+	symbol string
+
+	reconnects int64 // atomic
+}
+
+func NewDerivsIngester(feed *positioning.Feed, symbol string) *DerivsIngester {
+	return &DerivsIngester{feed: feed, symbol: symbol}
+}
+
+func (k *DerivsIngester) Start(ctx context.Context) {
+	go k.loop(ctx)
+}
+
+// Reconnects returns how many times the derivatives stream has dropped and redialed.
+func (k *DerivsIngester) Reconnects() int64 {
+	return atomic.LoadInt64(&k.reconnects)
+}
+
+func (k *DerivsIngester) loop(ctx context.Context) {
+	delay := reconnectDelay
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		start := time.Now()
+		err := k.connectAndConsume(ctx)
+		if err != nil {
+			atomic.AddInt64(&k.reconnects, 1)
+			var wait time.Duration
+			wait, delay = redialDelay(start, delay, reconnectDelay, maxReconnectDelay)
+			log.Printf("Derivatives ingest error: %v. Reconnecting in %v...", err, wait)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		} else {
+			delay = reconnectDelay
+		}
+	}
+}
+
+func (k *DerivsIngester) connectAndConsume(ctx context.Context) error {
+	markStream, liqStream := derivsStreams(k.symbol)
+	url := binance.WSCombined(markStream, liqStream)
+	c, _, err := binance.Dialer().Dial(url, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Connected to Binance derivatives stream (%s: markPrice + forceOrder)", k.symbol)
+	keepAlive(c, "Derivatives stream", derivsIdle)
+
+	// Rotation: markPrice is the full state and the feed drops liquidations
+	// it has counted, so the replacement simply takes over (as for bookTicker).
+	rotateAt := time.Now().Add(connMaxAge)
+	var next <-chan *websocket.Conn
+	defer func() { c.Close(); discardDial(next) }()
+
+	buf := make([]byte, 0, 512)
+	var m markPrice
+	var l forceOrder
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case nc := <-next:
+			next, rotateAt = nil, time.Now().Add(rotateRetry)
+			if nc != nil {
+				c.Close()
+				c, rotateAt = nc, time.Now().Add(connMaxAge)
+				log.Printf("Derivatives stream: rotated connection")
+			}
+		default:
+		}
+		if next == nil && time.Now().After(rotateAt) {
+			next = dialAsync(url, "Derivatives stream", derivsIdle)
+		}
+
+		buf, err = readFrame(c, buf)
+		if err != nil {
+			return err
+		}
+		touch(c, derivsIdle)
+
+		stream, data, err := parseCombined(buf)
+		if err != nil {
+			return err
+		}
+		switch string(stream) {
+		case markStream:
+			if err := parseMarkPrice(data, &m); err != nil {
+				return err
+			}
+			k.feed.UpdateMark(m.mark, m.index, m.funding, m.eventMs)
+		case liqStream:
+			if err := parseForceOrder(data, &l); err != nil {
+				return err
+			}
+			l.apply(k.feed)
+		}
+	}
+}
@@ -2,20 +2,23 @@ package ingest
 
 import (
 	"context"
-	"encoding/json"
-	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"market-indikator/internal/binance"
 	oi "market-indikator/internal/oi"
 )
 
 const (
-	// Binance Futures Open Interest endpoint.
-	// Poll every 3 seconds — well within 1200 req/min rate limit.
-	oiURL      = "https://fapi.binance.com/fapi/v1/openInterest?symbol=BTCUSDT"
+	// Binance Futures Open Interest endpoint (weight 1).
+	// Poll every 3 seconds — 20 of the 1200 weight/min budget shared through
+	// binance.Client.
+	oiPath     = "/fapi/v1/openInterest"
 	oiInterval = 3 * time.Second
 )
 
@@ -28,26 +31,42 @@ type oiResponse struct {
 // Runs entirely OFF the hot path in its own goroutine.
 type OIPoller struct {
 	engine   *oi.Engine
+	symbol   string
 	priceFn  func() float64 // returns latest price (lock-free read)
-	client   *http.Client
+	client   *binance.Client
+	agg      *oi.Aggregator // nil = Binance OI only
+
+	errors int64 // atomic — failed polls
 }
 
 // NewOIPoller creates a poller.
 // priceFn should be a closure that returns the latest trade price.
-func NewOIPoller(engine *oi.Engine, priceFn func() float64) *OIPoller {
+func NewOIPoller(engine *oi.Engine, symbol string, priceFn func() float64) *OIPoller {
+	client := binance.NewClient(binance.Current().REST, "", "")
+	client.SetTimeout(2 * time.Second) // Never block beyond 2s (request or rate-limit queue)
 	return &OIPoller{
 		engine:  engine,
+		symbol:  symbol,
 		priceFn: priceFn,
-		client: &http.Client{
-			Timeout: 2 * time.Second, // Never block beyond 2s
-		},
+		client:  client,
 	}
 }
 
+// SetAggregator routes the polls through a multi-venue aggregator, which
+// publishes the summed OI to its engine. Must be called before Start.
+func (p *OIPoller) SetAggregator(agg *oi.Aggregator) {
+	p.agg = agg
+}
+
 func (p *OIPoller) Start(ctx context.Context) {
 	go p.loop(ctx)
 }
 
+// Errors returns the number of failed polls since start.
+func (p *OIPoller) Errors() int64 {
+	return atomic.LoadInt64(&p.errors)
+}
+
 func (p *OIPoller) loop(ctx context.Context) {
 	// Initial poll
 	p.poll()
@@ -66,28 +85,18 @@ func (p *OIPoller) loop(ctx context.Context) {
 }
 
 func (p *OIPoller) poll() {
-	resp, err := p.client.Get(oiURL)
-	if err != nil {
-		log.Printf("OI poll error: %v", err)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("OI poll HTTP %d: %s", resp.StatusCode, string(body))
-		return
-	}
-
 	var data oiResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		log.Printf("OI decode error: %v", err)
+	q := url.Values{"symbol": {strings.ToUpper(p.symbol)}}
+	if err := p.client.Do(http.MethodGet, oiPath, q, false, &data); err != nil {
+		log.Printf("OI poll error: %v", err)
+		atomic.AddInt64(&p.errors, 1)
 		return
 	}
 
 	oiVal, err := strconv.ParseFloat(data.OpenInterest, 64)
 	if err != nil {
 		log.Printf("OI parse error: %v", err)
+		atomic.AddInt64(&p.errors, 1)
 		return
 	}
 
@@ -95,6 +104,10 @@ func (p *OIPoller) poll() {
 	currentPrice := p.priceFn()
 
 	// Update OI engine — computes deltas and behavior classification
-	p.engine.Update(oiVal, currentPrice)
+	if p.agg != nil {
+		p.agg.Update(oi.Binance, oiVal, currentPrice)
+	} else {
+		p.engine.Update(oiVal, currentPrice)
+	}
 	log.Printf("OI updated: %.2f contracts (price: $%.2f)", oiVal, currentPrice)
 }
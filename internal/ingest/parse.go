@@ -0,0 +1,554 @@
+package ingest
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/orderbook"
+
+	"github.com/gorilla/websocket"
+)
+
+// =============================================================================
+// ZERO-ALLOCATION STREAM PARSING — aggTrade, depth, bookTicker, markPrice,
+// forceOrder, combined envelope
+// =============================================================================
+//
+// ReadJSON decodes through reflection, and depth's [][]string allocates one
+// string per price and quantity: 80 strings per 100ms update, far more during
+// a cascade, all of it garbage the engine pays for in GC pauses.
+//
+// Instead each frame is read into a buffer reused across messages and
+// scanned in place:
+//
+//   - only the fields the engine uses are decoded; everything else is
+//     skipped without materializing it
+//   - decimal strings ("16850.10") are converted with the exact fast path
+//     (mantissa < 2^53, |exp10| ≤ 22 → one float division, correctly
+//     rounded, as strconv would return); anything else falls back to
+//     strconv.ParseFloat
+//   - depth levels are appended straight into the caller's pre-allocated
+//     []orderbook.PriceLevel
+//
+// Parsing allocates nothing; what remains per message is gorilla's own
+// 8-byte reader from NextReader.
+//
+// The scanner assumes Binance's payloads: keys are plain ASCII without
+// escapes. Malformed JSON is an error, and the caller reconnects as it did
+// on a ReadJSON failure.
+// =============================================================================
+
+var errSyntax = errors.New("malformed stream JSON")
+
+// pow10 — exactly representable powers of ten for the float fast path.
+var pow10 = [...]float64{1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10,
+	1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19, 1e20, 1e21, 1e22}
+
+// readFrame reads the next data message into buf[:0], growing it only when
+// a message is larger than any seen before.
+func readFrame(c *websocket.Conn, buf []byte) ([]byte, error) {
+	_, r, err := c.NextReader()
+	if err != nil {
+		return buf, err
+	}
+	buf = buf[:0]
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return buf, err
+		}
+	}
+}
+
+// parseAggTrade fills t from an aggTrade payload (see aggTradeEvent). Fields
+// the payload lacks are left zero.
+func parseAggTrade(b []byte, t *model.Trade) error {
+	*t = model.Trade{}
+	s := scanner{b: b}
+	if !s.consume('{') {
+		return errSyntax
+	}
+	for {
+		key, ok, err := s.next()
+		if err != nil || !ok {
+			return err
+		}
+		switch {
+		case len(key) != 1:
+			err = s.skip()
+		case key[0] == 'a':
+			t.ID, err = s.int()
+		case key[0] == 'p':
+			t.Price, err = s.decimal()
+		case key[0] == 'q':
+			t.Quantity, err = s.decimal()
+		case key[0] == 'T':
+			t.Time, err = s.int()
+		case key[0] == 'm':
+			t.IsBuyer, err = s.bool()
+		default:
+			err = s.skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseDepth appends the levels of a partial depth payload to bids and asks
+// (levels with zero quantity are dropped) and returns its event time ("E",
+// 0 when absent). Accepts both the futures keys ("b" / "a") and the
+// spot-style ones ("bids" / "asks").
+func parseDepth(b []byte, bids, asks []orderbook.PriceLevel) (_, _ []orderbook.PriceLevel, eventMs int64, err error) {
+	s := scanner{b: b}
+	if !s.consume('{') {
+		return bids, asks, 0, errSyntax
+	}
+	for {
+		key, ok, err := s.next()
+		if err != nil || !ok {
+			return bids, asks, eventMs, err
+		}
+		switch string(key) { // no allocation: compared, not stored
+		case "b", "bids":
+			bids, err = s.levels(bids)
+		case "a", "asks":
+			asks, err = s.levels(asks)
+		case "E":
+			eventMs, err = s.int()
+		default:
+			err = s.skip()
+		}
+		if err != nil {
+			return bids, asks, eventMs, err
+		}
+	}
+}
+
+// parseBookTicker fills q from a bookTicker payload (see bookTickerEvent).
+func parseBookTicker(b []byte, q *bookTicker) error {
+	*q = bookTicker{}
+	s := scanner{b: b}
+	if !s.consume('{') {
+		return errSyntax
+	}
+	for {
+		key, ok, err := s.next()
+		if err != nil || !ok {
+			return err
+		}
+		switch {
+		case len(key) != 1:
+			err = s.skip()
+		case key[0] == 'u':
+			q.updateID, err = s.int()
+		case key[0] == 'b':
+			q.bid, err = s.decimal()
+		case key[0] == 'B':
+			q.bidQty, err = s.decimal()
+		case key[0] == 'a':
+			q.ask, err = s.decimal()
+		case key[0] == 'A':
+			q.askQty, err = s.decimal()
+		default:
+			err = s.skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseMarkPrice fills m from a markPrice payload (see markPriceEvent). An
+// empty funding rate (a contract without funding) reads as 0.
+func parseMarkPrice(b []byte, m *markPrice) error {
+	*m = markPrice{}
+	s := scanner{b: b}
+	if !s.consume('{') {
+		return errSyntax
+	}
+	for {
+		key, ok, err := s.next()
+		if err != nil || !ok {
+			return err
+		}
+		switch {
+		case len(key) != 1:
+			err = s.skip()
+		case key[0] == 'E':
+			m.eventMs, err = s.int()
+		case key[0] == 'p':
+			m.mark, err = s.decimal()
+		case key[0] == 'i':
+			m.index, err = s.decimal()
+		case key[0] == 'r':
+			var v []byte
+			if v, err = s.str(); err == nil && len(v) > 0 {
+				m.funding, err = parseDecimal(v)
+			}
+		default:
+			err = s.skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseForceOrder fills l from a forceOrder payload (see forceOrderEvent):
+// the order object under "o".
+func parseForceOrder(b []byte, l *forceOrder) error {
+	*l = forceOrder{}
+	s := scanner{b: b}
+	if !s.consume('{') {
+		return errSyntax
+	}
+	for {
+		key, ok, err := s.next()
+		if err != nil || !ok {
+			return err
+		}
+		if string(key) != "o" {
+			if err := s.skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		s.ws()
+		start := s.i
+		if err := s.skip(); err != nil {
+			return err
+		}
+		if err := l.parseOrder(b[start:s.i]); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *forceOrder) parseOrder(b []byte) error {
+	s := scanner{b: b}
+	if !s.consume('{') {
+		return errSyntax
+	}
+	for {
+		key, ok, err := s.next()
+		if err != nil || !ok {
+			return err
+		}
+		switch string(key) {
+		case "S":
+			var side []byte
+			side, err = s.str()
+			l.sell = string(side) == "SELL"
+		case "p":
+			l.price, err = s.decimal()
+		case "ap":
+			l.avgPrice, err = s.decimal()
+		case "z":
+			l.filled, err = s.decimal()
+		case "T":
+			l.timeMs, err = s.int()
+		default:
+			err = s.skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseCombined splits a combined-stream envelope,
+// {"stream":"btcusdt@aggTrade","data":{…}}, into the stream name and the raw
+// payload (both slices of b).
+func parseCombined(b []byte) (stream, data []byte, err error) {
+	s := scanner{b: b}
+	if !s.consume('{') {
+		return nil, nil, errSyntax
+	}
+	for {
+		key, ok, err := s.next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			break
+		}
+		switch string(key) {
+		case "stream":
+			stream, err = s.str()
+		case "data":
+			s.ws()
+			start := s.i
+			err = s.skip()
+			data = b[start:s.i]
+		default:
+			err = s.skip()
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if stream == nil || data == nil {
+		return nil, nil, errSyntax
+	}
+	return stream, data, nil
+}
+
+// scanner — cursor over one JSON message.
+type scanner struct {
+	b    []byte
+	i    int
+	more bool // a key of the top-level object has been read
+}
+
+// next returns the next key of the top-level object (after its opening
+// brace), ok = false at the closing brace. The caller must consume the
+// value before calling next again.
+func (s *scanner) next() (key []byte, ok bool, err error) {
+	if s.consume('}') {
+		return nil, false, nil
+	}
+	if s.more && !s.consume(',') {
+		return nil, false, errSyntax
+	}
+	s.more = true
+	if key, err = s.str(); err != nil {
+		return nil, false, err
+	}
+	if !s.consume(':') {
+		return nil, false, errSyntax
+	}
+	return key, true, nil
+}
+
+func (s *scanner) ws() {
+	for s.i < len(s.b) {
+		switch s.b[s.i] {
+		case ' ', '\t', '\n', '\r':
+			s.i++
+		default:
+			return
+		}
+	}
+}
+
+// consume skips whitespace and then c, if it is next.
+func (s *scanner) consume(c byte) bool {
+	s.ws()
+	if s.i < len(s.b) && s.b[s.i] == c {
+		s.i++
+		return true
+	}
+	return false
+}
+
+// str returns the raw bytes of the next string (escapes are not decoded).
+func (s *scanner) str() ([]byte, error) {
+	if !s.consume('"') {
+		return nil, errSyntax
+	}
+	start := s.i
+	for s.i < len(s.b) {
+		switch s.b[s.i] {
+		case '"':
+			s.i++
+			return s.b[start : s.i-1], nil
+		case '\\':
+			s.i++
+		}
+		s.i++
+	}
+	return nil, errSyntax
+}
+
+// token returns the next bare literal (number, true, false, null).
+func (s *scanner) token() []byte {
+	s.ws()
+	start := s.i
+	for s.i < len(s.b) {
+		switch s.b[s.i] {
+		case ',', '}', ']', ' ', '\t', '\n', '\r':
+			return s.b[start:s.i]
+		}
+		s.i++
+	}
+	return s.b[start:s.i]
+}
+
+func (s *scanner) int() (int64, error) {
+	tok := s.token()
+	if len(tok) == 0 {
+		return 0, errSyntax
+	}
+	neg := tok[0] == '-'
+	if neg {
+		tok = tok[1:]
+	}
+	var v int64
+	for _, c := range tok {
+		if c < '0' || c > '9' {
+			return 0, errSyntax
+		}
+		v = v*10 + int64(c-'0')
+	}
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+func (s *scanner) bool() (bool, error) {
+	switch string(s.token()) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, errSyntax
+}
+
+// decimal parses a quoted (Binance) or bare decimal number.
+func (s *scanner) decimal() (float64, error) {
+	s.ws()
+	if s.i < len(s.b) && s.b[s.i] == '"' {
+		v, err := s.str()
+		if err != nil {
+			return 0, err
+		}
+		return parseDecimal(v)
+	}
+	return parseDecimal(s.token())
+}
+
+// levels appends a [[price, qty], …] array to dst.
+func (s *scanner) levels(dst []orderbook.PriceLevel) ([]orderbook.PriceLevel, error) {
+	if !s.consume('[') {
+		return dst, errSyntax
+	}
+	if s.consume(']') {
+		return dst, nil
+	}
+	for {
+		if !s.consume('[') {
+			return dst, errSyntax
+		}
+		price, err := s.decimal()
+		if err != nil {
+			return dst, err
+		}
+		if !s.consume(',') {
+			return dst, errSyntax
+		}
+		qty, err := s.decimal()
+		if err != nil {
+			return dst, err
+		}
+		for s.consume(',') { // tolerate extra elements
+			if err := s.skip(); err != nil {
+				return dst, err
+			}
+		}
+		if !s.consume(']') {
+			return dst, errSyntax
+		}
+		if qty > 0 {
+			dst = append(dst, orderbook.PriceLevel{Price: price, Quantity: qty})
+		}
+		if s.consume(',') {
+			continue
+		}
+		if s.consume(']') {
+			return dst, nil
+		}
+		return dst, errSyntax
+	}
+}
+
+// skip consumes one value of any type.
+func (s *scanner) skip() error {
+	s.ws()
+	if s.i >= len(s.b) {
+		return errSyntax
+	}
+	switch s.b[s.i] {
+	case '"':
+		_, err := s.str()
+		return err
+	case '{', '[':
+		depth := 0
+		for s.i < len(s.b) {
+			switch s.b[s.i] {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			case '"':
+				if _, err := s.str(); err != nil {
+					return err
+				}
+				continue
+			}
+			s.i++
+			if depth == 0 {
+				return nil
+			}
+		}
+		return errSyntax
+	}
+	if len(s.token()) == 0 {
+		return errSyntax
+	}
+	return nil
+}
+
+// parseDecimal converts a decimal number without allocating when it fits
+// the exact fast path.
+func parseDecimal(b []byte) (float64, error) {
+	var mant uint64
+	exp, digits, i := 0, 0, 0
+	neg := len(b) > 0 && b[0] == '-'
+	if neg {
+		i++
+	}
+	sawDot, sawDigit, ok := false, false, true
+	for ; i < len(b) && ok; i++ {
+		switch c := b[i]; {
+		case c >= '0' && c <= '9':
+			sawDigit = true
+			if digits < 19 {
+				mant = mant*10 + uint64(c-'0')
+				if mant != 0 {
+					digits++
+				}
+				if sawDot {
+					exp--
+				}
+			} else if !sawDot {
+				ok = false // too many digits for the fast path
+			} else if c != '0' {
+				ok = false
+			}
+		case c == '.' && !sawDot:
+			sawDot = true
+		default:
+			ok = false // exponent, garbage
+		}
+	}
+	if ok && sawDigit && mant < 1<<53 && -exp < len(pow10) {
+		v := float64(mant) / pow10[-exp]
+		if neg {
+			v = -v
+		}
+		return v, nil
+	}
+	return strconv.ParseFloat(string(b), 64)
+}
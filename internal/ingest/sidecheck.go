@@ -0,0 +1,218 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"market-indikator/internal/binance"
+	"market-indikator/internal/model"
+)
+
+// =============================================================================
+// SIDE CHECK — classified buy volume vs the exchange's taker-buy volume
+// =============================================================================
+//
+// Every 1m kline carries the exchange's own split of the minute's volume:
+// takerBuyBaseAssetVolume. The validator sums the same minute from the trade
+// bus through model.Trade.AggressiveBuy and compares the buy shares:
+//
+//   share  = buyVol / totalVol                (ours and the kline's)
+//   drift  = share_ours − share_kline         (percentage points)
+//   volume = totalVol_ours / totalVol_kline   (coverage: dropped trades,
+//                                              reconnect gaps)
+//
+// A correct convention gives |drift| ≈ 0 even when trades were dropped —
+// drops hit both sides alike. An inverted convention gives
+// share_ours ≈ 1 − share_kline: it is only distinguishable from a balanced
+// minute when the kline is one-sided, so a minute counts as inverted only
+// when the kline's share is at least invertedSkew away from 50%.
+//
+// Only minutes seen whole are checked: after the first trade's minute and
+// before the minute of the latest trade.
+// =============================================================================
+
+const (
+	klinesPath      = "/fapi/v1/klines" // weight 1 at limit < 100
+	sideCheckLimit  = 5                 // klines per request
+	sideCheckEvery  = time.Minute
+	sideCheckDelay  = 5 * time.Second // after the minute, for the kline to close
+	sideDriftWarn   = 2.0             // |drift| in pp worth a log warning
+	invertedSkew    = 10.0            // kline share pp from 50% before inversion is judged
+	sideCheckMinute = 60000
+)
+
+// SideStats — counters for /admin/stats.
+type SideStats struct {
+	Checks   int64   // minutes compared
+	Drift    float64 // last minute's drift, pp
+	MaxDrift float64 // largest |drift| seen, pp
+	Volume   float64 // last minute's volume coverage, %
+	Inverted int64   // minutes that looked inverted
+	Errors   int64   // failed kline requests
+}
+
+type sideMinute struct {
+	start         int64 // unix ms; 0 = empty slot
+	buyVol, total float64
+}
+
+// SideValidator — see above. Feed it a bus subscription with Run and start
+// the kline poller with Start.
+type SideValidator struct {
+	symbol string
+	client *binance.Client
+
+	mu      sync.Mutex
+	minutes [sideCheckLimit + 2]sideMinute // indexed by minute % len
+	first   int64                          // first trade's minute (partial)
+	latest  int64                          // latest trade's minute (in progress)
+	checked int64                          // last minute compared
+	stats   SideStats
+
+	errors int64 // atomic
+}
+
+func NewSideValidator(symbol string) *SideValidator {
+	client := binance.NewClient(binance.Current().REST, "", "")
+	client.SetTimeout(5 * time.Second)
+	return &SideValidator{symbol: symbol, client: client}
+}
+
+// Run folds trades until the channel closes.
+func (v *SideValidator) Run(trades <-chan model.Trade) {
+	for t := range trades {
+		start := t.Time / sideCheckMinute * sideCheckMinute
+		v.mu.Lock()
+		if v.first == 0 {
+			v.first = start
+		}
+		if start > v.latest {
+			v.latest = start
+		}
+		m := &v.minutes[(start/sideCheckMinute)%int64(len(v.minutes))]
+		if m.start != start {
+			if start < m.start {
+				v.mu.Unlock()
+				continue // older than the ring
+			}
+			*m = sideMinute{start: start}
+		}
+		m.total += t.Quantity
+		if t.AggressiveBuy() {
+			m.buyVol += t.Quantity
+		}
+		v.mu.Unlock()
+	}
+}
+
+func (v *SideValidator) Start(ctx context.Context) {
+	go v.loop(ctx)
+}
+
+// Stats returns the comparison counters.
+func (v *SideValidator) Stats() SideStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s := v.stats
+	s.Errors = atomic.LoadInt64(&v.errors)
+	return s
+}
+
+func (v *SideValidator) loop(ctx context.Context) {
+	for {
+		now := time.Now()
+		next := now.Truncate(sideCheckEvery).Add(sideCheckEvery + sideCheckDelay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next.Sub(now)):
+		}
+		if err := v.check(); err != nil {
+			log.Printf("Side check error: %v", err)
+			atomic.AddInt64(&v.errors, 1)
+		}
+	}
+}
+
+// check compares the recent closed klines with the whole minutes seen.
+func (v *SideValidator) check() error {
+	var rows [][]any
+	q := url.Values{
+		"symbol":   {strings.ToUpper(v.symbol)},
+		"interval": {"1m"},
+		"limit":    {strconv.Itoa(sideCheckLimit)},
+	}
+	if err := v.client.Do(http.MethodGet, klinesPath, q, false, &rows); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, k := range rows {
+		// [openTime, open, high, low, close, volume, closeTime, quoteVolume,
+		//  trades, takerBuyBase, takerBuyQuote, ignore]
+		if len(k) < 10 {
+			return fmt.Errorf("kline: %d fields", len(k))
+		}
+		openMs, _ := k[0].(float64)
+		start := int64(openMs)
+		if start <= v.first || start >= v.latest || start <= v.checked {
+			continue
+		}
+		m := &v.minutes[(start/sideCheckMinute)%int64(len(v.minutes))]
+		total, err1 := klineFloat(k[5])
+		takerBuy, err2 := klineFloat(k[9])
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("kline %d: bad volume", start)
+		}
+		v.checked = start
+		if m.start != start || m.total == 0 || total == 0 {
+			continue // no trades seen, or the exchange had none
+		}
+		v.compare(start, m, takerBuy, total)
+	}
+	return nil
+}
+
+// compare records and logs one minute. Caller holds v.mu.
+func (v *SideValidator) compare(start int64, m *sideMinute, takerBuy, total float64) {
+	ours := 100 * m.buyVol / m.total
+	theirs := 100 * takerBuy / total
+	drift := ours - theirs
+	s := &v.stats
+	s.Checks++
+	s.Drift = drift
+	s.MaxDrift = math.Max(s.MaxDrift, math.Abs(drift))
+	s.Volume = 100 * m.total / total
+
+	label := time.UnixMilli(start).UTC().Format("15:04")
+	inverted := math.Abs(theirs-50) >= invertedSkew &&
+		math.Abs(ours-(100-theirs)) < math.Abs(drift)
+	switch {
+	case inverted:
+		s.Inverted++
+		log.Printf("Side check %s: buy %.1f%% vs exchange %.1f%% — looks INVERTED, check -trade-side (now %s)",
+			label, ours, theirs, model.Side)
+	case math.Abs(drift) >= sideDriftWarn:
+		log.Printf("Side check %s: buy %.1f%% vs exchange %.1f%% (drift %+.1fpp, volume %.1f%%)",
+			label, ours, theirs, drift, s.Volume)
+	}
+}
+
+// klineFloat — kline volumes are decimal strings.
+func klineFloat(f any) (float64, error) {
+	s, ok := f.(string)
+	if !ok {
+		return 0, fmt.Errorf("not a string: %v", f)
+	}
+	return strconv.ParseFloat(s, 64)
+}
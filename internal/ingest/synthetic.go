@@ -0,0 +1,320 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+
+	"market-indikator/internal/bus"
+	"market-indikator/internal/model"
+	oi "market-indikator/internal/oi"
+	"market-indikator/internal/orderbook"
+)
+
+// =============================================================================
+// SYNTHETIC FEED — offline trades, depth and OI
+// =============================================================================
+//
+// Replaces the three Binance feeds (-synthetic) so the full stack runs with
+// no network. Generator is pure and seeded: the same config yields the same
+// trade / depth / OI sequence on a virtual clock, usable directly in
+// integration tests. SyntheticIngester paces it on the wall clock.
+//
+// PRICE (per trade, Δt ~ Exp(rate), log-returns in bps):
+//
+//   r = μ·Δt + σ·√Δt·N(0,1)
+//   trend  μ = ±0.15 bps/s, σ = 1.2, direction flips every 15 min
+//   chop   μ = −θ·ln(P/anchor)·10⁴ with θ = 1/60 s⁻¹ (mean-reverting), σ = 1.5
+//   crash  −15 bps/s for 20 s (≈ −3%, rate ×5, σ = 4), then +2 bps/s for
+//          60 s (rate ×2.5), then chop around the new level
+//
+// SIDE: P(buy aggressor) = 0.5 + 0.35·tanh(m/2), m = EW mean of recent
+// returns — flow follows price. Size ~ lognormal (median 0.02), 1% of prints
+// ×20. DEPTH: 20 levels per side at the tick, size growing with distance,
+// skewed toward the flow; bids thin to 20% during a crash leg. OI: builds
+// +0.02% per poll in a trend, noise in chop, −0.3% per poll in a crash leg
+// (forced closing).
+//
+// REGIMES: trend, chop, crash (chop with a 90 s flash crash at minute 5 of
+// every 10) and mixed (40 min cycle: trend up, chop, trend down, chop with a
+// crash at minute 35).
+// =============================================================================
+
+// Synthetic regimes.
+const (
+	RegimeTrend = "trend"
+	RegimeChop  = "chop"
+	RegimeCrash = "crash"
+	RegimeMixed = "mixed"
+)
+
+const (
+	synthTicks      = 10 // price ticks per unit (tick 0.1)
+	synthLevels     = 20
+	synthDepthEvery = 250 // ms, as the partial depth stream
+	crashDropMs     = 20_000
+	crashReboundMs  = 60_000
+)
+
+// SyntheticConfig — generator parameters.
+type SyntheticConfig struct {
+	Regime       string
+	Seed         int64
+	StartMs      int64   // virtual clock origin, unix ms (0 = wall clock at creation)
+	Price        float64 // initial price
+	OI           float64 // initial open interest
+	TradesPerSec float64 // base trade rate
+}
+
+// DefaultSyntheticConfig — a BTC-like market in the mixed regime.
+func DefaultSyntheticConfig() SyntheticConfig {
+	return SyntheticConfig{Regime: RegimeMixed, Seed: 1, Price: 60000, OI: 80000, TradesPerSec: 20}
+}
+
+// Validate — the config is usable.
+func (c *SyntheticConfig) Validate() error {
+	switch c.Regime {
+	case RegimeTrend, RegimeChop, RegimeCrash, RegimeMixed:
+	default:
+		return fmt.Errorf("unknown regime %q (want trend, chop, crash or mixed)", c.Regime)
+	}
+	if c.Price <= 0 || c.OI <= 0 || c.TradesPerSec <= 0 {
+		return fmt.Errorf("price, OI and trade rate must be > 0")
+	}
+	return nil
+}
+
+// phase — the market state at a virtual time.
+type phase struct {
+	kind  int     // phaseTrend, phaseChop, phaseDrop, phaseRebound
+	dir   float64 // trend direction
+	since int64   // phase start, virtual ms
+}
+
+const (
+	phaseTrend = iota
+	phaseChop
+	phaseDrop
+	phaseRebound
+)
+
+// Generator — deterministic synthetic market on a virtual clock. Trades,
+// depth and OI draw from separate seeded sources, so the trade sequence does
+// not depend on how often depth or OI are sampled.
+type Generator struct {
+	cfg                    SyntheticConfig
+	trades, depth, oiNoise *rand.Rand
+
+	now    int64 // virtual ms of the last trade
+	id     int64
+	price  float64
+	anchor float64 // chop mean
+	mom    float64 // EW mean of recent returns, bps
+	oi     float64
+	cur    phase
+}
+
+func NewGenerator(cfg SyntheticConfig) *Generator {
+	if cfg.StartMs == 0 {
+		cfg.StartMs = time.Now().UnixMilli()
+	}
+	return &Generator{
+		cfg:     cfg,
+		trades:  rand.New(rand.NewSource(cfg.Seed)),
+		depth:   rand.New(rand.NewSource(cfg.Seed + 1)),
+		oiNoise: rand.New(rand.NewSource(cfg.Seed + 2)),
+		now:     cfg.StartMs,
+		price:   cfg.Price,
+		anchor:  cfg.Price,
+		oi:      cfg.OI,
+		cur:     phase{kind: -1},
+	}
+}
+
+// Now — the virtual time of the last trade, unix ms.
+func (g *Generator) Now() int64 {
+	return g.now
+}
+
+// phaseAt — the scheduled phase at virtual time t.
+func (g *Generator) phaseAt(t int64) phase {
+	el := t - g.cfg.StartMs
+	const minute = 60_000
+	crash := func(at int64) (phase, bool) {
+		switch d := el - at; {
+		case d >= 0 && d < crashDropMs:
+			return phase{kind: phaseDrop, since: g.cfg.StartMs + at}, true
+		case d >= crashDropMs && d < crashDropMs+crashReboundMs:
+			return phase{kind: phaseRebound, since: g.cfg.StartMs + at + crashDropMs}, true
+		}
+		return phase{}, false
+	}
+	switch g.cfg.Regime {
+	case RegimeTrend:
+		seg := el / (15 * minute)
+		dir := 1.0
+		if seg%2 == 1 {
+			dir = -1
+		}
+		return phase{kind: phaseTrend, dir: dir, since: g.cfg.StartMs + seg*15*minute}
+	case RegimeChop:
+		return phase{kind: phaseChop, since: g.cfg.StartMs}
+	case RegimeCrash:
+		cycle := el / (10 * minute) * 10 * minute
+		if p, ok := crash(cycle + 5*minute); ok {
+			return p
+		}
+		return phase{kind: phaseChop, since: g.cfg.StartMs + cycle}
+	}
+	// Mixed: 40-minute cycle
+	cycle := el / (40 * minute) * 40 * minute
+	switch in := el - cycle; {
+	case in < 10*minute:
+		return phase{kind: phaseTrend, dir: 1, since: g.cfg.StartMs + cycle}
+	case in < 20*minute:
+		return phase{kind: phaseChop, since: g.cfg.StartMs + cycle + 10*minute}
+	case in < 30*minute:
+		return phase{kind: phaseTrend, dir: -1, since: g.cfg.StartMs + cycle + 20*minute}
+	}
+	if p, ok := crash(cycle + 35*minute); ok {
+		return p
+	}
+	return phase{kind: phaseChop, since: g.cfg.StartMs + cycle + 30*minute}
+}
+
+// Trade — the next trade.
+func (g *Generator) Trade() model.Trade {
+	rate := g.cfg.TradesPerSec
+	switch g.cur.kind {
+	case phaseDrop:
+		rate *= 5
+	case phaseRebound:
+		rate *= 2.5
+	case phaseTrend:
+		rate *= 1.2
+	}
+	dtMs := int64(math.Max(1, math.Round(g.trades.ExpFloat64()/rate*1000)))
+	g.now += dtMs
+
+	if p := g.phaseAt(g.now); p.kind != g.cur.kind || p.since != g.cur.since {
+		g.cur = p
+		if p.kind == phaseChop {
+			g.anchor = g.price
+		}
+	}
+
+	var mu, sigma float64 // bps/s, bps/√s
+	switch g.cur.kind {
+	case phaseTrend:
+		mu, sigma = 0.15*g.cur.dir, 1.2
+	case phaseChop:
+		mu, sigma = -math.Log(g.price/g.anchor)*1e4/60, 1.5
+	case phaseDrop:
+		mu, sigma = -15, 4
+	case phaseRebound:
+		mu, sigma = 2, 2.5
+	}
+	dt := float64(dtMs) / 1000
+	r := mu*dt + sigma*math.Sqrt(dt)*g.trades.NormFloat64()
+	g.price *= 1 + r/1e4
+	g.mom = 0.9*g.mom + 0.1*r
+
+	pBuy := 0.5 + 0.35*math.Tanh(g.mom/2)
+	qty := 0.02 * math.Exp(g.trades.NormFloat64())
+	if g.trades.Float64() < 0.01 {
+		qty *= 20
+	}
+	g.id++
+	return model.Trade{
+		ID:       g.id,
+		Price:    math.Round(g.price*synthTicks) / synthTicks,
+		Quantity: math.Round(qty*1000) / 1000,
+		Time:     g.now,
+		IsBuyer:  g.trades.Float64() >= pBuy, // buyer is maker = aggressive sell
+	}
+}
+
+// Depth — the book around the last trade, appended to bids and asks.
+func (g *Generator) Depth(bids, asks []orderbook.PriceLevel) ([]orderbook.PriceLevel, []orderbook.PriceLevel) {
+	mid := math.Round(g.price * synthTicks) // in ticks
+	skew := 0.5 * math.Tanh(g.mom/3)        // flow up → bids stack
+	bidMult, askMult := 1+skew, 1-skew
+	if g.cur.kind == phaseDrop {
+		bidMult *= 0.2
+	}
+	for k := 0; k < synthLevels; k++ {
+		base := 2.0 * (1 + 0.15*float64(k))
+		bids = append(bids, orderbook.PriceLevel{
+			Price:    (mid - float64(k+1)) / synthTicks,
+			Quantity: math.Round(base*bidMult*math.Exp(0.4*g.depth.NormFloat64())*1000) / 1000,
+		})
+		asks = append(asks, orderbook.PriceLevel{
+			Price:    (mid + float64(k)) / synthTicks,
+			Quantity: math.Round(base*askMult*math.Exp(0.4*g.depth.NormFloat64())*1000) / 1000,
+		})
+	}
+	return bids, asks
+}
+
+// OI — the next open-interest poll.
+func (g *Generator) OI() float64 {
+	var drift float64
+	switch g.cur.kind {
+	case phaseTrend:
+		drift = 0.0002
+	case phaseDrop:
+		drift = -0.003
+	}
+	g.oi *= 1 + drift + 0.00005*g.oiNoise.NormFloat64()
+	return math.Round(g.oi*1000) / 1000
+}
+
+// SyntheticIngester — drives a Generator on the wall clock in place of the
+// trade, depth and OI ingesters.
+type SyntheticIngester struct {
+	gen  *Generator
+	bus  *bus.Bus
+	book *orderbook.Book
+	oi   *oi.Engine
+}
+
+// NewSyntheticIngester — cfg.StartMs is ignored: the virtual clock starts now.
+func NewSyntheticIngester(cfg SyntheticConfig, b *bus.Bus, book *orderbook.Book, oiEngine *oi.Engine) *SyntheticIngester {
+	cfg.StartMs = 0
+	return &SyntheticIngester{gen: NewGenerator(cfg), bus: b, book: book, oi: oiEngine}
+}
+
+func (s *SyntheticIngester) Start(ctx context.Context) {
+	go s.run(ctx)
+}
+
+func (s *SyntheticIngester) run(ctx context.Context) {
+	log.Printf("Synthetic feed started (regime %s, seed %d)", s.gen.cfg.Regime, s.gen.cfg.Seed)
+	bids := make([]orderbook.PriceLevel, 0, synthLevels)
+	asks := make([]orderbook.PriceLevel, 0, synthLevels)
+	nextDepth := s.gen.Now()
+	nextOI := s.gen.Now()
+
+	for {
+		t := s.gen.Trade()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(time.UnixMilli(t.Time))):
+		}
+		for ; nextDepth <= t.Time; nextDepth += synthDepthEvery {
+			bids, asks = s.gen.Depth(bids[:0], asks[:0])
+			if err := s.book.UpdateDepth(bids, asks, nextDepth); err != nil {
+				log.Printf("Synthetic depth: %v", err)
+			}
+		}
+		for ; nextOI <= t.Time; nextOI += oiInterval.Milliseconds() {
+			s.oi.Update(s.gen.OI(), t.Price)
+		}
+		t.RecvTime = time.Now().UnixNano()
+		s.bus.Publish(t)
+	}
+}
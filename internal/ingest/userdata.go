@@ -0,0 +1,220 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"market-indikator/internal/account"
+	"market-indikator/internal/binance"
+)
+
+// =============================================================================
+// USER-DATA STREAM — own fills and position
+// =============================================================================
+//
+// Authenticated with the API key/secret (read-only permissions suffice):
+//
+//   1. POST /fapi/v1/listenKey          → listenKey (valid 60 min)
+//   2. GET  /fapi/v2/positionRisk       → current position (signed)
+//   3. WS   /ws/<listenKey>             → ACCOUNT_UPDATE, ORDER_TRADE_UPDATE
+//   4. PUT  /fapi/v1/listenKey every 30 min keeps the key alive
+//
+// A listenKeyExpired event or any error redials from step 1. Only events for
+// the traded symbol are applied. The position is assumed one-way (hedge-mode
+// legs are netted into one signed size). Commission is deducted from realized
+// PnL only when charged in the symbol's quote asset.
+// =============================================================================
+
+const (
+	listenKeyRenew = 30 * time.Minute
+	userIdle       = 10 * time.Minute // quiet account: only server pings (~3 min)
+)
+
+// userEvent covers the two event types used. encoding/json matches keys
+// case-insensitively, so every key that also exists in the other case is
+// declared to keep them apart.
+type userEvent struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+	Account   struct {
+		Positions []struct {
+			Symbol     string `json:"s"`
+			Amount     string `json:"pa"`
+			EntryPrice string `json:"ep"`
+		} `json:"P"`
+	} `json:"a"`
+	Order struct {
+		Symbol          string `json:"s"`
+		Side            string `json:"S"`
+		ExecType        string `json:"x"`
+		Status          string `json:"X"`
+		LastQty         string `json:"l"`
+		LastPrice       string `json:"L"`
+		Commission      string `json:"n"`
+		CommissionAsset string `json:"N"`
+		TradeID         int64  `json:"t"`
+		TradeTime       int64  `json:"T"`
+		RealizedProfit  string `json:"rp"`
+	} `json:"o"`
+}
+
+// UserDataStream follows the account's user-data stream for one symbol.
+type UserDataStream struct {
+	client  *binance.Client
+	symbol  string
+	account *account.Tracker
+
+	reconnects int64 // atomic
+}
+
+func NewUserDataStream(apiKey, secret, symbol string, acct *account.Tracker) *UserDataStream {
+	return &UserDataStream{
+		client:  binance.NewClient(binance.Current().REST, apiKey, secret),
+		symbol:  strings.ToUpper(symbol),
+		account: acct,
+	}
+}
+
+func (u *UserDataStream) Start(ctx context.Context) {
+	go u.loop(ctx)
+}
+
+// Reconnects returns how many times the user-data stream has been redialed.
+func (u *UserDataStream) Reconnects() int64 {
+	return atomic.LoadInt64(&u.reconnects)
+}
+
+func (u *UserDataStream) loop(ctx context.Context) {
+	delay := reconnectDelay
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		err := u.connectAndConsume(ctx)
+		if err != nil {
+			atomic.AddInt64(&u.reconnects, 1)
+			log.Printf("User-data stream error: %v. Reconnecting in %v...", err, delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay *= 2
+			if delay > maxReconnectDelay {
+				delay = maxReconnectDelay
+			}
+		} else {
+			delay = reconnectDelay
+		}
+	}
+}
+
+func (u *UserDataStream) connectAndConsume(ctx context.Context) error {
+	var key struct {
+		ListenKey string `json:"listenKey"`
+	}
+	if err := u.client.Do(http.MethodPost, "/fapi/v1/listenKey", nil, false, &key); err != nil {
+		return fmt.Errorf("listenKey: %w", err)
+	}
+	// Seed the position (the stream only reports changes)
+	size, entry, err := u.client.Position(u.symbol)
+	if err != nil {
+		return fmt.Errorf("positionRisk: %w", err)
+	}
+	u.account.SetPosition(size, entry)
+
+	c, _, err := binance.Dialer().Dial(binance.WSStream(key.ListenKey), nil)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	log.Printf("Connected to Binance User-Data Stream (%s)", u.symbol)
+	keepAlive(c, "User-data stream", userIdle)
+
+	// Keep the listenKey alive while the connection is up
+	renewCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go func() {
+		ticker := time.NewTicker(listenKeyRenew)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-renewCtx.Done():
+				return
+			case <-ticker.C:
+				if err := u.client.Do(http.MethodPut, "/fapi/v1/listenKey", nil, false, nil); err != nil {
+					log.Printf("User-data listenKey keepalive failed: %v", err)
+				}
+			}
+		}
+	}()
+
+	var ev userEvent
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		ev = userEvent{}
+		if err := c.ReadJSON(&ev); err != nil {
+			return err
+		}
+		touch(c, userIdle)
+
+		switch ev.EventType {
+		case "listenKeyExpired":
+			return fmt.Errorf("listenKey expired")
+		case "ACCOUNT_UPDATE":
+			u.applyPositions(ev)
+		case "ORDER_TRADE_UPDATE":
+			u.applyFill(ev)
+		}
+	}
+}
+
+func (u *UserDataStream) applyPositions(ev userEvent) {
+	var size, cost float64
+	found := false
+	for _, p := range ev.Account.Positions {
+		if p.Symbol != u.symbol {
+			continue
+		}
+		amt, _ := strconv.ParseFloat(p.Amount, 64)
+		entry, _ := strconv.ParseFloat(p.EntryPrice, 64)
+		size += amt
+		cost += amt * entry
+		found = true
+	}
+	if found {
+		u.account.SetPosition(size, binance.EntryOf(size, cost))
+	}
+}
+
+func (u *UserDataStream) applyFill(ev userEvent) {
+	o := &ev.Order
+	if o.Symbol != u.symbol || o.ExecType != "TRADE" {
+		return
+	}
+	qty, _ := strconv.ParseFloat(o.LastQty, 64)
+	price, _ := strconv.ParseFloat(o.LastPrice, 64)
+	realized, _ := strconv.ParseFloat(o.RealizedProfit, 64)
+	var commission float64
+	if strings.HasSuffix(u.symbol, o.CommissionAsset) && o.CommissionAsset != "" {
+		commission, _ = strconv.ParseFloat(o.Commission, 64)
+	}
+	if o.Side == "SELL" {
+		qty = -qty
+	}
+	u.account.AddFill(o.TradeTime, price, qty, realized, commission)
+}
@@ -0,0 +1,148 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"market-indikator/internal/binance"
+	oi "market-indikator/internal/oi"
+)
+
+// Other venues' public open-interest endpoints, in base asset units like
+// Binance's. Both allow far more than one request per 5s per IP.
+const (
+	bybitOIURL    = "https://api.bybit.com/v5/market/tickers?category=linear&symbol=%s"
+	okxOIURL      = "https://www.okx.com/api/v5/public/open-interest?instType=SWAP&instId=%s"
+	venueInterval = 5 * time.Second
+)
+
+// bybitTickers — /v5/market/tickers reply (only the fields in use).
+type bybitTickers struct {
+	RetCode int    `json:"retCode"`
+	RetMsg  string `json:"retMsg"`
+	Result  struct {
+		List []struct {
+			OpenInterest string `json:"openInterest"` // base coin
+		} `json:"list"`
+	} `json:"result"`
+}
+
+// okxOpenInterest — /api/v5/public/open-interest reply.
+type okxOpenInterest struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+	Data []struct {
+		OICcy string `json:"oiCcy"` // base currency ("oi" is contracts)
+	} `json:"data"`
+}
+
+// VenueOIPoller polls one non-Binance venue's OI for the same underlying
+// into the aggregator. Its own goroutine, off the hot path.
+type VenueOIPoller struct {
+	venue   int
+	url     string
+	agg     *oi.Aggregator
+	priceFn func() float64
+	http    *http.Client
+
+	errors int64 // atomic — failed polls
+}
+
+// NewVenueOIPoller — symbol is the Binance symbol (e.g. BTCUSDT); the
+// venue's instrument is derived from it.
+func NewVenueOIPoller(venue int, agg *oi.Aggregator, symbol string, priceFn func() float64) (*VenueOIPoller, error) {
+	symbol = strings.ToUpper(symbol)
+	p := &VenueOIPoller{venue: venue, agg: agg, priceFn: priceFn, http: binance.HTTPClient(2 * time.Second)}
+	switch venue {
+	case oi.Bybit:
+		p.url = fmt.Sprintf(bybitOIURL, symbol)
+	case oi.OKX:
+		base, ok := strings.CutSuffix(symbol, "USDT")
+		if !ok {
+			return nil, fmt.Errorf("okx: no USDT swap for %s", symbol)
+		}
+		p.url = fmt.Sprintf(okxOIURL, base+"-USDT-SWAP")
+	default:
+		return nil, fmt.Errorf("no OI poller for venue %d", venue)
+	}
+	return p, nil
+}
+
+func (p *VenueOIPoller) Start(ctx context.Context) {
+	go p.loop(ctx)
+}
+
+// Errors returns the number of failed polls since start.
+func (p *VenueOIPoller) Errors() int64 {
+	return atomic.LoadInt64(&p.errors)
+}
+
+func (p *VenueOIPoller) loop(ctx context.Context) {
+	ticker := time.NewTicker(venueInterval)
+	defer ticker.Stop()
+	for {
+		p.poll()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *VenueOIPoller) poll() {
+	v, err := p.fetch()
+	if err != nil {
+		log.Printf("%s OI poll error: %v", oi.VenueNames[p.venue], err)
+		atomic.AddInt64(&p.errors, 1)
+		return
+	}
+	p.agg.Update(p.venue, v, p.priceFn())
+}
+
+func (p *VenueOIPoller) fetch() (float64, error) {
+	resp, err := p.http.Get(p.url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, body)
+	}
+
+	var field string
+	switch p.venue {
+	case oi.Bybit:
+		var r bybitTickers
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			return 0, err
+		}
+		if r.RetCode != 0 || len(r.Result.List) == 0 {
+			return 0, fmt.Errorf("retCode %d: %s", r.RetCode, r.RetMsg)
+		}
+		field = r.Result.List[0].OpenInterest
+	case oi.OKX:
+		var r okxOpenInterest
+		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+			return 0, err
+		}
+		if r.Code != "0" || len(r.Data) == 0 {
+			return 0, fmt.Errorf("code %s: %s", r.Code, r.Msg)
+		}
+		field = r.Data[0].OICcy
+	}
+	v, err := strconv.ParseFloat(field, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("bad open interest %q", field)
+	}
+	return v, nil
+}
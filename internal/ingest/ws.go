@@ -0,0 +1,172 @@
+package ingest
+
+import (
+	"log"
+	"time"
+
+	"market-indikator/internal/binance"
+	"market-indikator/internal/bus"
+	"market-indikator/internal/model"
+
+	"github.com/gorilla/websocket"
+)
+
+// Binance pings every connection periodically (every ~3 minutes on futures)
+// and closes it if no pong comes back within 10 minutes. gorilla answers pings
+// by default, but only while a read is in progress, and it does not tell us
+// anything about a stream that has silently stopped delivering data.
+//
+// keepAlive makes both explicit:
+//   - pings are answered immediately with the same payload, and
+//   - every ping or data frame pushes the read deadline forward by idle,
+//     so a stream that goes quiet for longer than idle fails the read and
+//     the caller's reconnect loop takes over.
+const pongWriteWait = 5 * time.Second
+
+func keepAlive(c *websocket.Conn, name string, idle time.Duration) {
+	c.SetReadDeadline(time.Now().Add(idle))
+	c.SetPingHandler(func(payload string) error {
+		c.SetReadDeadline(time.Now().Add(idle))
+		err := c.WriteControl(websocket.PongMessage, []byte(payload), time.Now().Add(pongWriteWait))
+		if err != nil {
+			log.Printf("%s: pong failed: %v", name, err)
+		}
+		return err
+	})
+}
+
+// touch extends the read deadline after a data frame.
+func touch(c *websocket.Conn, idle time.Duration) {
+	c.SetReadDeadline(time.Now().Add(idle))
+}
+
+// =============================================================================
+// 24h ROTATION — zero-gap connection handover
+// =============================================================================
+//
+// Binance closes every market-stream connection after 24h. Waiting for that
+// close costs a reconnect (and, with backoff, seconds of missing trades), so
+// each ingester rotates on its own schedule, before the deadline:
+//
+//   1. after connMaxAge, dial a replacement in the background while the old
+//      connection keeps delivering
+//   2. read the new connection up to its first trade F (depth from it is
+//      applied right away — it is the newer book)
+//   3. drain the old connection up to trade F−1 (aggregate trade IDs are
+//      consecutive), skipping its depth, then close it
+//   4. publish F and continue on the new connection
+//
+// tradeSeq drops any trade at or below the last published ID, so the bus
+// sees every trade once, in order. Depth-only connections swap directly:
+// each frame is a full top-20 snapshot.
+//
+// If the connection drops anyway (rotation failed, or Binance closed it
+// early), a connection that had been up for more than stableConn is redialed
+// at once instead of after the backoff delay.
+// =============================================================================
+
+const (
+	connMaxAge   = 23*time.Hour + 30*time.Minute
+	rotateRetry  = time.Minute     // after a failed replacement dial
+	handoverWait = 5 * time.Second // draining the old connection
+	stableConn   = time.Minute
+)
+
+// dialAsync dials url in the background. The connection, with keepAlive set
+// up, or nil on error arrives on the returned channel.
+func dialAsync(url, name string, idle time.Duration) <-chan *websocket.Conn {
+	out := make(chan *websocket.Conn, 1)
+	go func() {
+		c, _, err := binance.Dialer().Dial(url, nil)
+		if err != nil {
+			log.Printf("%s: replacement dial failed: %v", name, err)
+			out <- nil
+			return
+		}
+		keepAlive(c, name, idle)
+		out <- c
+	}()
+	return out
+}
+
+// discardDial closes the connection of a dialAsync that is no longer
+// wanted (nil = none pending).
+func discardDial(next <-chan *websocket.Conn) {
+	if next == nil {
+		return
+	}
+	go func() {
+		if c := <-next; c != nil {
+			c.Close()
+		}
+	}()
+}
+
+// redialDelay — the wait before the next dial after a connection that was up
+// since start failed, and the backoff to use after that.
+func redialDelay(start time.Time, delay, initial, limit time.Duration) (wait, next time.Duration) {
+	if time.Since(start) > stableConn {
+		return 0, initial
+	}
+	next = delay * 2
+	if next > limit {
+		next = limit
+	}
+	return delay, next
+}
+
+// tradeSeq — publishes trades in aggregate-ID order across connections,
+// dropping duplicates. Ingest goroutine only.
+type tradeSeq struct {
+	bus  *bus.Bus
+	last int64 // last published trade ID (0 = none yet)
+}
+
+func (q *tradeSeq) publish(t *model.Trade) {
+	if t.ID <= q.last {
+		return
+	}
+	q.last = t.ID
+	q.bus.Publish(*t)
+}
+
+// frameReader reads one frame from c and returns its trade, if it carried
+// one. Depth in the frame is applied only when applyDepth is set.
+type frameReader func(c *websocket.Conn, applyDepth bool) (t model.Trade, ok bool, err error)
+
+// handover moves the trade sequence from old to next (see above) and returns
+// the connection to keep reading; on failure that is old, still open.
+func handover(name string, old, next *websocket.Conn, q *tradeSeq, read frameReader) *websocket.Conn {
+	var first model.Trade
+	for {
+		t, ok, err := read(next, true)
+		if err != nil {
+			log.Printf("%s: replacement connection failed before its first trade: %v", name, err)
+			next.Close()
+			return old
+		}
+		if ok {
+			first = t
+			break
+		}
+	}
+
+	if q.last > 0 {
+		deadline := time.Now().Add(handoverWait)
+		for q.last < first.ID-1 {
+			old.SetReadDeadline(deadline) // read's touch pushes it out
+			t, ok, err := read(old, false)
+			if err != nil {
+				log.Printf("%s: handover lost %d trades: %v", name, first.ID-1-q.last, err)
+				break
+			}
+			if ok && t.ID < first.ID {
+				q.publish(&t)
+			}
+		}
+	}
+	old.Close()
+	q.publish(&first)
+	log.Printf("%s: rotated connection (handover at trade %d)", name, first.ID)
+	return next
+}
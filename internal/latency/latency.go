@@ -0,0 +1,216 @@
+package latency
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// =============================================================================
+// LATENCY — exchange event → bus → engine → WebSocket write
+// =============================================================================
+//
+// Each trade is stamped with its local receive time (model.Trade.RecvTime)
+// right after it is read off the socket. The stages are then:
+//
+//   exchange_to_recv  RecvTime − exchange event time   (network + Binance;
+//                     includes clock skew, negative samples are counted and
+//                     folded into 0)
+//   recv_to_engine    engine dequeue − RecvTime        (bus + SPSC ring)
+//   engine            ProcessTrade duration
+//   recv_to_write     frame written to a client socket − RecvTime
+//                     (engine + snapshot channel + hub encode + client queue
+//                     + socket write; one sample per client per frame)
+//
+// Histograms are log-linear (HDR-style): exact below 16 ns, then 16
+// sub-buckets per power of two — relative error ≤ 6.25% up to ~18 min.
+// Counts are atomic, so any goroutine may record without locks; quantiles
+// report the bucket's upper bound (capped at the largest sample).
+//
+// TRADING INTERPRETATION:
+//   exchange_to_recv p99 is the floor on how stale every signal is; a rising
+//   recv_to_engine or recv_to_write with flat exchange_to_recv points at the
+//   process (GC, a slow client, a saturated hub), not the network.
+// =============================================================================
+
+// Stage — one measured leg of the pipeline.
+type Stage int
+
+const (
+	ExchangeToRecv Stage = iota
+	RecvToEngine
+	Engine
+	RecvToWrite
+	NumStages
+)
+
+// StageNames — JSON names, Stage order.
+var StageNames = [NumStages]string{"exchange_to_recv", "recv_to_engine", "engine", "recv_to_write"}
+
+const (
+	subBits    = 4
+	subCount   = 1 << subBits
+	maxExp     = 40 // values ≥ 2^40 ns land in the last bucket
+	numBuckets = (maxExp - subBits + 1) * subCount
+)
+
+// Histogram — lock-free log-linear histogram of nanosecond durations.
+type Histogram struct {
+	counts   [numBuckets]int64 // atomic
+	count    int64             // atomic
+	sum      int64             // atomic, ns
+	max      int64             // atomic, ns
+	negative int64             // atomic, samples < 0 (clock skew)
+}
+
+// bucketOf — bucket index of v ≥ 0.
+func bucketOf(v int64) int {
+	if v < subCount {
+		return int(v)
+	}
+	e := bits.Len64(uint64(v)) - 1
+	if e >= maxExp {
+		return numBuckets - 1
+	}
+	sub := int(v>>(e-subBits)) & (subCount - 1)
+	return (e-subBits+1)*subCount + sub
+}
+
+// bucketUpper — largest value in bucket i.
+func bucketUpper(i int) int64 {
+	if i < subCount {
+		return int64(i)
+	}
+	e := i/subCount + subBits - 1
+	sub := int64(i % subCount)
+	width := int64(1) << (e - subBits)
+	return (subCount+sub)*width + width - 1
+}
+
+// Observe records one duration in nanoseconds.
+func (h *Histogram) Observe(ns int64) {
+	if ns < 0 {
+		atomic.AddInt64(&h.negative, 1)
+		ns = 0
+	}
+	atomic.AddInt64(&h.counts[bucketOf(ns)], 1)
+	atomic.AddInt64(&h.count, 1)
+	atomic.AddInt64(&h.sum, ns)
+	for {
+		m := atomic.LoadInt64(&h.max)
+		if ns <= m || atomic.CompareAndSwapInt64(&h.max, m, ns) {
+			return
+		}
+	}
+}
+
+// Quantile — the q-quantile (0..1) in nanoseconds; 0 with no samples.
+func (h *Histogram) Quantile(q float64) int64 {
+	n := atomic.LoadInt64(&h.count)
+	if n == 0 {
+		return 0
+	}
+	rank := int64(q*float64(n) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i := range h.counts {
+		seen += atomic.LoadInt64(&h.counts[i])
+		if seen >= rank {
+			return min(bucketUpper(i), atomic.LoadInt64(&h.max))
+		}
+	}
+	return atomic.LoadInt64(&h.max)
+}
+
+// Reset — zero all counts. Samples recorded concurrently may be split
+// across the reset.
+func (h *Histogram) Reset() {
+	for i := range h.counts {
+		atomic.StoreInt64(&h.counts[i], 0)
+	}
+	atomic.StoreInt64(&h.count, 0)
+	atomic.StoreInt64(&h.sum, 0)
+	atomic.StoreInt64(&h.max, 0)
+	atomic.StoreInt64(&h.negative, 0)
+}
+
+// StageSummary — one stage in the /admin/latency report, microseconds.
+type StageSummary struct {
+	Stage    string  `json:"stage"`
+	Count    int64   `json:"count"`
+	Negative int64   `json:"negative,omitempty"` // samples < 0, counted as 0
+	MeanUs   float64 `json:"mean_us"`
+	P50Us    float64 `json:"p50_us"`
+	P99Us    float64 `json:"p99_us"`
+	P999Us   float64 `json:"p999_us"`
+	MaxUs    float64 `json:"max_us"`
+}
+
+// Report — all stages since Since.
+type Report struct {
+	Since  time.Time      `json:"since"`
+	Stages []StageSummary `json:"stages"`
+}
+
+// Recorder — one histogram per Stage. Safe for concurrent use.
+type Recorder struct {
+	stages [NumStages]Histogram
+	since  int64 // atomic, unix ns of the last reset
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{since: time.Now().UnixNano()}
+}
+
+// Observe records a duration for stage s.
+func (r *Recorder) Observe(s Stage, ns int64) {
+	r.stages[s].Observe(ns)
+}
+
+// Trade records the stages up to the engine for one trade: exchange time
+// (unix ms), local receive, engine start and engine done (unix ns).
+func (r *Recorder) Trade(exchangeMs, recvNs, startNs, doneNs int64) {
+	r.stages[ExchangeToRecv].Observe(recvNs - exchangeMs*int64(time.Millisecond))
+	r.stages[RecvToEngine].Observe(startNs - recvNs)
+	r.stages[Engine].Observe(doneNs - startNs)
+}
+
+// Report — p50/p99/p999 per stage.
+func (r *Recorder) Report() Report {
+	rep := Report{
+		Since:  time.Unix(0, atomic.LoadInt64(&r.since)),
+		Stages: make([]StageSummary, NumStages),
+	}
+	for s := range r.stages {
+		h := &r.stages[s]
+		n := atomic.LoadInt64(&h.count)
+		ss := StageSummary{
+			Stage:    StageNames[s],
+			Count:    n,
+			Negative: atomic.LoadInt64(&h.negative),
+			P50Us:    us(h.Quantile(0.5)),
+			P99Us:    us(h.Quantile(0.99)),
+			P999Us:   us(h.Quantile(0.999)),
+			MaxUs:    us(atomic.LoadInt64(&h.max)),
+		}
+		if n > 0 {
+			ss.MeanUs = us(atomic.LoadInt64(&h.sum) / n)
+		}
+		rep.Stages[s] = ss
+	}
+	return rep
+}
+
+// Reset — start a new measurement window.
+func (r *Recorder) Reset() {
+	for s := range r.stages {
+		r.stages[s].Reset()
+	}
+	atomic.StoreInt64(&r.since, time.Now().UnixNano())
+}
+
+func us(ns int64) float64 {
+	return float64(ns) / 1e3
+}
@@ -0,0 +1,234 @@
+package leadlag
+
+import (
+	"math"
+	"sync"
+
+	"market-indikator/internal/model"
+)
+
+// =============================================================================
+// LEADER / FOLLOWER — cross-asset lead-lag
+// =============================================================================
+//
+// Alt perps rarely move on their own flow alone: BTC moves first and the alt
+// follows a few seconds later. With -leader set, the leader symbol's trades
+// are ingested alongside the traded (follower) symbol and both are sampled
+// once per second (last price, cumulative CVD):
+//
+//   r_t = (p_t − p_{t−1}) / p_{t−1} · 10⁴     per-second return, bps
+//   c_t = CVD_t − CVD_{t−1}                    per-second aggressor delta
+//
+// Over the trailing 300 seconds, for each lag k = 0..10:
+//
+//   ρ_k  = corr(r_leader[t−k], r_follower[t])
+//   β_k  = cov / var(r_leader)                 follower bps per leader bps
+//
+// The reported Lag is the k with the highest ρ_k (k = 0: they move together,
+// no lead). CVDCorr is the CVD-delta correlation at that lag. When the leader
+// leads (Lag ≥ 1, ρ > 0), the leader's last Lag seconds have not reached the
+// follower yet:
+//
+//   Expected = β_Lag · Σ_{j<Lag} r_leader[t−j]   implied follower move, bps
+//
+// Ret10s / CVD10s are the leader's own return and aggressor delta over the
+// last 10 seconds. Values are 0 until a minute of paired seconds exists.
+//
+// TRADING INTERPRETATION:
+//   Expected +5 bps with ρ 0.6 at Lag 2 → BTC just lifted and ETH usually
+//   follows within ~2s; a follower score already long gains conviction, a
+//   short one should wait. ρ collapsing toward 0 → the pair has decoupled
+//   (idiosyncratic news); ignore the leader.
+// =============================================================================
+
+const (
+	windowSecs = 300
+	maxLag     = 10
+	minPairs   = 60
+	recentSecs = 10
+	feedSecs   = 64 // per-second leader history kept by Feed
+)
+
+// Sample — a symbol's state at the end of a second.
+type Sample struct {
+	Sec   int64
+	Price float64
+	CVD   float64
+}
+
+// Feed — the leader's per-second samples. Run consumes its trades on its own
+// goroutine; Close may be called from any goroutine.
+type Feed struct {
+	mu   sync.Mutex
+	hist [feedSecs]Sample // indexed by sec % feedSecs
+	last Sample
+}
+
+func NewFeed() *Feed {
+	return &Feed{}
+}
+
+// Run folds trades until the channel closes.
+func (f *Feed) Run(trades <-chan model.Trade) {
+	var cvd float64
+	for t := range trades {
+		cvd += t.SignedQty()
+		s := Sample{Sec: t.Time / 1000, Price: t.Price, CVD: cvd}
+		f.mu.Lock()
+		f.hist[s.Sec%feedSecs] = s
+		f.last = s
+		f.mu.Unlock()
+	}
+}
+
+// Close — the leader's sample at the end of sec (carried forward over
+// seconds without trades). ok is false if sec is not covered.
+func (f *Feed) Close(sec int64) (s Sample, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.last.Sec == 0 || sec < f.last.Sec-feedSecs+1 {
+		return Sample{}, false
+	}
+	if sec >= f.last.Sec {
+		return f.last, true
+	}
+	for k := int64(0); k < feedSecs; k++ {
+		if h := f.hist[(sec-k)%feedSecs]; h.Sec == sec-k && h.Sec != 0 {
+			return h, true
+		}
+	}
+	return Sample{}, false
+}
+
+// Reading — the live lead-lag statistics.
+type Reading struct {
+	Lag      int
+	Corr     float64
+	CVDCorr  float64
+	Expected float64 // bps
+	Ret10s   float64 // leader, bps
+	CVD10s   float64 // leader
+}
+
+// Tracker — pairs the follower's seconds with the leader's. Owned by the
+// engine goroutine.
+type Tracker struct {
+	feed *Feed
+
+	sec        int64 // follower second being sampled
+	price, cvd float64
+
+	prev, prevLeader Sample
+	hasPrev          bool
+
+	// Paired per-second series, oldest overwritten first
+	rf, rl, cf, cl [windowSecs]float64
+	next, count    int
+
+	reading Reading
+}
+
+// NewTracker — a tracker following feed.
+func NewTracker(feed *Feed) *Tracker {
+	return &Tracker{feed: feed}
+}
+
+// Update — feeds the follower's price and CVD at sec; a new second pairs the
+// previous one with the leader and recomputes the statistics.
+func (t *Tracker) Update(sec int64, price, cvd float64) {
+	if sec != t.sec {
+		if t.sec != 0 {
+			t.commit()
+		}
+		t.sec = sec
+	}
+	t.price, t.cvd = price, cvd
+}
+
+func (t *Tracker) commit() {
+	cur := Sample{Sec: t.sec, Price: t.price, CVD: t.cvd}
+	lead, ok := t.feed.Close(t.sec)
+	if !ok {
+		t.hasPrev = false
+		return
+	}
+	if t.hasPrev && t.prev.Price > 0 && t.prevLeader.Price > 0 && cur.Sec == t.prev.Sec+1 {
+		i := t.next
+		t.rf[i] = (cur.Price - t.prev.Price) / t.prev.Price * 1e4
+		t.rl[i] = (lead.Price - t.prevLeader.Price) / t.prevLeader.Price * 1e4
+		t.cf[i] = cur.CVD - t.prev.CVD
+		t.cl[i] = lead.CVD - t.prevLeader.CVD
+		t.next = (t.next + 1) % windowSecs
+		if t.count < windowSecs {
+			t.count++
+		}
+		t.recompute()
+	} else if t.hasPrev && cur.Sec != t.prev.Sec+1 {
+		t.count, t.next = 0, 0 // gap: pairs no longer consecutive
+		t.reading = Reading{}
+	}
+	t.prev, t.prevLeader, t.hasPrev = cur, lead, true
+}
+
+// at — index of the k-th most recent pair (0 = newest).
+func (t *Tracker) at(k int) int {
+	return (t.next - 1 - k + 2*windowSecs) % windowSecs
+}
+
+func (t *Tracker) recompute() {
+	if t.count < minPairs {
+		t.reading = Reading{}
+		return
+	}
+	r := Reading{Corr: math.Inf(-1)}
+	var beta float64
+	for k := 0; k <= maxLag; k++ {
+		c, b := t.corr(&t.rl, &t.rf, k)
+		if c > r.Corr {
+			r.Lag, r.Corr, beta = k, c, b
+		}
+	}
+	r.CVDCorr, _ = t.corr(&t.cl, &t.cf, r.Lag)
+	if r.Lag > 0 && r.Corr > 0 {
+		for j := 0; j < r.Lag; j++ {
+			r.Expected += t.rl[t.at(j)]
+		}
+		r.Expected *= beta
+	}
+	for j := 0; j < recentSecs && j < t.count; j++ {
+		r.Ret10s += t.rl[t.at(j)]
+		r.CVD10s += t.cl[t.at(j)]
+	}
+	t.reading = r
+}
+
+// corr — correlation of lead[t−k] with follow[t] and the regression slope
+// of follow on lead, over the paired window.
+func (t *Tracker) corr(lead, follow *[windowSecs]float64, k int) (rho, beta float64) {
+	n := t.count - k
+	if n < 2 {
+		return 0, 0
+	}
+	var sx, sy, sxx, syy, sxy float64
+	for j := 0; j < n; j++ {
+		x, y := lead[t.at(j+k)], follow[t.at(j)]
+		sx += x
+		sy += y
+		sxx += x * x
+		syy += y * y
+		sxy += x * y
+	}
+	fn := float64(n)
+	vx := sxx - sx*sx/fn
+	vy := syy - sy*sy/fn
+	cxy := sxy - sx*sy/fn
+	if vx <= 0 || vy <= 0 {
+		return 0, 0
+	}
+	return cxy / math.Sqrt(vx*vy), cxy / vx
+}
+
+// Live — the statistics as of the last committed second.
+func (t *Tracker) Live() Reading {
+	return t.reading
+}
@@ -7,6 +7,7 @@ import (
 	"market-indikator/internal/model"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -24,19 +25,40 @@ import (
 //   • bufio buffer: 1MB — absorbs bursts, minimizes syscalls
 //   • Append-only daily rotation via filename: logs/YYYY-MM-DD.csv
 //
-// CSV schema (18 columns):
+// CSV schema v3 (30 columns — v1's 18 plus 9 in v2 and 3 in v3, all
+// appended, so readers that pick columns by name keep working):
 //   timestamp,price,final_score,
 //   score_1s,score_1m,score_5m,score_15m,score_1h,
 //   htf_bias,market_state,action_hint,
 //   delta_1s,cvd,ob_score,oi,oi_delta,
-//   behavior,event_flags
+//   behavior,event_flags,
+//   open,high,low,close,buy_vol,sell_vol,   ← completed 1s candle
+//   score_4h,score_1d,imbalance,
+//   buy_count,sell_count,avg_trade_size   ← v3, same 1s candle
+//
+// Each row is the CLOSING snapshot of its second. If today's file was started
+// with an older header, rows go to logs/YYYY-MM-DD_v3.csv instead of mixing
+// schemas in one file.
 // =============================================================================
 
+// SchemaVersion — the snapshot log's schema (v3 above), bumped whenever
+// columns are appended.
+const SchemaVersion = 3
+
 const (
 	chanSize    = 4096
 	bufSize     = 1 << 20 // 1 MB
 	flushPeriod = 1 * time.Second
 	logDir      = "logs"
+
+	csvHeader = "timestamp,price,final_score," +
+		"score_1s,score_1m,score_5m,score_15m,score_1h," +
+		"htf_bias,market_state,action_hint," +
+		"delta_1s,cvd,ob_score,oi,oi_delta," +
+		"behavior,event_flags," +
+		"open,high,low,close,buy_vol,sell_vol," +
+		"score_4h,score_1d,imbalance," +
+		"buy_count,sell_count,avg_trade_size"
 )
 
 // LogRow — pre-computed in the engine goroutine (NOT the hot path).
@@ -56,7 +78,7 @@ type LogRow struct {
 	// Decision layer (computed in Go, not just frontend)
 	HTFBias     string // BULLISH / BEARISH / RANGE
 	MarketState string // TRENDING_UP / PULLBACK_IN_UPTREND / etc.
-	ActionHint  string // WATCH_LONG / WATCH_SHORT / NO_TRADE
+	ActionHint  string // WATCH_LONG / WATCH_SHORT / NO_TRADE (HOLD / ADD / REDUCE / EXIT in a position)
 
 	// Raw metrics
 	Delta1s float64
@@ -68,17 +90,36 @@ type LogRow struct {
 	// Positioning
 	Behavior   int
 	EventFlags uint32
+
+	// v2: completed 1s candle + remaining HTF scores + book imbalance
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	BuyVol    float64
+	SellVol   float64
+	Score4h   float64
+	Score1d   float64
+	Imbalance float64
+
+	// v3: 1s candle trade counts
+	BuyCount  int64
+	SellCount int64
+	AvgSize   float64
 }
 
 // Logger — async CSV writer.
 type Logger struct {
-	ch chan LogRow
+	ch       chan LogRow
+	maxBytes int64
 }
 
 // NewLogger — creates the logger and starts its background goroutine.
-func NewLogger() *Logger {
+// maxBytes > 0 additionally rolls the day's file over at that size.
+func NewLogger(maxBytes int64) *Logger {
 	l := &Logger{
-		ch: make(chan LogRow, chanSize),
+		ch:       make(chan LogRow, chanSize),
+		maxBytes: maxBytes,
 	}
 	go l.run()
 	return l
@@ -94,7 +135,7 @@ func (l *Logger) Log(row LogRow) {
 	}
 }
 
-// run — background goroutine. Batches writes, rotates daily.
+// run — background goroutine. Batches writes, rotates daily and by size.
 func (l *Logger) run() {
 	// Ensure log directory exists
 	if err := os.MkdirAll(logDir, 0755); err != nil {
@@ -102,73 +143,35 @@ func (l *Logger) run() {
 		return
 	}
 
-	var (
-		currentDay string
-		file       *os.File
-		writer     *bufio.Writer
-	)
+	out := newRotatingFile("Logger", logDir, csvHeader, l.maxBytes)
+	out.stemFn = func(day string) string {
+		if !headerMatches(filepath.Join(logDir, day+".csv")) {
+			return day + "_v3"
+		}
+		return day
+	}
 
 	ticker := time.NewTicker(flushPeriod)
 	defer ticker.Stop()
 
-	openFile := func(day string) {
-		if file != nil {
-			writer.Flush()
-			file.Close()
-		}
-
-		path := filepath.Join(logDir, day+".csv")
-		var err error
-		file, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			log.Printf("Logger: failed to open %s: %v", path, err)
-			return
-		}
-
-		writer = bufio.NewWriterSize(file, bufSize)
-
-		// Write header if new file
-		info, _ := file.Stat()
-		if info != nil && info.Size() == 0 {
-			fmt.Fprintln(writer,
-				"timestamp,price,final_score,"+
-					"score_1s,score_1m,score_5m,score_15m,score_1h,"+
-					"htf_bias,market_state,action_hint,"+
-					"delta_1s,cvd,ob_score,oi,oi_delta,"+
-					"behavior,event_flags")
-		}
-
-		currentDay = day
-		log.Printf("Logger: writing to %s", path)
-	}
-
 	for {
 		select {
 		case row, ok := <-l.ch:
 			if !ok {
 				// Channel closed — shutdown
-				if writer != nil {
-					writer.Flush()
-				}
-				if file != nil {
-					file.Close()
-				}
+				out.close()
 				return
 			}
 
-			// Daily rotation
 			day := time.UnixMilli(row.Timestamp).UTC().Format("2006-01-02")
-			if day != currentDay {
-				openFile(day)
-			}
-
-			if writer == nil {
+			if !out.prepare(day) {
 				continue
 			}
 
 			// Encode CSV row — fmt.Fprintf with fixed format, no allocations beyond buffer
-			fmt.Fprintf(writer,
-				"%d,%.2f,%.2f,%.2f,%.2f,%.2f,%.2f,%.2f,%s,%s,%s,%.6f,%.4f,%d,%.2f,%.4f,%d,%d\n",
+			fmt.Fprintf(out,
+				"%d,%.2f,%.2f,%.2f,%.2f,%.2f,%.2f,%.2f,%s,%s,%s,%.6f,%.4f,%d,%.2f,%.4f,%d,%d,"+
+					"%.2f,%.2f,%.2f,%.2f,%.6f,%.6f,%.2f,%.2f,%.4f,%d,%d,%.6f\n",
 				row.Timestamp,
 				row.Price,
 				row.FinalScore,
@@ -187,16 +190,42 @@ func (l *Logger) run() {
 				row.OIDelta,
 				row.Behavior,
 				row.EventFlags,
+				row.Open,
+				row.High,
+				row.Low,
+				row.Close,
+				row.BuyVol,
+				row.SellVol,
+				row.Score4h,
+				row.Score1d,
+				row.Imbalance,
+				row.BuyCount,
+				row.SellCount,
+				row.AvgSize,
 			)
 
 		case <-ticker.C:
-			if writer != nil {
-				writer.Flush()
-			}
+			out.flush()
 		}
 	}
 }
 
+// headerMatches — true if path doesn't exist yet, is empty, or starts with
+// the current csvHeader (safe to append).
+func headerMatches(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return true // empty file
+	}
+	return strings.TrimRight(line, "\r\n") == csvHeader
+}
+
 // ─── DECISION LAYER (Go-side, mirrors frontend logic) ───
 
 // ComputeHTFBias — weighted average of 1h, 4h, 1d scores.
@@ -273,16 +302,131 @@ func ComputeActionHint(htfBias string, finalScore float64, imbalance float64, be
 	return "NO_TRADE"
 }
 
+// ─── POSITION-AWARE HINTS (optional, -position-hints) ───
+//
+// With an open position the question is what to do with it, not whether to
+// look for an entry. For a long (a short mirrors it):
+//
+//   EXIT    hint WATCH_SHORT — HTF bias and LTF pressure both turned against
+//   REDUCE  hint WAIT_RALLY (HTF bias turned bearish), or NO_TRADE with
+//           finalScore < −10 (no bias, pressure against)
+//   ADD     hint WATCH_LONG, finalScore ≥ 25 and the position in profit —
+//           adds to winners only, never averages down
+//   HOLD    otherwise
+//
+// Flat, the stateless hint is kept. Published where the decision layer is
+// consumed, each with the position it knows:
+//
+//   CSV ActionHint          Snapshot.Position (user-data stream), in place
+//                           of the stateless hint
+//   MQTT position_action    the same, beside the stateless action
+//   /admin/execution        the executor's own book (on testnet, the paper
+//   position_hint           position); its orders still follow the
+//                           stateless hint
+
+// positionHints — set once at startup (SetPositionHints).
+var positionHints bool
+
+// SetPositionHints turns the position-aware hints on (see above). Call once
+// at startup.
+func SetPositionHints(on bool) {
+	positionHints = on
+}
+
+// ComputePositionHint — action for an open position of size (signed, + long)
+// entered at entry, at price; action is the stateless ComputeActionHint.
+func ComputePositionHint(action string, finalScore, size, entry, price float64) string {
+	if size == 0 {
+		return action
+	}
+	// Mirror a short into the long case
+	dir := 1.0
+	with, against, waitAgainst := "WATCH_LONG", "WATCH_SHORT", "WAIT_RALLY"
+	if size < 0 {
+		dir = -1
+		with, against, waitAgainst = "WATCH_SHORT", "WATCH_LONG", "WAIT_DIP"
+	}
+	score := finalScore * dir
+
+	switch {
+	case action == against:
+		return "EXIT"
+	case action == waitAgainst, action == "NO_TRADE" && score < -10:
+		return "REDUCE"
+	case action == with && score >= 25 && (price-entry)*dir > 0:
+		return "ADD"
+	}
+	return "HOLD"
+}
+
+// PositionHintFor — action (DecisionFor's) made position-aware for a
+// position of size entered at entry; "" while flat or without
+// SetPositionHints.
+func PositionHintFor(snap *model.Snapshot, action string, size, entry float64) string {
+	if !positionHints || size == 0 {
+		return ""
+	}
+	return ComputePositionHint(action, snap.FinalScore, size, entry, snap.Price)
+}
+
+// thinBookWiden — set once at startup (SetThinBookWiden).
+var thinBookWiden = 1.0
+
+// SetThinBookWiden widens the market-state and action-hint thresholds
+// (score and imbalance) by factor while the snapshot is flagged
+// QualityThinBook (1 = off). Call once at startup.
+func SetThinBookWiden(factor float64) {
+	thinBookWiden = factor
+}
+
+// DecisionFor — the stateless decision layer for a snapshot (HTF bias,
+// market state, action hint). Warmup and event risk do not change the hint:
+// they only suppress entries (EntriesSuppressed), so a position held through
+// a cold start or into an event window is still managed by it. On a thin
+// book the LTF thresholds are widened (SetThinBookWiden).
+func DecisionFor(snap *model.Snapshot) (htfBias, mktState, action string) {
+	// Looked up by bucket length — the HTF set is configurable (0 if absent)
+	htfBias = ComputeHTFBias(snap.HTFScore(3600), snap.HTFScore(14400), snap.HTFScore(86400))
+	// Widening every threshold by w = comparing score / w with the usual ones
+	score, imbalance := snap.FinalScore, float64(snap.Orderbook.Imbalance)
+	if snap.Quality.Flags&model.QualityThinBook != 0 && thinBookWiden > 1 {
+		score, imbalance = score/thinBookWiden, imbalance/thinBookWiden
+	}
+	mktState = ComputeMarketState(htfBias, score)
+	action = ComputeActionHint(htfBias, score, imbalance, snap.OI.Behavior)
+	return htfBias, mktState, action
+}
+
+// EntriesSuppressed — whether no position should be opened on snap: the
+// score or an HTF feeding the bias is still warming up after a cold start
+// (Snapshot.Warmup), or the snapshot is inside a scheduled event's window
+// (QualityEventRisk). Exits are not suppressed.
+func EntriesSuppressed(snap *model.Snapshot) bool {
+	return snap.Warmup.Warming(3600, 14400, 86400) || snap.Quality.Flags&model.QualityEventRisk != 0
+}
+
+// EntryHint — action (DecisionFor's) as published while flat: NO_TRADE
+// while entries are suppressed.
+func EntryHint(snap *model.Snapshot, action string) string {
+	if EntriesSuppressed(snap) {
+		return "NO_TRADE"
+	}
+	return action
+}
+
 // BuildLogRow — constructs a LogRow from a Snapshot.
 // Called in the engine goroutine (off hot-path), ~50ns.
 func BuildLogRow(snap *model.Snapshot, eventFlags uint32) LogRow {
-	score1h := snap.HTF[2].AvgScore  // idx 2 = 1h
-	score4h := snap.HTF[3].AvgScore  // idx 3 = 4h
-	score1d := snap.HTF[4].AvgScore  // idx 4 = 1d
-
-	htfBias := ComputeHTFBias(score1h, score4h, score1d)
-	mktState := ComputeMarketState(htfBias, snap.FinalScore)
-	action := ComputeActionHint(htfBias, snap.FinalScore, float64(snap.Orderbook.Imbalance), snap.OI.Behavior)
+	score1h := snap.HTFScore(3600)
+	score4h := snap.HTFScore(14400)
+	score1d := snap.HTFScore(86400)
+
+	htfBias, mktState, action := DecisionFor(snap)
+	if hint := PositionHintFor(snap, action, snap.Position.Size, snap.Position.EntryPrice); hint != "" {
+		action = hint
+	} else {
+		action = EntryHint(snap, action)
+	}
 
 	return LogRow{
 		Timestamp:   snap.Time,
@@ -290,8 +434,8 @@ func BuildLogRow(snap *model.Snapshot, eventFlags uint32) LogRow {
 		FinalScore:  snap.FinalScore,
 		Score1s:     snap.FinalScore,
 		Score1m:     snap.Candle1m.AvgScore,
-		Score5m:     snap.HTF[0].AvgScore,
-		Score15m:    snap.HTF[1].AvgScore,
+		Score5m:     snap.HTFScore(300),
+		Score15m:    snap.HTFScore(900),
 		Score1h:     score1h,
 		HTFBias:     htfBias,
 		MarketState: mktState,
@@ -303,5 +447,17 @@ func BuildLogRow(snap *model.Snapshot, eventFlags uint32) LogRow {
 		OIDelta:     snap.OI.OIDelta1m,
 		Behavior:    snap.OI.Behavior,
 		EventFlags:  eventFlags,
+		Open:        snap.Candle1s.Open,
+		High:        snap.Candle1s.High,
+		Low:         snap.Candle1s.Low,
+		Close:       snap.Candle1s.Close,
+		BuyVol:      snap.Candle1s.BuyVol,
+		SellVol:     snap.Candle1s.SellVol,
+		Score4h:     score4h,
+		Score1d:     score1d,
+		Imbalance:   snap.Orderbook.Imbalance,
+		BuyCount:    snap.Candle1s.BuyCount,
+		SellCount:   snap.Candle1s.SellCount,
+		AvgSize:     snap.Candle1s.AvgSize,
 	}
 }
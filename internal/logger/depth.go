@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"market-indikator/internal/orderbook"
+)
+
+// =============================================================================
+// DEPTH SNAPSHOT LOGGER — top-of-book levels at a fixed cadence
+// =============================================================================
+//
+// Samples book.GetDepth() (lock-free copy) every `every` and writes the top
+// `levels` bids and asks to logs/depth/YYYY-MM-DD.csv. Runs on its own
+// ticker, so the depth stream and engine never wait on disk.
+//
+// CSV schema (1 + 4×levels columns):
+//   timestamp,bid_px_1,bid_qty_1,…,bid_px_N,bid_qty_N,ask_px_1,ask_qty_1,…
+// Missing levels (thin book) are written as empty fields.
+// =============================================================================
+
+const depthDir = "depth"
+
+// DepthLogger — periodic order book sampler.
+type DepthLogger struct {
+	book     *orderbook.Book
+	every    time.Duration
+	levels   int
+	maxBytes int64
+}
+
+// NewDepthLogger — levels is capped at orderbook.MaxDepthLevels.
+// maxBytes > 0 additionally rolls the day's file over at that size.
+func NewDepthLogger(book *orderbook.Book, every time.Duration, levels int, maxBytes int64) *DepthLogger {
+	if levels > orderbook.MaxDepthLevels {
+		levels = orderbook.MaxDepthLevels
+	}
+	if levels < 1 {
+		levels = 1
+	}
+	return &DepthLogger{book: book, every: every, levels: levels, maxBytes: maxBytes}
+}
+
+func (l *DepthLogger) Start(ctx context.Context) {
+	go l.run(ctx)
+}
+
+func (l *DepthLogger) header() string {
+	var sb strings.Builder
+	sb.WriteString("timestamp")
+	for _, side := range []string{"bid", "ask"} {
+		for i := 1; i <= l.levels; i++ {
+			fmt.Fprintf(&sb, ",%s_px_%d,%s_qty_%d", side, i, side, i)
+		}
+	}
+	return sb.String()
+}
+
+func (l *DepthLogger) run(ctx context.Context) {
+	dir := filepath.Join(logDir, depthDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("DepthLogger: failed to create dir: %v", err)
+		return
+	}
+
+	out := newRotatingFile("DepthLogger", dir, l.header(), l.maxBytes)
+	defer out.close()
+	var lastTime int64
+	log.Printf("DepthLogger: sampling every %v (%d levels)", l.every, l.levels)
+
+	ticker := time.NewTicker(l.every)
+	defer ticker.Stop()
+	flush := time.NewTicker(flushPeriod)
+	defer flush.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-flush.C:
+			out.flush()
+		case <-ticker.C:
+			d := l.book.GetDepth()
+			if d.Time == 0 || d.Time == lastTime {
+				continue // no update since the last sample
+			}
+			lastTime = d.Time
+
+			day := time.UnixMilli(d.Time).UTC().Format("2006-01-02")
+			if !out.prepare(day) {
+				continue
+			}
+
+			fmt.Fprintf(out, "%d", d.Time)
+			writeLevels(out, d.Bids[:d.BidN], l.levels)
+			writeLevels(out, d.Asks[:d.AskN], l.levels)
+			out.Write([]byte{'\n'})
+		}
+	}
+}
+
+func writeLevels(w io.Writer, lv []orderbook.PriceLevel, n int) {
+	for i := 0; i < n; i++ {
+		if i < len(lv) {
+			fmt.Fprintf(w, ",%.2f,%.4f", lv[i].Price, lv[i].Quantity)
+		} else {
+			io.WriteString(w, ",,")
+		}
+	}
+}
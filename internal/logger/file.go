@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// =============================================================================
+// ROTATING CSV FILE — daily + size-based rotation shared by all loggers
+// =============================================================================
+//
+// Files are named <stem>.csv, then <stem>_001.csv, <stem>_002.csv, … once the
+// current part exceeds maxBytes, where stem is normally the UTC day
+// (YYYY-MM-DD). The names sort chronologically, which the CSV loader and the
+// retention janitor rely on, and every part starts with the header.
+//
+// On restart the highest existing part for the day is resumed, so a restart
+// never appends to a part that was already rolled over.
+// =============================================================================
+
+type rotatingFile struct {
+	name     string // logger name for log messages
+	dir      string
+	header   string
+	maxBytes int64                   // 0 = daily rotation only
+	stemFn   func(day string) string // optional, maps day → file stem
+
+	day  string
+	stem string
+	part int
+	size int64
+
+	file   *os.File
+	writer *bufio.Writer
+}
+
+func newRotatingFile(name, dir, header string, maxBytes int64) *rotatingFile {
+	return &rotatingFile{name: name, dir: dir, header: header, maxBytes: maxBytes}
+}
+
+// prepare — makes sure a file for `day` with room left is open.
+// Returns false if no file could be opened (row should be skipped).
+func (r *rotatingFile) prepare(day string) bool {
+	if day != r.day {
+		r.close()
+		r.day = day
+		r.stem = day
+		if r.stemFn != nil {
+			r.stem = r.stemFn(day)
+		}
+		r.part = r.lastPart()
+		r.open()
+	} else if r.maxBytes > 0 && r.size >= r.maxBytes {
+		r.close()
+		r.part++
+		r.open()
+	}
+	return r.writer != nil
+}
+
+func (r *rotatingFile) path(part int) string {
+	if part == 0 {
+		return filepath.Join(r.dir, r.stem+".csv")
+	}
+	return filepath.Join(r.dir, fmt.Sprintf("%s_%03d.csv", r.stem, part))
+}
+
+// lastPart — highest existing part number for the current stem (0 if none).
+func (r *rotatingFile) lastPart() int {
+	part := 0
+	for {
+		if _, err := os.Stat(r.path(part + 1)); err != nil {
+			return part
+		}
+		part++
+	}
+}
+
+func (r *rotatingFile) open() {
+	for {
+		path := r.path(r.part)
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Printf("%s: failed to open %s: %v", r.name, path, err)
+			return
+		}
+
+		info, _ := file.Stat()
+		r.size = 0
+		if info != nil {
+			r.size = info.Size()
+		}
+		if r.maxBytes > 0 && r.size >= r.maxBytes {
+			// Resumed part is already full — move on
+			file.Close()
+			r.part++
+			continue
+		}
+
+		r.file = file
+		r.writer = bufio.NewWriterSize(file, bufSize)
+		if r.size == 0 {
+			fmt.Fprintln(r, r.header)
+		}
+		log.Printf("%s: writing to %s", r.name, path)
+		return
+	}
+}
+
+// Write — io.Writer, counts bytes toward the size limit.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	n, err := r.writer.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) flush() {
+	if r.writer != nil {
+		r.writer.Flush()
+	}
+}
+
+func (r *rotatingFile) close() {
+	if r.file != nil {
+		r.writer.Flush()
+		r.file.Close()
+	}
+	r.file, r.writer = nil, nil
+}
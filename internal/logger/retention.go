@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// LOG RETENTION — compress finished days, delete expired ones
+// =============================================================================
+//
+// Every sweepPeriod (and once at start) the janitor walks logs/, logs/trades/,
+// logs/depth/, logs/signals/ and logs/transitions/ and, for files whose name
+// starts with a YYYY-MM-DD date:
+//
+//   • date < today (UTC) and *.csv  → gzip to *.csv.gz, remove the original
+//   • date older than keepDays      → delete (.csv and .csv.gz)
+//
+// With an Uploader attached, every finished day's file (after compression) is
+// uploaded, and expired files are kept until their upload has succeeded.
+//
+// Today's file is never touched — the writers still have it open. Compression
+// runs in the janitor goroutine with gzip.BestSpeed: a day of snapshot CSV
+// (~10MB) takes well under a second and shrinks ~5x.
+// =============================================================================
+
+const sweepPeriod = 1 * time.Hour
+
+// Retention — background log janitor.
+type Retention struct {
+	mu       sync.Mutex
+	compress bool
+	keepDays int // 0 = keep forever
+	dirs     []string
+	uploader *Uploader // optional
+}
+
+// NewRetention — compress finished days if compress, delete files older than
+// keepDays (0 = never delete).
+func NewRetention(compress bool, keepDays int) *Retention {
+	return &Retention{
+		compress: compress,
+		keepDays: keepDays,
+		dirs: []string{
+			logDir,
+			filepath.Join(logDir, tradeDir),
+			filepath.Join(logDir, depthDir),
+			filepath.Join(logDir, signalDir),
+			filepath.Join(logDir, transitionDir),
+		},
+	}
+}
+
+// SetPolicy changes compression and expiry (config reload); the next hourly
+// sweep applies them. Safe from any goroutine.
+func (r *Retention) SetPolicy(compress bool, keepDays int) {
+	r.mu.Lock()
+	r.compress, r.keepDays = compress, keepDays
+	r.mu.Unlock()
+}
+
+// SetUploader — ship finished files to object storage. Must be called before Start.
+func (r *Retention) SetUploader(u *Uploader) {
+	r.uploader = u
+}
+
+func (r *Retention) Start(ctx context.Context) {
+	go r.loop(ctx)
+}
+
+func (r *Retention) loop(ctx context.Context) {
+	r.sweep(ctx, time.Now().UTC())
+
+	ticker := time.NewTicker(sweepPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.sweep(ctx, now.UTC())
+		}
+	}
+}
+
+func (r *Retention) sweep(ctx context.Context, now time.Time) {
+	r.mu.Lock()
+	compress, keepDays := r.compress, r.keepDays
+	r.mu.Unlock()
+
+	today := now.Format("2006-01-02")
+	cutoff := ""
+	if keepDays > 0 {
+		cutoff = now.AddDate(0, 0, -keepDays).Format("2006-01-02")
+	}
+
+	for _, dir := range r.dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue // dir not created (feature off)
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || len(name) < 10 {
+				continue
+			}
+			day := name[:10]
+			if _, err := time.Parse("2006-01-02", day); err != nil {
+				continue
+			}
+			path := filepath.Join(dir, name)
+
+			if cutoff != "" && day < cutoff {
+				if !r.upload(ctx, path) {
+					continue // keep until it's safely stored remotely
+				}
+				if err := os.Remove(path); err != nil {
+					log.Printf("Retention: failed to delete %s: %v", path, err)
+				} else {
+					log.Printf("Retention: deleted %s", path)
+				}
+				continue
+			}
+			if day >= today {
+				continue
+			}
+
+			if compress && strings.HasSuffix(name, ".csv") {
+				if err := gzipFile(path); err != nil {
+					log.Printf("Retention: failed to compress %s: %v", path, err)
+					continue
+				}
+				log.Printf("Retention: compressed %s", path)
+				path += ".gz"
+			}
+			r.upload(ctx, path)
+		}
+	}
+}
+
+// upload — true if there is no uploader or path is (now) uploaded.
+func (r *Retention) upload(ctx context.Context, path string) bool {
+	if r.uploader == nil {
+		return true
+	}
+	rel, err := filepath.Rel(logDir, path)
+	if err != nil {
+		return false
+	}
+	if err := r.uploader.Upload(ctx, rel); err != nil {
+		log.Printf("Retention: %v", err)
+		return false
+	}
+	return true
+}
+
+// gzipFile — path → path.gz, then removes path. The .gz is written to a temp
+// name first so a crash never leaves a truncated archive next to a deleted CSV.
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := path + ".gz.tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	zw, _ := gzip.NewWriterLevel(out, gzip.BestSpeed)
+	zw.Name = filepath.Base(path)
+
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"market-indikator/internal/model"
+)
+
+// ─── SIGNAL LOG ───
+//
+// logs/signals/YYYY-MM-DD.csv, one row per signal state change (see
+// internal/signals):
+//   time,id,side,state,reason,armed_at,triggered_at,closed_at,
+//   arm_price,entry_price,exit_price,score,return_bps
+
+const signalDir = "signals"
+
+const signalHeader = "time,id,side,state,reason,armed_at,triggered_at,closed_at," +
+	"arm_price,entry_price,exit_price,score,return_bps"
+
+// NewSignalLogger — writes (and logs) signal state changes from ch,
+// typically a bus.Signals subscription, until it closes.
+func NewSignalLogger(ch <-chan model.Signal, maxBytes int64) {
+	go func() {
+		dir := filepath.Join(logDir, signalDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Printf("SignalLogger: failed to create dir: %v", err)
+			return
+		}
+		out := newRotatingFile("SignalLogger", dir, signalHeader, maxBytes)
+		defer out.close()
+
+		for s := range ch {
+			at, price := s.Last()
+			log.Printf("Signal %d %s %s: %s (score %.1f @ %.2f)",
+				s.ID, s.SideName(), s.StateName(), s.Reason, s.Score, price)
+
+			day := time.UnixMilli(at).UTC().Format("2006-01-02")
+			if !out.prepare(day) {
+				continue
+			}
+			fmt.Fprintf(out, "%d,%d,%s,%s,%s,%d,%d,%d,%.2f,%.2f,%.2f,%.2f,%.1f\n",
+				at, s.ID, s.SideName(), s.StateName(), s.Reason, s.ArmedAt, s.TriggeredAt, s.ClosedAt,
+				s.ArmPrice, s.EntryPrice, s.ExitPrice, s.Score, s.ReturnBps)
+			out.flush() // a few rows an hour: write through
+		}
+	}()
+}
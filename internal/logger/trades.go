@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"fmt"
+	"log"
+	"market-indikator/internal/model"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// =============================================================================
+// TIME & SALES LOGGER — every aggTrade, unaggregated
+// =============================================================================
+//
+// Architecture:
+//   bus → own subscriber channel → TradeLogger goroutine → daily CSV
+//
+// Same guarantees as the snapshot logger (non-blocking, 1MB bufio, 1s flush),
+// but fed directly from the trade bus so it sees every trade, not just the
+// once-per-second snapshot. Files: logs/trades/YYYY-MM-DD.csv
+//
+// CSV schema (5 columns):
+//   agg_id,time,price,qty,side       side = BUY / SELL (taker side)
+// =============================================================================
+
+const tradeDir = "trades"
+
+// TradeLogger — async time & sales writer.
+type TradeLogger struct {
+	ch       <-chan model.Trade
+	maxBytes int64
+}
+
+// NewTradeLogger — starts writing trades from ch (typically a bus subscription).
+// maxBytes > 0 additionally rolls the day's file over at that size.
+func NewTradeLogger(ch <-chan model.Trade, maxBytes int64) *TradeLogger {
+	l := &TradeLogger{ch: ch, maxBytes: maxBytes}
+	go l.run()
+	return l
+}
+
+func (l *TradeLogger) run() {
+	dir := filepath.Join(logDir, tradeDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("TradeLogger: failed to create dir: %v", err)
+		return
+	}
+
+	out := newRotatingFile("TradeLogger", dir, "agg_id,time,price,qty,side", l.maxBytes)
+
+	ticker := time.NewTicker(flushPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case t, ok := <-l.ch:
+			if !ok {
+				out.close()
+				return
+			}
+
+			day := time.UnixMilli(t.Time).UTC().Format("2006-01-02")
+			if !out.prepare(day) {
+				continue
+			}
+
+			side := "SELL"
+			if t.AggressiveBuy() {
+				side = "BUY"
+			}
+			fmt.Fprintf(out, "%d,%d,%.2f,%.6f,%s\n", t.ID, t.Time, t.Price, t.Quantity, side)
+
+		case <-ticker.C:
+			out.flush()
+		}
+	}
+}
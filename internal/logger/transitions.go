@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"market-indikator/internal/model"
+)
+
+// =============================================================================
+// DECISION TRANSITIONS — HTF bias flips and market-state changes
+// =============================================================================
+//
+// The decision layer (DecisionFor) is stateless and re-evaluated on every
+// snapshot; what a trader wants to hear about is the moment a value
+// changes. The tracker follows HTF bias and market state and confirms a new
+// value once it has held for `hold`:
+//
+//   candidate  a value differing from the confirmed one, with the time it
+//              was first seen (reset whenever the value goes back)
+//   confirmed  snapshot time − first seen ≥ hold
+//   Prior      first seen(new) − first seen(old) — how long the old value
+//              held
+//
+// MarketState follows finalScore across ±15, which can flap several times a
+// second at the threshold; the hold keeps those out. Each confirmed
+// transition is returned to the caller (published on the transitions bus)
+// and sets EventBiasFlip / EventStateChange on the snapshots of the second
+// it is confirmed in. The first values after start are a baseline, not a
+// transition.
+//
+// The tracker also follows OI behavior (confirmed the same way, never
+// published) and stamps every snapshot with how long each confirmed value
+// has held (Snapshot.States): BULLISH for four hours and BULLISH for forty
+// seconds deserve different confidence. The durations count from process
+// start.
+//
+// Engine goroutine only.
+// =============================================================================
+
+// DefaultTransitionHold — how long a new bias / state must hold.
+const DefaultTransitionHold = 5 * time.Second
+
+type decisionTrack struct {
+	value     string
+	since     int64 // unix ms the value was first seen
+	cand      string
+	candSince int64
+}
+
+// step feeds the current value; ok reports a confirmed transition.
+func (d *decisionTrack) step(value string, nowMs, holdMs int64) (tr model.Transition, ok bool) {
+	switch {
+	case d.value == "":
+		d.value, d.since = value, nowMs
+		return tr, false
+	case value == d.value:
+		d.cand = ""
+		return tr, false
+	case value != d.cand:
+		d.cand, d.candSince = value, nowMs
+	}
+	if nowMs-d.candSince < holdMs {
+		return tr, false
+	}
+	tr = model.Transition{From: d.value, To: value, Time: d.candSince, Prior: d.candSince - d.since}
+	d.value, d.since, d.cand = value, d.candSince, ""
+	return tr, true
+}
+
+// held — whole seconds the confirmed value has held at nowMs.
+func (d *decisionTrack) held(nowMs int64) int64 {
+	if d.value == "" {
+		return 0
+	}
+	return max(nowMs-d.since, 0) / 1000
+}
+
+// TransitionTracker — see above.
+type TransitionTracker struct {
+	holdMs  int64
+	bias    decisionTrack
+	state   decisionTrack
+	oi      decisionTrack // OI behavior, durations only
+	flags   int           // transition event flags of flagSec
+	flagSec int64         // unix seconds
+}
+
+// NewTransitionTracker — hold ≤ 0 confirms every change at once.
+func NewTransitionTracker(hold time.Duration) *TransitionTracker {
+	return &TransitionTracker{holdMs: hold.Milliseconds()}
+}
+
+// SetHold changes the hold (config reload); a candidate already pending is
+// confirmed against the new one. Same goroutine as Observe.
+func (t *TransitionTracker) SetHold(hold time.Duration) {
+	t.holdMs = hold.Milliseconds()
+}
+
+// Observe feeds a snapshot, ORs the transition flags into snap.Flow.Events,
+// sets snap.States and returns the transitions it confirmed (out[:n], at
+// most one per kind).
+func (t *TransitionTracker) Observe(snap *model.Snapshot) (out [2]model.Transition, n int) {
+	bias, state, _ := DecisionFor(snap)
+	if sec := snap.Time / 1000; sec != t.flagSec {
+		t.flags, t.flagSec = 0, sec
+	}
+	if tr, ok := t.bias.step(bias, snap.Time, t.holdMs); ok {
+		tr.Kind, tr.Price = model.TransitionBias, snap.Price
+		out[n], n = tr, n+1
+		t.flags |= model.EventBiasFlip
+	}
+	if tr, ok := t.state.step(state, snap.Time, t.holdMs); ok {
+		tr.Kind, tr.Price = model.TransitionState, snap.Price
+		out[n], n = tr, n+1
+		t.flags |= model.EventStateChange
+	}
+	t.oi.step(strconv.Itoa(snap.OI.Behavior), snap.Time, t.holdMs)
+	snap.Flow.Events |= t.flags
+	snap.States = model.StatesSnapshot{
+		BiasSec:       t.bias.held(snap.Time),
+		StateSec:      t.state.held(snap.Time),
+		OIBehaviorSec: t.oi.held(snap.Time),
+	}
+	return out, n
+}
+
+// ─── TRANSITION LOG ───
+//
+// logs/transitions/YYYY-MM-DD.csv, one row per confirmed transition:
+//   time,kind,from,to,prior_sec,price
+
+const transitionDir = "transitions"
+
+// NewTransitionLogger — writes (and logs) transitions from ch, typically a
+// bus.Transitions subscription, until it closes.
+func NewTransitionLogger(ch <-chan model.Transition, maxBytes int64) {
+	go func() {
+		dir := filepath.Join(logDir, transitionDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Printf("TransitionLogger: failed to create dir: %v", err)
+			return
+		}
+		out := newRotatingFile("TransitionLogger", dir, "time,kind,from,to,prior_sec,price", maxBytes)
+		defer out.close()
+
+		for tr := range ch {
+			prior := time.Duration(tr.Prior) * time.Millisecond
+			log.Printf("Transition %s: %s → %s after %v @ %.2f",
+				tr.KindName(), tr.From, tr.To, prior.Round(time.Second), tr.Price)
+
+			day := time.UnixMilli(tr.Time).UTC().Format("2006-01-02")
+			if !out.prepare(day) {
+				continue
+			}
+			fmt.Fprintf(out, "%d,%s,%s,%s,%d,%.2f\n",
+				tr.Time, tr.KindName(), tr.From, tr.To, tr.Prior/1000, tr.Price)
+			out.flush() // a few rows an hour: write through
+		}
+	}()
+}
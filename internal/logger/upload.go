@@ -0,0 +1,209 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// LOG UPLOADER — finished log files to S3 / GCS
+// =============================================================================
+//
+// Plain HTTP PUT with AWS Signature V4, no SDK. Works against:
+//   • AWS S3                    endpoint https://s3.<region>.amazonaws.com
+//   • Google Cloud Storage      endpoint https://storage.googleapis.com
+//                               (S3-interoperable API, HMAC keys, region "auto")
+//   • MinIO / R2 / other S3-compatibles
+//
+// Objects use path-style URLs: <endpoint>/<bucket>/<prefix><relative path>,
+// where the relative path is taken from logs/ (e.g. "trades/2026-02-18.csv.gz").
+//
+// The retention janitor calls Upload for every finished day's file; uploaded
+// paths are recorded in logs/state/uploaded.txt so restarts don't re-upload,
+// and expired files are only deleted once they have been uploaded.
+// =============================================================================
+
+const (
+	uploadTimeout = 5 * time.Minute
+	manifestFile  = "state/uploaded.txt"
+)
+
+// UploadConfig — destination bucket and credentials.
+type UploadConfig struct {
+	Endpoint  string // e.g. https://s3.eu-west-1.amazonaws.com
+	Region    string // e.g. eu-west-1 ("auto" for GCS)
+	Bucket    string
+	Prefix    string // object key prefix, e.g. "orderflow/"
+	AccessKey string
+	SecretKey string
+}
+
+// Uploader — SigV4 PUT client with an on-disk manifest of uploaded files.
+type Uploader struct {
+	cfg    UploadConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	uploaded map[string]bool
+}
+
+func NewUploader(cfg UploadConfig) *Uploader {
+	u := &Uploader{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: uploadTimeout},
+		uploaded: make(map[string]bool),
+	}
+	u.cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+
+	if f, err := os.Open(filepath.Join(logDir, manifestFile)); err == nil {
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			u.uploaded[sc.Text()] = true
+		}
+		f.Close()
+	}
+	return u
+}
+
+// IsUploaded — true if path (relative to logs/) was uploaded before.
+func (u *Uploader) IsUploaded(rel string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.uploaded[rel]
+}
+
+// Upload — PUTs logs/<rel> to the bucket and records it in the manifest.
+func (u *Uploader) Upload(ctx context.Context, rel string) error {
+	if u.IsUploaded(rel) {
+		return nil
+	}
+	path := filepath.Join(logDir, rel)
+
+	// Payload hash is part of the signature — one pass to hash, one to send.
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := u.cfg.Prefix + filepath.ToSlash(rel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut,
+		u.cfg.Endpoint+"/"+u.cfg.Bucket+"/"+uriEncode(key, false), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	u.sign(req, hex.EncodeToString(h.Sum(nil)), time.Now().UTC())
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("upload %s: HTTP %d: %s", key, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	u.record(rel)
+	log.Printf("Uploader: %s → %s/%s (%d bytes)", rel, u.cfg.Bucket, key, size)
+	return nil
+}
+
+func (u *Uploader) record(rel string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.uploaded[rel] = true
+
+	path := filepath.Join(logDir, manifestFile)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Uploader: failed to update manifest: %v", err)
+		return
+	}
+	fmt.Fprintln(f, rel)
+	f.Close()
+}
+
+// sign — adds AWS Signature Version 4 headers for the S3 service.
+func (u *Uploader) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"", // no query string
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + payloadHash + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + u.cfg.Region + "/s3/aws4_request"
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	k := hmacSHA256([]byte("AWS4"+u.cfg.SecretKey), date)
+	k = hmacSHA256(k, u.cfg.Region)
+	k = hmacSHA256(k, "s3")
+	k = hmacSHA256(k, "aws4_request")
+	sig := hex.EncodeToString(hmacSHA256(k, toSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+u.cfg.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+sig)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
+
+// uriEncode — RFC 3986 encoding as SigV4 expects: everything except
+// unreserved characters is percent-encoded; '/' is kept unless encodeSlash.
+func uriEncode(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			sb.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(hexDigits[c>>4])
+			sb.WriteByte(hexDigits[c&15])
+		}
+	}
+	return sb.String()
+}
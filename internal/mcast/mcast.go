@@ -0,0 +1,130 @@
+package mcast
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net"
+	"sync/atomic"
+
+	"market-indikator/internal/model"
+)
+
+// =============================================================================
+// UDP MULTICAST FEED — snapshots for co-located consumers
+// =============================================================================
+//
+// Within a rack a lost datagram costs less than TCP's head-of-line blocking:
+// every snapshot is sent to a multicast group as one or more fixed-size
+// datagrams (-mcast-size bytes, zero-padded), each with a 16-byte header:
+//
+//   0   "MI"           magic
+//   2   version        1
+//   3   parts          datagrams carrying this snapshot (1..255)
+//   4   seq            uint64 BE, +1 per snapshot from 1
+//   12  part           0..parts−1
+//   13  reserved       0
+//   14  length         uint16 BE, payload bytes in this datagram
+//   16  payload        the next `length` bytes of the snapshot's MsgPack
+//
+// The concatenated payloads of parts 0..parts−1 are byte-identical to a full
+// /ws live frame (model.Snapshot.AppendMsgPack). A consumer reassembles by
+// seq and drops a snapshot with a missing part; a gap in seq is a loss.
+//
+// The TTL is the OS default for multicast (1: the local segment); the route
+// for the group picks the interface (e.g. ip route add 239.0.0.0/8 dev eth1).
+// =============================================================================
+
+const (
+	headerLen   = 16
+	version     = 1
+	DefaultSize = 1400 // fits a 1500-byte Ethernet MTU with IP/UDP headers
+	minSize     = 256
+	maxSize     = 65507 // largest UDP payload over IPv4
+)
+
+// Sender — see above.
+type Sender struct {
+	conn *net.UDPConn
+	size int
+	seq  uint64
+
+	sent   int64 // atomic — datagrams sent
+	errors int64 // atomic — failed writes
+	skips  int64 // atomic — snapshots too large for 255 parts
+}
+
+// NewSender — group is "239.1.1.1:5007" (an IPv4 or IPv6 multicast
+// address); size is the fixed datagram size.
+func NewSender(group string, size int) (*Sender, error) {
+	if size < minSize || size > maxSize {
+		return nil, fmt.Errorf("mcast datagram size %d: want %d..%d", size, minSize, maxSize)
+	}
+	addr, err := net.ResolveUDPAddr("udp", group)
+	if err != nil {
+		return nil, err
+	}
+	if !addr.IP.IsMulticast() {
+		return nil, fmt.Errorf("mcast group %s: not a multicast address", addr.IP)
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Multicast feed → %s (%d-byte datagrams)", addr, size)
+	return &Sender{conn: conn, size: size}, nil
+}
+
+// Run sends each snapshot until snaps closes, then closes the socket.
+func (s *Sender) Run(snaps <-chan model.Snapshot) {
+	defer s.conn.Close()
+	payload := make([]byte, 0, 4096)
+	dgram := make([]byte, s.size)
+	for snap := range snaps {
+		payload = snap.AppendMsgPack(payload[:0])
+		s.send(payload, dgram)
+	}
+}
+
+// send splits one encoded snapshot into datagrams.
+func (s *Sender) send(payload, dgram []byte) {
+	chunk := s.size - headerLen
+	parts := (len(payload) + chunk - 1) / chunk
+	if parts > 255 {
+		atomic.AddInt64(&s.skips, 1)
+		return
+	}
+	s.seq++
+	for part := 0; part < parts; part++ {
+		body := payload[part*chunk : min(len(payload), (part+1)*chunk)]
+		dgram[0], dgram[1], dgram[2], dgram[3] = 'M', 'I', version, byte(parts)
+		binary.BigEndian.PutUint64(dgram[4:], s.seq)
+		dgram[12], dgram[13] = byte(part), 0
+		binary.BigEndian.PutUint16(dgram[14:], uint16(len(body)))
+		n := copy(dgram[headerLen:], body)
+		clear(dgram[headerLen+n:])
+		if _, err := s.conn.Write(dgram); err != nil {
+			if atomic.AddInt64(&s.errors, 1) == 1 {
+				log.Printf("Multicast write error: %v", err) // first only; see /admin/stats
+			}
+			return
+		}
+		atomic.AddInt64(&s.sent, 1)
+	}
+}
+
+// Sent returns the datagrams sent since start.
+func (s *Sender) Sent() int64 {
+	return atomic.LoadInt64(&s.sent)
+}
+
+// Errors returns the failed datagram writes (the rest of that snapshot is
+// not sent).
+func (s *Sender) Errors() int64 {
+	return atomic.LoadInt64(&s.errors)
+}
+
+// Skips returns the snapshots too large to send at this datagram size.
+func (s *Sender) Skips() int64 {
+	return atomic.LoadInt64(&s.skips)
+}
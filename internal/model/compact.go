@@ -0,0 +1,127 @@
+package model
+
+import (
+	"fmt"
+	"math"
+)
+
+// =============================================================================
+// COMPACT WIRE ENCODING — float32 values, scaled-integer prices
+// =============================================================================
+//
+// The full encoding sends every number as a MsgPack float64 or int64: 9
+// bytes each, most of a ~1.7 KB snapshot. A client can ask for the compact
+// encoding instead at connect time (/ws?encoding=compact); the layout is the
+// same, only the numbers shrink:
+//
+//   prices   int, price × PriceScale rounded (candle OHLC, bid/ask/spread,
+//            session levels, VWAP bands, profile levels, EMA ribbon, …)
+//   other    float32 (scores, volumes, CVD, OI, ratios)
+//   ints     the smallest MsgPack int that holds them
+//
+// which is roughly half the size. The descriptor tells the client the
+// encoding and the scale ("encoding", "price_scale"); prices are divided by
+// the scale on decode. float32 keeps ~7 significant digits: exact enough for
+// a chart, not for accounting (CVD or OI notional at 1e9 lose units).
+//
+// Compact clients get full frames only: delta frames (delta.go) are
+// float64 diffs of the full encoding.
+//
+// TRADING INTERPRETATION: the price scale must resolve the tick size —
+// -wire-price-decimals 2 is right for BTC and ETH, not for a coin trading at
+// 0.1234 (use 4 or more there).
+// =============================================================================
+
+// Encoding — a client's snapshot wire encoding.
+type Encoding uint8
+
+const (
+	EncodingFull    Encoding = iota // float64 / int64 everywhere (default)
+	EncodingCompact                 // float32, scaled-integer prices
+)
+
+// ParseEncoding parses a client's ?encoding= ("" = full).
+func ParseEncoding(s string) (Encoding, error) {
+	switch s {
+	case "", "full":
+		return EncodingFull, nil
+	case "compact":
+		return EncodingCompact, nil
+	}
+	return EncodingFull, fmt.Errorf("encoding %q: want full or compact", s)
+}
+
+func (e Encoding) String() string {
+	if e == EncodingCompact {
+		return "compact"
+	}
+	return "full"
+}
+
+// DefaultPriceDecimals — cent resolution.
+const DefaultPriceDecimals = 2
+
+// PriceScale — compact prices are price × PriceScale. Set once at startup
+// (SetPriceDecimals), before any snapshot is encoded.
+var PriceScale float64 = 100
+
+// SetPriceDecimals sets PriceScale to 10^n (0 ≤ n ≤ 8).
+func SetPriceDecimals(n int) error {
+	if n < 0 || n > 8 {
+		return fmt.Errorf("price decimals %d: want 0..8", n)
+	}
+	PriceScale = math.Pow10(n)
+	return nil
+}
+
+// AppendEncoded appends the snapshot in the given encoding.
+func (s *Snapshot) AppendEncoded(b []byte, e Encoding) []byte {
+	return s.appendWire(b, wire{compact: e == EncodingCompact})
+}
+
+// wire — the encoding threaded through the append helpers: f for values, px
+// for prices, i for integers.
+type wire struct {
+	compact bool
+}
+
+func (w wire) f(b []byte, v float64) []byte {
+	if w.compact {
+		return appendFloat32(b, v)
+	}
+	return appendFloat64(b, v)
+}
+
+func (w wire) px(b []byte, v float64) []byte {
+	if w.compact {
+		return appendIntTight(b, int64(math.Round(v*PriceScale)))
+	}
+	return appendFloat64(b, v)
+}
+
+func (w wire) i(b []byte, v int64) []byte {
+	if w.compact {
+		return appendIntTight(b, v)
+	}
+	return appendInt64(b, v)
+}
+
+func appendFloat32(b []byte, v float64) []byte {
+	bits := math.Float32bits(float32(v))
+	return append(b, 0xca, byte(bits>>24), byte(bits>>16), byte(bits>>8), byte(bits))
+}
+
+// appendIntTight — the smallest MsgPack encoding of v.
+func appendIntTight(b []byte, v int64) []byte {
+	switch {
+	case v >= 0 && v <= 127, v < 0 && v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return append(b, 0xd1, byte(v>>8), byte(v))
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return append(b, 0xd2, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+	}
+	return appendInt64(b, v)
+}
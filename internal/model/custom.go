@@ -0,0 +1,20 @@
+package model
+
+// MaxCustomFields bounds the scalars contributed by custom analyzers
+// (internal/analyzer).
+const MaxCustomFields = 16
+
+// Active analyzer field names, "<analyzer>.<field>" (Snapshot.Custom order).
+// Set once at startup with SetCustomFields; read-only afterwards. None by
+// default.
+var (
+	CustomFields []string
+	NumCustom    = 0
+)
+
+// SetCustomFields replaces the analyzer field names (at most
+// MaxCustomFields, each at most 31 bytes).
+func SetCustomFields(names []string) {
+	CustomFields = names
+	NumCustom = len(names)
+}
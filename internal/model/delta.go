@@ -0,0 +1,311 @@
+package model
+
+import (
+	"math"
+)
+
+// =============================================================================
+// DELTA-ENCODED SNAPSHOT FRAMES
+// =============================================================================
+//
+// Between keyframes, a snapshot is sent as the set of scalars that changed
+// since the previous frame the client received.
+//
+// The snapshot is flattened into FlatLen scalars in wire order:
+//   [0]      price
+//   [1]      cvd
+//   [2]      time
+//   [3..17]  candle1s  (time, o, h, l, c, buyVol, sellVol, delta, avgScore,
+//                       buyCount, sellCount, avgSize, deltaHigh, deltaLow,
+//                       deltaPct)
+//   [18..32] candle1m
+//   [33..40] orderbook (bestBid, bestAsk, spread, imbalance, score,
+//                       bidCoG, askCoG, cogSkew)
+//   [41..47] oi        (oi, oiDelta1s, oiDelta1m, behavior, notional,
+//                       notionalDelta1s, notionalDelta1m)
+//   [48]     finalScore
+//   [49..]   htf       NumHTF × candle
+//   [+0..+3] quality   (depthAgeMs, oiAgeMs, tradeGapMs, flags)
+//   [+4..+14] session  (id, start, open, high, low, vwap, asiaOpen,
+//                       londonOpen, nyOpen, prevDayHigh, prevDayLow)
+//   [+15..+29] vwap    3 × (vwap, +1σ, -1σ, +2σ, -2σ)  session, day, rolling
+//   [+30..]   anchors  NumAnchors × (id, fromMs, vwap, +1σ, -1σ, +2σ, -2σ)
+//   [..+7]    flow     (tradesPerSec, avgTradeSize, intensityZ,
+//                       effortResult1s, effortResult1m, events, vpin, lambda)
+//   [..]      ind      3 × (rsi, macd, signal, hist, NumRibbon × ema)
+//   [..]      squeeze  2 × (on, bars, ratio, dir)
+//   [..]      levels   NumLevels × (price, kind, strength)
+//   [..+2]    scoreDyn (velocity, accel, percentile)
+//   [..]      tfScore  NumHTF × score
+//   [..]      formulas NumFormulas × value
+//   [..]      custom   NumCustom × value
+//   [..+7]    context  (label, side, oiZ, oiRange, funding, basisBps,
+//                       liq1m, liqZ)
+//   [..+5]    leader   (lag, corr, cvdCorr, expected, ret10s, cvd10s)
+//   [..+6]    position (size, entry, unrealizedPnl, realizedPnl,
+//                       lastFillTime, lastFillPrice, lastFillQty)
+//   [..+1]    latency  (eventUs, recvUs)
+//   [..+9]    l1       (bidQty, askQty, micro, spreadRatio, ofi1s, ofi10s,
+//                       bidRefill, askRefill, bidRefillRatio, askRefillRatio)
+//   [..+1]    cvdAnchor (anchored, sinceMs)
+//   [..+2]    oiVenues (binance, bybit, okx)
+//   [..+2]    states   (biasSec, stateSec, oiBehaviorSec)
+//   [..]      confidence
+//   [..+NumHTF] warmup (scoreReadyMs, htfReadyMs × NumHTF)
+//   [..+5]    structure (high, low, swingHigh, swingLow, toHighBps, toLowBps)
+//   [..+6]    tpo      (poc, vah, val, brackets, singles, poorHigh, poorLow)
+//   [..+5]    openRange (high, low, endMs, dir, confirm, failed)
+//
+// With the default 5 timeframes, 5-EMA ribbon, no anchors, levels, formulas
+// or analyzers that is 272 scalars (quality at 124..127). The length changes
+// when an anchor is added or removed or the level count changes; a delta is
+// only valid between frames of the same length, so the broadcaster sends a
+// full snapshot whenever it changes.
+//
+// Delta wire format: FixMap(2)
+//   "m" → bin8(⌈FlatLen/8⌉)  bitmask, bit i set = scalar i changed (LSB-first per byte)
+//   "v" → array     new values of the changed scalars, in index order (float64)
+//
+// A full snapshot is an array, a delta is a map — the client tells them
+// apart by type. Per tick usually only the close/volume/score fields move, so
+// a delta is roughly half the size of a full frame; OI, orderbook and HTF
+// opens/times are sent only when they actually change.
+// =============================================================================
+
+// MaxFlatLen bounds the number of scalars in a flattened Snapshot.
+const MaxFlatLen = 49 + MaxHTF*candleLen + 4 + 11 + 15 + MaxAnchors*7 + 8 +
+	NumIndicatorTFs*(4+MaxRibbon) + NumSqueezeTFs*4 +
+	MaxLevels*3 + 3 + MaxHTF + MaxFormulas + MaxCustomFields + 8 + 6 + 7 + 2 + 10 + 2 + NumOIVenues + 3 + 1 + 1 + MaxHTF + 6 + 7 + 6
+
+// candleLen — scalars per flattened candle.
+const candleLen = 15
+
+const maxFlatMaskLen = (MaxFlatLen + 7) / 8
+
+// Flat is a Snapshot flattened into scalars (ints widened to float64).
+// Only the first FlatLen entries (as returned by Flatten) are used.
+type Flat [MaxFlatLen]float64
+
+// Flatten writes the snapshot's scalars into f in wire order and returns
+// how many it wrote (FlatLen).
+func (s *Snapshot) Flatten(f *Flat) int {
+	f[0] = s.Price
+	f[1] = s.CVD
+	f[2] = float64(s.Time)
+	flattenCandle(f[3:18], &s.Candle1s)
+	flattenCandle(f[18:33], &s.Candle1m)
+	f[33] = s.Orderbook.BestBid
+	f[34] = s.Orderbook.BestAsk
+	f[35] = s.Orderbook.Spread
+	f[36] = s.Orderbook.Imbalance
+	f[37] = float64(s.Orderbook.Score)
+	f[38] = s.Orderbook.BidCoG
+	f[39] = s.Orderbook.AskCoG
+	f[40] = s.Orderbook.CoGSkew
+	f[41] = s.OI.OI
+	f[42] = s.OI.OIDelta1s
+	f[43] = s.OI.OIDelta1m
+	f[44] = float64(s.OI.Behavior)
+	f[45] = s.OI.Notional
+	f[46] = s.OI.NotionalDelta1s
+	f[47] = s.OI.NotionalDelta1m
+	f[48] = s.FinalScore
+	for i := 0; i < NumHTF; i++ {
+		off := 49 + i*candleLen
+		flattenCandle(f[off:off+candleLen], &s.HTF[i])
+	}
+	q := 49 + NumHTF*candleLen
+	f[q] = float64(s.Quality.DepthAgeMs)
+	f[q+1] = float64(s.Quality.OIAgeMs)
+	f[q+2] = float64(s.Quality.TradeGapMs)
+	f[q+3] = float64(s.Quality.Flags)
+	ss := &s.Session
+	f[q+4] = float64(ss.ID)
+	f[q+5] = float64(ss.Start)
+	f[q+6] = ss.Open
+	f[q+7] = ss.High
+	f[q+8] = ss.Low
+	f[q+9] = ss.VWAP
+	f[q+10] = ss.AsiaOpen
+	f[q+11] = ss.LondonOpen
+	f[q+12] = ss.NYOpen
+	f[q+13] = ss.PrevDayHigh
+	f[q+14] = ss.PrevDayLow
+	flattenBand(f[q+15:q+20], &s.VWAP.Session)
+	flattenBand(f[q+20:q+25], &s.VWAP.Day)
+	flattenBand(f[q+25:q+30], &s.VWAP.Rolling)
+	n := q + 30
+	for i := 0; i < s.NumAnchors; i++ {
+		a := &s.Anchors[i]
+		f[n] = float64(a.ID)
+		f[n+1] = float64(a.From)
+		flattenBand(f[n+2:n+7], &a.Band)
+		n += 7
+	}
+	f[n] = s.Flow.TradesPerSec
+	f[n+1] = s.Flow.AvgTradeSize
+	f[n+2] = s.Flow.IntensityZ
+	f[n+3] = s.Flow.EffortResult1s
+	f[n+4] = s.Flow.EffortResult1m
+	f[n+5] = float64(s.Flow.Events)
+	f[n+6] = s.Flow.VPIN
+	f[n+7] = s.Flow.Lambda
+	n += 8
+	for i := range s.Indicators {
+		flattenIndicator(f[n:], &s.Indicators[i])
+		n += 4 + NumRibbon
+	}
+	for i := range s.Squeeze {
+		sq := &s.Squeeze[i]
+		f[n] = float64(sq.On)
+		f[n+1] = float64(sq.Bars)
+		f[n+2] = sq.Ratio
+		f[n+3] = float64(sq.Dir)
+		n += 4
+	}
+	for i := 0; i < s.NumLevels; i++ {
+		l := &s.Levels[i]
+		f[n] = l.Price
+		f[n+1] = float64(l.Kind)
+		f[n+2] = l.Strength
+		n += 3
+	}
+	f[n] = s.ScoreDyn.Velocity
+	f[n+1] = s.ScoreDyn.Accel
+	f[n+2] = s.ScoreDyn.Percentile
+	n += 3
+	n += copy(f[n:], s.TFScore[:NumHTF])
+	n += copy(f[n:], s.Formulas[:NumFormulas])
+	n += copy(f[n:], s.Custom[:NumCustom])
+	f[n] = float64(s.Context.Label)
+	f[n+1] = float64(s.Context.Side)
+	f[n+2] = s.Context.OIZ
+	f[n+3] = s.Context.OIRange
+	f[n+4] = s.Context.Funding
+	f[n+5] = s.Context.BasisBps
+	f[n+6] = s.Context.Liq1m
+	f[n+7] = s.Context.LiqZ
+	n += 8
+	f[n] = float64(s.Leader.Lag)
+	f[n+1] = s.Leader.Corr
+	f[n+2] = s.Leader.CVDCorr
+	f[n+3] = s.Leader.Expected
+	f[n+4] = s.Leader.Ret10s
+	f[n+5] = s.Leader.CVD10s
+	n += 6
+	f[n] = s.Position.Size
+	f[n+1] = s.Position.EntryPrice
+	f[n+2] = s.Position.UnrealizedPnL
+	f[n+3] = s.Position.RealizedPnL
+	f[n+4] = float64(s.Position.LastFillTime)
+	f[n+5] = s.Position.LastFillPrice
+	f[n+6] = s.Position.LastFillQty
+	n += 7
+	f[n] = float64(s.Latency.EventUs)
+	f[n+1] = float64(s.Latency.RecvUs)
+	n += 2
+	f[n] = s.L1.BidQty
+	f[n+1] = s.L1.AskQty
+	f[n+2] = s.L1.Micro
+	f[n+3] = s.L1.SpreadRatio
+	f[n+4] = s.L1.OFI1s
+	f[n+5] = s.L1.OFI10s
+	f[n+6] = s.L1.BidRefill
+	f[n+7] = s.L1.AskRefill
+	f[n+8] = s.L1.BidRefillRatio
+	f[n+9] = s.L1.AskRefillRatio
+	n += 10
+	f[n] = s.CVDAnchor.Anchored
+	f[n+1] = float64(s.CVDAnchor.Since)
+	n += 2
+	n += copy(f[n:], s.OI.Venues[:])
+	f[n] = float64(s.States.BiasSec)
+	f[n+1] = float64(s.States.StateSec)
+	f[n+2] = float64(s.States.OIBehaviorSec)
+	n += 3
+	f[n] = s.Confidence
+	n++
+	f[n] = float64(s.Warmup.Score)
+	n++
+	for i := 0; i < NumHTF; i++ {
+		f[n+i] = float64(s.Warmup.HTF[i])
+	}
+	n += NumHTF
+	f[n] = s.Structure.High
+	f[n+1] = s.Structure.Low
+	f[n+2] = s.Structure.SwingHigh
+	f[n+3] = s.Structure.SwingLow
+	f[n+4] = s.Structure.ToHighBps
+	f[n+5] = s.Structure.ToLowBps
+	n += 6
+	f[n] = s.TPO.POC
+	f[n+1] = s.TPO.VAH
+	f[n+2] = s.TPO.VAL
+	f[n+3] = float64(s.TPO.Brackets)
+	f[n+4] = float64(s.TPO.Singles)
+	f[n+5] = float64(s.TPO.PoorHigh)
+	f[n+6] = float64(s.TPO.PoorLow)
+	n += 7
+	f[n] = s.OpenRange.High
+	f[n+1] = s.OpenRange.Low
+	f[n+2] = float64(s.OpenRange.End)
+	f[n+3] = float64(s.OpenRange.Dir)
+	f[n+4] = float64(s.OpenRange.Confirm)
+	f[n+5] = float64(s.OpenRange.Failed)
+	n += 6
+	return n
+}
+
+func flattenCandle(dst []float64, c *CandleSnapshot) {
+	dst[0] = float64(c.Time)
+	dst[1] = c.Open
+	dst[2] = c.High
+	dst[3] = c.Low
+	dst[4] = c.Close
+	dst[5] = c.BuyVol
+	dst[6] = c.SellVol
+	dst[7] = c.Delta
+	dst[8] = c.AvgScore
+	dst[9] = float64(c.BuyCount)
+	dst[10] = float64(c.SellCount)
+	dst[11] = c.AvgSize
+	dst[12] = c.DeltaHigh
+	dst[13] = c.DeltaLow
+	dst[14] = c.DeltaPct
+}
+
+func flattenBand(dst []float64, v *BandSnapshot) {
+	dst[0] = v.VWAP
+	dst[1] = v.Upper1
+	dst[2] = v.Lower1
+	dst[3] = v.Upper2
+	dst[4] = v.Lower2
+}
+
+// AppendDelta appends the delta frame that turns prev into cur (both n long).
+func AppendDelta(b []byte, prev, cur *Flat, n int) []byte {
+	maskLen := (n + 7) / 8
+	var mask [maxFlatMaskLen]byte
+	changed := 0
+	for i := 0; i < n; i++ {
+		if math.Float64bits(prev[i]) != math.Float64bits(cur[i]) {
+			mask[i/8] |= 1 << (i % 8)
+			changed++
+		}
+	}
+
+	b = append(b, 0x82) // FixMap(2)
+
+	b = append(b, 0xa1, 'm') // FixStr(1)
+	b = append(b, 0xc4, byte(maskLen))
+	b = append(b, mask[:maskLen]...)
+
+	b = append(b, 0xa1, 'v')
+	b = AppendArrayHeader(b, changed)
+	for i := 0; i < n; i++ {
+		if mask[i/8]&(1<<(i%8)) != 0 {
+			b = appendFloat64(b, cur[i])
+		}
+	}
+	return b
+}
@@ -0,0 +1,18 @@
+package model
+
+// MaxFormulas bounds the number of user-defined scores (internal/formula).
+const MaxFormulas = 8
+
+// Active user-defined score names (Snapshot.Formulas order). Set once at
+// startup with SetFormulas; read-only afterwards. None by default.
+var (
+	FormulaNames []string
+	NumFormulas  = 0
+)
+
+// SetFormulas replaces the user-defined score names (at most MaxFormulas,
+// each at most 31 bytes).
+func SetFormulas(names []string) {
+	FormulaNames = names
+	NumFormulas = len(names)
+}
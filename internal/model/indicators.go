@@ -0,0 +1,80 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Indicator timeframes (fixed): Snapshot.Indicators[i] is on IndicatorTFs[i]
+// seconds candles.
+var IndicatorTFs = [NumIndicatorTFs]int64{60, 300, 3600}
+
+// NumIndicatorTFs — 1m, 5m, 1h.
+const NumIndicatorTFs = 3
+
+// MaxRibbon bounds the number of EMA ribbon periods.
+const MaxRibbon = 8
+
+// Active EMA ribbon periods. Set once at startup with SetRibbon; read-only
+// afterwards.
+var (
+	RibbonPeriods = []int{8, 13, 21, 34, 55}
+	NumRibbon     = 5
+)
+
+// SetRibbon replaces the EMA ribbon periods (at most MaxRibbon).
+func SetRibbon(periods []int) {
+	RibbonPeriods = periods
+	NumRibbon = len(periods)
+}
+
+// ParseRibbon parses a comma-separated list of EMA periods like "8,13,21".
+func ParseRibbon(spec string) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(spec, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		p, err := strconv.Atoi(f)
+		if err != nil || p < 2 {
+			return nil, fmt.Errorf("ribbon period %q: want an integer ≥ 2", f)
+		}
+		out = append(out, p)
+	}
+	if len(out) > MaxRibbon {
+		return nil, fmt.Errorf("%d ribbon periods, max %d", len(out), MaxRibbon)
+	}
+	return out, nil
+}
+
+// IndicatorSnapshot — live RSI/MACD/EMA ribbon on one candle timeframe
+// (internal/indicators). 0 = still warming up.
+type IndicatorSnapshot struct {
+	RSI    float64
+	MACD   float64
+	Signal float64
+	Hist   float64
+	Ribbon [MaxRibbon]float64 // first NumRibbon in use, RibbonPeriods order
+}
+
+func appendIndicatorSnapshot(b []byte, in *IndicatorSnapshot, w wire) []byte {
+	b = AppendArrayHeader(b, 4+NumRibbon)
+	b = w.f(b, in.RSI)
+	b = w.f(b, in.MACD)
+	b = w.f(b, in.Signal)
+	b = w.f(b, in.Hist)
+	for i := 0; i < NumRibbon; i++ {
+		b = w.px(b, in.Ribbon[i]) // EMAs of price
+	}
+	return b
+}
+
+func flattenIndicator(dst []float64, in *IndicatorSnapshot) {
+	dst[0] = in.RSI
+	dst[1] = in.MACD
+	dst[2] = in.Signal
+	dst[3] = in.Hist
+	copy(dst[4:], in.Ribbon[:NumRibbon])
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// TRADE SIDE CLASSIFICATION — which raw flag means an aggressive buy
+// =============================================================================
+//
+// Delta, CVD, VPIN, big-print net and every score built on them hinge on the
+// aggressor side of each print, and venues publish it in opposite senses:
+//
+//   binance   aggTrade "m"  buyer is the MAKER   → true = taker SOLD
+//   coinbase  "side"        maker order side     → buy  = taker SOLD
+//   bybit     "S"           taker side           → Buy  = taker BOUGHT
+//   okx       "side"        taker side           → buy  = taker BOUGHT
+//   kraken    "side"        taker side           → b    = taker BOUGHT
+//
+// Trade.IsBuyer carries the venue's raw flag (true for "m":true, "Buy",
+// "buy", "b"); Trade.AggressiveBuy maps it through the configured
+// convention. Nothing else may read IsBuyer directly.
+//
+// Getting this backwards flips every signed metric while leaving the
+// unsigned ones (volume, tape speed) plausible, so nothing looks broken.
+// The side check (ingest.SideValidator) compares the classified buy volume
+// with the exchange's own taker-buy volume per minute to catch it.
+// =============================================================================
+
+// SideConvention — meaning of Trade.IsBuyer.
+type SideConvention uint8
+
+const (
+	BuyerIsMaker SideConvention = iota // IsBuyer = buyer was the maker (aggressive sell)
+	BuyerIsTaker                       // IsBuyer = buyer was the taker (aggressive buy)
+)
+
+// venueSides — the convention of each supported venue's trade feed.
+var venueSides = map[string]SideConvention{
+	"binance":  BuyerIsMaker,
+	"coinbase": BuyerIsMaker,
+	"bybit":    BuyerIsTaker,
+	"okx":      BuyerIsTaker,
+	"kraken":   BuyerIsTaker,
+}
+
+// Side is the active convention (Binance by default).
+var Side = BuyerIsMaker
+
+// SetSideConvention — must be called before the feeds start.
+func SetSideConvention(c SideConvention) {
+	Side = c
+}
+
+// ParseSideConvention accepts a venue name ("binance", "bybit", ...) or the
+// convention itself ("buyer-maker", "buyer-taker").
+func ParseSideConvention(spec string) (SideConvention, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	switch spec {
+	case "buyer-maker":
+		return BuyerIsMaker, nil
+	case "buyer-taker":
+		return BuyerIsTaker, nil
+	}
+	if c, ok := venueSides[spec]; ok {
+		return c, nil
+	}
+	return 0, fmt.Errorf("trade side %q: want a venue (binance, coinbase, bybit, okx, kraken) or buyer-maker / buyer-taker", spec)
+}
+
+func (c SideConvention) String() string {
+	if c == BuyerIsTaker {
+		return "buyer-taker"
+	}
+	return "buyer-maker"
+}
+
+// AggressiveBuy — true if the taker bought (lifted the ask).
+func (t *Trade) AggressiveBuy() bool {
+	return t.IsBuyer == (Side == BuyerIsTaker)
+}
+
+// SignedQty — +Quantity for an aggressive buy, −Quantity for a sell.
+func (t *Trade) SignedQty() float64 {
+	if t.AggressiveBuy() {
+		return t.Quantity
+	}
+	return -t.Quantity
+}
@@ -0,0 +1,68 @@
+package model
+
+// Signal lifecycle states.
+const (
+	SignalArmed       = 1 // score crossed the arm level, waiting for the trigger
+	SignalTriggered   = 2 // score crossed the trigger level: the entry
+	SignalCompleted   = 3 // triggered signal closed normally (score faded / max hold)
+	SignalInvalidated = 4 // armed signal expired or reversed, or a triggered one was stopped
+)
+
+// Signal — one discrete score signal at one point of its lifecycle (see
+// internal/signals). Every state change is emitted as a new Signal with the
+// same ID; the timestamps of earlier states are carried along.
+type Signal struct {
+	ID     int64 // unix ms the signal was armed
+	Side   int   // +1 long, -1 short
+	State  int   // SignalXxx
+	Reason string
+
+	ArmedAt     int64 // unix ms
+	TriggeredAt int64 // unix ms, 0 = not triggered
+	ClosedAt    int64 // unix ms, 0 = open
+
+	ArmPrice   float64 // price when armed
+	EntryPrice float64 // entry reference: price when triggered
+	ExitPrice  float64 // price when closed
+	Score      float64 // final score at this state change
+	ReturnBps  float64 // side-signed (exit − entry) / entry, bps (closed after trigger only)
+}
+
+// StateName — "armed" / "triggered" / "completed" / "invalidated".
+func (s *Signal) StateName() string {
+	switch s.State {
+	case SignalArmed:
+		return "armed"
+	case SignalTriggered:
+		return "triggered"
+	case SignalCompleted:
+		return "completed"
+	case SignalInvalidated:
+		return "invalidated"
+	}
+	return "unknown"
+}
+
+// SideName — "long" / "short".
+func (s *Signal) SideName() string {
+	if s.Side < 0 {
+		return "short"
+	}
+	return "long"
+}
+
+// Last — time (unix ms) and price of the latest state change.
+func (s *Signal) Last() (int64, float64) {
+	switch {
+	case s.ClosedAt != 0:
+		return s.ClosedAt, s.ExitPrice
+	case s.TriggeredAt != 0:
+		return s.TriggeredAt, s.EntryPrice
+	}
+	return s.ArmedAt, s.ArmPrice
+}
+
+// Open reports whether the signal is still armed or triggered.
+func (s *Signal) Open() bool {
+	return s.State == SignalArmed || s.State == SignalTriggered
+}
@@ -7,15 +7,35 @@ import (
 // CandleSnapshot — point-in-time copy of a candle bucket.
 // Now includes AvgScore (EMA of finalScore within the bucket).
 type CandleSnapshot struct {
-	Time     int64
-	Open     float64
-	High     float64
-	Low      float64
-	Close    float64
-	BuyVol   float64
-	SellVol  float64
-	Delta    float64
-	AvgScore float64 // EMA of per-tick finalScore
+	Time      int64
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	BuyVol    float64
+	SellVol   float64
+	Delta     float64
+	AvgScore  float64 // EMA of per-tick finalScore
+	BuyCount  int64   // aggressive buy trades
+	SellCount int64   // aggressive sell trades
+	AvgSize   float64 // (BuyVol + SellVol) / (BuyCount + SellCount), 0 = no trades
+	DeltaHigh float64 // running max of Delta within the bucket
+	DeltaLow  float64 // running min of Delta within the bucket
+	DeltaPct  float64 // 100 × Delta / (BuyVol + SellVol), 0 = no volume
+}
+
+// CandleBook — order book statistics over a candle: the top-level
+// imbalance (-1..+1) time-weighted and its extremes, the largest single
+// level seen on each side (the wall, base-asset qty) and the time-weighted
+// spread. Zero while the book is unusable. Not on the WS wire; see
+// GET /klines.
+type CandleBook struct {
+	AvgImbalance float64
+	MinImbalance float64
+	MaxImbalance float64
+	MaxBidWall   float64
+	MaxAskWall   float64
+	AvgSpread    float64
 }
 
 type OrderbookSnapshot struct {
@@ -24,6 +44,12 @@ type OrderbookSnapshot struct {
 	Spread    float64
 	Imbalance float64
 	Score     int
+
+	// Center of gravity: volume-weighted price of each side's kept levels
+	// and their skew around mid, +1 = bids hug the touch (see orderbook)
+	BidCoG  float64
+	AskCoG  float64
+	CoGSkew float64
 }
 
 type OISnapshot struct {
@@ -31,23 +57,370 @@ type OISnapshot struct {
 	OIDelta1s float64
 	OIDelta1m float64
 	Behavior  int
+
+	// Notional (quote asset): OI × price; the deltas value the contracts
+	// opened / closed (see internal/oi)
+	Notional        float64
+	NotionalDelta1s float64
+	NotionalDelta1m float64
+
+	// Venues — per-venue OI (binance, bybit, okx; 0 = not polled or stale)
+	// when OI is aggregated across venues (see oi.Aggregator). Sent as its
+	// own top-level entry [27].
+	Venues [NumOIVenues]float64
+}
+
+// NumOIVenues — venues in the OI breakdown (oi.NumVenues).
+const NumOIVenues = 3
+
+// Data-quality flags (bitmask) carried in QualitySnapshot.Flags.
+const (
+	QualityDepthStale   = 1 << 0 // no depth update within the staleness window
+	QualityOIStale      = 1 << 1 // no successful OI poll within the staleness window
+	QualityTradeGap     = 1 << 2 // gap between this trade and the previous one exceeded the window
+	QualityHeartbeat    = 1 << 3 // wall-clock tick with no trade (candles rolled by timer)
+	QualityDepthSuspect = 1 << 4 // latest depth frame failed the book's sanity checks (dropped)
+	QualityThinBook     = 1 << 5 // top-N depth below its rolling percentile (liquidity vacuum, engine/thinbook.go)
+	QualityEventRisk    = 1 << 6 // inside a scheduled event's window (FOMC, CPI, …; internal/calendar)
+)
+
+// QualitySnapshot — freshness of the inputs that fed this snapshot.
+// Ages are wall-clock milliseconds since the last update (-1 = never updated).
+type QualitySnapshot struct {
+	DepthAgeMs int64
+	OIAgeMs    int64
+	TradeGapMs int64 // exchange-time gap since the previous trade
+	Flags      int
+}
+
+// SessionSnapshot — active trading session (see internal/session) and
+// today's/yesterday's reference levels. Zero opens = session not reached yet.
+type SessionSnapshot struct {
+	ID          int   // 0=Asia, 1=London, 2=NY
+	Start       int64 // unix seconds
+	Open        float64
+	High        float64
+	Low         float64
+	VWAP        float64
+	AsiaOpen    float64
+	LondonOpen  float64
+	NYOpen      float64
+	PrevDayHigh float64
+	PrevDayLow  float64
+}
+
+// BandSnapshot — a VWAP with its ±1σ/±2σ deviation bands (0s until the
+// anchor has seen volume).
+type BandSnapshot struct {
+	VWAP   float64
+	Upper1 float64
+	Lower1 float64
+	Upper2 float64
+	Lower2 float64
+}
+
+// NewBand — VWAP ± k·σ for k = 1, 2.
+func NewBand(vwap, sigma float64) BandSnapshot {
+	if vwap == 0 {
+		return BandSnapshot{}
+	}
+	return BandSnapshot{
+		VWAP:   vwap,
+		Upper1: vwap + sigma,
+		Lower1: vwap - sigma,
+		Upper2: vwap + 2*sigma,
+		Lower2: vwap - 2*sigma,
+	}
+}
+
+// VWAPSnapshot — session-anchored, day-anchored and rolling VWAP bands.
+type VWAPSnapshot struct {
+	Session BandSnapshot
+	Day     BandSnapshot // anchored at 00:00 UTC
+	Rolling BandSnapshot // trailing window (1h)
+}
+
+// Event flags (bitmask) carried in FlowSnapshot.Events and the CSV
+// event_flags column. Effort-vs-result events, bits 0-3 for the 1s candle and
+// the same four shifted by 4 for the 1m candle (see internal/flow); squeeze
+// fires from bit 8 (see internal/indicators), decision-layer transitions
+// from bit 12 (see Transition) and structure breakouts from bit 14 (see
+// engine/structure.go) and opening-range breakouts from bit 16 (see
+// engine/openrange.go), set for the second they fire.
+const (
+	EventAbsorbBuy1s  = 1 << 0 // heavy buying absorbed, no upside progress
+	EventAbsorbSell1s = 1 << 1 // heavy selling absorbed, no downside progress
+	EventVacuumUp1s   = 1 << 2 // price lifted on little net buying
+	EventVacuumDown1s = 1 << 3 // price dropped on little net selling
+	EventAbsorbBuy1m  = 1 << 4
+	EventAbsorbSell1m = 1 << 5
+	EventVacuumUp1m   = 1 << 6
+	EventVacuumDown1m = 1 << 7
+
+	EventSqueezeUp5m    = 1 << 8
+	EventSqueezeDown5m  = 1 << 9
+	EventSqueezeUp15m   = 1 << 10
+	EventSqueezeDown15m = 1 << 11
+
+	EventBiasFlip    = 1 << 12 // HTF bias changed value
+	EventStateChange = 1 << 13 // market state changed value
+
+	EventBreakoutUp   = 1 << 14 // price took out the rolling high on positive 1m delta
+	EventBreakoutDown = 1 << 15 // … the rolling low on negative 1m delta
+
+	EventORBreakUp   = 1 << 16 // price left the session's opening range upwards
+	EventORBreakDown = 1 << 17 // … downwards
+	EventORFailed    = 1 << 18 // a 1m close back inside the range after a breakout
+)
+
+// FlowSnapshot — tape / order-flow microstructure metrics (internal/flow).
+type FlowSnapshot struct {
+	TradesPerSec   float64 // EMA of trades per completed second (~10s)
+	AvgTradeSize   float64
+	IntensityZ     float64 // live second's trade count vs 5-minute baseline
+	EffortResult1s float64 // >0 absorption (effort, no result), <0 vacuum
+	EffortResult1m float64
+	Events         int     // EventXxx bitmask
+	VPIN           float64 // flow toxicity over the last n volume buckets, [0, 1]
+	Lambda         float64 // Kyle's λ: price change per unit of signed volume
+}
+
+// SqueezeSnapshot — Bollinger-in-Keltner squeeze state on one timeframe.
+type SqueezeSnapshot struct {
+	On    int     // 1 = in squeeze
+	Bars  int     // consecutive closes in squeeze
+	Ratio float64 // BB width / KC width (< 1 = squeeze)
+	Dir   int     // direction of the last fire: +1 up, -1 down, 0 none yet
+}
+
+// Squeeze timeframes (fixed): Snapshot.Squeeze[i] is on SqueezeTFs[i] seconds.
+var SqueezeTFs = [NumSqueezeTFs]int64{300, 900}
+
+// NumSqueezeTFs — 5m, 15m.
+const NumSqueezeTFs = 2
+
+// ScoreDynamicsSnapshot — how the final score is moving (internal/pressure).
+type ScoreDynamicsSnapshot struct {
+	Velocity   float64 // points / second
+	Accel      float64 // points / second²
+	Percentile float64 // rank of FinalScore within the trailing hour, 0..100
+}
+
+// MaxLevels bounds the volume-profile level list (6 POC/value-area levels,
+// 3 HVNs, 3 LVNs).
+const MaxLevels = 12
+
+// LevelSnapshot — a support/resistance level from the volume profile
+// (see internal/profile; Kind is one of profile.Level*).
+type LevelSnapshot struct {
+	Price    float64
+	Kind     int // 0 POC, 1 VAH, 2 VAL, 3-5 prior day POC/VAH/VAL, 6 HVN, 7 LVN
+	Strength float64
+}
+
+// MaxAnchors bounds the number of concurrent anchored VWAPs.
+const MaxAnchors = 8
+
+// ContextSnapshot — fused OI/price/funding/liquidation positioning label
+// (see internal/positioning; Label is one of positioning.Label*). The
+// funding, basis and liquidation fields are zero without -derivs.
+type ContextSnapshot struct {
+	Label    int
+	Side     int     // +1 longs, -1 shorts, 0 none
+	OIZ      float64 // 1-minute notional OI change z-score vs the trailing day
+	OIRange  float64 // OI position within its trailing-day range, 0..1
+	Funding  float64 // current funding rate (0.0001 = 0.01%)
+	BasisBps float64 // mark over index, bp
+	Liq1m    float64 // liquidated notional this minute, longs − shorts
+	LiqZ     float64 // this minute's liquidated notional z-score
+}
+
+// LeaderSnapshot — lead-lag against the leader symbol (see internal/leadlag;
+// zero without -leader or before a minute of paired seconds).
+type LeaderSnapshot struct {
+	Lag      int     // seconds the leader leads by (0 = moves together)
+	Corr     float64 // return correlation at Lag
+	CVDCorr  float64 // CVD-delta correlation at Lag
+	Expected float64 // implied follower move from the leader's last Lag seconds, bps
+	Ret10s   float64 // leader return over the last 10s, bps
+	CVD10s   float64 // leader aggressor delta over the last 10s
+}
+
+// PositionSnapshot — the user's own position on the traded symbol (see
+// internal/account; zero without -user-stream).
+type PositionSnapshot struct {
+	Size          float64 // signed base-asset quantity, + long / - short
+	EntryPrice    float64
+	UnrealizedPnL float64 // at Price
+	RealizedPnL   float64 // today (UTC), net of quote-asset commission
+	LastFillTime  int64   // unix ms (0 = no fill yet)
+	LastFillPrice float64
+	LastFillQty   float64 // signed, + bought / - sold
+}
+
+// LatencySnapshot — how old the trade was when its snapshot was built (see
+// internal/latency; zero without -latency-field and on heartbeats).
+type LatencySnapshot struct {
+	EventUs int64 // since the exchange event time (includes clock skew)
+	RecvUs  int64 // since the local receive time
+}
+
+// L1Snapshot — bookTicker-resolution top of book (see orderbook.L1Tracker;
+// zero without -book-ticker). BestBid/BestAsk/Spread are in Orderbook, taken
+// from here when the quote is newer than the last depth frame.
+type L1Snapshot struct {
+	BidQty      float64
+	AskQty      float64
+	Micro       float64 // microprice
+	SpreadRatio float64 // spread / its EWMA (blowout > ~3)
+	OFI1s       float64 // order flow imbalance in the current second
+	OFI10s      float64 // … over the last 10 seconds
+
+	// Touch replenishment (see engine/replenish.go): size refilled at the
+	// best bid / ask per second, and per unit consumed by trades (0..2)
+	BidRefill      float64
+	AskRefill      float64
+	BidRefillRatio float64
+	AskRefillRatio float64
+}
+
+// CVDSnapshot — CVD since the reset anchor (see engine.CVDReset; equal to
+// the lifetime Snapshot.CVD with no reset policy).
+type CVDSnapshot struct {
+	Anchored float64
+	Since    int64 // anchor time, unix ms (0 = lifetime)
+}
+
+// StatesSnapshot — how long the confirmed HTF bias, market state and OI
+// behavior have held, whole seconds as of the snapshot (see
+// logger.TransitionTracker; counted from process start, 0 before the first
+// value).
+type StatesSnapshot struct {
+	BiasSec       int64
+	StateSec      int64
+	OIBehaviorSec int64
+}
+
+// WarmupSnapshot — cold-start state of the score and each HTF AvgScore EMA:
+// the estimated time the component is warm, unix ms (0 = warm; see
+// engine/warmup.go).
+type WarmupSnapshot struct {
+	Score int64
+	HTF   [MaxHTF]int64 // HTFs order, first NumHTF in use
+}
+
+// Warming — whether the score or the HTF of that bucket length (if
+// configured) is still warming up.
+func (w *WarmupSnapshot) Warming(htfSeconds ...int64) bool {
+	if w.Score != 0 {
+		return true
+	}
+	for _, sec := range htfSeconds {
+		if i := HTFIndex(sec); i >= 0 && w.HTF[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// StructureSnapshot — rolling range and swing points of the 1m bars (see
+// engine/structure.go; 0 = not formed yet).
+type StructureSnapshot struct {
+	High      float64 // highest high of the last N completed 1m bars
+	Low       float64
+	SwingHigh float64 // latest confirmed pivot high
+	SwingLow  float64
+	ToHighBps float64 // (High − price) / price in bp, < 0 once broken
+	ToLowBps  float64 // (price − Low) / price in bp
+}
+
+// TPOSnapshot — the session's developing market profile (see internal/tpo;
+// zero until the first bracket).
+type TPOSnapshot struct {
+	POC      float64 // most TPOs
+	VAH      float64 // 70% value area of the TPOs
+	VAL      float64
+	Brackets int // 30-minute brackets so far
+	Singles  int // single-print bins inside the profile
+	PoorHigh int // 1 = top bin has ≥ 2 TPOs (no excess)
+	PoorLow  int
 }
 
-// NumHTF is the number of higher timeframe buckets.
-const NumHTF = 5
+// OpenRangeSnapshot — the current session's opening range and breakout
+// (see engine/openrange.go; zero when the session has no range).
+type OpenRangeSnapshot struct {
+	High    float64
+	Low     float64
+	End     int64 // range end, unix ms (still forming before it)
+	Dir     int   // +1 / −1 broken out, 0 inside
+	Confirm int   // breakout context: 1 = with 1m delta, 2 = with rising OI
+	Failed  int   // direction of the last failed breakout, 0 = none
+}
+
+// AnchorSnapshot — a user-anchored VWAP (see /admin/anchors).
+type AnchorSnapshot struct {
+	ID   int64
+	From int64 // anchor time, unix ms
+	Band BandSnapshot
+}
 
 // Snapshot — full enriched state broadcast on each trade.
 //
-// MsgPack wire format: FixArray(9)
+// MsgPack wire format: Array16(34)
 //   [0] price      float64
 //   [1] cvd        float64
 //   [2] time       int64
-//   [3] candle1s   FixArray(9) [time, o, h, l, c, buyVol, sellVol, delta, avgScore]
-//   [4] candle1m   FixArray(9)
-//   [5] orderbook  FixArray(5) [bestBid, bestAsk, spread, imbalance, score]
-//   [6] oi         FixArray(4) [oi, oiDelta1s, oiDelta1m, behavior]
+//   [3] candle1s   FixArray(15) [time, o, h, l, c, buyVol, sellVol, delta, avgScore,
+//                  buyCount, sellCount, avgSize, deltaHigh, deltaLow, deltaPct]
+//   [4] candle1m   FixArray(15)
+//   [5] orderbook  FixArray(8) [bestBid, bestAsk, spread, imbalance, score,
+//                  bidCoG, askCoG, cogSkew]
+//   [6] oi         FixArray(7) [oi, oiDelta1s, oiDelta1m, behavior,
+//                  notional, notionalDelta1s, notionalDelta1m]
 //   [7] finalScore float64
-//   [8] htf        FixArray(5) — each is FixArray(9) [5m, 15m, 1h, 4h, 1d]
+//   [8] htf        Array(NumHTF) — each is FixArray(15), in HTFs order
+//                  (default 5m, 15m, 1h, 4h, 1d; see AppendDescriptor)
+//   [9] quality    FixArray(4) [depthAgeMs, oiAgeMs, tradeGapMs, flags]
+//   [10] session   FixArray(11) [id, start, open, high, low, vwap,
+//                  asiaOpen, londonOpen, nyOpen, prevDayHigh, prevDayLow]
+//   [11] vwap      FixArray(3) [session, day, rolling] — each FixArray(5)
+//                  [vwap, +1σ, -1σ, +2σ, -2σ]
+//   [12] anchors   Array(NumAnchors) — each FixArray(7)
+//                  [id, fromMs, vwap, +1σ, -1σ, +2σ, -2σ]
+//   [13] flow      FixArray(8) [tradesPerSec, avgTradeSize, intensityZ,
+//                  effortResult1s, effortResult1m, events, vpin, lambda]
+//   [14] ind       FixArray(3) [1m, 5m, 1h] — each Array(4+NumRibbon)
+//                  [rsi, macd, signal, hist, ema_1 … ema_NumRibbon]
+//   [15] squeeze   FixArray(2) [5m, 15m] — each FixArray(4) [on, bars, ratio, dir]
+//   [16] levels    Array(NumLevels) — each FixArray(3) [price, kind, strength],
+//                  ranked (see internal/profile)
+//   [17] scoreDyn  FixArray(3) [velocity, accel, percentile]
+//   [18] tfScore   Array(NumHTF) float64 — per-timeframe scorer, HTFs order
+//   [19] formulas  Array(NumFormulas) float64 — user-defined scores,
+//                  FormulaNames order (see internal/formula)
+//   [20] custom    Array(NumCustom) float64 — analyzer fields, CustomFields
+//                  order (see internal/analyzer)
+//   [21] context   FixArray(8) [label, side, oiZ, oiRange, funding,
+//                  basisBps, liq1m, liqZ]
+//   [22] leader    FixArray(6) [lag, corr, cvdCorr, expected, ret10s, cvd10s]
+//   [23] position  FixArray(7) [size, entry, unrealizedPnl, realizedPnl,
+//                  lastFillTime, lastFillPrice, lastFillQty]
+//   [24] latency   FixArray(2) [eventUs, recvUs]
+//   [25] l1        FixArray(10) [bidQty, askQty, micro, spreadRatio, ofi1s,
+//                  ofi10s, bidRefill, askRefill, bidRefillRatio, askRefillRatio]
+//   [26] cvdAnchor FixArray(2) [anchored, sinceMs]
+//   [27] oiVenues  FixArray(3) [binance, bybit, okx] — OI per venue
+//   [28] states    FixArray(3) [biasSec, stateSec, oiBehaviorSec] — time in
+//                  the current HTF bias, market state and OI behavior
+//   [29] confidence float64 — confidence in FinalScore, 0..1
+//   [30] warmup    Array(1+NumHTF) [scoreReadyMs, htfReadyMs…] — estimated
+//                  warm time per component, 0 = warm
+//   [31] structure FixArray(6) [high, low, swingHigh, swingLow, toHighBps,
+//                  toLowBps] — rolling 1m range and swing points
+//   [32] tpo       FixArray(7) [poc, vah, val, brackets, singles, poorHigh,
+//                  poorLow] — the session's developing market profile
+//   [33] openRange FixArray(6) [high, low, endMs, dir, confirm, failed] —
+//                  the session's opening range and breakout
 type Snapshot struct {
 	Price      float64
 	Time       int64
@@ -57,62 +430,336 @@ type Snapshot struct {
 	Orderbook  OrderbookSnapshot
 	OI         OISnapshot
 	FinalScore float64
-	HTF        [NumHTF]CandleSnapshot
+	Confidence float64                // in FinalScore, 0..1 (pressure/confidence.go)
+	HTF        [MaxHTF]CandleSnapshot // first NumHTF in use
+	Quality    QualitySnapshot
+	Session    SessionSnapshot
+	VWAP       VWAPSnapshot
+	Anchors    [MaxAnchors]AnchorSnapshot // first NumAnchors in use
+	NumAnchors int
+	Flow       FlowSnapshot
+	Indicators [NumIndicatorTFs]IndicatorSnapshot
+	Squeeze    [NumSqueezeTFs]SqueezeSnapshot
+	Levels     [MaxLevels]LevelSnapshot // first NumLevels in use
+	NumLevels  int
+	ScoreDyn   ScoreDynamicsSnapshot
+
+	// TFScore — independent per-HTF scorer on closed candles (HTFs order);
+	// HTF[i].AvgScore is the legacy tick-score EMA.
+	TFScore [MaxHTF]float64
+
+	Formulas [MaxFormulas]float64     // first NumFormulas in use, FormulaNames order
+	Custom   [MaxCustomFields]float64 // first NumCustom in use, CustomFields order
+
+	Context  ContextSnapshot
+	Leader   LeaderSnapshot
+	Position PositionSnapshot
+	Latency  LatencySnapshot
+	L1       L1Snapshot
+
+	CVDAnchor CVDSnapshot
+	States    StatesSnapshot
+	Warmup    WarmupSnapshot
+	Structure StructureSnapshot
+	TPO       TPOSnapshot
+	OpenRange OpenRangeSnapshot
+
+	// Book1m / HTFBook — order book statistics of the live 1m and HTF
+	// candles (HTFs order). Not on the wire; kept with the history.
+	Book1m  CandleBook
+	HTFBook [MaxHTF]CandleBook
+
+	// RecvNs — local receive time of the trade, unix ns (0 for heartbeats).
+	// Not on the wire; the broadcaster measures write latency from it.
+	RecvNs int64
 }
 
 // AppendMsgPack — ZERO heap allocations.
 func (s *Snapshot) AppendMsgPack(b []byte) []byte {
-	b = append(b, 0x99) // FixArray(9)
-
-	b = appendFloat64(b, s.Price)
-	b = appendFloat64(b, s.CVD)
-	b = appendInt64(b, s.Time)
-	b = appendCandleSnapshot(b, &s.Candle1s)
-	b = appendCandleSnapshot(b, &s.Candle1m)
-	b = appendOrderbookSnapshot(b, &s.Orderbook)
-	b = appendOISnapshot(b, &s.OI)
-	b = appendFloat64(b, s.FinalScore)
-
-	// HTF array: FixArray(5), each element is a candle
-	b = append(b, 0x95) // FixArray(5)
+	return s.appendWire(b, wire{})
+}
+
+func (s *Snapshot) appendWire(b []byte, w wire) []byte {
+	b = AppendArrayHeader(b, 34)
+
+	b = w.px(b, s.Price)
+	b = w.f(b, s.CVD)
+	b = w.i(b, s.Time)
+	b = appendCandleSnapshot(b, &s.Candle1s, w)
+	b = appendCandleSnapshot(b, &s.Candle1m, w)
+	b = appendOrderbookSnapshot(b, &s.Orderbook, w)
+	b = appendOISnapshot(b, &s.OI, w)
+	b = w.f(b, s.FinalScore)
+
+	// HTF array: one candle per configured timeframe
+	b = AppendArrayHeader(b, NumHTF)
+	for i := 0; i < NumHTF; i++ {
+		b = appendCandleSnapshot(b, &s.HTF[i], w)
+	}
+
+	b = appendQualitySnapshot(b, &s.Quality, w)
+	b = appendSessionSnapshot(b, &s.Session, w)
+	b = append(b, 0x93)
+	b = appendBandSnapshot(b, &s.VWAP.Session, w)
+	b = appendBandSnapshot(b, &s.VWAP.Day, w)
+	b = appendBandSnapshot(b, &s.VWAP.Rolling, w)
+
+	b = AppendArrayHeader(b, s.NumAnchors)
+	for i := 0; i < s.NumAnchors; i++ {
+		a := &s.Anchors[i]
+		b = append(b, 0x97)
+		b = w.i(b, a.ID)
+		b = w.i(b, a.From)
+		b = w.px(b, a.Band.VWAP)
+		b = w.px(b, a.Band.Upper1)
+		b = w.px(b, a.Band.Lower1)
+		b = w.px(b, a.Band.Upper2)
+		b = w.px(b, a.Band.Lower2)
+	}
+
+	b = appendFlowSnapshot(b, &s.Flow, w)
+
+	b = append(b, 0x90|NumIndicatorTFs)
+	for i := range s.Indicators {
+		b = appendIndicatorSnapshot(b, &s.Indicators[i], w)
+	}
+
+	b = append(b, 0x90|NumSqueezeTFs)
+	for i := range s.Squeeze {
+		q := &s.Squeeze[i]
+		b = append(b, 0x94)
+		b = w.i(b, int64(q.On))
+		b = w.i(b, int64(q.Bars))
+		b = w.f(b, q.Ratio)
+		b = w.i(b, int64(q.Dir))
+	}
+
+	b = AppendArrayHeader(b, s.NumLevels)
+	for i := 0; i < s.NumLevels; i++ {
+		l := &s.Levels[i]
+		b = append(b, 0x93)
+		b = w.px(b, l.Price)
+		b = w.i(b, int64(l.Kind))
+		b = w.f(b, l.Strength)
+	}
+
+	b = append(b, 0x93)
+	b = w.f(b, s.ScoreDyn.Velocity)
+	b = w.f(b, s.ScoreDyn.Accel)
+	b = w.f(b, s.ScoreDyn.Percentile)
+
+	b = AppendArrayHeader(b, NumHTF)
 	for i := 0; i < NumHTF; i++ {
-		b = appendCandleSnapshot(b, &s.HTF[i])
+		b = w.f(b, s.TFScore[i])
+	}
+
+	b = AppendArrayHeader(b, NumFormulas)
+	for i := 0; i < NumFormulas; i++ {
+		b = w.f(b, s.Formulas[i])
 	}
 
+	b = AppendArrayHeader(b, NumCustom)
+	for i := 0; i < NumCustom; i++ {
+		b = w.f(b, s.Custom[i])
+	}
+
+	b = append(b, 0x98)
+	b = w.i(b, int64(s.Context.Label))
+	b = w.i(b, int64(s.Context.Side))
+	b = w.f(b, s.Context.OIZ)
+	b = w.f(b, s.Context.OIRange)
+	b = w.f(b, s.Context.Funding)
+	b = w.f(b, s.Context.BasisBps)
+	b = w.f(b, s.Context.Liq1m)
+	b = w.f(b, s.Context.LiqZ)
+
+	b = append(b, 0x96)
+	b = w.i(b, int64(s.Leader.Lag))
+	b = w.f(b, s.Leader.Corr)
+	b = w.f(b, s.Leader.CVDCorr)
+	b = w.f(b, s.Leader.Expected)
+	b = w.f(b, s.Leader.Ret10s)
+	b = w.f(b, s.Leader.CVD10s)
+
+	b = append(b, 0x97)
+	b = w.f(b, s.Position.Size)
+	b = w.px(b, s.Position.EntryPrice)
+	b = w.f(b, s.Position.UnrealizedPnL)
+	b = w.f(b, s.Position.RealizedPnL)
+	b = w.i(b, s.Position.LastFillTime)
+	b = w.px(b, s.Position.LastFillPrice)
+	b = w.f(b, s.Position.LastFillQty)
+
+	b = append(b, 0x92)
+	b = w.i(b, s.Latency.EventUs)
+	b = w.i(b, s.Latency.RecvUs)
+
+	b = append(b, 0x9a)
+	b = w.f(b, s.L1.BidQty)
+	b = w.f(b, s.L1.AskQty)
+	b = w.px(b, s.L1.Micro)
+	b = w.f(b, s.L1.SpreadRatio)
+	b = w.f(b, s.L1.OFI1s)
+	b = w.f(b, s.L1.OFI10s)
+	b = w.f(b, s.L1.BidRefill)
+	b = w.f(b, s.L1.AskRefill)
+	b = w.f(b, s.L1.BidRefillRatio)
+	b = w.f(b, s.L1.AskRefillRatio)
+
+	b = append(b, 0x92)
+	b = w.f(b, s.CVDAnchor.Anchored)
+	b = w.i(b, s.CVDAnchor.Since)
+
+	b = append(b, 0x90|NumOIVenues)
+	for _, v := range s.OI.Venues {
+		b = w.f(b, v)
+	}
+
+	b = append(b, 0x93)
+	b = w.i(b, s.States.BiasSec)
+	b = w.i(b, s.States.StateSec)
+	b = w.i(b, s.States.OIBehaviorSec)
+
+	b = w.f(b, s.Confidence)
+
+	b = AppendArrayHeader(b, 1+NumHTF)
+	b = w.i(b, s.Warmup.Score)
+	for i := 0; i < NumHTF; i++ {
+		b = w.i(b, s.Warmup.HTF[i])
+	}
+
+	b = append(b, 0x96)
+	b = w.px(b, s.Structure.High)
+	b = w.px(b, s.Structure.Low)
+	b = w.px(b, s.Structure.SwingHigh)
+	b = w.px(b, s.Structure.SwingLow)
+	b = w.f(b, s.Structure.ToHighBps)
+	b = w.f(b, s.Structure.ToLowBps)
+
+	b = append(b, 0x97)
+	b = w.px(b, s.TPO.POC)
+	b = w.px(b, s.TPO.VAH)
+	b = w.px(b, s.TPO.VAL)
+	b = w.i(b, int64(s.TPO.Brackets))
+	b = w.i(b, int64(s.TPO.Singles))
+	b = w.i(b, int64(s.TPO.PoorHigh))
+	b = w.i(b, int64(s.TPO.PoorLow))
+
+	b = append(b, 0x96)
+	b = w.px(b, s.OpenRange.High)
+	b = w.px(b, s.OpenRange.Low)
+	b = w.i(b, s.OpenRange.End)
+	b = w.i(b, int64(s.OpenRange.Dir))
+	b = w.i(b, int64(s.OpenRange.Confirm))
+	b = w.i(b, int64(s.OpenRange.Failed))
+
 	return b
 }
 
-// Candle: FixArray(9) — now includes avgScore
-func appendCandleSnapshot(b []byte, c *CandleSnapshot) []byte {
-	b = append(b, 0x99) // FixArray(9)
-	b = appendInt64(b, c.Time)
-	b = appendFloat64(b, c.Open)
-	b = appendFloat64(b, c.High)
-	b = appendFloat64(b, c.Low)
-	b = appendFloat64(b, c.Close)
-	b = appendFloat64(b, c.BuyVol)
-	b = appendFloat64(b, c.SellVol)
-	b = appendFloat64(b, c.Delta)
-	b = appendFloat64(b, c.AvgScore)
+// Candle: FixArray(15) — now includes avgScore, trade counts and intrabar delta
+func appendCandleSnapshot(b []byte, c *CandleSnapshot, w wire) []byte {
+	b = append(b, 0x9f) // FixArray(15)
+	b = w.i(b, c.Time)
+	b = w.px(b, c.Open)
+	b = w.px(b, c.High)
+	b = w.px(b, c.Low)
+	b = w.px(b, c.Close)
+	b = w.f(b, c.BuyVol)
+	b = w.f(b, c.SellVol)
+	b = w.f(b, c.Delta)
+	b = w.f(b, c.AvgScore)
+	b = w.i(b, c.BuyCount)
+	b = w.i(b, c.SellCount)
+	b = w.f(b, c.AvgSize)
+	b = w.f(b, c.DeltaHigh)
+	b = w.f(b, c.DeltaLow)
+	b = w.f(b, c.DeltaPct)
 	return b
 }
 
-func appendOrderbookSnapshot(b []byte, o *OrderbookSnapshot) []byte {
-	b = append(b, 0x95)
-	b = appendFloat64(b, o.BestBid)
-	b = appendFloat64(b, o.BestAsk)
-	b = appendFloat64(b, o.Spread)
-	b = appendFloat64(b, o.Imbalance)
-	b = appendInt64(b, int64(o.Score))
+func appendOrderbookSnapshot(b []byte, o *OrderbookSnapshot, w wire) []byte {
+	b = append(b, 0x98)
+	b = w.px(b, o.BestBid)
+	b = w.px(b, o.BestAsk)
+	b = w.px(b, o.Spread)
+	b = w.f(b, o.Imbalance)
+	b = w.i(b, int64(o.Score))
+	b = w.px(b, o.BidCoG)
+	b = w.px(b, o.AskCoG)
+	b = w.f(b, o.CoGSkew)
 	return b
 }
 
-func appendOISnapshot(b []byte, o *OISnapshot) []byte {
+func appendOISnapshot(b []byte, o *OISnapshot, w wire) []byte {
+	b = append(b, 0x97)
+	b = w.f(b, o.OI)
+	b = w.f(b, o.OIDelta1s)
+	b = w.f(b, o.OIDelta1m)
+	b = w.i(b, int64(o.Behavior))
+	b = w.f(b, o.Notional)
+	b = w.f(b, o.NotionalDelta1s)
+	b = w.f(b, o.NotionalDelta1m)
+	return b
+}
+
+// AppendArrayHeader appends a MsgPack array header for n elements
+// (FixArray up to 15, Array16 up to 65535, Array32 beyond).
+func AppendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= 0xffff:
+		return append(b, 0xdc, byte(n>>8), byte(n))
+	default:
+		return append(b, 0xdd, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func appendQualitySnapshot(b []byte, q *QualitySnapshot, w wire) []byte {
 	b = append(b, 0x94)
-	b = appendFloat64(b, o.OI)
-	b = appendFloat64(b, o.OIDelta1s)
-	b = appendFloat64(b, o.OIDelta1m)
-	b = appendInt64(b, int64(o.Behavior))
+	b = w.i(b, q.DepthAgeMs)
+	b = w.i(b, q.OIAgeMs)
+	b = w.i(b, q.TradeGapMs)
+	b = w.i(b, int64(q.Flags))
+	return b
+}
+
+func appendSessionSnapshot(b []byte, ss *SessionSnapshot, w wire) []byte {
+	b = append(b, 0x9b)
+	b = w.i(b, int64(ss.ID))
+	b = w.i(b, ss.Start)
+	b = w.px(b, ss.Open)
+	b = w.px(b, ss.High)
+	b = w.px(b, ss.Low)
+	b = w.px(b, ss.VWAP)
+	b = w.px(b, ss.AsiaOpen)
+	b = w.px(b, ss.LondonOpen)
+	b = w.px(b, ss.NYOpen)
+	b = w.px(b, ss.PrevDayHigh)
+	b = w.px(b, ss.PrevDayLow)
+	return b
+}
+
+func appendBandSnapshot(b []byte, v *BandSnapshot, w wire) []byte {
+	b = append(b, 0x95)
+	b = w.px(b, v.VWAP)
+	b = w.px(b, v.Upper1)
+	b = w.px(b, v.Lower1)
+	b = w.px(b, v.Upper2)
+	b = w.px(b, v.Lower2)
+	return b
+}
+
+func appendFlowSnapshot(b []byte, f *FlowSnapshot, w wire) []byte {
+	b = append(b, 0x98)
+	b = w.f(b, f.TradesPerSec)
+	b = w.f(b, f.AvgTradeSize)
+	b = w.f(b, f.IntensityZ)
+	b = w.f(b, f.EffortResult1s)
+	b = w.f(b, f.EffortResult1m)
+	b = w.i(b, int64(f.Events))
+	b = w.f(b, f.VPIN)
+	b = w.f(b, f.Lambda)
 	return b
 }
 
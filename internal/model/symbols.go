@@ -0,0 +1,14 @@
+package model
+
+// Traded symbol and optional lead-lag leader (see internal/leadlag). Set once
+// at startup with SetSymbols; read-only afterwards.
+var (
+	Symbol       = "BTCUSDT"
+	LeaderSymbol = "" // "" = no leader feed
+)
+
+// SetSymbols sets the traded and leader symbols (each at most 31 bytes).
+func SetSymbols(symbol, leader string) {
+	Symbol = symbol
+	LeaderSymbol = leader
+}
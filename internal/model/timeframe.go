@@ -0,0 +1,209 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"market-indikator/internal/buildinfo"
+)
+
+// Timeframe describes one higher-timeframe candle bucket (beyond the fixed
+// 1s and 1m candles).
+type Timeframe struct {
+	Label   string  // e.g. "5m", "1w"
+	Seconds int64   // bucket length
+	Offset  int64   // bucket alignment offset in seconds (weekly → Monday)
+	Alpha   float64 // EMA alpha of the per-tick finalScore within the bucket
+}
+
+// MaxHTF bounds the number of configurable higher timeframes (fixed-size
+// arrays keep Snapshot allocation-free).
+const MaxHTF = 8
+
+// mondayOffset — the unix epoch is a Thursday; 1970-01-05 00:00 UTC is the
+// first Monday, which is where exchange weekly candles start.
+const mondayOffset = 4 * 86400
+
+// DefaultTimeframes — 5m, 15m, 1h, 4h, 1d with the hand-tuned score EMAs.
+var DefaultTimeframes = []Timeframe{
+	{"5m", 300, 0, 0.039},   // N≈50
+	{"15m", 900, 0, 0.020},  // N≈100
+	{"1h", 3600, 0, 0.010},  // N≈200
+	{"4h", 14400, 0, 0.004}, // N≈500
+	{"1d", 86400, 0, 0.002}, // N≈1000
+}
+
+// Active higher timeframes, in wire order. Set once at startup with
+// SetTimeframes, before the engine, loggers or broadcaster run; read-only
+// afterwards.
+var (
+	HTFs   = DefaultTimeframes
+	NumHTF = len(DefaultTimeframes)
+)
+
+// SetTimeframes replaces the active timeframe set (at most MaxHTF entries).
+func SetTimeframes(tfs []Timeframe) {
+	HTFs = tfs
+	NumHTF = len(tfs)
+}
+
+// HTFIndex returns the index of the active timeframe with the given bucket
+// length, or -1 if it isn't configured.
+func HTFIndex(seconds int64) int {
+	for i := range HTFs {
+		if HTFs[i].Seconds == seconds {
+			return i
+		}
+	}
+	return -1
+}
+
+// HTFScore returns the AvgScore of the timeframe with the given bucket length,
+// or 0 if it isn't configured.
+func (s *Snapshot) HTFScore(seconds int64) float64 {
+	if i := HTFIndex(seconds); i >= 0 {
+		return s.HTF[i].AvgScore
+	}
+	return 0
+}
+
+// WireVersion — the snapshot's MsgPack layout, bumped whenever it changes
+// incompatibly (fields inserted or reordered; appended ones don't count).
+const WireVersion = 1
+
+// AppendDescriptor appends the descriptor frame sent to each client before
+// history:
+//
+//	FixMap(10)
+//	  "tf"       → array of FixArray(2) [label, seconds]  (htf entries, in order)
+//	  "ribbon"   → array of EMA ribbon periods            (indicator ribbon order)
+//	  "formulas" → array of user-defined score names      (formulas order)
+//	  "custom"   → array of analyzer field names          (custom order)
+//	  "symbol"   → traded symbol
+//	  "leader"   → lead-lag leader symbol ("" = none)
+//	  "build"    → server commit (see internal/buildinfo, ≤ 31 chars)
+//	  "wire"     → WireVersion
+//	  "encoding" → "full" or "compact" (see compact.go)
+//	  "price_scale" → divisor of the snapshot's prices (1 unless compact)
+//
+// so clients can label the variable-length parts of each snapshot, and tell
+// which build they are talking to and how to decode it.
+func AppendDescriptor(b []byte, enc Encoding) []byte {
+	b = append(b, 0x8a)           // FixMap(10)
+	b = append(b, 0xa2, 't', 'f') // FixStr(2)
+	b = AppendArrayHeader(b, NumHTF)
+	for i := range HTFs {
+		b = append(b, 0x92)
+		b = append(b, 0xa0|byte(len(HTFs[i].Label)))
+		b = append(b, HTFs[i].Label...)
+		b = appendInt64(b, HTFs[i].Seconds)
+	}
+	b = append(b, 0xa6, 'r', 'i', 'b', 'b', 'o', 'n')
+	b = AppendArrayHeader(b, NumRibbon)
+	for _, p := range RibbonPeriods {
+		b = appendInt64(b, int64(p))
+	}
+	b = append(b, 0xa8, 'f', 'o', 'r', 'm', 'u', 'l', 'a', 's')
+	b = AppendArrayHeader(b, NumFormulas)
+	for _, name := range FormulaNames {
+		b = append(b, 0xa0|byte(len(name)))
+		b = append(b, name...)
+	}
+	b = append(b, 0xa6, 'c', 'u', 's', 't', 'o', 'm')
+	b = AppendArrayHeader(b, NumCustom)
+	for _, name := range CustomFields {
+		b = append(b, 0xa0|byte(len(name)))
+		b = append(b, name...)
+	}
+	b = append(b, 0xa6, 's', 'y', 'm', 'b', 'o', 'l', 0xa0|byte(len(Symbol)))
+	b = append(b, Symbol...)
+	b = append(b, 0xa6, 'l', 'e', 'a', 'd', 'e', 'r', 0xa0|byte(len(LeaderSymbol)))
+	b = append(b, LeaderSymbol...)
+	commit := buildinfo.Get().Commit
+	commit = commit[:min(len(commit), 31)]
+	b = append(b, 0xa5, 'b', 'u', 'i', 'l', 'd', 0xa0|byte(len(commit)))
+	b = append(b, commit...)
+	b = append(b, 0xa4, 'w', 'i', 'r', 'e')
+	b = appendInt64(b, WireVersion)
+	name := enc.String()
+	b = append(b, 0xa8, 'e', 'n', 'c', 'o', 'd', 'i', 'n', 'g', 0xa0|byte(len(name)))
+	b = append(b, name...)
+	scale := int64(1)
+	if enc == EncodingCompact {
+		scale = int64(PriceScale)
+	}
+	b = append(b, 0xab, 'p', 'r', 'i', 'c', 'e', '_', 's', 'c', 'a', 'l', 'e')
+	b = appendInt64(b, scale)
+	return b
+}
+
+// Bucket returns the start (unix seconds) of the bucket containing sec.
+func (tf *Timeframe) Bucket(sec int64) int64 {
+	return (sec-tf.Offset)/tf.Seconds*tf.Seconds + tf.Offset
+}
+
+// ParseTimeframes parses a comma-separated list like "5m,15m,30m,1h,4h,1d,1w".
+// Units: s, m, h, d, w. Known labels keep the tuned default alphas; others get
+// N = 50·√(seconds/300), the curve the defaults roughly follow.
+func ParseTimeframes(spec string) ([]Timeframe, error) {
+	var out []Timeframe
+	for _, label := range strings.Split(spec, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		if len(label) < 2 || len(label) > 31 {
+			return nil, fmt.Errorf("timeframe %q: want <number><s|m|h|d|w>", label)
+		}
+		n, err := strconv.ParseInt(label[:len(label)-1], 10, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("timeframe %q: bad number", label)
+		}
+		var unit int64
+		switch label[len(label)-1] {
+		case 's':
+			unit = 1
+		case 'm':
+			unit = 60
+		case 'h':
+			unit = 3600
+		case 'd':
+			unit = 86400
+		case 'w':
+			unit = 7 * 86400
+		default:
+			return nil, fmt.Errorf("timeframe %q: unknown unit", label)
+		}
+
+		tf := Timeframe{Label: label, Seconds: n * unit}
+		if unit == 7*86400 {
+			tf.Offset = mondayOffset
+		}
+		tf.Alpha = defaultAlpha(tf.Seconds)
+		for _, prev := range out {
+			if prev.Seconds == tf.Seconds {
+				return nil, fmt.Errorf("timeframe %q: duplicates %q", label, prev.Label)
+			}
+		}
+		out = append(out, tf)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no timeframes in %q", spec)
+	}
+	if len(out) > MaxHTF {
+		return nil, fmt.Errorf("%d timeframes, max %d", len(out), MaxHTF)
+	}
+	return out, nil
+}
+
+func defaultAlpha(seconds int64) float64 {
+	for _, tf := range DefaultTimeframes {
+		if tf.Seconds == seconds {
+			return tf.Alpha
+		}
+	}
+	n := math.Max(5, 50*math.Sqrt(float64(seconds)/300))
+	return 2 / (n + 1)
+}
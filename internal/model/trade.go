@@ -11,7 +11,8 @@ type Trade struct {
 	Price    float64
 	Quantity float64
 	Time     int64
-	IsBuyer  bool // true if buyer is maker (aggTrade 'm')
+	IsBuyer  bool  // raw venue side flag (Binance aggTrade 'm': buyer is maker) — read via AggressiveBuy
+	RecvTime int64 // local receive time, unix ns (0 = not stamped, e.g. replay); not on the wire
 }
 
 // AppendMsgPack appends the MsgPack representation of the Trade to the provided buffer.
@@ -0,0 +1,26 @@
+package model
+
+// Transition kinds.
+const (
+	TransitionBias  = 1 // HTF bias (BULLISH / BEARISH / RANGE)
+	TransitionState = 2 // market state (TRENDING_UP / PULLBACK_IN_UPTREND / …)
+)
+
+// Transition — a decision-layer value change, emitted once the new value
+// has held for the confirmation time (see logger.TransitionTracker).
+type Transition struct {
+	Kind  int    // TransitionXxx
+	From  string // "" for the first value after start
+	To    string
+	Time  int64 // unix ms the new value was first seen
+	Prior int64 // ms the previous value held (0 = first value)
+	Price float64
+}
+
+// KindName — "htf_bias" / "market_state".
+func (t *Transition) KindName() string {
+	if t.Kind == TransitionBias {
+		return "htf_bias"
+	}
+	return "market_state"
+}
@@ -0,0 +1,189 @@
+package mqtt
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+)
+
+// =============================================================================
+// MQTT 3.1.1 — the publish-only subset (QoS 0), no client library
+// =============================================================================
+//
+//   packet    type<<4 | flags, remaining length (7-bit varint), body
+//   CONNECT   "MQTT", level 4, flags (clean session, user, password),
+//             keepalive; client id, user, password
+//   CONNACK   session present, return code (0 = accepted)
+//   PUBLISH   QoS 0: topic, payload (flag 0x01 = retain)
+//   PINGREQ / PINGRESP, DISCONNECT
+//
+// QoS 0 has no acknowledgements, so after CONNACK the only traffic from the
+// broker is PINGRESP; reading it is how a dead connection is noticed.
+// =============================================================================
+
+const (
+	typeConnect    = 1
+	typeConnack    = 2
+	typePublish    = 3
+	typePingreq    = 12
+	typePingresp   = 13
+	typeDisconnect = 14
+
+	dialTimeout = 5 * time.Second
+	keepAlive   = 30 * time.Second
+)
+
+// client — one broker connection. Writes from a single goroutine.
+type client struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dial connects and completes CONNECT / CONNACK. broker is
+// tcp://host:1883 (or mqtt://) or ssl://host:8883 (or mqtts://, tls://),
+// with optional user:password@.
+func dial(broker *url.URL, clientID string) (*client, error) {
+	host := broker.Host
+	var conn net.Conn
+	var err error
+	d := &net.Dialer{Timeout: dialTimeout}
+	switch broker.Scheme {
+	case "tcp", "mqtt":
+		if broker.Port() == "" {
+			host += ":1883"
+		}
+		conn, err = d.Dial("tcp", host)
+	case "ssl", "tls", "mqtts":
+		if broker.Port() == "" {
+			host += ":8883"
+		}
+		conn, err = tls.DialWithDialer(d, "tcp", host, &tls.Config{ServerName: broker.Hostname()})
+	default:
+		return nil, fmt.Errorf("mqtt broker scheme %q: want tcp, mqtt, ssl, tls or mqtts", broker.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{conn: conn, r: bufio.NewReader(conn)}
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+	if err := c.connect(broker.User, clientID); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return c, nil
+}
+
+func (c *client) connect(user *url.Userinfo, clientID string) error {
+	var body []byte
+	body = appendString(body, "MQTT")
+	flags := byte(0x02) // clean session
+	if user != nil {
+		flags |= 0x80
+		if _, ok := user.Password(); ok {
+			flags |= 0x40
+		}
+	}
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(keepAlive/time.Second))
+	body = appendString(body, clientID)
+	if user != nil {
+		body = appendString(body, user.Username())
+		if pw, ok := user.Password(); ok {
+			body = appendString(body, pw)
+		}
+	}
+	if err := c.write(typeConnect<<4, body); err != nil {
+		return err
+	}
+
+	typ, resp, err := c.read()
+	if err != nil {
+		return err
+	}
+	if typ != typeConnack || len(resp) != 2 {
+		return errors.New("mqtt: expected CONNACK")
+	}
+	if resp[1] != 0 {
+		return fmt.Errorf("mqtt: connection refused (code %d)", resp[1])
+	}
+	return nil
+}
+
+// publish sends a QoS 0 PUBLISH.
+func (c *client) publish(topic string, payload []byte, retain bool) error {
+	header := byte(typePublish << 4)
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(make([]byte, 0, 2+len(topic)+len(payload)), topic)
+	return c.write(header, append(body, payload...))
+}
+
+func (c *client) ping() error {
+	return c.write(typePingreq<<4, nil)
+}
+
+func (c *client) close() {
+	c.write(typeDisconnect<<4, nil)
+	c.conn.Close()
+}
+
+// write sends one packet.
+func (c *client) write(header byte, body []byte) error {
+	pkt := make([]byte, 0, 5+len(body))
+	pkt = append(pkt, header)
+	for n := len(body); ; {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		pkt = append(pkt, b)
+		if n == 0 {
+			break
+		}
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(dialTimeout))
+	_, err := c.conn.Write(append(pkt, body...))
+	return err
+}
+
+// read reads one packet: its type and body.
+func (c *client) read() (byte, []byte, error) {
+	header, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var n, shift int
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		n |= int(b&0x7F) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		if shift += 7; shift > 21 {
+			return 0, nil, errors.New("mqtt: bad remaining length")
+		}
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+	return header >> 4, body, nil
+}
+
+func appendString(dst []byte, s string) []byte {
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(s)))
+	return append(dst, s...)
+}
@@ -0,0 +1,226 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	csvlogger "market-indikator/internal/logger"
+	"market-indikator/internal/model"
+)
+
+// =============================================================================
+// MQTT SINK — snapshots and alerts for small dashboards
+// =============================================================================
+//
+// ESP32 tickers and phone widgets speak MQTT, not the MsgPack /ws protocol.
+// The sink publishes JSON at QoS 0 under <prefix>/<symbol> (lower case):
+//
+//   <prefix>/btcusdt/snapshot   the latest snapshot, at most once per second,
+//                               retained (a new subscriber gets it at once):
+//                               {"time", "price", "score", "cvd", "oi",
+//                                "imbalance", "htf_bias", "market_state",
+//                                "action"}
+//   <prefix>/btcusdt/alert      not retained, one message per
+//                               HTF bias / market-state transition
+//                               {"type": "transition", "kind", "from", "to", …}
+//                               and per score signal state change
+//                               {"type": "signal", "id", "side", "state", …}
+//
+// The broker connection is redialed with backoff (1s … 30s); while it is
+// down, messages are dropped (counted) — QoS 0 semantics end to end.
+// =============================================================================
+
+const (
+	publishEvery = time.Second
+	maxBackoff   = 30 * time.Second
+)
+
+// Sink — see above.
+type Sink struct {
+	broker   *url.URL
+	clientID string
+	topic    string // <prefix>/<symbol>
+
+	published int64 // atomic
+	drops     int64 // atomic — messages not sent (no connection, write error)
+	dials     int64 // atomic — failed connection attempts
+}
+
+// NewSink — broker as in dial; prefix e.g. "marketind".
+func NewSink(broker, prefix, symbol string) (*Sink, error) {
+	u, err := url.Parse(broker)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("mqtt broker %q: want scheme://host[:port]", broker)
+	}
+	id := fmt.Sprintf("marketind-%s-%d", strings.ToLower(symbol), os.Getpid())
+	return &Sink{
+		broker:   u,
+		clientID: id[:min(len(id), 23)], // MQTT 3.1.1 brokers need only accept 23
+		topic:    strings.TrimSuffix(prefix, "/") + "/" + strings.ToLower(symbol),
+	}, nil
+}
+
+// snapshotMsg — the snapshot topic's JSON.
+type snapshotMsg struct {
+	Time        int64   `json:"time"`
+	Price       float64 `json:"price"`
+	Score       float64 `json:"score"`
+	Confidence  float64 `json:"confidence"`
+	CVD         float64 `json:"cvd"`
+	OI          float64 `json:"oi"`
+	Imbalance   float64 `json:"imbalance"`
+	HTFBias     string  `json:"htf_bias"`
+	MarketState string  `json:"market_state"`
+	Action      string  `json:"action"`
+
+	// HOLD / ADD / REDUCE / EXIT for the user-data position (-position-hints)
+	PositionAction string `json:"position_action,omitempty"`
+}
+
+// Run publishes until snaps closes. transitions / signals may be nil.
+func (s *Sink) Run(snaps <-chan model.Snapshot, transitions <-chan model.Transition, signals <-chan model.Signal) {
+	var (
+		c       *client
+		dead    chan struct{} // closed by the reader when c fails
+		retryAt time.Time
+		backoff = time.Second
+		latest  model.Snapshot
+		pending bool
+	)
+	connect := func() {
+		if c != nil || time.Now().Before(retryAt) {
+			return
+		}
+		var err error
+		if c, err = dial(s.broker, s.clientID); err != nil {
+			if atomic.AddInt64(&s.dials, 1) == 1 || backoff == maxBackoff {
+				log.Printf("MQTT connect to %s failed: %v", s.broker.Redacted(), err)
+			}
+			c, retryAt, backoff = nil, time.Now().Add(backoff), min(2*backoff, maxBackoff)
+			return
+		}
+		log.Printf("MQTT connected to %s, publishing %s/…", s.broker.Redacted(), s.topic)
+		backoff = time.Second
+		dead = make(chan struct{})
+		go func(c *client, dead chan struct{}) {
+			defer close(dead)
+			for {
+				if _, _, err := c.read(); err != nil {
+					return
+				}
+			}
+		}(c, dead)
+	}
+	drop := func() {
+		if c != nil {
+			c.conn.Close()
+			c, dead = nil, nil
+		}
+	}
+	send := func(topic string, v any, retain bool) {
+		payload, err := json.Marshal(v)
+		if err == nil && c != nil {
+			if err = c.publish(topic, payload, retain); err == nil {
+				atomic.AddInt64(&s.published, 1)
+				return
+			}
+			log.Printf("MQTT publish failed: %v", err)
+			drop()
+		}
+		atomic.AddInt64(&s.drops, 1)
+	}
+
+	tick := time.NewTicker(publishEvery)
+	defer tick.Stop()
+	ping := time.NewTicker(keepAlive / 2)
+	defer ping.Stop()
+	connect()
+	for {
+		select {
+		case snap, ok := <-snaps:
+			if !ok {
+				if c != nil {
+					c.close()
+				}
+				return
+			}
+			latest, pending = snap, true
+
+		case <-tick.C:
+			connect()
+			if pending {
+				bias, state, action := csvlogger.DecisionFor(&latest)
+				send(s.topic+"/snapshot", snapshotMsg{
+					Time: latest.Time, Price: latest.Price, Score: round2(latest.FinalScore),
+					Confidence: round2(latest.Confidence), CVD: round2(latest.CVD),
+					OI: latest.OI.OI, Imbalance: round2(latest.Orderbook.Imbalance),
+					HTFBias: bias, MarketState: state, Action: csvlogger.EntryHint(&latest, action),
+					PositionAction: csvlogger.PositionHintFor(&latest, action,
+						latest.Position.Size, latest.Position.EntryPrice),
+				}, true)
+				pending = false
+			}
+
+		case tr, ok := <-transitions:
+			if !ok {
+				transitions = nil
+				continue
+			}
+			send(s.topic+"/alert", map[string]any{
+				"type": "transition", "kind": tr.KindName(), "from": tr.From, "to": tr.To,
+				"time": tr.Time, "prior_sec": tr.Prior / 1000, "price": tr.Price,
+			}, false)
+
+		case sig, ok := <-signals:
+			if !ok {
+				signals = nil
+				continue
+			}
+			at, price := sig.Last()
+			send(s.topic+"/alert", map[string]any{
+				"type": "signal", "id": sig.ID, "side": sig.SideName(), "state": sig.StateName(),
+				"reason": sig.Reason, "time": at, "price": price, "score": round2(sig.Score),
+				"return_bps": round2(sig.ReturnBps),
+			}, false)
+
+		case <-ping.C:
+			if c != nil && c.ping() != nil {
+				drop()
+			}
+
+		case <-dead:
+			log.Printf("MQTT connection to %s lost", s.broker.Redacted())
+			drop()
+		}
+	}
+}
+
+// Published returns the messages published since start.
+func (s *Sink) Published() int64 {
+	return atomic.LoadInt64(&s.published)
+}
+
+// Drops returns the messages dropped while disconnected.
+func (s *Sink) Drops() int64 {
+	return atomic.LoadInt64(&s.drops)
+}
+
+// DialErrors returns the failed connection attempts.
+func (s *Sink) DialErrors() int64 {
+	return atomic.LoadInt64(&s.dials)
+}
+
+// round2 — two decimals keep the payloads short for small devices.
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
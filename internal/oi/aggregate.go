@@ -0,0 +1,124 @@
+package oi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// MULTI-VENUE OPEN INTEREST — Binance + Bybit + OKX
+// =============================================================================
+//
+// Positioning migrates between exchanges: a fund closing 1000 BTC on Binance
+// and reopening it on Bybit reads as a LONG LIQUIDATION on Binance alone
+// while the market's exposure did not change. The aggregator sums the same
+// underlying's OI across venues (base asset units on all three) and feeds
+// the sum to the Engine, so OIDelta, notional and the behavior matrix all
+// run on the aggregate:
+//
+//   OI_agg = Σ_v OI_v     over venues polled within venueStale
+//
+// The Binance poll drives the Engine (every 3s); the other venues' latest
+// readings are folded in as of then. A venue going stale or coming back
+// changes OI_agg by its whole OI — that is membership, not flow, so the
+// Engine's delta baselines are shifted by the same amount instead.
+//
+// Per-venue values go out in State.Venues for the breakdown.
+// =============================================================================
+
+// Venue IDs (State.Venues index, also the wire order).
+const (
+	Binance = 0
+	Bybit   = 1
+	OKX     = 2
+
+	NumVenues = 3
+)
+
+// VenueNames — flag / display name of each venue ID.
+var VenueNames = [NumVenues]string{"binance", "bybit", "okx"}
+
+// venueStale — a venue's last reading older than this drops out of the sum.
+const venueStale = 30 * time.Second
+
+// ParseVenues parses a comma-separated list of extra venues ("bybit,okx").
+// Binance is always included.
+func ParseVenues(spec string) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(spec, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" || f == VenueNames[Binance] {
+			continue
+		}
+		id := -1
+		for i, name := range VenueNames {
+			if name == f {
+				id = i
+			}
+		}
+		if id < 0 {
+			return nil, fmt.Errorf("oi venue %q: want bybit or okx", f)
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+type venueOI struct {
+	oi        float64
+	updatedAt int64 // unix ms, 0 = never
+}
+
+// Aggregator — see above. Update may be called from any goroutine (one
+// poller per venue); writes to the Engine are serialized by mu.
+type Aggregator struct {
+	engine *Engine
+
+	mu     sync.Mutex
+	venues [NumVenues]venueOI
+	in     [NumVenues]bool // venue counted in the last published sum
+}
+
+func NewAggregator(engine *Engine) *Aggregator {
+	return &Aggregator{engine: engine}
+}
+
+// Update records a venue's OI. A Binance reading publishes the aggregate.
+func (a *Aggregator) Update(venue int, oi, currentPrice float64) {
+	a.UpdateAt(venue, oi, currentPrice, time.Now().UnixMilli())
+}
+
+// UpdateAt is Update stamped with nowMs instead of the wall clock.
+func (a *Aggregator) UpdateAt(venue int, oi, currentPrice float64, nowMs int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.venues[venue] = venueOI{oi: oi, updatedAt: nowMs}
+	if venue != Binance {
+		return
+	}
+
+	var sum, shift float64
+	var breakdown [NumVenues]float64
+	for v := range a.venues {
+		vo := &a.venues[v]
+		fresh := vo.updatedAt > 0 && nowMs-vo.updatedAt <= venueStale.Milliseconds()
+		if fresh {
+			breakdown[v] = vo.oi
+			sum += vo.oi
+		}
+		if v != Binance && fresh != a.in[v] {
+			if fresh {
+				shift += vo.oi
+			} else {
+				shift -= vo.oi
+			}
+			a.in[v] = fresh
+		}
+	}
+	if shift != 0 {
+		a.engine.shift(shift)
+	}
+	a.engine.update(sum, currentPrice, nowMs, breakdown)
+}
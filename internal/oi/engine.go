@@ -2,6 +2,7 @@ package oi
 
 import (
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -32,7 +33,9 @@ import (
 //     Existing long positions are being closed (forced or voluntary).
 //     Weakly bearish — longs exiting, often cascading.
 //
-// NEUTRAL: when OI or price change is negligible (below threshold).
+// NEUTRAL: when OI or price change is negligible (below threshold). The OI
+// threshold is 0.01% of OI; the price threshold is per symbol, in basis
+// points, widened with volatility (threshold.go).
 //
 // OI DELTA:
 //   We compute short-term OI change rate:
@@ -41,6 +44,16 @@ import (
 //
 //   These are stored in ring buffers indexed by unix seconds/minutes.
 //
+// NOTIONAL (quote asset, USD for USDT-M):
+//     Notional        = OI × price
+//     NotionalDelta1s = OIDelta1s × price
+//     NotionalDelta1m = OIDelta1m × price
+//   price is the last trade at the poll — within a few bp of the mark price.
+//   The deltas value the contracts opened or closed, not the mark-to-market
+//   change of the whole book, so a pure price move leaves them at zero. A
+//   1000-contract build-up means 10× more money at 100k than at 10k; in
+//   notional terms it compares across price regimes and symbols.
+//
 // =============================================================================
 
 // Behavior classification enum
@@ -59,6 +72,16 @@ type State struct {
 	OIDelta1m  float64 // OI change in last ~1m
 	Behavior   int     // BehaviorXxx enum
 	PriceAtOI  float64 // Price when OI was last sampled
+	UpdatedAt  int64   // Wall-clock unix ms of the last successful poll
+
+	// Notional (quote asset) — see above
+	Notional        float64
+	NotionalDelta1s float64
+	NotionalDelta1m float64
+
+	// Venues — OI per venue in VenueNames order (aggregate.go); the
+	// aggregate is their sum. Binance only: Venues[Binance] = OI.
+	Venues [NumVenues]float64
 }
 
 // Engine maintains OI state and computes behavior classification.
@@ -75,10 +98,13 @@ type Engine struct {
 	ring    [20]float64
 	ringIdx int
 	ringLen int
+
+	// Behavior price threshold (threshold.go)
+	price priceThreshold
 }
 
 func NewEngine() *Engine {
-	e := &Engine{}
+	e := &Engine{price: priceThreshold{bps: DefaultPriceBps, volK: DefaultPriceVolK}}
 	initial := &State{}
 	atomic.StorePointer(&e.state, unsafe.Pointer(initial))
 	return e
@@ -94,9 +120,21 @@ func (e *Engine) GetState() State {
 // Update is called by the OI poller goroutine with fresh data.
 // currentPrice is the latest price from the trade engine (passed in by main).
 func (e *Engine) Update(oi float64, currentPrice float64) {
+	e.UpdateAt(oi, currentPrice, time.Now().UnixMilli())
+}
+
+// UpdateAt is Update stamped with nowMs instead of the wall clock (replays
+// and deterministic test harnesses).
+func (e *Engine) UpdateAt(oi float64, currentPrice float64, nowMs int64) {
+	e.update(oi, currentPrice, nowMs, [NumVenues]float64{Binance: oi})
+}
+
+func (e *Engine) update(oi float64, currentPrice float64, nowMs int64, venues [NumVenues]float64) {
 	s := &State{
 		OI:        oi,
 		PriceAtOI: currentPrice,
+		UpdatedAt: nowMs,
+		Venues:    venues,
 	}
 
 	// ─── OI DELTA (short-term: vs previous poll) ───
@@ -117,6 +155,11 @@ func (e *Engine) Update(oi float64, currentPrice float64) {
 		e.ringLen++
 	}
 
+	// ─── NOTIONAL ───
+	s.Notional = oi * currentPrice
+	s.NotionalDelta1s = s.OIDelta1s * currentPrice
+	s.NotionalDelta1m = s.OIDelta1m * currentPrice
+
 	// ─── BEHAVIOR CLASSIFICATION ───
 	if e.prevOI > 0 && e.prevPrice > 0 {
 		oiChange := oi - e.prevOI
@@ -125,8 +168,9 @@ func (e *Engine) Update(oi float64, currentPrice float64) {
 		// Thresholds to avoid noise
 		// OI must change by at least 0.01% of current OI
 		oiThreshold := e.prevOI * 0.0001
-		// Price must change by at least $1
-		priceThreshold := 1.0
+		// Price must move by more than the symbol's bps or the recent
+		// per-poll volatility, whichever is wider
+		priceThreshold := e.price.at(e.prevPrice)
 
 		oiUp := oiChange > oiThreshold
 		oiDown := oiChange < -oiThreshold
@@ -147,9 +191,22 @@ func (e *Engine) Update(oi float64, currentPrice float64) {
 		}
 	}
 
+	e.price.observe(e.prevPrice, currentPrice)
 	e.prevOI = oi
 	e.prevPrice = currentPrice
 
 	// Atomic publish
 	atomic.StorePointer(&e.state, unsafe.Pointer(s))
 }
+
+// shift moves the delta baselines by d, as if the OI had always included
+// it — a venue joining (+) or leaving (−) the aggregate is not a build-up or
+// an unwind.
+func (e *Engine) shift(d float64) {
+	if e.prevOI > 0 {
+		e.prevOI += d
+	}
+	for i := 0; i < e.ringLen; i++ {
+		e.ring[i] += d
+	}
+}
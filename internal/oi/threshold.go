@@ -0,0 +1,126 @@
+package oi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// BEHAVIOR PRICE THRESHOLD — per-symbol, volatility-scaled
+// =============================================================================
+//
+// The behavior matrix needs a price move that is "not noise". A fixed $1
+// is meaningless across symbols and regimes: at $3k ETH moves $1 on every
+// poll and the classifier flaps, at $100k BTC it is a tenth of a basis
+// point. The threshold is relative instead, and widens with volatility:
+//
+//   σ       EWMA std of poll-to-poll log returns (α = volAlpha ≈ the last
+//           minute of 3s polls)
+//   thr     price × max(Bps / 10⁴, VolK × σ)
+//
+// so a calm book classifies on a Bps move and a violent one only on a move
+// larger than VolK typical polls. Bps is per symbol: a built-in table for
+// the majors (symbolPriceBps, DefaultPriceBps otherwise), overridable with
+// ParsePriceBps. Until σ has volWarmup returns, the Bps floor alone applies.
+//
+// TRADING INTERPRETATION: a LONG BUILDUP now means price moved more than it
+// typically does between polls while OI grew — not that it ticked once.
+// =============================================================================
+
+const (
+	// DefaultPriceBps — Bps for symbols not in symbolPriceBps.
+	DefaultPriceBps = 3.0
+
+	// DefaultPriceVolK — multiple of the per-poll σ a move must exceed.
+	DefaultPriceVolK = 1.0
+
+	volAlpha  = 2.0 / (20 + 1)
+	volWarmup = 5
+)
+
+// symbolPriceBps — Bps of the majors; the rest use DefaultPriceBps.
+var symbolPriceBps = map[string]float64{
+	"BTCUSDT": 1,
+	"ETHUSDT": 2,
+}
+
+// SymbolPriceBps — the built-in Bps of symbol.
+func SymbolPriceBps(symbol string) float64 {
+	if bps, ok := symbolPriceBps[strings.ToUpper(symbol)]; ok {
+		return bps
+	}
+	return DefaultPriceBps
+}
+
+// ParsePriceBps resolves symbol's Bps from a comma-separated spec of
+// SYMBOL=bps entries and an optional bare bps for every other symbol, e.g.
+// "BTCUSDT=0.5,ETHUSDT=2,4". An empty spec (or no entry matching) falls
+// back to SymbolPriceBps.
+func ParsePriceBps(spec, symbol string) (float64, error) {
+	bps := SymbolPriceBps(symbol)
+	fallback, exact := false, false
+	for _, f := range strings.Split(spec, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		sym, val, ok := strings.Cut(f, "=")
+		if !ok {
+			sym, val = "", f
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || v <= 0 {
+			return 0, fmt.Errorf("oi price bps %q: want SYMBOL=bps or bps (> 0)", f)
+		}
+		switch {
+		case !ok && !exact && !fallback:
+			bps, fallback = v, true
+		case ok && strings.EqualFold(strings.TrimSpace(sym), symbol):
+			bps, exact = v, true
+		}
+	}
+	return bps, nil
+}
+
+// priceThreshold — the Engine's behavior price threshold state.
+type priceThreshold struct {
+	bps  float64
+	volK float64
+
+	variance float64 // EWMA of squared poll returns
+	n        int     // returns seen, capped at volWarmup
+}
+
+// observe folds the poll-to-poll move prev → price into σ.
+func (t *priceThreshold) observe(prev, price float64) {
+	if prev <= 0 || price <= 0 {
+		return
+	}
+	r := math.Log(price / prev)
+	if t.n == 0 {
+		t.variance = r * r
+	} else {
+		t.variance += volAlpha * (r*r - t.variance)
+	}
+	if t.n < volWarmup {
+		t.n++
+	}
+}
+
+// at — the threshold (price units) at price.
+func (t *priceThreshold) at(price float64) float64 {
+	rel := t.bps / 1e4
+	if t.n >= volWarmup {
+		rel = math.Max(rel, t.volK*math.Sqrt(t.variance))
+	}
+	return price * rel
+}
+
+// SetPriceThreshold sets the behavior price threshold: bps of price,
+// widened to volK × the per-poll σ (0 = the fixed bps alone). Call before
+// the first Update.
+func (e *Engine) SetPriceThreshold(bps, volK float64) {
+	e.price.bps, e.price.volK = bps, volK
+}
@@ -0,0 +1,421 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// ONNX INFERENCE — small scoring models, pure Go
+// =============================================================================
+//
+// Loads an .onnx file and evaluates its graph on one float32 input row.
+// There is no onnxruntime here (cgo, and a large shared library per
+// platform), so only the operators small tabular models are exported with
+// are implemented:
+//
+//   linear / MLP   MatMul, Gemm, Add, Sub, Mul, Div, Relu, LeakyRelu,
+//                  Sigmoid, Tanh, Softmax, Clip, Identity, Dropout, Cast,
+//                  Flatten, Reshape, Squeeze, Unsqueeze
+//   scikit-learn   ai.onnx.ml LinearRegressor (skl2onnx linear models)
+//
+// — enough for torch.onnx.export of an nn.Sequential of Linear layers and
+// activations, and for skl2onnx linear / ridge / lasso regressions. A model
+// using anything else is refused at load with the operator's name.
+//
+// Graph inputs other than the first non-initializer input are not
+// supported; the model's first output is the result. Node outputs are
+// buffers reused across runs, so after the first Run evaluating a model
+// does not allocate.
+// =============================================================================
+
+// Model — a loaded graph. Not safe for concurrent use.
+type Model struct {
+	Metadata map[string]string // metadata_props (e.g. feature_version)
+
+	input    *tensor
+	inputLen int // last dimension of the declared input, −1 if dynamic
+	output   *tensor
+	nodes    []node
+}
+
+type node struct {
+	op   string
+	run  opFunc
+	in   []*tensor // nil entries for omitted optional inputs
+	out  []*tensor
+	attr attrs
+}
+
+// tensor — float32 data with a shape (integer tensors are held as float32
+// too; they are only shapes and axes here).
+type tensor struct {
+	name  string
+	shape []int
+	data  []float32
+}
+
+func (t *tensor) size() int {
+	n := 1
+	for _, d := range t.shape {
+		n *= d
+	}
+	return n
+}
+
+// resize sets the shape and length, reusing the buffers.
+func (t *tensor) resize(shape ...int) {
+	t.shape = append(t.shape[:0], shape...)
+	n := t.size()
+	if cap(t.data) < n {
+		t.data = make([]float32, n)
+	}
+	t.data = t.data[:n]
+}
+
+type attrs map[string]attr
+
+type attr struct {
+	f      float32
+	i      int64
+	s      string
+	floats []float32
+	ints   []int64
+}
+
+func (a attrs) float(name string, def float32) float32 {
+	if v, ok := a[name]; ok {
+		return v.f
+	}
+	return def
+}
+
+func (a attrs) int(name string, def int64) int64 {
+	if v, ok := a[name]; ok {
+		return v.i
+	}
+	return def
+}
+
+// Load reads and checks an .onnx file.
+func Load(path string) (*Model, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	// Dry run: checks shapes and sizes every buffer
+	in := make([]float32, max(m.inputLen, 1))
+	if _, err := m.Run(in); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// InputLen — the model's input width, −1 if the file leaves it dynamic.
+func (m *Model) InputLen() int { return m.inputLen }
+
+// Run evaluates the graph on one input row and returns the first output's
+// data, valid until the next Run.
+func (m *Model) Run(in []float32) (out []float32, err error) {
+	m.input.resize(1, len(in))
+	copy(m.input.data, in)
+	for i := range m.nodes {
+		n := &m.nodes[i]
+		if err := n.run(n); err != nil {
+			return nil, fmt.Errorf("onnx: %s: %w", n.op, err)
+		}
+	}
+	return m.output.data, nil
+}
+
+// parse decodes ModelProto → GraphProto and wires the nodes.
+func parse(b []byte) (*Model, error) {
+	m := &Model{Metadata: make(map[string]string), inputLen: -1}
+	var graph []byte
+	err := fields(b, func(f field) error {
+		switch f.num {
+		case 7: // graph
+			graph = f.data
+		case 14: // metadata_props
+			var k, v string
+			fields(f.data, func(p field) error {
+				switch p.num {
+				case 1:
+					k = string(p.data)
+				case 2:
+					v = string(p.data)
+				}
+				return nil
+			})
+			m.Metadata[k] = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if graph == nil {
+		return nil, fmt.Errorf("onnx: no graph")
+	}
+
+	values := make(map[string]*tensor)
+	get := func(name string) *tensor {
+		t, ok := values[name]
+		if !ok {
+			t = &tensor{name: name}
+			values[name] = t
+		}
+		return t
+	}
+	var (
+		nodeMsgs [][]byte
+		inputs   []field
+		outputs  []string
+		inits    = make(map[string]bool)
+	)
+	err = fields(graph, func(f field) error {
+		switch f.num {
+		case 1: // node
+			nodeMsgs = append(nodeMsgs, f.data)
+		case 5: // initializer
+			t, err := parseTensor(f.data)
+			if err != nil {
+				return err
+			}
+			*get(t.name) = *t
+			inits[t.name] = true
+		case 11: // input
+			inputs = append(inputs, f)
+		case 12: // output
+			name, _ := valueInfo(f.data)
+			outputs = append(outputs, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range inputs {
+		name, dims := valueInfo(f.data)
+		if inits[name] {
+			continue // IR < 4 lists initializers as inputs
+		}
+		if m.input != nil {
+			return nil, fmt.Errorf("onnx: more than one graph input (%s, %s)", m.input.name, name)
+		}
+		m.input = get(name)
+		if len(dims) > 0 && dims[len(dims)-1] > 0 {
+			m.inputLen = int(dims[len(dims)-1])
+		}
+	}
+	if m.input == nil || len(outputs) == 0 {
+		return nil, fmt.Errorf("onnx: graph needs an input and an output")
+	}
+	m.output = get(outputs[0])
+
+	for _, msg := range nodeMsgs {
+		var (
+			n            node
+			ins, outs    []string
+			domain, kind string
+		)
+		n.attr = make(attrs)
+		err := fields(msg, func(f field) error {
+			switch f.num {
+			case 1:
+				ins = append(ins, string(f.data))
+			case 2:
+				outs = append(outs, string(f.data))
+			case 4:
+				kind = string(f.data)
+			case 5:
+				name, a, err := parseAttr(f.data)
+				if err != nil {
+					return err
+				}
+				n.attr[name] = a
+			case 7:
+				domain = string(f.data)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		n.op = kind
+		if domain != "" && domain != "ai.onnx" {
+			n.op = domain + "." + kind
+		}
+		if n.run = ops[n.op]; n.run == nil {
+			return nil, fmt.Errorf("onnx: unsupported operator %s (supported: %s)", n.op, supported())
+		}
+		for _, name := range ins {
+			if name == "" {
+				n.in = append(n.in, nil)
+			} else {
+				n.in = append(n.in, get(name))
+			}
+		}
+		for _, name := range outs {
+			n.out = append(n.out, get(name))
+		}
+		m.nodes = append(m.nodes, n)
+	}
+	return m, nil
+}
+
+// valueInfo returns a ValueInfoProto's name and dims (0 = symbolic).
+func valueInfo(b []byte) (string, []int64) {
+	var name string
+	var dims []int64
+	fields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			name = string(f.data)
+		case 2: // TypeProto
+			fields(f.data, func(tp field) error {
+				if tp.num != 1 { // tensor_type
+					return nil
+				}
+				return fields(tp.data, func(tt field) error {
+					if tt.num != 2 { // shape
+						return nil
+					}
+					return fields(tt.data, func(d field) error {
+						var v int64
+						fields(d.data, func(dv field) error {
+							if dv.num == 1 {
+								v = int64(dv.v)
+							}
+							return nil
+						})
+						dims = append(dims, v)
+						return nil
+					})
+				})
+			})
+		}
+		return nil
+	})
+	return name, dims
+}
+
+// TensorProto data types.
+const (
+	dtFloat  = 1
+	dtInt32  = 6
+	dtInt64  = 7
+	dtDouble = 11
+)
+
+func parseTensor(b []byte) (*tensor, error) {
+	t := &tensor{}
+	var (
+		dims  []int64
+		dtype int
+		raw   []byte
+		ints  []int64
+		err   error
+	)
+	err = fields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			dims, err = f.ints(dims)
+			return err
+		case 2:
+			dtype = int(f.v)
+		case 4:
+			t.data = f.floats(t.data)
+		case 5, 7: // int32_data, int64_data
+			ints, err = f.ints(ints)
+			return err
+		case 8:
+			t.name = string(f.data)
+		case 9:
+			raw = f.data
+		case 10:
+			t.data = f.doubles(t.data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dims {
+		t.shape = append(t.shape, int(d))
+	}
+	for _, v := range ints {
+		t.data = append(t.data, float32(v))
+	}
+	if raw != nil {
+		switch dtype {
+		case dtFloat:
+			for ; len(raw) >= 4; raw = raw[4:] {
+				t.data = append(t.data, math.Float32frombits(binary.LittleEndian.Uint32(raw)))
+			}
+		case dtDouble:
+			for ; len(raw) >= 8; raw = raw[8:] {
+				t.data = append(t.data, float32(math.Float64frombits(binary.LittleEndian.Uint64(raw))))
+			}
+		case dtInt64:
+			for ; len(raw) >= 8; raw = raw[8:] {
+				t.data = append(t.data, float32(int64(binary.LittleEndian.Uint64(raw))))
+			}
+		case dtInt32:
+			for ; len(raw) >= 4; raw = raw[4:] {
+				t.data = append(t.data, float32(int32(binary.LittleEndian.Uint32(raw))))
+			}
+		default:
+			return nil, fmt.Errorf("onnx: initializer %s: data type %d", t.name, dtype)
+		}
+	}
+	if t.size() != len(t.data) {
+		return nil, fmt.Errorf("onnx: initializer %s: %d values for shape %v", t.name, len(t.data), t.shape)
+	}
+	return t, nil
+}
+
+func parseAttr(b []byte) (string, attr, error) {
+	var (
+		name string
+		a    attr
+		err  error
+	)
+	err = fields(b, func(f field) error {
+		switch f.num {
+		case 1:
+			name = string(f.data)
+		case 2:
+			a.f = math.Float32frombits(uint32(f.v))
+		case 3:
+			a.i = int64(f.v)
+		case 4:
+			a.s = string(f.data)
+		case 7:
+			a.floats = f.floats(a.floats)
+		case 8:
+			a.ints, err = f.ints(a.ints)
+			return err
+		}
+		return nil
+	})
+	return name, a, err
+}
+
+func supported() string {
+	names := make([]string, 0, len(ops))
+	for name := range ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,388 @@
+package onnx
+
+import (
+	"fmt"
+	"math"
+)
+
+// opFunc evaluates one node into its (reused) output tensors.
+type opFunc func(n *node) error
+
+// ops — the supported operators, by op_type (domain-qualified outside the
+// default domain).
+var ops = map[string]opFunc{
+	"MatMul":    opMatMul,
+	"Gemm":      opGemm,
+	"Add":       broadcast(func(a, b float32) float32 { return a + b }),
+	"Sub":       broadcast(func(a, b float32) float32 { return a - b }),
+	"Mul":       broadcast(func(a, b float32) float32 { return a * b }),
+	"Div":       broadcast(func(a, b float32) float32 { return a / b }),
+	"Relu":      unary(func(x float32) float32 { return max(x, 0) }),
+	"Sigmoid":   unary(func(x float32) float32 { return float32(1 / (1 + math.Exp(-float64(x)))) }),
+	"Tanh":      unary(func(x float32) float32 { return float32(math.Tanh(float64(x))) }),
+	"Identity":  unary(func(x float32) float32 { return x }),
+	"Dropout":   unary(func(x float32) float32 { return x }), // inference: identity
+	"Cast":      unary(func(x float32) float32 { return x }), // everything is float32 here
+	"LeakyRelu": opLeakyRelu,
+	"Softmax":   opSoftmax,
+	"Clip":      opClip,
+	"Flatten":   opFlatten,
+	"Reshape":   opReshape,
+	"Squeeze":   opSqueeze,
+	"Unsqueeze": opUnsqueeze,
+
+	"ai.onnx.ml.LinearRegressor": opLinearRegressor,
+}
+
+func unary(fn func(float32) float32) opFunc {
+	return func(n *node) error {
+		in, out := n.in[0], n.out[0]
+		out.resize(in.shape...)
+		for i, x := range in.data {
+			out.data[i] = fn(x)
+		}
+		return nil
+	}
+}
+
+// broadcast — element-wise with multidirectional (numpy) broadcasting.
+func broadcast(fn func(a, b float32) float32) opFunc {
+	return func(n *node) error {
+		a, b, out := n.in[0], n.in[1], n.out[0]
+		rank := max(len(a.shape), len(b.shape))
+		if rank > maxRank {
+			return fmt.Errorf("rank %d", rank)
+		}
+		var shape, sa, sb [maxRank]int
+		stride := func(t *tensor, s *[maxRank]int) {
+			step := 1
+			for i := len(t.shape) - 1; i >= 0; i-- {
+				if d := t.shape[i]; d != 1 {
+					s[rank-len(t.shape)+i] = step
+				}
+				step *= t.shape[i]
+			}
+		}
+		for i := 0; i < rank; i++ {
+			da, db := dimAt(a.shape, rank, i), dimAt(b.shape, rank, i)
+			switch {
+			case da == db, db == 1:
+				shape[i] = da
+			case da == 1:
+				shape[i] = db
+			default:
+				return fmt.Errorf("shapes %v and %v don't broadcast", a.shape, b.shape)
+			}
+		}
+		stride(a, &sa)
+		stride(b, &sb)
+		out.resize(shape[:rank]...)
+
+		var idx [maxRank]int
+		ia, ib := 0, 0
+		for k := range out.data {
+			out.data[k] = fn(a.data[ia], b.data[ib])
+			for d := rank - 1; d >= 0; d-- { // odometer increment
+				idx[d]++
+				ia += sa[d]
+				ib += sb[d]
+				if idx[d] < shape[d] {
+					break
+				}
+				ia -= sa[d] * idx[d]
+				ib -= sb[d] * idx[d]
+				idx[d] = 0
+			}
+		}
+		return nil
+	}
+}
+
+const maxRank = 6
+
+func dimAt(shape []int, rank, i int) int {
+	if j := i - (rank - len(shape)); j >= 0 {
+		return shape[j]
+	}
+	return 1
+}
+
+// opMatMul — A [..., K] × B [K, N] → [..., N].
+func opMatMul(n *node) error {
+	a, b, out := n.in[0], n.in[1], n.out[0]
+	if len(a.shape) == 0 || len(b.shape) != 2 || a.shape[len(a.shape)-1] != b.shape[0] {
+		return fmt.Errorf("shapes %v × %v", a.shape, b.shape)
+	}
+	k, cols := b.shape[0], b.shape[1]
+	rows := a.size() / k
+	var shape [maxRank]int
+	r := copy(shape[:], a.shape[:len(a.shape)-1])
+	shape[r] = cols
+	out.resize(shape[:r+1]...)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			var sum float32
+			for x := 0; x < k; x++ {
+				sum += a.data[i*k+x] * b.data[x*cols+j]
+			}
+			out.data[i*cols+j] = sum
+		}
+	}
+	return nil
+}
+
+// opGemm — alpha · A' × B' + beta · C.
+func opGemm(n *node) error {
+	a, b, out := n.in[0], n.in[1], n.out[0]
+	if len(a.shape) != 2 || len(b.shape) != 2 {
+		return fmt.Errorf("shapes %v, %v: want matrices", a.shape, b.shape)
+	}
+	alpha, beta := n.attr.float("alpha", 1), n.attr.float("beta", 1)
+	ta, tb := n.attr.int("transA", 0) != 0, n.attr.int("transB", 0) != 0
+	m, k := a.shape[0], a.shape[1]
+	if ta {
+		m, k = k, m
+	}
+	kb, cols := b.shape[0], b.shape[1]
+	if tb {
+		kb, cols = cols, kb
+	}
+	if k != kb {
+		return fmt.Errorf("shapes %v × %v", a.shape, b.shape)
+	}
+	out.resize(m, cols)
+	for i := 0; i < m; i++ {
+		for j := 0; j < cols; j++ {
+			var sum float32
+			for x := 0; x < k; x++ {
+				av, bv := a.data[i*k+x], b.data[x*cols+j]
+				if ta {
+					av = a.data[x*m+i]
+				}
+				if tb {
+					bv = b.data[j*k+x]
+				}
+				sum += av * bv
+			}
+			out.data[i*cols+j] = alpha * sum
+		}
+	}
+	if len(n.in) > 2 && n.in[2] != nil {
+		c := n.in[2]
+		for i := 0; i < m; i++ {
+			for j := 0; j < cols; j++ {
+				var cv float32
+				switch c.size() {
+				case 1:
+					cv = c.data[0]
+				case cols:
+					cv = c.data[j]
+				case m:
+					cv = c.data[i]
+				default:
+					cv = c.data[i*cols+j]
+				}
+				out.data[i*cols+j] += beta * cv
+			}
+		}
+	}
+	return nil
+}
+
+func opLeakyRelu(n *node) error {
+	alpha := n.attr.float("alpha", 0.01)
+	in, out := n.in[0], n.out[0]
+	out.resize(in.shape...)
+	for i, x := range in.data {
+		if x < 0 {
+			x *= alpha
+		}
+		out.data[i] = x
+	}
+	return nil
+}
+
+// opSoftmax — over the last axis.
+func opSoftmax(n *node) error {
+	in, out := n.in[0], n.out[0]
+	out.resize(in.shape...)
+	if len(in.shape) == 0 {
+		out.data[0] = 1
+		return nil
+	}
+	w := in.shape[len(in.shape)-1]
+	for r := 0; r+w <= len(in.data); r += w {
+		hi := in.data[r]
+		for _, x := range in.data[r : r+w] {
+			hi = max(hi, x)
+		}
+		var sum float64
+		for i, x := range in.data[r : r+w] {
+			e := math.Exp(float64(x - hi))
+			out.data[r+i] = float32(e)
+			sum += e
+		}
+		for i := r; i < r+w; i++ {
+			out.data[i] = float32(float64(out.data[i]) / sum)
+		}
+	}
+	return nil
+}
+
+// opClip — bounds from inputs 1, 2 (opset ≥ 11) or the min / max attributes.
+func opClip(n *node) error {
+	lo := n.attr.float("min", float32(math.Inf(-1)))
+	hi := n.attr.float("max", float32(math.Inf(1)))
+	if len(n.in) > 1 && n.in[1] != nil {
+		lo = n.in[1].data[0]
+	}
+	if len(n.in) > 2 && n.in[2] != nil {
+		hi = n.in[2].data[0]
+	}
+	in, out := n.in[0], n.out[0]
+	out.resize(in.shape...)
+	for i, x := range in.data {
+		out.data[i] = min(max(x, lo), hi)
+	}
+	return nil
+}
+
+func opFlatten(n *node) error {
+	in, out := n.in[0], n.out[0]
+	axis := int(n.attr.int("axis", 1))
+	if axis < 0 {
+		axis += len(in.shape)
+	}
+	outer := 1
+	for _, d := range in.shape[:min(axis, len(in.shape))] {
+		outer *= d
+	}
+	out.resize(outer, in.size()/max(outer, 1))
+	copy(out.data, in.data)
+	return nil
+}
+
+func opReshape(n *node) error {
+	in, spec, out := n.in[0], n.in[1], n.out[0]
+	if len(spec.data) > maxRank {
+		return fmt.Errorf("rank %d", len(spec.data))
+	}
+	var shape [maxRank]int
+	known, infer := 1, -1
+	for i, v := range spec.data {
+		switch d := int(v); {
+		case d == 0 && i < len(in.shape):
+			shape[i] = in.shape[i]
+		case d == -1:
+			infer = i
+			continue
+		default:
+			shape[i] = d
+		}
+		known *= shape[i]
+	}
+	if infer >= 0 {
+		shape[infer] = in.size() / max(known, 1)
+	}
+	out.resize(shape[:len(spec.data)]...)
+	if out.size() != in.size() {
+		return fmt.Errorf("reshape %v to %v", in.shape, out.shape)
+	}
+	copy(out.data, in.data)
+	return nil
+}
+
+// axes — from the attribute (opset < 13) or input 1.
+func axes(n *node, rank int) (set [maxRank + 1]bool, any bool) {
+	var list []int64
+	if a, ok := n.attr["axes"]; ok {
+		list = a.ints
+	}
+	for _, ax := range list {
+		if ax < 0 {
+			ax += int64(rank)
+		}
+		if ax >= 0 && ax <= maxRank {
+			set[ax], any = true, true
+		}
+	}
+	if len(n.in) > 1 && n.in[1] != nil {
+		for _, v := range n.in[1].data {
+			ax := int(v)
+			if ax < 0 {
+				ax += rank
+			}
+			if ax >= 0 && ax <= maxRank {
+				set[ax], any = true, true
+			}
+		}
+	}
+	return set, any
+}
+
+func opSqueeze(n *node) error {
+	in, out := n.in[0], n.out[0]
+	set, any := axes(n, len(in.shape))
+	var shape [maxRank]int
+	r := 0
+	for i, d := range in.shape {
+		if d == 1 && (!any || set[i]) {
+			continue
+		}
+		shape[r] = d
+		r++
+	}
+	out.resize(shape[:r]...)
+	copy(out.data, in.data)
+	return nil
+}
+
+func opUnsqueeze(n *node) error {
+	in, out := n.in[0], n.out[0]
+	rank := len(in.shape)
+	set, _ := axes(n, rank+1) // negative axes count in the output rank
+	var shape [maxRank]int
+	r, j := 0, 0
+	for r < maxRank && (j < rank || set[r]) {
+		if set[r] {
+			shape[r] = 1
+		} else {
+			shape[r] = in.shape[j]
+			j++
+		}
+		r++
+	}
+	out.resize(shape[:r]...)
+	copy(out.data, in.data)
+	return nil
+}
+
+// opLinearRegressor — ai.onnx.ml: y[t] = Σ x·coef[t] + intercept[t].
+func opLinearRegressor(n *node) error {
+	in, out := n.in[0], n.out[0]
+	coef, intercepts := n.attr["coefficients"].floats, n.attr["intercepts"].floats
+	targets := int(n.attr.int("targets", 1))
+	if post := n.attr["post_transform"].s; post != "" && post != "NONE" {
+		return fmt.Errorf("post_transform %s", post)
+	}
+	width := in.shape[len(in.shape)-1]
+	if len(coef) != targets*width {
+		return fmt.Errorf("%d coefficients for %d inputs × %d targets", len(coef), width, targets)
+	}
+	rows := in.size() / width
+	out.resize(rows, targets)
+	for r := 0; r < rows; r++ {
+		x := in.data[r*width : (r+1)*width]
+		for t := 0; t < targets; t++ {
+			var y float32
+			if t < len(intercepts) {
+				y = intercepts[t]
+			}
+			for i, c := range coef[t*width : (t+1)*width] {
+				y += c * x[i]
+			}
+			out.data[r*targets+t] = y
+		}
+	}
+	return nil
+}
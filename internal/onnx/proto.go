@@ -0,0 +1,107 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// =============================================================================
+// PROTOBUF WIRE FORMAT — the reading half, for ONNX model files
+// =============================================================================
+//
+//   key     varint  field << 3 | wire type
+//   types   0 varint, 1 fixed64, 2 length-delimited, 5 fixed32
+//
+// Repeated scalars may be packed (one length-delimited run) or not; the
+// readers below accept both, as protobuf requires.
+// =============================================================================
+
+var errTruncated = errors.New("onnx: truncated protobuf")
+
+// field — one decoded protobuf field.
+type field struct {
+	num  int
+	typ  int
+	v    uint64 // varint / fixed value
+	data []byte // length-delimited payload
+}
+
+// fields calls fn for every field of a message.
+func fields(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errTruncated
+		}
+		b = b[n:]
+		f := field{num: int(key >> 3), typ: int(key & 7)}
+		switch f.typ {
+		case 0:
+			if f.v, n = binary.Uvarint(b); n <= 0 {
+				return errTruncated
+			}
+			b = b[n:]
+		case 1:
+			if len(b) < 8 {
+				return errTruncated
+			}
+			f.v, b = binary.LittleEndian.Uint64(b), b[8:]
+		case 2:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return errTruncated
+			}
+			f.data, b = b[n:n+int(l)], b[n+int(l):]
+		case 5:
+			if len(b) < 4 {
+				return errTruncated
+			}
+			f.v, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		default:
+			return fmt.Errorf("onnx: protobuf wire type %d", f.typ)
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ints appends a repeated int64 field (packed or not).
+func (f field) ints(dst []int64) ([]int64, error) {
+	if f.typ == 0 {
+		return append(dst, int64(f.v)), nil
+	}
+	for b := f.data; len(b) > 0; {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		dst, b = append(dst, int64(v)), b[n:]
+	}
+	return dst, nil
+}
+
+// floats appends a repeated float field (packed or not).
+func (f field) floats(dst []float32) []float32 {
+	if f.typ == 5 {
+		return append(dst, math.Float32frombits(uint32(f.v)))
+	}
+	for b := f.data; len(b) >= 4; b = b[4:] {
+		dst = append(dst, math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+	return dst
+}
+
+// doubles appends a repeated double field, as float32.
+func (f field) doubles(dst []float32) []float32 {
+	if f.typ == 1 {
+		return append(dst, float32(math.Float64frombits(f.v)))
+	}
+	for b := f.data; len(b) >= 8; b = b[8:] {
+		dst = append(dst, float32(math.Float64frombits(binary.LittleEndian.Uint64(b))))
+	}
+	return dst
+}
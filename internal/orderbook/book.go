@@ -2,6 +2,7 @@ package orderbook
 
 import (
 	"sync/atomic"
+	"time"
 	"unsafe"
 )
 
@@ -47,11 +48,20 @@ import (
 //      )
 //    Default weights: w1=0.5, w2=0.3, w3=0.2
 //
+// 5) CENTER OF GRAVITY (per side, all kept levels):
+//      BidCoG = Σ p_i·q_i / Σ q_i   over bid levels   (likewise AskCoG)
+//      d_bid  = mid - BidCoG,  d_ask = AskCoG - mid
+//      CoGSkew = (d_ask - d_bid) / (d_ask + d_bid)    ∈ [-1, +1]
+//    Where the liquidity sits, not how much of it: +1 = bids hug the touch
+//    while asks sit back (support close to price, supply set away), -1 =
+//    the reverse. Equal volumes at different distances read the same to
+//    Imbalance but not here. Not part of the pressure score.
+//
 // =============================================================================
 
 const (
-	MaxDepthLevels  = 20 // we track top 20 levels
-	ImbalanceLevels = 10 // use top 10 for imbalance calc
+	MaxDepthLevels  = 20 // capacity: the most levels any depth stream sends
+	ImbalanceLevels = 10 // default levels summed for the imbalance calc
 )
 
 // PriceLevel is a single bid or ask level.
@@ -69,9 +79,16 @@ type Pressure struct {
 	BidVol    float64 // Total bid volume (top N levels)
 	AskVol    float64 // Total ask volume (top N levels)
 	Imbalance float64 // [-1, +1] volume imbalance
+	MaxBidQty float64 // Largest single bid level (all kept levels) — the bid wall
+	MaxAskQty float64 // Largest single ask level
+	BidCoG    float64 // Volume-weighted bid price (all kept levels)
+	AskCoG    float64 // Volume-weighted ask price
+	CoGSkew   float64 // [-1, +1] ask vs bid CoG distance from mid
 	LiqVel    float64 // Liquidity velocity (bid growth - ask growth)
 	Absorb    float64 // Absorption score [0, 1]
 	Score     int     // Pressure score [-100, +100]
+	UpdatedAt int64   // Wall-clock unix ms of the depth update that produced this
+	Suspect   int     // Suspect* reasons of the latest frame, dropped (0 = clean; see sanity.go)
 }
 
 // Book maintains the L2 orderbook and computes pressure metrics.
@@ -83,6 +100,9 @@ type Book struct {
 	BidN int // number of active bid levels
 	AskN int // number of active ask levels
 
+	levels    int // levels kept per side (≤ MaxDepthLevels)
+	imbLevels int // levels summed for BidVol/AskVol (≤ levels)
+
 	// Previous state for velocity calculation
 	prevBidVol float64
 	prevAskVol float64
@@ -96,17 +116,55 @@ type Book struct {
 	askStableCount int
 	askVolRecovery float64
 
+	// Depth sanity baselines (see sanity.go)
+	lastEventMs int64   // exchange event time of the last applied frame
+	meanQty     float64 // EWMA of the mean level quantity
+	accepted    int     // frames in meanQty
+	suspectRun  int     // consecutive suspect frames
+	suspects    int64   // atomic — suspect frames dropped
+
 	// Atomic pointer for lock-free sharing with engine goroutine
 	pressure unsafe.Pointer // *Pressure
+
+	// Copy of the levels for off-goroutine readers (depth logger)
+	depth unsafe.Pointer // *Depth
+}
+
+// Depth is a point-in-time copy of the book levels.
+type Depth struct {
+	Time int64 // wall-clock unix ms of the update
+	Bids [MaxDepthLevels]PriceLevel
+	Asks [MaxDepthLevels]PriceLevel
+	BidN int
+	AskN int
 }
 
 func NewBook() *Book {
-	b := &Book{}
+	b := &Book{levels: MaxDepthLevels, imbLevels: ImbalanceLevels}
 	initial := &Pressure{}
 	atomic.StorePointer(&b.pressure, unsafe.Pointer(initial))
+	atomic.StorePointer(&b.depth, unsafe.Pointer(&Depth{}))
 	return b
 }
 
+// SetLevels sets how many levels per side the book keeps and how many of
+// them the imbalance sums (defaults MaxDepthLevels and ImbalanceLevels;
+// both capped, imbalance at levels). Must be called before the first update.
+func (b *Book) SetLevels(levels, imbalance int) {
+	b.levels = clampI(levels, 1, MaxDepthLevels)
+	b.imbLevels = clampI(imbalance, 1, b.levels)
+}
+
+// Levels — levels kept per side and levels in the imbalance.
+func (b *Book) Levels() (levels, imbalance int) {
+	return b.levels, b.imbLevels
+}
+
+// GetDepth returns the latest level snapshot. LOCK-FREE, safe from any goroutine.
+func (b *Book) GetDepth() Depth {
+	return *(*Depth)(atomic.LoadPointer(&b.depth))
+}
+
 // GetPressure returns the latest pressure snapshot.
 // LOCK-FREE: uses atomic load, safe for concurrent reads from any goroutine.
 // ~1ns latency.
@@ -118,25 +176,43 @@ func (b *Book) GetPressure() Pressure {
 // UpdateDepth replaces the full depth snapshot (from Binance partial depth stream).
 // Called from the depth ingest goroutine ONLY — single writer, no locks needed.
 //
-// bids and asks are sorted by price (bids descending, asks ascending) from Binance.
-func (b *Book) UpdateDepth(bids, asks []PriceLevel) {
+// bids and asks are sorted by price (bids descending, asks ascending) from Binance;
+// eventMs is the frame's exchange event time (0 = unknown). A frame failing the
+// sanity checks is dropped (sanity.go); ErrResync means the caller should
+// reconnect the stream.
+func (b *Book) UpdateDepth(bids, asks []PriceLevel, eventMs int64) error {
+	return b.UpdateDepthAt(bids, asks, eventMs, time.Now().UnixMilli())
+}
+
+// UpdateDepthAt is UpdateDepth stamped with nowMs instead of the wall clock
+// (replays and deterministic test harnesses).
+func (b *Book) UpdateDepthAt(bids, asks []PriceLevel, eventMs, nowMs int64) error {
+	if reason := b.check(bids, asks, eventMs); reason != 0 {
+		return b.reject(reason)
+	}
+	b.accept(bids, asks, eventMs)
+
 	// Copy into fixed arrays (zero allocation, just field writes)
-	b.BidN = min(len(bids), MaxDepthLevels)
+	b.BidN = min(len(bids), b.levels)
 	for i := 0; i < b.BidN; i++ {
 		b.Bids[i] = bids[i]
 	}
 
-	b.AskN = min(len(asks), MaxDepthLevels)
+	b.AskN = min(len(asks), b.levels)
 	for i := 0; i < b.AskN; i++ {
 		b.Asks[i] = asks[i]
 	}
 
 	// Compute metrics and publish atomically
-	b.computeAndPublish()
+	b.computeAndPublish(nowMs)
+
+	d := &Depth{Time: nowMs, Bids: b.Bids, Asks: b.Asks, BidN: b.BidN, AskN: b.AskN}
+	atomic.StorePointer(&b.depth, unsafe.Pointer(d))
+	return nil
 }
 
-func (b *Book) computeAndPublish() {
-	p := &Pressure{}
+func (b *Book) computeAndPublish(nowMs int64) {
+	p := &Pressure{UpdatedAt: nowMs}
 
 	if b.BidN == 0 || b.AskN == 0 {
 		atomic.StorePointer(&b.pressure, unsafe.Pointer(p))
@@ -149,15 +225,42 @@ func (b *Book) computeAndPublish() {
 	p.Spread = p.BestAsk - p.BestBid
 
 	// ─── VOLUME SUMS (top N levels) ───
-	levels := min(ImbalanceLevels, b.BidN)
+	levels := min(b.imbLevels, b.BidN)
 	for i := 0; i < levels; i++ {
 		p.BidVol += b.Bids[i].Quantity
 	}
-	levels = min(ImbalanceLevels, b.AskN)
+	levels = min(b.imbLevels, b.AskN)
 	for i := 0; i < levels; i++ {
 		p.AskVol += b.Asks[i].Quantity
 	}
 
+	// ─── WALLS (largest single level per side) ───
+	for i := 0; i < b.BidN; i++ {
+		p.MaxBidQty = max(p.MaxBidQty, b.Bids[i].Quantity)
+	}
+	for i := 0; i < b.AskN; i++ {
+		p.MaxAskQty = max(p.MaxAskQty, b.Asks[i].Quantity)
+	}
+
+	// ─── CENTER OF GRAVITY ───
+	var bidW, bidQ, askW, askQ float64
+	for i := 0; i < b.BidN; i++ {
+		bidW += b.Bids[i].Price * b.Bids[i].Quantity
+		bidQ += b.Bids[i].Quantity
+	}
+	for i := 0; i < b.AskN; i++ {
+		askW += b.Asks[i].Price * b.Asks[i].Quantity
+		askQ += b.Asks[i].Quantity
+	}
+	if bidQ > 0 && askQ > 0 {
+		p.BidCoG, p.AskCoG = bidW/bidQ, askW/askQ
+		mid := (p.BestBid + p.BestAsk) / 2
+		dBid, dAsk := mid-p.BidCoG, p.AskCoG-mid
+		if dBid+dAsk > 0 {
+			p.CoGSkew = clampF((dAsk-dBid)/(dAsk+dBid), -1, 1)
+		}
+	}
+
 	// ─── IMBALANCE ───
 	total := p.BidVol + p.AskVol
 	if total > 0 {
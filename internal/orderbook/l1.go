@@ -0,0 +1,130 @@
+package orderbook
+
+import (
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// =============================================================================
+// L1 TRACKER — best bid / ask from the bookTicker stream
+// =============================================================================
+//
+// Partial depth arrives every 100ms at best; bookTicker pushes every change
+// of the best bid or ask as it happens. The tracker keeps the latest quote
+// and three metrics that only make sense at that resolution:
+//
+// 1) MICROPRICE — the mid weighted toward the side about to be consumed:
+//      Micro = (Bid × AskQty + Ask × BidQty) / (BidQty + AskQty)
+//    A thin ask (AskQty → 0) pulls Micro toward Ask: the next print is
+//    likely an uptick.
+//
+// 2) SPREAD BLOWOUT:
+//      SpreadRatio = Spread / EWMA(Spread)
+//    1 = normal; market makers pulling quotes (news, liquidation cascade)
+//    widen the spread several-fold before the depth snapshot shows it.
+//
+// 3) ORDER FLOW IMBALANCE (Cont, Kukanov & Stoikov 2014) — per quote update n:
+//      e_n = 1{Pb_n ≥ Pb_n-1}·Qb_n − 1{Pb_n ≤ Pb_n-1}·Qb_n-1
+//          − 1{Pa_n ≤ Pa_n-1}·Qa_n + 1{Pa_n ≥ Pa_n-1}·Qa_n-1
+//    Bid added / ask cancelled or hit → positive; the reverse → negative.
+//    OFI is the running Σe_n; the engine differences it over its windows.
+//
+// TRADING INTERPRETATION:
+//   Sustained positive OFI with Micro above mid = passive demand building at
+//   the touch; OFI flipping negative while CVD still rises = buyers lifting
+//   into a retreating bid — late. SpreadRatio > 3 marks the book as unsafe
+//   to read: depth-based pressure lags the quote.
+//
+// Single writer (the bookTicker ingest goroutine); readers get the latest
+// L1 through an atomic pointer, as with Book.GetPressure.
+// =============================================================================
+
+const spreadAlpha = 0.01 // EWMA weight of one quote's spread (≈100 updates)
+
+// L1 — the latest best bid / ask and its metrics.
+type L1 struct {
+	Bid, BidQty float64
+	Ask, AskQty float64
+	Spread      float64
+	Micro       float64 // microprice
+	SpreadRatio float64 // Spread / EWMA(Spread)
+	OFI         float64 // cumulative order flow imbalance since start
+	UpdateID    int64   // exchange update id of the quote
+	UpdatedAt   int64   // wall-clock unix ms
+}
+
+// L1Tracker — see above.
+type L1Tracker struct {
+	cur unsafe.Pointer // *L1
+
+	// Writer only
+	last       L1
+	spreadEWMA float64
+	updates    int64 // atomic
+}
+
+func NewL1Tracker() *L1Tracker {
+	t := &L1Tracker{}
+	atomic.StorePointer(&t.cur, unsafe.Pointer(&L1{}))
+	return t
+}
+
+// Get returns the latest quote. LOCK-FREE, safe from any goroutine; zero
+// until the first update.
+func (t *L1Tracker) Get() L1 {
+	return *(*L1)(atomic.LoadPointer(&t.cur))
+}
+
+// Updates returns how many quotes have been applied.
+func (t *L1Tracker) Updates() int64 {
+	return atomic.LoadInt64(&t.updates)
+}
+
+// Update applies a quote. Called from the bookTicker ingest goroutine ONLY.
+func (t *L1Tracker) Update(bid, bidQty, ask, askQty float64, updateID int64) {
+	t.UpdateAt(bid, bidQty, ask, askQty, updateID, time.Now().UnixMilli())
+}
+
+// UpdateAt is Update stamped with nowMs instead of the wall clock. Quotes
+// older than the last one (update id going backwards, e.g. across a
+// reconnect overlap) and crossed or empty quotes are ignored.
+func (t *L1Tracker) UpdateAt(bid, bidQty, ask, askQty float64, updateID, nowMs int64) {
+	if updateID != 0 && updateID <= t.last.UpdateID {
+		return
+	}
+	if !(bid > 0) || !(ask > bid) || !(bidQty > 0) || !(askQty > 0) {
+		return
+	}
+
+	q := &L1{Bid: bid, BidQty: bidQty, Ask: ask, AskQty: askQty, UpdateID: updateID, UpdatedAt: nowMs}
+	q.Spread = ask - bid
+	q.Micro = (bid*askQty + ask*bidQty) / (bidQty + askQty)
+
+	if t.spreadEWMA == 0 {
+		t.spreadEWMA = q.Spread
+	} else {
+		t.spreadEWMA += spreadAlpha * (q.Spread - t.spreadEWMA)
+	}
+	q.SpreadRatio = q.Spread / t.spreadEWMA
+
+	q.OFI = t.last.OFI
+	if p := &t.last; p.Bid > 0 {
+		if bid >= p.Bid {
+			q.OFI += bidQty
+		}
+		if bid <= p.Bid {
+			q.OFI -= p.BidQty
+		}
+		if ask <= p.Ask {
+			q.OFI -= askQty
+		}
+		if ask >= p.Ask {
+			q.OFI += p.AskQty
+		}
+	}
+
+	t.last = *q
+	atomic.AddInt64(&t.updates, 1)
+	atomic.StorePointer(&t.cur, unsafe.Pointer(q))
+}
@@ -0,0 +1,164 @@
+package orderbook
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"unsafe"
+)
+
+// =============================================================================
+// DEPTH SANITY — crossed books, stale frames, quantity spikes
+// =============================================================================
+//
+// A partial depth frame replaces the whole book, so one bad frame rewrites
+// every pressure metric at once. Each frame is checked before it is applied:
+//
+//   SuspectCrossed   bestBid ≥ bestAsk — crossed, or locked at one price.
+//                    Binance never publishes this; it is a corrupted or
+//                    half-applied frame
+//   SuspectUnsorted  bids not strictly descending, asks not strictly
+//                    ascending, or a price / quantity that is not a finite
+//                    positive number
+//   SuspectStale     exchange event time earlier than the last applied
+//                    frame's (out-of-order delivery, a replayed frame)
+//   SuspectSpike     a level's quantity above spikeFactor × the running mean
+//                    level quantity (EWMA over accepted frames, armed after
+//                    spikeWarmup frames)
+//
+// A suspect frame is dropped: the levels and the velocity / absorption state
+// keep the last good frame, and the published Pressure is that frame's with
+// Suspect set to the reasons (UpdatedAt unchanged, so a book stuck on bad
+// frames also ages into stale). The engine drops the passive domain for a
+// suspect book, as for a stale one.
+//
+// One bad frame is noise; resyncAfter in a row means the stream itself is
+// off, and UpdateDepth returns ErrResync so the ingester reconnects — every
+// partial depth frame is a full snapshot, so the next good frame after the
+// reconnect is a complete resync. The event-time and spike baselines restart
+// then too, so a genuine regime change (a venue-wide liquidity jump) cannot
+// keep the book rejected forever.
+// =============================================================================
+
+// Suspect reasons (bitmask) carried in Pressure.Suspect.
+const (
+	SuspectCrossed  = 1 << 0
+	SuspectUnsorted = 1 << 1
+	SuspectStale    = 1 << 2
+	SuspectSpike    = 1 << 3
+)
+
+const (
+	resyncAfter = 5    // consecutive suspect frames before ErrResync
+	spikeFactor = 500  // × mean level quantity
+	spikeWarmup = 50   // accepted frames before the spike check arms
+	spikeAlpha  = 0.05 // EWMA weight of a frame's mean level quantity
+)
+
+// ErrResync — the depth stream keeps sending suspect frames; reconnect.
+var ErrResync = errors.New("orderbook: suspect depth, resync")
+
+var suspectNames = [...]string{"crossed", "unsorted", "stale", "spike"}
+
+// SuspectString — reasons as "crossed+stale".
+func SuspectString(reason int) string {
+	var parts []string
+	for i, name := range suspectNames {
+		if reason&(1<<i) != 0 {
+			parts = append(parts, name)
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// Suspects returns how many depth frames have been dropped as suspect.
+func (b *Book) Suspects() int64 {
+	return atomic.LoadInt64(&b.suspects)
+}
+
+// check returns the suspect reasons of a frame (0 = clean).
+func (b *Book) check(bids, asks []PriceLevel, eventMs int64) int {
+	reason := 0
+	if len(bids) > 0 && len(asks) > 0 && bids[0].Price >= asks[0].Price {
+		reason |= SuspectCrossed
+	}
+	if !ordered(bids, -1) || !ordered(asks, +1) {
+		reason |= SuspectUnsorted
+	}
+	if eventMs > 0 && eventMs < b.lastEventMs {
+		reason |= SuspectStale
+	}
+	if b.accepted >= spikeWarmup {
+		limit := spikeFactor * b.meanQty
+		if spiked(bids, limit) || spiked(asks, limit) {
+			reason |= SuspectSpike
+		}
+	}
+	return reason
+}
+
+// ordered — levels valid and strictly sorted in dir (+1 ascending, -1
+// descending).
+func ordered(levels []PriceLevel, dir float64) bool {
+	for i, l := range levels {
+		if !(l.Price > 0) || !(l.Quantity > 0) || math.IsInf(l.Price, 0) || math.IsInf(l.Quantity, 0) {
+			return false
+		}
+		if i > 0 && (l.Price-levels[i-1].Price)*dir <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func spiked(levels []PriceLevel, limit float64) bool {
+	for _, l := range levels {
+		if l.Quantity > limit {
+			return true
+		}
+	}
+	return false
+}
+
+// accept folds an applied frame into the baselines.
+func (b *Book) accept(bids, asks []PriceLevel, eventMs int64) {
+	b.suspectRun = 0
+	if eventMs > 0 {
+		b.lastEventMs = eventMs
+	}
+	n := len(bids) + len(asks)
+	if n == 0 {
+		return
+	}
+	var sum float64
+	for _, l := range bids {
+		sum += l.Quantity
+	}
+	for _, l := range asks {
+		sum += l.Quantity
+	}
+	if mean := sum / float64(n); b.accepted == 0 {
+		b.meanQty = mean
+	} else {
+		b.meanQty += spikeAlpha * (mean - b.meanQty)
+	}
+	b.accepted++
+}
+
+// reject republishes the last good Pressure flagged with reason; ErrResync
+// after resyncAfter rejects in a row.
+func (b *Book) reject(reason int) error {
+	atomic.AddInt64(&b.suspects, 1)
+	p := b.GetPressure()
+	p.Suspect = reason
+	atomic.StorePointer(&b.pressure, unsafe.Pointer(&p))
+
+	b.suspectRun++
+	if b.suspectRun < resyncAfter {
+		return nil
+	}
+	b.suspectRun, b.lastEventMs, b.accepted, b.meanQty = 0, 0, 0, 0
+	return fmt.Errorf("%w: %d frames in a row (%s)", ErrResync, resyncAfter, SuspectString(reason))
+}
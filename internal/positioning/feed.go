@@ -0,0 +1,68 @@
+package positioning
+
+import (
+	"sync/atomic"
+)
+
+// Derivs — the latest funding / basis reading and the liquidations seen so
+// far (see ingest.DerivsIngester).
+type Derivs struct {
+	Funding   float64 // funding rate of the current interval (0.0001 = 0.01%)
+	Mark      float64 // mark price
+	Index     float64 // index (spot basket) price
+	UpdatedAt int64   // event time of the last markPrice, unix ms (0 = none)
+
+	// Cumulative liquidated notional (quote asset) since start: a forced
+	// SELL closes a long, a forced BUY closes a short
+	LiqLong  float64
+	LiqShort float64
+}
+
+// Feed — the latest Derivs, written by the derivatives ingest goroutine and
+// read lock-free by the engine (as orderbook.L1Tracker).
+type Feed struct {
+	cur atomic.Pointer[Derivs]
+
+	// Writer only
+	last    Derivs
+	lastLiq int64 // trade time of the last liquidation counted
+}
+
+func NewFeed() *Feed {
+	f := &Feed{}
+	f.cur.Store(&Derivs{})
+	return f
+}
+
+// Get returns the latest reading. Safe from any goroutine.
+func (f *Feed) Get() Derivs {
+	return *f.cur.Load()
+}
+
+// UpdateMark applies a markPrice update. Ingest goroutine only.
+func (f *Feed) UpdateMark(mark, index, funding float64, eventMs int64) {
+	f.last.Mark, f.last.Index, f.last.Funding, f.last.UpdatedAt = mark, index, funding, eventMs
+	f.publish()
+}
+
+// AddLiquidation adds a forced order's filled notional; sell = a long
+// liquidated. The stream pushes at most one order per second, so one at or
+// before the last counted time (both connections during a handover) is a
+// repeat and dropped. Ingest goroutine only.
+func (f *Feed) AddLiquidation(sell bool, notional float64, timeMs int64) {
+	if timeMs <= f.lastLiq {
+		return
+	}
+	f.lastLiq = timeMs
+	if sell {
+		f.last.LiqLong += notional
+	} else {
+		f.last.LiqShort += notional
+	}
+	f.publish()
+}
+
+func (f *Feed) publish() {
+	d := f.last
+	f.cur.Store(&d)
+}
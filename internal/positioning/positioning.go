@@ -0,0 +1,278 @@
+package positioning
+
+import (
+	"math"
+
+	"market-indikator/internal/oi"
+)
+
+// =============================================================================
+// POSITIONING CONTEXT — fused derivatives labels
+// =============================================================================
+//
+// The OI behavior class (internal/oi) reads one poll at a time. This module
+// synthesizes open interest, price, volatility compression and — with the
+// derivatives feed (Feed, ingest.DerivsIngester) — funding, basis and
+// liquidations over a trailing day into a structural label:
+//
+// Per minute m (last OI and price in the minute, ring of 1440 minutes):
+//
+//   ΔN_m    = (OI_m − OI_{m−1}) × P_m    notional OI flow (quote asset)
+//   μ, σ²   = EW mean / variance of ΔN_m (N = 1440, ≈ one day)
+//
+// The shock statistics run on notional so that a day in which price moved a
+// lot does not skew them: the same contract count is more money at a higher
+// price, and the z-score should measure money entering or leaving.
+//
+// LIVE (the current minute treated as closed at the latest poll):
+//
+//   OIZ     = (ΔN_live − μ) / σ                   1-minute notional OI shock
+//   OIRange = (OI − min₁₄₄₀) / (max₁₄₄₀ − min₁₄₄₀)  OI within its daily range
+//   ΔOI_1h, ΔP_1h = change over the last 60 minutes
+//   Funding  = the current interval's funding rate
+//   Basis    = (mark − index) / index, bp — the perp's premium over spot
+//   Liq1m    = liquidated notional this minute, longs − shorts
+//   LiqZ     = (longs + shorts − μ_L) / σ_L, μ_L, σ²_L the EW stats of the
+//              per-minute liquidated notional (as for ΔN)
+//
+// LABELS (first match wins):
+//
+//   DELEVERAGING    OIZ ≤ −3: positions closed at a 3σ rate in one minute,
+//                   or LiqZ ≥ 3 with OI falling: a liquidation cascade.
+//                   Side +1 = longs flushed (long liquidation / price down),
+//                   −1 = shorts squeezed; by Liq1m when anything was
+//                   liquidated, else by the OI behavior / price.
+//   SQUEEZE SETUP   crowded (below) while the 5m or 15m volatility squeeze is
+//                   on: a stretched book of one side with price coiled — the
+//                   fuel for a move against that side.
+//   CROWDED         OIRange ≥ 0.9 and ΔOI_1h > 0: OI at the top of its daily
+//                   range and still building. Side +1 = longs (ΔP_1h > 0),
+//                   −1 = shorts (ΔP_1h < 0). With the derivatives feed the
+//                   side must also be paying for it — funding or basis of
+//                   its sign; OI building with neither is delta-neutral
+//                   (basis / carry trades hedged in spot), not crowding.
+//
+// Labels need an hour of minutes (Ready). Without the derivatives feed (or
+// with a markPrice older than derivsStaleMs) Funding / Basis / Liq are zero
+// and the labels fall back to OI and price alone.
+//
+// TRADING INTERPRETATION:
+//   CROWDED +1 → late longs; fading upside breakouts is cheaper than chasing.
+//   SQUEEZE SETUP +1 → the same with price coiled: a break down can cascade.
+//   DELEVERAGING → forced flow; trends exhaust when it ends, not during.
+//   OI at its high with flat funding and basis → hedged size; no fuel.
+// =============================================================================
+
+// Labels (Context.Label).
+const (
+	LabelNone         = 0
+	LabelCrowded      = 1
+	LabelSqueezeSetup = 2
+	LabelDeleveraging = 3
+)
+
+const (
+	windowMins    = 1440
+	hourMins      = 60
+	statsAlpha    = 2.0 / (windowMins + 1)
+	delevZ        = -3.0
+	liqZ          = 3.0
+	derivsStaleMs = 60_000
+	crowdedRange  = 0.9
+	minRangeWidth = 1e-9
+)
+
+// Context — the live reading.
+type Context struct {
+	Label   int
+	Side    int     // +1 longs, −1 shorts, 0 none
+	OIZ     float64 // 1-minute notional OI change z-score
+	OIRange float64 // OI position in its trailing-day range, [0, 1]
+
+	Funding  float64 // funding rate (0 without a fresh derivatives reading)
+	BasisBps float64 // mark over index, bp
+	Liq1m    float64 // liquidated notional this minute, longs − shorts
+	LiqZ     float64 // this minute's liquidated notional z-score
+}
+
+// Tracker — per-minute OI/price history and labels. Owned by the engine
+// goroutine.
+type Tracker struct {
+	Min      int64   // minute being sampled (unix minutes)
+	OI       float64 // latest OI in Min
+	Price    float64 // latest price in Min
+	Behavior int     // latest OI behavior
+
+	// Committed minutes, oldest overwritten first
+	OIs    []float64
+	Prices []float64
+	Next   int
+	Count  int
+
+	Mean, Var float64 // EW ΔN stats (notional)
+	Lo, Hi    float64 // OI range of the committed window
+
+	// Derivatives feed (all zero without it)
+	Feed                  bool    // a Derivs reading came with the latest update
+	Fresh                 bool    // … and its markPrice is recent
+	Funding, Basis        float64 // latest funding rate, basis (bp)
+	LiqLong, LiqShort     float64 // cumulative liquidations at the latest update
+	LiqLongAt, LiqShortAt float64 // … at the start of Min
+	LiqMean, LiqVar       float64 // EW per-minute liquidated notional stats
+	LiqCount              int     // minutes in LiqMean
+}
+
+// New — a tracker over the trailing day.
+func New() *Tracker {
+	return &Tracker{OIs: make([]float64, windowMins), Prices: make([]float64, windowMins)}
+}
+
+// Update — feeds the latest OI state, price and derivatives reading (nil =
+// no feed) at sec. Polls before the first OI reading are ignored.
+func (t *Tracker) Update(sec int64, price float64, st *oi.State, d *Derivs) {
+	if st.OI <= 0 {
+		return
+	}
+	m := sec / 60
+	if m != t.Min {
+		if t.Min != 0 {
+			t.commit()
+		}
+		t.Min = m
+		t.LiqLongAt, t.LiqShortAt = t.LiqLong, t.LiqShort
+	}
+	t.OI, t.Price, t.Behavior = st.OI, price, st.Behavior
+
+	t.Feed, t.Fresh = d != nil, false
+	if d == nil {
+		return
+	}
+	if d.LiqLong < t.LiqLong || d.LiqShort < t.LiqShort {
+		// A new feed (restart after a checkpoint) counts from zero
+		t.LiqLongAt, t.LiqShortAt = 0, 0
+	}
+	t.LiqLong, t.LiqShort = d.LiqLong, d.LiqShort
+	if d.UpdatedAt > 0 && sec*1000-d.UpdatedAt <= derivsStaleMs && d.Index > 0 {
+		t.Fresh = true
+		t.Funding, t.Basis = d.Funding, (d.Mark-d.Index)/d.Index*1e4
+	}
+}
+
+func (t *Tracker) commit() {
+	if t.Count > 0 {
+		d := t.flow()
+		a := statsAlpha
+		if t.Count == 1 {
+			a = 1
+		}
+		diff := d - t.Mean
+		t.Mean += a * diff
+		t.Var = (1 - a) * (t.Var + a*diff*diff)
+	}
+	if t.Feed {
+		long, short := t.liq()
+		a := statsAlpha
+		if t.LiqCount == 0 {
+			a = 1
+		}
+		diff := long + short - t.LiqMean
+		t.LiqMean += a * diff
+		t.LiqVar = (1 - a) * (t.LiqVar + a*diff*diff)
+		if t.LiqCount < windowMins {
+			t.LiqCount++
+		}
+	}
+	t.OIs[t.Next] = t.OI
+	t.Prices[t.Next] = t.Price
+	t.Next = (t.Next + 1) % windowMins
+	if t.Count < windowMins {
+		t.Count++
+	}
+
+	t.Lo, t.Hi = math.Inf(1), math.Inf(-1)
+	for i := 0; i < t.Count; i++ {
+		t.Lo = math.Min(t.Lo, t.OIs[i])
+		t.Hi = math.Max(t.Hi, t.OIs[i])
+	}
+}
+
+// at — OI of the k-th most recent committed minute (1 = newest).
+func (t *Tracker) at(k int) float64 {
+	return t.OIs[(t.Next-k+windowMins)%windowMins]
+}
+
+// flow — notional OI change of the sampled minute against the newest
+// committed one.
+func (t *Tracker) flow() float64 {
+	return (t.OI - t.at(1)) * t.Price
+}
+
+// liq — notional liquidated in the sampled minute, per side.
+func (t *Tracker) liq() (long, short float64) {
+	return t.LiqLong - t.LiqLongAt, t.LiqShort - t.LiqShortAt
+}
+
+func (t *Tracker) priceAt(k int) float64 {
+	return t.Prices[(t.Next-k+windowMins)%windowMins]
+}
+
+// Ready — an hour of minutes has been committed.
+func (t *Tracker) Ready() bool {
+	return t.Count >= hourMins
+}
+
+// Live — the current context (label and OI fields zero until Ready).
+func (t *Tracker) Live(squeezeOn bool) Context {
+	var c Context
+	if t.Fresh {
+		c.Funding, c.BasisBps = t.Funding, t.Basis
+	}
+	if t.Feed {
+		long, short := t.liq()
+		c.Liq1m = long - short
+		if sd := math.Sqrt(t.LiqVar); sd > 0 && t.LiqCount >= hourMins {
+			c.LiqZ = (long + short - t.LiqMean) / sd
+		}
+	}
+	if !t.Ready() {
+		return c
+	}
+	if sd := math.Sqrt(t.Var); sd > 0 {
+		c.OIZ = (t.flow() - t.Mean) / sd
+	}
+	lo, hi := math.Min(t.Lo, t.OI), math.Max(t.Hi, t.OI)
+	if hi-lo > minRangeWidth {
+		c.OIRange = (t.OI - lo) / (hi - lo)
+	}
+
+	if c.OIZ <= delevZ || c.LiqZ >= liqZ && t.flow() < 0 {
+		c.Label, c.Side = LabelDeleveraging, -1
+		switch {
+		case c.Liq1m != 0:
+			if c.Liq1m > 0 {
+				c.Side = 1
+			}
+		case t.Behavior == oi.BehaviorLongLiquidation ||
+			t.Behavior != oi.BehaviorShortCovering && t.Price < t.priceAt(1):
+			c.Side = 1
+		}
+		return c
+	}
+
+	oiHour := t.OI - t.at(hourMins)
+	priceHour := t.Price - t.priceAt(hourMins)
+	if c.OIRange >= crowdedRange && oiHour > 0 && priceHour != 0 {
+		c.Label, c.Side = LabelCrowded, 1
+		if priceHour < 0 {
+			c.Side = -1
+		}
+		if side := float64(c.Side); t.Fresh && t.Funding*side <= 0 && t.Basis*side <= 0 {
+			c.Label, c.Side = LabelNone, 0 // delta-neutral build
+			return c
+		}
+		if squeezeOn {
+			c.Label = LabelSqueezeSetup
+		}
+	}
+	return c
+}
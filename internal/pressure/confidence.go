@@ -0,0 +1,64 @@
+package pressure
+
+import "math"
+
+// =============================================================================
+// SCORE CONFIDENCE — how much a FinalScore reading can be trusted
+// =============================================================================
+//
+// FinalScore says how strong the pressure is; it does not say how much of
+// the evidence is there. Beside it the scorer keeps a confidence in [0, 1],
+// the product of four factors:
+//
+//   freshness   1 − the weight share of domains dropped as stale (a stale
+//               book removes w_p / Σw, a stale OI feed w_o / Σw)
+//   agreement   0.4 + 0.6·|Σ c| / Σ|c| over the three weighted domain
+//               contributions c: 1 when aggressive, passive and positioning
+//               point the same way, 0.4 when they cancel out
+//   warmup      min(1, updates / ConfidenceWarmup): the normalizers' σ (or
+//               windows) start from defaults and are meaningless at first
+//   intensity   clamp(0.75 + 0.25·IntensityZ, 0.5, 1): a score built on a
+//               handful of prints counts for less than one on a busy tape
+//
+// smoothed with the same period as the score (α = 2 / (SmoothingPeriod+1)),
+// so a single conflicting tick doesn't make it flicker.
+//
+// TRADING INTERPRETATION: +70 at 0.95 is all three domains agreeing on live
+// data on an active tape; +70 at 0.3 is usually aggressive flow alone
+// against the book, or a stale feed — size down or wait for confirmation.
+// =============================================================================
+
+// ConfidenceWarmup — scorer updates until the warmup factor reaches 1
+// (≈ 3 σ time constants of the default EMA normalizer).
+const ConfidenceWarmup = 60
+
+// updateConfidence folds this update's factors into s.Confidence. agg, pas
+// and pos are the weighted domain contributions (0 for a stale domain).
+func (s *Scorer) updateConfidence(in *Input, agg, pas, pos float64) {
+	w := &s.Weights
+	total := w.Aggressive + w.Passive + w.Positioning
+	fresh := 1.0
+	if total > 0 {
+		if in.BookStale {
+			fresh -= w.Passive / total
+		}
+		if in.OIStale {
+			fresh -= w.Positioning / total
+		}
+	}
+
+	agree := 0.4
+	if abs := math.Abs(agg) + math.Abs(pas) + math.Abs(pos); abs > 0 {
+		agree += 0.6 * math.Abs(agg+pas+pos) / abs
+	}
+
+	if s.updates < ConfidenceWarmup {
+		s.updates++
+	}
+	warm := float64(s.updates) / ConfidenceWarmup
+
+	intensity := clamp(0.75+0.25*in.Intensity, 0.5, 1)
+
+	raw := clamp(fresh, 0, 1) * agree * warm * intensity
+	s.Confidence = emaUpdate(s.Confidence, raw, 2.0/(SmoothingPeriod+1))
+}
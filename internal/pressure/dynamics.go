@@ -0,0 +1,126 @@
+package pressure
+
+// =============================================================================
+// SCORE DYNAMICS — velocity, acceleration and percentile rank
+// =============================================================================
+//
+// The score is sampled once per second (its last value in that second):
+//
+//   velocity_t     = EMA₅( s_t − s_{t−1} )              points / second
+//   acceleration_t = EMA₅( velocity_t − velocity_{t−1} ) points / second²
+//
+// Like the candle indicators, the reported values are LIVE: the current
+// second is treated as if it closed at the latest score.
+//
+// Percentile rank of the latest score among the per-second samples of the
+// trailing hour (3600 samples):
+//
+//   pct = 100 · (#{samples < s} + ½·#{samples = s}) / n
+//
+// Samples are counted in 1-point buckets over [-100, +100], so a rank is
+// O(201) and the window O(1) per second.
+//
+// TRADING INTERPRETATION:
+//   +45 with velocity > 0 and pct ≥ 95 → pressure still building to an
+//   extreme for the hour — tradeable. +45 with velocity < 0 → the push is
+//   fading; the level alone says nothing. Acceleration turning against the
+//   velocity is the earliest sign of a fade.
+// =============================================================================
+
+const (
+	dynamicsAlpha   = 2.0 / (5 + 1)
+	dynamicsWindow  = 3600
+	percentileBins  = 201 // 1-point buckets over [-100, +100]
+	percentileFloor = -100
+)
+
+// Dynamics — score velocity / acceleration / percentile tracker. Owned by the
+// engine goroutine.
+type Dynamics struct {
+	Sec       int64   // second being sampled
+	Score     float64 // latest score in Sec
+	PrevScore float64 // score at the end of the previous sampled second
+	Velocity  float64 // EMA of per-second score change (committed)
+	Accel     float64 // EMA of per-second velocity change (committed)
+	Samples   int     // seconds committed
+
+	Window []int8 // ring of per-second samples (bucket − 100)
+	Next   int
+	Full   bool
+	Counts [percentileBins]int
+}
+
+// NewDynamics — a tracker over the trailing hour.
+func NewDynamics() *Dynamics {
+	return &Dynamics{Window: make([]int8, dynamicsWindow)}
+}
+
+// Update — feeds the score at sec; a new second commits the previous one.
+func (d *Dynamics) Update(sec int64, score float64) {
+	if sec != d.Sec {
+		if d.Sec != 0 {
+			d.commit()
+		}
+		d.Sec = sec
+	}
+	d.Score = score
+}
+
+func (d *Dynamics) commit() {
+	if d.Samples > 0 {
+		prev := d.Velocity
+		d.Velocity += dynamicsAlpha * (d.Score - d.PrevScore - d.Velocity)
+		d.Accel += dynamicsAlpha * (d.Velocity - prev - d.Accel)
+	}
+	d.PrevScore = d.Score
+	d.Samples++
+
+	b := scoreBucket(d.Score)
+	if d.Full {
+		d.Counts[int(d.Window[d.Next])-percentileFloor]--
+	}
+	d.Window[d.Next] = int8(b + percentileFloor)
+	d.Counts[b]++
+	d.Next++
+	if d.Next == len(d.Window) {
+		d.Next = 0
+		d.Full = true
+	}
+}
+
+// Live — velocity and acceleration with the current second treated as
+// closed (0 until two seconds have been seen).
+func (d *Dynamics) Live() (velocity, accel float64) {
+	if d.Samples == 0 {
+		return 0, 0
+	}
+	velocity = d.Velocity + dynamicsAlpha*(d.Score-d.PrevScore-d.Velocity)
+	accel = d.Accel + dynamicsAlpha*(velocity-d.Velocity-d.Accel)
+	return velocity, accel
+}
+
+// Percentile — rank of the latest score within the window, 0..100
+// (50 while the window is empty).
+func (d *Dynamics) Percentile() float64 {
+	n := d.Next
+	if d.Full {
+		n = len(d.Window)
+	}
+	if n == 0 {
+		return 50
+	}
+	b := scoreBucket(d.Score)
+	below := 0
+	for _, c := range d.Counts[:b] {
+		below += c
+	}
+	return 100 * (float64(below) + 0.5*float64(d.Counts[b])) / float64(n)
+}
+
+func scoreBucket(score float64) int {
+	b := int(clamp(score, -100, 100) + 100.5) // round to nearest point
+	if b >= percentileBins {
+		b = percentileBins - 1
+	}
+	return b
+}
@@ -0,0 +1,249 @@
+package pressure
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// NORMALIZATION — pluggable maps from raw signal to [-1, +1]
+// =============================================================================
+//
+// Each flow input (CVD velocity, 1s delta, ΔOI 1m) is normalized by its own
+// Normalizer before the domains are weighted. Selected per deployment with a
+// spec string (see ParseNormalizer):
+//
+//   ema         the original adaptive norm: σ = EMA(|x|) (α = 0.05),
+//               norm = clamp(x / σ, -1, 1). Cheap, but any move of more than
+//               one "typical" size saturates — in a volatile burst most ticks
+//               read ±1 and the score loses its granularity.
+//
+//   zscore[:N]  rolling z-score over the last N inputs (default 300),
+//               winsorized: each input is clipped to μ ± 3σ before it enters
+//               the window, so a single print cannot blow up σ, and
+//                 norm = clamp((x − μ) / (3σ), -1, 1)
+//               ±1 now means a 3σ move rather than a 1σ one.
+//
+//   rank[:N]    percentile rank of x among the last N inputs (default 300),
+//                 norm = 2 · (#{< x} + ½·#{= x}) / n − 1
+//               Distribution-free and never saturates before the window's own
+//               extremes; ignores magnitude beyond the ordering.
+//
+// zscore and rank cost O(1) and O(N) per input respectively.
+// =============================================================================
+
+const (
+	defaultNormWindow = 300
+	winsorK           = 3.0
+	zscoreWarmup      = 20
+)
+
+// Normalizer maps one input series to [-1, +1], adapting as it goes.
+type Normalizer interface {
+	// Name identifies the method and window (e.g. "rank:300"); a checkpoint
+	// is only restored into a normalizer of the same Name.
+	Name() string
+	// Norm folds x into the statistics and returns it normalized.
+	Norm(x float64) float64
+	// State / Restore flatten the statistics for checkpointing.
+	State() []float64
+	Restore(state []float64)
+}
+
+// NormalizerFactory builds one Normalizer per scorer input.
+type NormalizerFactory func() Normalizer
+
+// ParseNormalizer builds a factory from a spec: "ema", "zscore" or "rank",
+// the latter two optionally followed by ":N" for the window.
+func ParseNormalizer(spec string) (NormalizerFactory, error) {
+	kind, arg, hasArg := strings.Cut(strings.TrimSpace(spec), ":")
+	n := defaultNormWindow
+	if hasArg {
+		v, err := strconv.Atoi(arg)
+		if err != nil || v < zscoreWarmup {
+			return nil, fmt.Errorf("normalizer %q: window must be at least %d", spec, zscoreWarmup)
+		}
+		n = v
+	}
+	switch kind {
+	case "ema":
+		if hasArg {
+			return nil, fmt.Errorf("normalizer %q: ema takes no window", spec)
+		}
+		return func() Normalizer { return NewEMANorm() }, nil
+	case "zscore":
+		return func() Normalizer { return NewZScoreNorm(n) }, nil
+	case "rank":
+		return func() Normalizer { return NewRankNorm(n) }, nil
+	}
+	return nil, fmt.Errorf("unknown normalizer %q (want ema, zscore or rank)", spec)
+}
+
+// ─── EMA OF |x| (original) ───
+
+// EMANorm — x / EMA(|x|), clamped.
+type EMANorm struct {
+	sigma float64
+}
+
+func NewEMANorm() *EMANorm {
+	return &EMANorm{sigma: 1.0} // 1.0 avoids cold-start div-by-zero
+}
+
+func (e *EMANorm) Name() string { return "ema" }
+
+func (e *EMANorm) Norm(x float64) float64 {
+	e.sigma = emaUpdate(e.sigma, math.Abs(x), SigmaAlpha)
+	return adaptiveNorm(x, e.sigma)
+}
+
+func (e *EMANorm) State() []float64 { return []float64{e.sigma} }
+
+func (e *EMANorm) Restore(st []float64) {
+	if len(st) == 1 {
+		e.sigma = st[0]
+	}
+}
+
+// ─── WINSORIZED Z-SCORE ───
+
+// ZScoreNorm — rolling winsorized z-score (see file header).
+type ZScoreNorm struct {
+	window     series
+	sum, sumSq float64
+}
+
+func NewZScoreNorm(n int) *ZScoreNorm {
+	return &ZScoreNorm{window: newSeries(n)}
+}
+
+func (z *ZScoreNorm) Name() string { return "zscore:" + strconv.Itoa(len(z.window.buf)) }
+
+func (z *ZScoreNorm) Norm(x float64) float64 {
+	w := x
+	if z.window.count >= zscoreWarmup {
+		mean, sd := z.stats()
+		w = clamp(x, mean-winsorK*sd, mean+winsorK*sd)
+	}
+	if old, full := z.window.push(w); full {
+		z.sum -= old
+		z.sumSq -= old * old
+	}
+	z.sum += w
+	z.sumSq += w * w
+	if z.window.next == 0 {
+		z.resum() // once per lap, so the running sums can't drift
+	}
+
+	mean, sd := z.stats()
+	if sd < SigmaEpsilon {
+		sd = SigmaEpsilon
+	}
+	return clamp((x-mean)/(winsorK*sd), -1, 1)
+}
+
+func (z *ZScoreNorm) stats() (mean, sd float64) {
+	n := float64(z.window.count)
+	mean = z.sum / n
+	return mean, math.Sqrt(math.Max(z.sumSq/n-mean*mean, 0))
+}
+
+func (z *ZScoreNorm) resum() {
+	z.sum, z.sumSq = 0, 0
+	for i := 0; i < z.window.count; i++ {
+		v := z.window.at(i)
+		z.sum += v
+		z.sumSq += v * v
+	}
+}
+
+func (z *ZScoreNorm) State() []float64 { return z.window.state() }
+
+func (z *ZScoreNorm) Restore(st []float64) {
+	z.window.restore(st)
+	z.resum()
+}
+
+// ─── RANK ───
+
+// RankNorm — rolling percentile rank mapped to [-1, +1].
+type RankNorm struct {
+	window series
+}
+
+func NewRankNorm(n int) *RankNorm {
+	return &RankNorm{window: newSeries(n)}
+}
+
+func (r *RankNorm) Name() string { return "rank:" + strconv.Itoa(len(r.window.buf)) }
+
+func (r *RankNorm) Norm(x float64) float64 {
+	n := r.window.count
+	var below, equal int
+	for _, v := range r.window.buf[:n] {
+		switch {
+		case v < x:
+			below++
+		case v == x:
+			equal++
+		}
+	}
+	r.window.push(x)
+	if n == 0 {
+		return 0
+	}
+	return 2*(float64(below)+0.5*float64(equal))/float64(n) - 1
+}
+
+func (r *RankNorm) State() []float64     { return r.window.state() }
+func (r *RankNorm) Restore(st []float64) { r.window.restore(st) }
+
+// ─── helpers ───
+
+// series — ring of the last len(buf) values.
+type series struct {
+	buf   []float64
+	next  int
+	count int
+}
+
+func newSeries(n int) series {
+	return series{buf: make([]float64, n)}
+}
+
+// push appends x, returning the value it evicted once the ring is full.
+func (s *series) push(x float64) (old float64, full bool) {
+	old, full = s.buf[s.next], s.count == len(s.buf)
+	s.buf[s.next] = x
+	s.next = (s.next + 1) % len(s.buf)
+	if !full {
+		s.count++
+	}
+	return old, full
+}
+
+// at — i-th value, oldest first.
+func (s *series) at(i int) float64 {
+	return s.buf[(s.next-s.count+i+len(s.buf))%len(s.buf)]
+}
+
+// state — values oldest first.
+func (s *series) state() []float64 {
+	st := make([]float64, s.count)
+	for i := range st {
+		st[i] = s.at(i)
+	}
+	return st
+}
+
+func (s *series) restore(st []float64) {
+	s.next, s.count = 0, 0
+	if len(st) > len(s.buf) {
+		st = st[len(st)-len(s.buf):]
+	}
+	for _, v := range st {
+		s.push(v)
+	}
+}
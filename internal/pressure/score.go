@@ -1,7 +1,9 @@
 package pressure
 
 import (
-	"math"
+	"fmt"
+	"strconv"
+	"strings"
 )
 
 // =============================================================================
@@ -25,7 +27,9 @@ import (
 //
 //    Both are normalized via adaptive z-score:
 //      norm(x) = clamp(x / (σ + ε), -1, 1)
-//    where σ is a rolling standard deviation (EMA of |x|).
+//    where σ is a rolling standard deviation (EMA of |x|). Winsorized
+//    rolling z-score and rank normalization are alternatives (see
+//    normalize.go and SetNormalizer).
 //
 //    Weights: α₁=0.6 (CVD momentum), α₂=0.4 (instantaneous delta)
 //
@@ -70,6 +74,9 @@ import (
 //    Default N = 5 ticks (~500ms at typical tick rate).
 //    This gives α ≈ 0.333, half-life ≈ 2.5 ticks.
 //
+//    The stage is pluggable (Kalman, rolling median, Hull; see smoothing.go
+//    and SetSmoother); the EMA is the default.
+//
 // ─────────────────────────────────────────────────────────────────────────────
 //
 // INTERPRETATION:
@@ -91,6 +98,17 @@ import (
 //    3. Multi-domain fusion: a spike in one domain is dampened by the others.
 //       News events spike aggressive pressure but orderbook may show absorption,
 //       creating a balanced composite.
+//    4. Tape intensity (optional, IntensityGain > 0): the aggressive domain is
+//       scaled by clamp(1 + gain·IntensityZ, 0.5, 1.5), so flow on a busy tape
+//       counts for more than the same normalized flow on a handful of prints.
+//    5. Stale inputs: if the depth stream or OI poller has gone quiet, the
+//       engine flags the input as stale and that domain contributes 0 until
+//       fresh data arrives, instead of replaying a frozen reading forever.
+//    6. Time of day (optional, seasonal.go): flow and ΔOI are rescaled by
+//       how active that hour of the week usually is before normalization.
+//    7. Thin book (optional, ThinBookDamp > 0): while the top-N depth is
+//       in its thin-book regime the passive domain is damped — an empty
+//       book's imbalance is noise, not resting interest.
 //
 // CALIBRATION GUIDANCE:
 //    1. Run the engine for 1+ hours during active market hours (NY/London).
@@ -116,8 +134,8 @@ const (
 	BetaOIDelta  = 0.50
 	BetaBehavior = 0.50
 
-	// EMA smoothing: α = 2/(N+1), N=5 gives α≈0.333
-	SmoothingAlpha = 0.333
+	// Default EMA smoothing period: α = 2/(N+1), N=5 gives α≈0.333
+	SmoothingPeriod = 5
 
 	// Adaptive normalization EMA decay for σ estimation
 	SigmaAlpha = 0.05 // slow adaptation for stability
@@ -126,6 +144,65 @@ const (
 	SigmaEpsilon = 0.001
 )
 
+// Weights — the composite's domain and sub-weights. The constants above are
+// the defaults; -score-weights overrides them, also at runtime (config
+// reload) without touching the normalizer or smoother state.
+type Weights struct {
+	Aggressive, Passive, Positioning float64 // domain weights, w_a w_p w_o
+	CVD, Delta                       float64 // aggressive sub-weights, α₁ α₂
+	OIDelta, Behavior                float64 // positioning sub-weights, β₁ β₂
+}
+
+// DefaultWeights — the hand-tuned weights documented above.
+func DefaultWeights() Weights {
+	return Weights{
+		Aggressive: WeightAggressive, Passive: WeightPassive, Positioning: WeightPositioning,
+		CVD: AlphaCVD, Delta: AlphaDelta,
+		OIDelta: BetaOIDelta, Behavior: BetaBehavior,
+	}
+}
+
+// ParseWeights overrides the defaults with a spec like
+// "aggressive=0.5,passive=0.3,positioning=0.2" (keys: aggressive, passive,
+// positioning, cvd, delta, oi, behavior). Weights must be ≥ 0 and each
+// group must have a positive sum; they are not renormalized.
+func ParseWeights(spec string) (Weights, error) {
+	w := DefaultWeights()
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(item, "=")
+		v, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if !ok || err != nil || v < 0 {
+			return w, fmt.Errorf("weight %q: want name=value ≥ 0", item)
+		}
+		switch strings.TrimSpace(key) {
+		case "aggressive":
+			w.Aggressive = v
+		case "passive":
+			w.Passive = v
+		case "positioning":
+			w.Positioning = v
+		case "cvd":
+			w.CVD = v
+		case "delta":
+			w.Delta = v
+		case "oi":
+			w.OIDelta = v
+		case "behavior":
+			w.Behavior = v
+		default:
+			return w, fmt.Errorf("weight %q: unknown name (aggressive, passive, positioning, cvd, delta, oi, behavior)", key)
+		}
+	}
+	if w.Aggressive+w.Passive+w.Positioning <= 0 || w.CVD+w.Delta <= 0 || w.OIDelta+w.Behavior <= 0 {
+		return w, fmt.Errorf("weights %q: every group needs a positive sum", spec)
+	}
+	return w, nil
+}
+
 // Behavior signal mapping
 var behaviorSignal = [5]float64{
 	0.0,  // BehaviorNeutral
@@ -143,6 +220,16 @@ type Input struct {
 	OBScore     int     // orderbook pressure score [-100, +100]
 	OIDelta1m   float64 // OI change over ~1 minute
 	OIBehavior  int     // behavior enum (0-4)
+	BookStale   bool    // depth feed stale — drop passive domain
+	OIStale     bool    // OI feed stale — drop positioning domain
+	Intensity   float64 // tape burst z-score (flow.Tape.IntensityZ)
+
+	// Hour-of-week scales (Seasonal.Scale; 0 = none): CVD velocity and
+	// Delta1s are multiplied by FlowScale, OIDelta1m by OIScale.
+	FlowScale float64
+	OIScale   float64
+
+	ThinBook bool // top-N depth in its thin-book regime (engine/thinbook.go)
 }
 
 // Scorer computes the final composite pressure score.
@@ -152,28 +239,105 @@ type Scorer struct {
 	// Final output
 	FinalScore float64
 
-	// EMA state
+	// Confidence in FinalScore, [0, 1] (confidence.go)
+	Confidence float64
+	updates    int64 // capped at ConfidenceWarmup
+
+	// IntensityGain scales the aggressive domain by tape intensity
+	// (0 = off, the default). Configuration, not checkpointed.
+	IntensityGain float64
+
+	// ThinBookDamp scales the passive domain by 1 − damp while the book is
+	// thin (0 = off, the default). Configuration, not checkpointed.
+	ThinBookDamp float64
+
+	// Weights of the composite (DefaultWeights). Configuration, not
+	// checkpointed; may change between updates.
+	Weights Weights
+
+	// Smoothing stage (default EMA, see SetSmoother)
+	smoother Smoother
 	smoothed float64
-	hasInit  bool
 
 	// Adaptive normalization state
 	prevCVD float64
 	cvdVel  float64 // CVD velocity (change per tick)
 
-	// Rolling σ estimates (EMA of |value|)
-	sigmaCVDVel float64
-	sigmaDelta  float64
-	sigmaOI     float64
+	// Input normalizers (default EMA of |value|, see SetNormalizer)
+	normCVDVel Normalizer
+	normDelta  Normalizer
+	normOI     Normalizer
+}
+
+// ScorerState — the Scorer's internal state, for checkpointing.
+type ScorerState struct {
+	FinalScore  float64
+	Smoothed    float64
+	Smoother    string    // smoother Name() the state belongs to
+	SmoothState []float64 // Smoother.State()
+	PrevCVD     float64
+	CVDVel      float64
+	Norm        string       // normalizer Name() the states belong to
+	NormState   [3][]float64 // CVD velocity, delta, ΔOI
+	Confidence  float64      // zero in checkpoints saved before confidence
+	Updates     int64
+}
+
+// State returns a copy of the internal state.
+func (s *Scorer) State() ScorerState {
+	return ScorerState{
+		FinalScore:  s.FinalScore,
+		Smoothed:    s.smoothed,
+		Smoother:    s.smoother.Name(),
+		SmoothState: s.smoother.State(),
+		PrevCVD:     s.prevCVD,
+		CVDVel:      s.cvdVel,
+		Norm:        s.normCVDVel.Name(),
+		NormState:   [3][]float64{s.normCVDVel.State(), s.normDelta.State(), s.normOI.State()},
+		Confidence:  s.Confidence,
+		Updates:     s.updates,
+	}
+}
+
+// Restore replaces the internal state (warm restart). The smoother's and
+// normalizers' state is only kept if it was saved by the same kind.
+func (s *Scorer) Restore(st ScorerState) {
+	s.FinalScore = st.FinalScore
+	s.smoothed = st.Smoothed
+	if st.Smoother == s.smoother.Name() {
+		s.smoother.Restore(st.SmoothState)
+	}
+	s.prevCVD = st.PrevCVD
+	s.cvdVel = st.CVDVel
+	if st.Norm == s.normCVDVel.Name() {
+		s.normCVDVel.Restore(st.NormState[0])
+		s.normDelta.Restore(st.NormState[1])
+		s.normOI.Restore(st.NormState[2])
+		s.Confidence, s.updates = st.Confidence, st.Updates
+	}
 }
 
 func NewScorer() *Scorer {
 	return &Scorer{
-		sigmaCVDVel: 1.0, // Initialize to 1.0 to avoid cold-start div-by-zero
-		sigmaDelta:  1.0,
-		sigmaOI:     1.0,
+		normCVDVel: NewEMANorm(),
+		normDelta:  NewEMANorm(),
+		normOI:     NewEMANorm(),
+		smoother:   NewEMASmoother(SmoothingPeriod),
+		Weights:    DefaultWeights(),
 	}
 }
 
+// SetNormalizer replaces the input normalization (one normalizer per
+// input from f). Call before the first Update.
+func (s *Scorer) SetNormalizer(f NormalizerFactory) {
+	s.normCVDVel, s.normDelta, s.normOI = f(), f(), f()
+}
+
+// SetSmoother replaces the smoothing stage. Call before the first Update.
+func (s *Scorer) SetSmoother(sm Smoother) {
+	s.smoother = sm
+}
+
 // Update computes the composite score from all signal inputs.
 // HOT PATH — ~30ns, zero allocations, pure arithmetic.
 func (s *Scorer) Update(in Input) float64 {
@@ -182,41 +346,53 @@ func (s *Scorer) Update(in Input) float64 {
 	s.prevCVD = in.CVD
 
 	// ─── ADAPTIVE NORMALIZATION ───
-	// Update rolling σ (EMA of absolute values)
-	s.sigmaCVDVel = emaUpdate(s.sigmaCVDVel, math.Abs(s.cvdVel), SigmaAlpha)
-	s.sigmaDelta = emaUpdate(s.sigmaDelta, math.Abs(in.Delta1s), SigmaAlpha)
-	s.sigmaOI = emaUpdate(s.sigmaOI, math.Abs(in.OIDelta1m), SigmaAlpha)
-
-	// Normalize each signal to [-1, +1]
-	normCVDVel := adaptiveNorm(s.cvdVel, s.sigmaCVDVel)
-	normDelta := adaptiveNorm(in.Delta1s, s.sigmaDelta)
-	normOIDelta := adaptiveNorm(in.OIDelta1m, s.sigmaOI)
+	// Update each signal's statistics and normalize it to [-1, +1]
+	cvdVel, delta1s, oiDelta := s.cvdVel, in.Delta1s, in.OIDelta1m
+	if in.FlowScale > 0 {
+		cvdVel, delta1s = cvdVel*in.FlowScale, delta1s*in.FlowScale
+	}
+	if in.OIScale > 0 {
+		oiDelta *= in.OIScale
+	}
+	normCVDVel := s.normCVDVel.Norm(cvdVel)
+	normDelta := s.normDelta.Norm(delta1s)
+	normOIDelta := s.normOI.Norm(oiDelta)
 
 	// ─── AGGRESSIVE PRESSURE ───
-	aggressive := AlphaCVD*normCVDVel + AlphaDelta*normDelta
+	w := &s.Weights
+	aggressive := w.CVD*normCVDVel + w.Delta*normDelta
+	if s.IntensityGain > 0 {
+		aggressive *= clamp(1+s.IntensityGain*in.Intensity, 0.5, 1.5)
+	}
 
 	// ─── PASSIVE PRESSURE ───
 	passive := float64(in.OBScore) / 100.0
+	if in.BookStale {
+		passive = 0
+	} else if in.ThinBook {
+		passive *= 1 - s.ThinBookDamp
+	}
 
 	// ─── POSITIONING PRESSURE ───
 	behSig := 0.0
 	if in.OIBehavior >= 0 && in.OIBehavior < 5 {
 		behSig = behaviorSignal[in.OIBehavior]
 	}
-	positioning := BetaOIDelta*normOIDelta + BetaBehavior*behSig
+	positioning := w.OIDelta*normOIDelta + w.Behavior*behSig
+	if in.OIStale {
+		positioning = 0
+	}
 
 	// ─── WEIGHTED COMPOSITE ───
-	raw := (WeightAggressive*aggressive +
-		WeightPassive*passive +
-		WeightPositioning*positioning) * 100.0
+	raw := (w.Aggressive*aggressive +
+		w.Passive*passive +
+		w.Positioning*positioning) * 100.0
 
-	// ─── EMA SMOOTHING ───
-	if !s.hasInit {
-		s.smoothed = raw
-		s.hasInit = true
-	} else {
-		s.smoothed = SmoothingAlpha*raw + (1.0-SmoothingAlpha)*s.smoothed
-	}
+	// ─── CONFIDENCE (confidence.go) ───
+	s.updateConfidence(&in, w.Aggressive*aggressive, w.Passive*passive, w.Positioning*positioning)
+
+	// ─── SMOOTHING (EMA by default) ───
+	s.smoothed = s.smoother.Update(raw)
 
 	// ─── CLAMP TO [-100, +100] ───
 	s.FinalScore = clamp(s.smoothed, -100, 100)
@@ -0,0 +1,158 @@
+package pressure
+
+import "math"
+
+// =============================================================================
+// TIME-OF-DAY NORMALIZATION — hour-of-week baselines
+// =============================================================================
+//
+// The input normalizers adapt to one σ per series, so they conflate the
+// quiet Asian night with the NY open: a 1s delta that is huge at 03:00 UTC
+// is routine at 14:30, and the σ carried over from one reads the other
+// wrongly for the first minutes of each. With a Seasonal profile the scorer
+// first rescales each input by how active that hour of the week usually is:
+//
+//   Mean[k][h]   typical |x| of series k in hour-of-week h (Monday 00:00
+//                UTC = 0 … Sunday 23:00 = 167), per completed second:
+//                  intensity  trades in the second
+//                  delta      |1s delta|
+//                  oi         |ΔOI 1m|
+//   Scale(k, t)  Global[k] / Mean[k][hour(t)], clamped to [¼, 4], where
+//                Global is the mean over the hours learned so far
+//
+//   x' = x · Scale   (CVD velocity and 1s delta by the delta scale, ΔOI by
+//                     the oi scale) before the Normalizer sees it
+//
+// and the tape-intensity input becomes the live second's trade count
+// against the hour's typical count instead of the 5-minute baseline.
+//
+// Learning is online: the seconds of each clock hour are averaged and, if
+// at least half the hour was seen, folded into that hour-of-week's mean
+// with SeasonalAlpha (≈ the last four weeks; the first week seeds it). An
+// hour not learned yet scales by 1. The profile can be seeded from the
+// snapshot log's 1s rows (buy_count + sell_count, delta_1s, oi_delta) and is
+// checkpointed with the engine.
+//
+// TRADING INTERPRETATION: the same +60 means the same thing at 03:00 and at
+// the NY open — relative to what that hour normally trades. A weekend
+// score is no longer inflated by the weekday σ having decayed onto a thin
+// tape.
+// =============================================================================
+
+// Seasonal series.
+const (
+	SeasonIntensity = iota // trades per second
+	SeasonDelta            // |1s delta|
+	SeasonOI               // |ΔOI 1m|
+	NumSeasonSeries
+)
+
+const (
+	// HoursOfWeek — hour-of-week buckets.
+	HoursOfWeek = 168
+
+	// SeasonalAlpha — weight of a new hour in its bucket's mean.
+	SeasonalAlpha = 0.25
+
+	seasonalMinSeconds = 1800 // of an hour, for it to be learned
+	seasonalMaxScale   = 4.0
+)
+
+// HourOfWeek — the hour-of-week bucket of a unix time (s), Monday 00:00
+// UTC = 0. The epoch was a Thursday (hour 72).
+func HourOfWeek(unixSec int64) int {
+	return int((unixSec/3600 + 72) % HoursOfWeek)
+}
+
+// Seasonal — hour-of-week baselines of the scorer's inputs (see file
+// header). Engine goroutine only; all fields exported for checkpointing.
+type Seasonal struct {
+	Mean   [NumSeasonSeries][HoursOfWeek]float64
+	Hours  [HoursOfWeek]int // hours learned per bucket (0 = not learned)
+	Global [NumSeasonSeries]float64
+
+	// The clock hour being accumulated
+	Hour int64 // unix hours
+	Sum  [NumSeasonSeries]float64
+	N    int
+}
+
+// NewSeasonal returns an empty profile (every hour scales by 1).
+func NewSeasonal() *Seasonal {
+	return &Seasonal{}
+}
+
+// Add folds one completed second at sec (unix s): x holds the series
+// values (signs are ignored). Seconds must arrive in time order.
+func (s *Seasonal) Add(sec int64, x [NumSeasonSeries]float64) {
+	if hr := sec / 3600; hr != s.Hour {
+		s.fold()
+		s.Hour, s.Sum, s.N = hr, [NumSeasonSeries]float64{}, 0
+	}
+	for k, v := range x {
+		s.Sum[k] += math.Abs(v)
+	}
+	s.N++
+}
+
+// fold learns the accumulated hour, if enough of it was seen.
+func (s *Seasonal) fold() {
+	if s.N < seasonalMinSeconds {
+		return
+	}
+	h := HourOfWeek(s.Hour * 3600)
+	for k := range s.Mean {
+		m := s.Sum[k] / float64(s.N)
+		if s.Hours[h] == 0 {
+			s.Mean[k][h] = m
+		} else {
+			s.Mean[k][h] += SeasonalAlpha * (m - s.Mean[k][h])
+		}
+	}
+	s.Hours[h]++
+
+	var n int
+	s.Global = [NumSeasonSeries]float64{}
+	for h, learned := range s.Hours {
+		if learned == 0 {
+			continue
+		}
+		n++
+		for k := range s.Global {
+			s.Global[k] += s.Mean[k][h]
+		}
+	}
+	for k := range s.Global {
+		s.Global[k] /= float64(n)
+	}
+}
+
+// Baseline — series k's typical value in the hour-of-week of sec (0 = not
+// learned).
+func (s *Seasonal) Baseline(k int, sec int64) float64 {
+	h := HourOfWeek(sec)
+	if s.Hours[h] == 0 {
+		return 0
+	}
+	return s.Mean[k][h]
+}
+
+// Scale — the factor series k is multiplied by at sec (1 = not learned).
+func (s *Seasonal) Scale(k int, sec int64) float64 {
+	m := s.Baseline(k, sec)
+	if m < SigmaEpsilon || s.Global[k] < SigmaEpsilon {
+		return 1
+	}
+	return clamp(s.Global[k]/m, 1/seasonalMaxScale, seasonalMaxScale)
+}
+
+// Learned — hour-of-week buckets with a baseline.
+func (s *Seasonal) Learned() int {
+	n := 0
+	for _, h := range s.Hours {
+		if h > 0 {
+			n++
+		}
+	}
+	return n
+}
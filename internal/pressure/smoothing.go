@@ -0,0 +1,323 @@
+package pressure
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// SMOOTHING STAGE — pluggable filters for the raw composite
+// =============================================================================
+//
+// The scorer's last stage turns the noisy per-tick composite into the final
+// score. The single EMA is a fixed lag/noise trade-off; the alternatives pick
+// a different point on that curve. Selected per deployment with a spec
+// string (see ParseSmoother):
+//
+//   ema[:N]     EMA_t = α·raw_t + (1−α)·EMA_{t−1},  α = 2/(N+1)   (default N=5)
+//
+//   kalman      2-state (level, trend) Kalman filter, constant-velocity model:
+//                 x = [s, v],  F = [[1, 1], [0, 1]],  H = [1, 0]
+//                 Q = diag(0.5, 0.05),  R = 6
+//               Tracks a trending score with less lag than an EMA of similar
+//               noise (the trend state extrapolates), at the cost of
+//               overshoot on sharp reversals.
+//
+//   median[:N]  rolling median of the last N raw values (default 5, odd).
+//               Discards single-tick spikes entirely instead of smearing
+//               them; steps pass through with N/2 ticks of delay.
+//
+//   hull[:N]    Hull moving average, HMA = WMA_√N( 2·WMA_{N/2} − WMA_N )
+//               (default N=9). Near-zero lag on trends, noisier than the
+//               EMA in chop.
+//
+// Every filter is O(N) or better per tick and allocation-free after
+// construction.
+// =============================================================================
+
+const maxSmootherWindow = 64
+
+// Smoother — the scorer's smoothing stage. Owned by the scorer's goroutine.
+type Smoother interface {
+	// Name identifies the filter and its window (e.g. "hull:9"); a
+	// checkpoint is only restored into a smoother of the same Name.
+	Name() string
+	// Update folds in one raw composite value and returns the smoothed one.
+	Update(raw float64) float64
+	// State / Restore flatten the filter state for checkpointing.
+	State() []float64
+	Restore(state []float64)
+}
+
+// ParseSmoother builds a smoother from a spec: "ema", "kalman", "median" or
+// "hull", optionally followed by ":N" for the window (not for kalman).
+func ParseSmoother(spec string) (Smoother, error) {
+	kind, arg, hasArg := strings.Cut(strings.TrimSpace(spec), ":")
+	n := 0
+	if hasArg {
+		v, err := strconv.Atoi(arg)
+		if err != nil || v < 2 || v > maxSmootherWindow {
+			return nil, fmt.Errorf("smoother %q: window must be 2..%d", spec, maxSmootherWindow)
+		}
+		n = v
+	}
+	switch kind {
+	case "ema":
+		if n == 0 {
+			n = SmoothingPeriod
+		}
+		return NewEMASmoother(n), nil
+	case "kalman":
+		if hasArg {
+			return nil, fmt.Errorf("smoother %q: kalman takes no window", spec)
+		}
+		return NewKalmanSmoother(), nil
+	case "median":
+		if n == 0 {
+			n = 5
+		}
+		if n%2 == 0 {
+			return nil, fmt.Errorf("smoother %q: median window must be odd", spec)
+		}
+		return NewMedianSmoother(n), nil
+	case "hull":
+		if n == 0 {
+			n = 9
+		}
+		return NewHullSmoother(n), nil
+	}
+	return nil, fmt.Errorf("unknown smoother %q (want ema, kalman, median or hull)", spec)
+}
+
+// ─── EMA ───
+
+// EMASmoother — exponential moving average seeded with its first input.
+type EMASmoother struct {
+	N       int
+	alpha   float64
+	value   float64
+	hasInit bool
+}
+
+func NewEMASmoother(n int) *EMASmoother {
+	return &EMASmoother{N: n, alpha: 2 / float64(n+1)}
+}
+
+func (e *EMASmoother) Name() string { return "ema:" + strconv.Itoa(e.N) }
+
+func (e *EMASmoother) Update(raw float64) float64 {
+	if !e.hasInit {
+		e.value = raw
+		e.hasInit = true
+	} else {
+		e.value = e.alpha*raw + (1.0-e.alpha)*e.value
+	}
+	return e.value
+}
+
+func (e *EMASmoother) State() []float64 {
+	return []float64{e.value, boolFloat(e.hasInit)}
+}
+
+func (e *EMASmoother) Restore(st []float64) {
+	if len(st) == 2 {
+		e.value, e.hasInit = st[0], st[1] != 0
+	}
+}
+
+// ─── KALMAN ───
+
+const (
+	kalmanQLevel = 0.5
+	kalmanQTrend = 0.05
+	kalmanR      = 6.0
+)
+
+// KalmanSmoother — level + trend Kalman filter (see file header).
+type KalmanSmoother struct {
+	s, v          float64 // state estimate
+	p00, p01, p11 float64 // covariance (symmetric)
+	hasInit       bool
+}
+
+func NewKalmanSmoother() *KalmanSmoother {
+	return &KalmanSmoother{}
+}
+
+func (k *KalmanSmoother) Name() string { return "kalman" }
+
+func (k *KalmanSmoother) Update(raw float64) float64 {
+	if !k.hasInit {
+		k.s, k.v = raw, 0
+		k.p00, k.p01, k.p11 = kalmanR, 0, 1
+		k.hasInit = true
+		return k.s
+	}
+	// Predict: x = F·x, P = F·P·Fᵀ + Q
+	k.s += k.v
+	p00 := k.p00 + 2*k.p01 + k.p11 + kalmanQLevel
+	p01 := k.p01 + k.p11
+	p11 := k.p11 + kalmanQTrend
+
+	// Update with z = raw
+	innov := raw - k.s
+	sInv := 1 / (p00 + kalmanR)
+	k0, k1 := p00*sInv, p01*sInv
+	k.s += k0 * innov
+	k.v += k1 * innov
+	k.p00 = (1 - k0) * p00
+	k.p01 = (1 - k0) * p01
+	k.p11 = p11 - k1*p01
+	return k.s
+}
+
+func (k *KalmanSmoother) State() []float64 {
+	return []float64{k.s, k.v, k.p00, k.p01, k.p11, boolFloat(k.hasInit)}
+}
+
+func (k *KalmanSmoother) Restore(st []float64) {
+	if len(st) == 6 {
+		k.s, k.v, k.p00, k.p01, k.p11, k.hasInit = st[0], st[1], st[2], st[3], st[4], st[5] != 0
+	}
+}
+
+// ─── ROLLING MEDIAN ───
+
+// MedianSmoother — median of the last N raw values (fewer while filling).
+type MedianSmoother struct {
+	N      int
+	window ring
+	sorted [maxSmootherWindow]float64
+}
+
+func NewMedianSmoother(n int) *MedianSmoother {
+	return &MedianSmoother{N: n, window: ring{size: n}}
+}
+
+func (m *MedianSmoother) Name() string { return "median:" + strconv.Itoa(m.N) }
+
+func (m *MedianSmoother) Update(raw float64) float64 {
+	m.window.push(raw)
+	// Insertion sort of ≤ N values — cheaper than a heap pair at these sizes
+	n := m.window.count
+	for i := 0; i < n; i++ {
+		x := m.window.at(i)
+		j := i
+		for ; j > 0 && m.sorted[j-1] > x; j-- {
+			m.sorted[j] = m.sorted[j-1]
+		}
+		m.sorted[j] = x
+	}
+	if n%2 == 1 {
+		return m.sorted[n/2]
+	}
+	return (m.sorted[n/2-1] + m.sorted[n/2]) / 2
+}
+
+func (m *MedianSmoother) State() []float64     { return m.window.state() }
+func (m *MedianSmoother) Restore(st []float64) { m.window.restore(st) }
+
+// ─── HULL ───
+
+// HullSmoother — Hull moving average over the last N raw values.
+type HullSmoother struct {
+	N    int
+	raw  ring // last N raw values
+	diff ring // last √N values of 2·WMA(N/2) − WMA(N)
+}
+
+func NewHullSmoother(n int) *HullSmoother {
+	sqrtN := int(math.Round(math.Sqrt(float64(n))))
+	return &HullSmoother{N: n, raw: ring{size: n}, diff: ring{size: sqrtN}}
+}
+
+func (h *HullSmoother) Name() string { return "hull:" + strconv.Itoa(h.N) }
+
+func (h *HullSmoother) Update(raw float64) float64 {
+	h.raw.push(raw)
+	half := h.N / 2
+	h.diff.push(2*h.raw.wma(half) - h.raw.wma(h.N))
+	return h.diff.wma(h.diff.size)
+}
+
+func (h *HullSmoother) State() []float64 {
+	return append(h.raw.state(), h.diff.state()...)
+}
+
+func (h *HullSmoother) Restore(st []float64) {
+	if n := h.raw.size + 1; len(st) == n+h.diff.size+1 {
+		h.raw.restore(st[:n])
+		h.diff.restore(st[n:])
+	}
+}
+
+// ─── helpers ───
+
+// ring — the last `size` values, oldest first via at(0).
+type ring struct {
+	buf   [maxSmootherWindow]float64
+	size  int
+	next  int
+	count int
+}
+
+func (r *ring) push(x float64) {
+	r.buf[r.next] = x
+	r.next = (r.next + 1) % r.size
+	if r.count < r.size {
+		r.count++
+	}
+}
+
+// at — i-th value, oldest first (0 ≤ i < count).
+func (r *ring) at(i int) float64 {
+	return r.buf[(r.next-r.count+i+r.size)%r.size]
+}
+
+// wma — linearly weighted average of the newest min(n, count) values,
+// newest weighted highest.
+func (r *ring) wma(n int) float64 {
+	if n > r.count {
+		n = r.count
+	}
+	if n == 0 {
+		return 0
+	}
+	var sum, wsum float64
+	for i := 0; i < n; i++ {
+		w := float64(n - i)
+		sum += w * r.at(r.count-1-i)
+		wsum += w
+	}
+	return sum / wsum
+}
+
+// state — values oldest first, followed by the count.
+func (r *ring) state() []float64 {
+	st := make([]float64, r.size+1)
+	for i := 0; i < r.count; i++ {
+		st[i] = r.at(i)
+	}
+	st[r.size] = float64(r.count)
+	return st
+}
+
+func (r *ring) restore(st []float64) {
+	if len(st) != r.size+1 {
+		return
+	}
+	count := int(st[r.size])
+	r.next, r.count = 0, 0
+	for i := 0; i < count && i < r.size; i++ {
+		r.push(st[i])
+	}
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,275 @@
+package profile
+
+import (
+	"math"
+	"sort"
+)
+
+// =============================================================================
+// VOLUME PROFILE — support / resistance levels from volume-at-price
+// =============================================================================
+//
+// Traded volume is binned by price (bin width Tick) over the UTC day. Once per
+// 1m candle close the profile is reduced to a ranked list of levels:
+//
+//   POC  point of control — the bin with the most volume.
+//   VA   value area — grown from the POC one bin at a time, always towards
+//        the heavier neighbour, until it holds 70% of the day's volume;
+//        VAH / VAL are its top and bottom bins.
+//   HVN  high-volume nodes — local maxima of the profile (smoothed over
+//        ±2 bins) with at least 1.5× the average bin volume.
+//   LVN  low-volume nodes — local minima of the smoothed profile that are at
+//        most half as heavy as the highest bin on either side of them.
+//
+// At the day roll, the finished day's POC / VAH / VAL are kept as the prior
+// value area. Levels are ranked POC, prior POC, VAH, VAL, prior VAH, prior
+// VAL, then up to three HVNs and three LVNs, each by strength:
+//
+//   POC / VA:  volume / POC volume                       ∈ (0, 1]
+//   HVN:       smoothed volume / highest smoothed bin     ∈ (0, 1]
+//   LVN:       1 − valley / min(left peak, right peak)    ∈ [0.5, 1]
+//
+// TRADING INTERPRETATION:
+//   HVNs and the POC are where the market agreed on price — price slows
+//   down and rotates there (support / resistance, magnets).
+//   LVNs were rejected quickly — price tends to travel through them fast and
+//   they make clean breakout / rejection points.
+//   Open outside the prior value area → trend day risk; acceptance back
+//   inside → rotation to the opposite VA edge (the "80% rule").
+// =============================================================================
+
+// Level kinds.
+const (
+	LevelPOC = iota
+	LevelVAH
+	LevelVAL
+	LevelPrevPOC
+	LevelPrevVAH
+	LevelPrevVAL
+	LevelHVN
+	LevelLVN
+)
+
+const (
+	valueAreaShare = 0.70
+	smoothBins     = 2
+	hvnMinRatio    = 1.5
+	lvnMaxRatio    = 0.5
+	maxNodes       = 3
+
+	// MaxBins bounds the day's price range in bins; trades beyond it are
+	// not profiled (a mis-set Tick would otherwise grow the profile without
+	// limit).
+	MaxBins = 20000
+)
+
+// Level — one ranked price level.
+type Level struct {
+	Price    float64
+	Kind     int
+	Strength float64
+}
+
+// ValueArea — a day's POC and value-area edges.
+type ValueArea struct {
+	POC, VAH, VAL Level
+}
+
+// Profile — the UTC day's volume profile. Owned by the engine goroutine.
+type Profile struct {
+	Tick   float64   // bin width (price units)
+	Day    int64     // UTC day (unix seconds / 86400) being profiled
+	Base   int64     // bin index of Vol[0]
+	Vol    []float64 // volume per bin
+	Total  float64
+	Minute int64 // 1m bucket of the last update; a change recomputes Levels
+
+	Prev   ValueArea // prior day (Prev.POC.Price == 0 = none yet)
+	Levels []Level   // ranked; recomputed on 1m closes
+
+	smooth []float64
+	right  []float64 // right[i] = max(smooth[i:])
+	nodes  []Level
+}
+
+// New — a profile with bins of tick price units.
+func New(tick float64) *Profile {
+	return &Profile{Tick: tick}
+}
+
+// Update — feeds a trade (or a heartbeat, qty = 0) at sec. A new 1m bucket
+// recomputes the levels from the profile so far; a new UTC day moves the
+// finished day's value area to Prev and starts an empty profile.
+func (p *Profile) Update(sec int64, price, qty float64) {
+	if day := sec / 86400; day != p.Day {
+		if p.Total > 0 {
+			va := p.valueArea()
+			va.POC.Kind, va.VAH.Kind, va.VAL.Kind = LevelPrevPOC, LevelPrevVAH, LevelPrevVAL
+			p.Prev = va
+		}
+		p.Day = day
+		p.Vol = p.Vol[:0]
+		p.Total = 0
+	}
+	if m := sec / 60; m != p.Minute {
+		p.Minute = m
+		p.compute()
+	}
+	if qty > 0 {
+		p.add(price, qty)
+	}
+}
+
+func (p *Profile) add(price, qty float64) {
+	bin := int64(math.Floor(price / p.Tick))
+	if len(p.Vol) == 0 {
+		p.Base = bin
+	}
+	switch {
+	case bin < p.Base:
+		grow := p.Base - bin
+		if int64(len(p.Vol))+grow > MaxBins {
+			return
+		}
+		vol := make([]float64, int64(len(p.Vol))+grow)
+		copy(vol[grow:], p.Vol)
+		p.Vol = vol
+		p.Base = bin
+	case bin >= p.Base+int64(len(p.Vol)):
+		n := bin - p.Base + 1
+		if n > MaxBins {
+			return
+		}
+		for int64(len(p.Vol)) < n {
+			p.Vol = append(p.Vol, 0)
+		}
+	}
+	p.Vol[bin-p.Base] += qty
+	p.Total += qty
+}
+
+func (p *Profile) price(i int) float64 {
+	return (float64(p.Base+int64(i)) + 0.5) * p.Tick
+}
+
+func (p *Profile) poc() int {
+	best := 0
+	for i, v := range p.Vol {
+		if v > p.Vol[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// valueArea — POC and 70% value area of the current profile (Total > 0).
+func (p *Profile) valueArea() ValueArea {
+	poc := p.poc()
+	lo, hi := poc, poc
+	acc := p.Vol[poc]
+	for acc < valueAreaShare*p.Total && (lo > 0 || hi < len(p.Vol)-1) {
+		below, above := -1.0, -1.0
+		if lo > 0 {
+			below = p.Vol[lo-1]
+		}
+		if hi < len(p.Vol)-1 {
+			above = p.Vol[hi+1]
+		}
+		if above >= below {
+			hi++
+			acc += above
+		} else {
+			lo--
+			acc += below
+		}
+	}
+	pv := p.Vol[poc]
+	return ValueArea{
+		POC: Level{Price: p.price(poc), Kind: LevelPOC, Strength: 1},
+		VAH: Level{Price: p.price(hi), Kind: LevelVAH, Strength: p.Vol[hi] / pv},
+		VAL: Level{Price: p.price(lo), Kind: LevelVAL, Strength: p.Vol[lo] / pv},
+	}
+}
+
+// compute — rebuilds the ranked level list. O(bins).
+func (p *Profile) compute() {
+	p.Levels = p.Levels[:0]
+	var va ValueArea
+	if p.Total > 0 {
+		va = p.valueArea()
+	}
+	for _, l := range [...]Level{va.POC, p.Prev.POC, va.VAH, va.VAL, p.Prev.VAH, p.Prev.VAL} {
+		if l.Price != 0 {
+			p.Levels = append(p.Levels, l)
+		}
+	}
+	if p.Total == 0 {
+		return
+	}
+
+	n := len(p.Vol)
+	if cap(p.smooth) < n {
+		p.smooth = make([]float64, n)
+	}
+	s := p.smooth[:n]
+	var peak float64
+	for i := range s {
+		lo, hi := max(i-smoothBins, 0), min(i+smoothBins, n-1)
+		var sum float64
+		for j := lo; j <= hi; j++ {
+			sum += p.Vol[j]
+		}
+		s[i] = sum / float64(hi-lo+1)
+		peak = math.Max(peak, s[i])
+	}
+	poc := p.poc()
+	mean := p.Total / float64(n)
+
+	// HVNs: smoothed local maxima away from the POC
+	p.nodes = p.nodes[:0]
+	at := func(i int) float64 { // 0 beyond the profile's range
+		if i < 0 || i >= n {
+			return 0
+		}
+		return s[i]
+	}
+	for i := 0; i < n; i++ {
+		if s[i] > at(i-1) && s[i] >= at(i+1) && s[i] >= hvnMinRatio*mean &&
+			(i < poc-smoothBins || i > poc+smoothBins) {
+			p.nodes = append(p.nodes, Level{Price: p.price(i), Kind: LevelHVN, Strength: s[i] / peak})
+		}
+	}
+	p.appendNodes()
+
+	// LVNs: valleys at most half the height of the lower surrounding peak
+	if cap(p.right) < n {
+		p.right = make([]float64, n)
+	}
+	right := p.right[:n]
+	right[n-1] = s[n-1]
+	for i := n - 2; i >= 0; i-- {
+		right[i] = math.Max(s[i], right[i+1])
+	}
+	p.nodes = p.nodes[:0]
+	left := 0.0
+	for i := 1; i < n-1; i++ {
+		left = math.Max(left, s[i-1])
+		if !(s[i] < s[i-1] && s[i] <= s[i+1]) {
+			continue
+		}
+		wall := math.Min(left, right[i+1])
+		if wall > 0 && s[i] <= lvnMaxRatio*wall {
+			p.nodes = append(p.nodes, Level{Price: p.price(i), Kind: LevelLVN, Strength: 1 - s[i]/wall})
+		}
+	}
+	p.appendNodes()
+}
+
+// appendNodes — adds the strongest maxNodes of p.nodes to Levels.
+func (p *Profile) appendNodes() {
+	sort.Slice(p.nodes, func(i, j int) bool { return p.nodes[i].Strength > p.nodes[j].Strength })
+	if len(p.nodes) > maxNodes {
+		p.nodes = p.nodes[:maxNodes]
+	}
+	p.Levels = append(p.Levels, p.nodes...)
+}
@@ -0,0 +1,392 @@
+package replication
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"market-indikator/internal/engine"
+	"market-indikator/internal/model"
+	"market-indikator/internal/state"
+
+	"github.com/gorilla/websocket"
+)
+
+// =============================================================================
+// WARM STANDBY — mirroring engine state to a second instance
+// =============================================================================
+//
+// A restarted instance is warm only if it was shut down cleanly on the same
+// host: the checkpoint and ring buffers live in logs/state. When the host
+// itself is gone, the replacement starts cold — σ estimates at 1.0, empty
+// 4h/1d candles, no history for the dashboard — and the scores are degraded
+// for about an hour.
+//
+// A standby instance (orderflow -standby ws://primary:8080/ws/replication)
+// keeps a copy of that state instead of running:
+//
+//   primary   GET /ws/replication (Source): every -replicate-every, a frame
+//             with a fresh engine checkpoint and the ring-buffer snapshots
+//             added since the previous frame (the whole buffers on the first)
+//   standby   Follow: applies the frames to its own ring buffers and writes
+//             the checkpoint to logs/state, ingesting nothing and serving
+//             nothing
+//
+// When the primary has been unreachable (or silent) for -failover-after, the
+// standby writes its buffers to logs/state and Follow returns: the instance
+// then starts as usual, and the warm restart picks up the primary's state as
+// of the last frame. The failover clock also runs while the primary was
+// never reached, so a standby started alone takes over too.
+//
+// The standby cannot tell a dead primary from a broken link to it: after a
+// network partition both ingest (each with its own logs). There is no
+// failback — restart the old primary with -standby pointing at the new one.
+//
+// The endpoint is off unless -replicate-every is set, and needs the admin
+// token (StandbyConfig.Token, sent as X-Admin-Token) from anywhere but the
+// primary's own host.
+//
+// Frames are gob-encoded, one per WebSocket message, and carry the symbol and
+// the checkpoint/ring-state versions: a standby for another symbol or build
+// refuses them rather than restoring mismatched state.
+// =============================================================================
+
+// Frame — one replication message.
+type Frame struct {
+	Symbol            string
+	CheckpointVersion int
+	PersistVersion    int
+	Full              bool // Rings hold the whole buffers, not the increment
+	Checkpoint        engine.Checkpoint
+	Rings             map[string][]model.Snapshot
+}
+
+const (
+	writeWait    = 10 * time.Second
+	maxFrameSize = 512 << 20 // the first frame carries every ring buffer
+	redialDelay  = time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// ErrIncompatible — the primary runs another symbol or state format.
+var ErrIncompatible = errors.New("replication: incompatible primary")
+
+// =============================================================================
+// PRIMARY
+// =============================================================================
+
+type namedRing struct {
+	name string
+	rb   *state.RingBuffer
+}
+
+// Source — the primary's side: the latest checkpoint and the ring buffers,
+// served to followers at /ws/replication.
+type Source struct {
+	symbol    string
+	rings     []namedRing
+	followers int32 // atomic
+
+	mu   sync.Mutex
+	cp   *engine.Checkpoint
+	next chan struct{} // closed by Publish
+}
+
+// NewSource — a source for symbol's state.
+func NewSource(symbol string) *Source {
+	return &Source{symbol: symbol, next: make(chan struct{})}
+}
+
+// AddRing replicates rb under name. Must be called before the first
+// follower connects.
+func (s *Source) AddRing(name string, rb *state.RingBuffer) {
+	s.rings = append(s.rings, namedRing{name, rb})
+}
+
+// Followers — connected standbys. The engine goroutine only takes
+// checkpoints for Publish while there is one.
+func (s *Source) Followers() int64 {
+	return int64(atomic.LoadInt32(&s.followers))
+}
+
+// Publish hands a checkpoint to the followers; each gets a frame with it and
+// the snapshots added since its previous frame. Called from the engine
+// goroutine (Checkpoint copies the state, the frame is encoded elsewhere).
+func (s *Source) Publish(cp engine.Checkpoint) {
+	s.mu.Lock()
+	s.cp = &cp
+	close(s.next)
+	s.next = make(chan struct{})
+	s.mu.Unlock()
+}
+
+func (s *Source) latest() (*engine.Checkpoint, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cp, s.next
+}
+
+// cursor — how far a follower has been sent one ring: the last snapshot
+// time and how many snapshots with that time (several trades can share a
+// millisecond).
+type cursor struct {
+	time int64
+	n    int
+}
+
+// increment returns the snapshots of rb after c and advances c.
+func (c *cursor) increment(rb *state.RingBuffer) []model.Snapshot {
+	snaps := rb.Range(c.time, math.MaxInt64)
+	skip := 0
+	for skip < len(snaps) && skip < c.n && snaps[skip].Time == c.time {
+		skip++
+	}
+	out := snaps[skip:]
+	if len(out) == 0 {
+		return nil
+	}
+	last, n := out[len(out)-1].Time, 0
+	for i := len(snaps) - 1; i >= 0 && snaps[i].Time == last; i-- {
+		n++
+	}
+	c.time, c.n = last, n
+	return out
+}
+
+// ServeHTTP — GET /ws/replication.
+func (s *Source) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Replication: upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+	atomic.AddInt32(&s.followers, 1)
+	defer atomic.AddInt32(&s.followers, -1)
+	log.Printf("Replication: standby %s connected", r.RemoteAddr)
+
+	// Nothing is expected from the standby; reading detects its close
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	cursors := make([]cursor, len(s.rings))
+	var sent *engine.Checkpoint
+	for {
+		cp, next := s.latest()
+		if cp != nil && cp != sent {
+			f := Frame{
+				Symbol:            s.symbol,
+				CheckpointVersion: engine.CheckpointVersion,
+				PersistVersion:    state.PersistVersion,
+				Full:              sent == nil,
+				Checkpoint:        *cp,
+				Rings:             make(map[string][]model.Snapshot, len(s.rings)),
+			}
+			for i, ring := range s.rings {
+				if snaps := cursors[i].increment(ring.rb); len(snaps) > 0 {
+					f.Rings[ring.name] = snaps
+				}
+			}
+			if err := writeFrame(conn, &f); err != nil {
+				log.Printf("Replication: standby %s: %v", r.RemoteAddr, err)
+				return
+			}
+			sent = cp
+		}
+		select {
+		case <-next:
+		case <-done:
+			log.Printf("Replication: standby %s disconnected", r.RemoteAddr)
+			return
+		}
+	}
+}
+
+func writeFrame(conn *websocket.Conn, f *Frame) error {
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	w, err := conn.NextWriter(websocket.BinaryMessage)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(w).Encode(f); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// =============================================================================
+// STANDBY
+// =============================================================================
+
+// RingFile — a ring buffer the standby mirrors, and where the warm restart
+// reads it from.
+type RingFile struct {
+	Name     string
+	Path     string
+	Capacity int
+}
+
+// StandbyConfig — what to follow and where to put it.
+type StandbyConfig struct {
+	URL            string // the primary's /ws/replication
+	Token          string // the primary's admin token ("" = none sent)
+	Symbol         string
+	FailoverAfter  time.Duration
+	CheckpointPath string
+	Rings          []RingFile
+}
+
+// ringSaveEvery — the mirrored buffers are also written out periodically,
+// so a standby killed outright still leaves recent state behind.
+const ringSaveEvery = time.Minute
+
+type standby struct {
+	cfg       StandbyConfig
+	rings     map[string]*state.RingBuffer
+	lastFrame time.Time
+	lastSave  time.Time
+	frames    int64
+}
+
+// Follow mirrors the primary until it has been unreachable for
+// FailoverAfter (returns nil: take over) or ctx is done (returns ctx.Err()).
+// Either way the state received so far is in the files. An incompatible
+// primary returns ErrIncompatible.
+func Follow(ctx context.Context, cfg StandbyConfig) error {
+	s := &standby{cfg: cfg, rings: make(map[string]*state.RingBuffer), lastFrame: time.Now()}
+	for _, rf := range cfg.Rings {
+		s.rings[rf.Name] = state.NewRingBuffer(rf.Capacity)
+	}
+	log.Printf("Standby: following %s, failover after %v of silence", cfg.URL, cfg.FailoverAfter)
+	for {
+		if silent := time.Since(s.lastFrame); silent >= cfg.FailoverAfter {
+			log.Printf("Standby: primary silent for %v, taking over (%d frames received)",
+				silent.Round(time.Second), s.frames)
+			if err := s.save(); err != nil {
+				log.Printf("Standby: %v", err)
+			}
+			return nil
+		}
+		err := s.follow(ctx)
+		if ctx.Err() != nil {
+			if err := s.save(); err != nil {
+				log.Printf("Standby: %v", err)
+			}
+			return ctx.Err()
+		}
+		if errors.Is(err, ErrIncompatible) {
+			return err
+		}
+		log.Printf("Standby: primary: %v; failover in %v", err,
+			(cfg.FailoverAfter - time.Since(s.lastFrame)).Round(time.Second))
+		select {
+		case <-ctx.Done():
+		case <-time.After(redialDelay):
+		}
+	}
+}
+
+// follow reads one connection until it fails.
+func (s *standby) follow(ctx context.Context) error {
+	dialCtx, cancel := context.WithDeadline(ctx, s.lastFrame.Add(s.cfg.FailoverAfter))
+	var header http.Header
+	if s.cfg.Token != "" {
+		header = http.Header{"X-Admin-Token": {s.cfg.Token}}
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(dialCtx, s.cfg.URL, header)
+	cancel()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxFrameSize)
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	for {
+		conn.SetReadDeadline(s.lastFrame.Add(s.cfg.FailoverAfter))
+		_, r, err := conn.NextReader()
+		if err != nil {
+			return err
+		}
+		var f Frame
+		if err := gob.NewDecoder(r).Decode(&f); err != nil {
+			return fmt.Errorf("frame: %v", err)
+		}
+		if err := s.apply(&f); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *standby) apply(f *Frame) error {
+	switch {
+	case f.Symbol != s.cfg.Symbol:
+		return fmt.Errorf("%w: symbol %s, want %s", ErrIncompatible, f.Symbol, s.cfg.Symbol)
+	case f.CheckpointVersion != engine.CheckpointVersion || f.PersistVersion != state.PersistVersion:
+		return fmt.Errorf("%w: checkpoint v%d ring state v%d, want v%d v%d", ErrIncompatible,
+			f.CheckpointVersion, f.PersistVersion, engine.CheckpointVersion, state.PersistVersion)
+	}
+	if f.Full {
+		for _, rf := range s.cfg.Rings {
+			s.rings[rf.Name] = state.NewRingBuffer(rf.Capacity)
+		}
+	}
+	for name, snaps := range f.Rings {
+		if rb := s.rings[name]; rb != nil {
+			for _, snap := range snaps {
+				rb.Add(snap)
+			}
+		}
+	}
+	if err := engine.SaveCheckpoint(s.cfg.CheckpointPath, f.Checkpoint); err != nil {
+		log.Printf("Standby: checkpoint save failed: %v", err)
+	}
+	if f.Full {
+		log.Printf("Standby: in sync with the primary (checkpoint %v old)",
+			time.Since(time.UnixMilli(f.Checkpoint.SavedAt)).Round(time.Millisecond))
+	}
+	s.lastFrame = time.Now()
+	s.frames++
+	if time.Since(s.lastSave) >= ringSaveEvery {
+		if err := s.save(); err != nil {
+			log.Printf("Standby: %v", err)
+		}
+	}
+	return nil
+}
+
+// save writes the mirrored buffers for the warm restart. Buffers never
+// filled by the primary are left alone (the previous files, if any, stay).
+func (s *standby) save() error {
+	s.lastSave = time.Now()
+	if s.frames == 0 {
+		return nil
+	}
+	for _, rf := range s.cfg.Rings {
+		if err := s.rings[rf.Name].SaveFile(rf.Path); err != nil {
+			return fmt.Errorf("ring %s: %v", rf.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// =============================================================================
+// RISK MANAGER — exposure, PnL and drawdown rails
+// =============================================================================
+//
+// Books every fill of a trading component (internal/execution) and marks the
+// position to the live price:
+//
+//   Exposure  = |Position| · Price                         quote notional
+//   Unrealized = (Price − EntryPrice) · Position
+//   DayPnL    = realized today (UTC) + Unrealized
+//   Equity    = realized since start + Unrealized
+//   Drawdown  = PeakEquity − Equity                          ≥ 0
+//
+// LIMITS (0 = off):
+//   MaxPosition   |Position after order| ≤ MaxPosition       (base asset)
+//   MaxNotional   |Position after order| · Price ≤ MaxNotional (quote)
+//   MaxDailyLoss  DayPnL > −MaxDailyLoss                     (quote)
+//   MaxDrawdown   Drawdown < MaxDrawdown                     (quote)
+//
+// Check vetoes an order that would grow the position past a size limit, and
+// every growing order while a loss limit is breached. Orders that only
+// reduce the position are always allowed — a rail must never block the way
+// out. A breach latches until Clear; Mark reports it so the caller can
+// flatten. Clear restarts the drawdown from the current equity; a daily loss
+// still beyond its limit breaches again on the next Mark.
+//
+// PnL excludes commission. Writes come from a SINGLE goroutine (the owner);
+// GetState is lock-free from any goroutine, same as the OI engine.
+// =============================================================================
+
+const qtyEpsilon = 1e-9
+
+// Limits — the rails (0 = off).
+type Limits struct {
+	MaxPosition  float64 `json:"max_position"`   // base asset
+	MaxNotional  float64 `json:"max_notional"`   // quote
+	MaxDailyLoss float64 `json:"max_daily_loss"` // quote
+	MaxDrawdown  float64 `json:"max_drawdown"`   // quote
+}
+
+// State — the published risk state (JSON for GET /admin/risk).
+type State struct {
+	Position      float64 `json:"position"`
+	EntryPrice    float64 `json:"entry_price"`
+	Price         float64 `json:"price"`
+	Exposure      float64 `json:"exposure"`
+	RealizedPnL   float64 `json:"realized_pnl"` // today (UTC)
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	DayPnL        float64 `json:"day_pnl"`
+	Equity        float64 `json:"equity"` // since start
+	PeakEquity    float64 `json:"peak_equity"`
+	Drawdown      float64 `json:"drawdown"`
+	Breach        string  `json:"breach,omitempty"` // latched loss-limit breach
+	Vetoes        int64   `json:"vetoes"`
+	LastVeto      string  `json:"last_veto,omitempty"`
+	Limits        Limits  `json:"limits"`
+	UpdatedAt     int64   `json:"updated_at"` // unix ms
+}
+
+// Manager — the risk book for one symbol.
+type Manager struct {
+	limits Limits
+	state  unsafe.Pointer // *State
+
+	// Owned by the writer goroutine
+	pos, entry, price  float64
+	dayRealized, total float64
+	peak               float64
+	day                int64
+	breach, lastVeto   string
+	vetoes             int64
+}
+
+func New(limits Limits) *Manager {
+	m := &Manager{limits: limits}
+	m.publish()
+	return m
+}
+
+// Limits — the configured rails.
+func (m *Manager) Limits() Limits {
+	return m.limits
+}
+
+// GetState returns the latest state. LOCK-FREE.
+func (m *Manager) GetState() State {
+	return *(*State)(atomic.LoadPointer(&m.state))
+}
+
+// Position — the booked position and entry price.
+func (m *Manager) Position() (size, entryPrice float64) {
+	return m.pos, m.entry
+}
+
+// SetPosition replaces the position (seed or resync from the exchange)
+// without booking PnL.
+func (m *Manager) SetPosition(size, entryPrice float64) {
+	m.pos, m.entry = size, entryPrice
+	if math.Abs(m.pos) < qtyEpsilon {
+		m.pos, m.entry = 0, 0
+	}
+	m.publish()
+}
+
+// Mark updates the price at timeMs and returns the loss-limit breach, if
+// any ("" = within limits).
+func (m *Manager) Mark(timeMs int64, price float64) string {
+	m.rollDay(timeMs)
+	m.price = price
+	m.evaluate()
+	m.publish()
+	return m.breach
+}
+
+// Fill books a fill of qty (signed, + buy) at price.
+func (m *Manager) Fill(timeMs int64, qty, price float64) {
+	if math.Abs(qty) < qtyEpsilon {
+		return
+	}
+	m.rollDay(timeMs)
+	if m.pos != 0 && (m.pos > 0) != (qty > 0) {
+		closed := math.Min(math.Abs(qty), math.Abs(m.pos))
+		pnl := closed * (price - m.entry) * math.Copysign(1, m.pos)
+		m.dayRealized += pnl
+		m.total += pnl
+		if math.Abs(qty) <= math.Abs(m.pos) {
+			m.pos += qty
+			if math.Abs(m.pos) < qtyEpsilon {
+				m.pos, m.entry = 0, 0
+			}
+		} else {
+			// Reversal: the remainder opens at the fill price
+			m.pos, m.entry = m.pos+qty, price
+		}
+	} else {
+		m.entry = (m.entry*math.Abs(m.pos) + price*math.Abs(qty)) / (math.Abs(m.pos) + math.Abs(qty))
+		m.pos += qty
+	}
+	m.evaluate()
+	m.publish()
+}
+
+// Check vetoes an order of qty (signed) at the current price: nil = allowed.
+func (m *Manager) Check(qty float64) error {
+	after := m.pos + qty
+	if math.Abs(after) <= math.Abs(m.pos)+qtyEpsilon && after*m.pos >= 0 {
+		return nil // only reduces
+	}
+	var reason string
+	switch {
+	case m.breach != "":
+		reason = m.breach
+	case m.limits.MaxPosition > 0 && math.Abs(after) > m.limits.MaxPosition+qtyEpsilon:
+		reason = fmt.Sprintf("position %g exceeds max %g", after, m.limits.MaxPosition)
+	case m.limits.MaxNotional > 0 && math.Abs(after)*m.price > m.limits.MaxNotional:
+		reason = fmt.Sprintf("notional %.2f exceeds max %g", math.Abs(after)*m.price, m.limits.MaxNotional)
+	default:
+		return nil
+	}
+	m.vetoes++
+	m.lastVeto = reason
+	m.publish()
+	return fmt.Errorf("risk veto: %s", reason)
+}
+
+// Clear releases a latched breach and resets the drawdown peak.
+func (m *Manager) Clear() {
+	m.breach = ""
+	m.peak = m.total + m.unrealized()
+	m.publish()
+}
+
+// evaluate latches a loss-limit breach.
+func (m *Manager) evaluate() {
+	upnl := m.unrealized()
+	equity := m.total + upnl
+	if equity > m.peak {
+		m.peak = equity
+	}
+	if m.breach != "" {
+		return
+	}
+	switch {
+	case m.limits.MaxDailyLoss > 0 && m.dayRealized+upnl <= -m.limits.MaxDailyLoss:
+		m.breach = fmt.Sprintf("daily loss %.2f reached limit %g", -(m.dayRealized + upnl), m.limits.MaxDailyLoss)
+	case m.limits.MaxDrawdown > 0 && m.peak-equity >= m.limits.MaxDrawdown:
+		m.breach = fmt.Sprintf("drawdown %.2f reached limit %g", m.peak-equity, m.limits.MaxDrawdown)
+	}
+}
+
+func (m *Manager) rollDay(timeMs int64) {
+	if day := timeMs / 86_400_000; day != m.day {
+		m.day, m.dayRealized = day, 0
+	}
+}
+
+func (m *Manager) unrealized() float64 {
+	if m.pos == 0 || m.price == 0 {
+		return 0
+	}
+	return (m.price - m.entry) * m.pos
+}
+
+func (m *Manager) publish() {
+	upnl := m.unrealized()
+	equity := m.total + upnl
+	atomic.StorePointer(&m.state, unsafe.Pointer(&State{
+		Position:      m.pos,
+		EntryPrice:    m.entry,
+		Price:         m.price,
+		Exposure:      math.Abs(m.pos) * m.price,
+		RealizedPnL:   m.dayRealized,
+		UnrealizedPnL: upnl,
+		DayPnL:        m.dayRealized + upnl,
+		Equity:        equity,
+		PeakEquity:    m.peak,
+		Drawdown:      m.peak - equity,
+		Breach:        m.breach,
+		Vetoes:        m.vetoes,
+		LastVeto:      m.lastVeto,
+		Limits:        m.limits,
+		UpdatedAt:     time.Now().UnixMilli(),
+	}))
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"market-indikator/internal/vwap"
+)
+
+// =============================================================================
+// TRADING SESSIONS — Asia / London / New York
+// =============================================================================
+//
+// Each UTC day is split into three sessions by fixed UTC hours (no DST
+// adjustment — the boundaries sit on the winter-time opens, close enough for
+// crypto where the sessions are a liquidity pattern, not an exchange rule):
+//
+//   Asia    00:00–08:00 UTC  (Tokyo / Hong Kong / Singapore)
+//   London  08:00–13:00 UTC
+//   NY      13:00–24:00 UTC  (includes the quiet post-close hours)
+//
+// Per session the tracker keeps:
+//   Open, High, Low           — first / extreme trade prices of the session
+//   VWAP (± σ bands)          — volume-weighted average price (internal/vwap)
+//
+// and per day:
+//   DayVWAP                   — VWAP anchored at 00:00 UTC
+//   Opens[s]                  — today's open of each session (0 = not yet)
+//   PrevHigh / PrevLow        — high / low of the previous UTC day
+//
+// TRADING INTERPRETATION:
+//   Price above NY open and above VWAP → session buyers in control.
+//   Sweeping yesterday's high then losing it → classic liquidity grab.
+//   Asia range (High-Low) is often the box London breaks out of.
+//
+// Owned by the engine goroutine (single writer, no locks).
+// =============================================================================
+
+// Session IDs (also the wire value).
+const (
+	Asia   = 0
+	London = 1
+	NY     = 2
+
+	NumSessions = 3
+)
+
+// Names — display label of each session ID.
+var Names = [NumSessions]string{"ASIA", "LONDON", "NY"}
+
+// Session start hours (UTC), in order.
+var startHour = [NumSessions]int64{0, 8, 13}
+
+// At returns the session active at sec (unix seconds).
+func At(sec int64) int {
+	hour := sec % 86400 / 3600
+	id := Asia
+	for s := 1; s < NumSessions; s++ {
+		if hour >= startHour[s] {
+			id = s
+		}
+	}
+	return id
+}
+
+// StartOf returns the start (unix seconds) of the session active at sec.
+func StartOf(sec int64) int64 {
+	return sec/86400*86400 + startHour[At(sec)]*3600
+}
+
+// EndOf returns the end (unix seconds, exclusive) of the session active at
+// sec: the next session's start, or the next midnight after NY.
+func EndOf(sec int64) int64 {
+	day, id := sec/86400*86400, At(sec)
+	if id == NumSessions-1 {
+		return day + 86400
+	}
+	return day + startHour[id+1]*3600
+}
+
+// Stats — running statistics of one session.
+type Stats struct {
+	Start int64 // unix seconds
+	Open  float64
+	High  float64
+	Low   float64
+	VWAP  vwap.Accumulator
+}
+
+// Tracker — current session, today's session opens and previous-day range.
+type Tracker struct {
+	ID      int
+	Current Stats
+
+	Day      int64 // unix seconds of the current UTC day's midnight
+	DayVWAP  vwap.Accumulator
+	Opens    [NumSessions]float64
+	DayHigh  float64
+	DayLow   float64
+	PrevHigh float64 // previous UTC day (0 = unknown)
+	PrevLow  float64
+}
+
+// Update — folds a trade (or, with qty=0, a heartbeat at the last price)
+// into the tracker, rolling the session and day at their boundaries. O(1).
+func (t *Tracker) Update(sec int64, price, qty float64) {
+	day := sec / 86400 * 86400
+	if day != t.Day {
+		if t.Day != 0 && day == t.Day+86400 {
+			t.PrevHigh, t.PrevLow = t.DayHigh, t.DayLow
+		} else if t.Day != 0 {
+			// Skipped a whole day (downtime) — yesterday is unknown
+			t.PrevHigh, t.PrevLow = 0, 0
+		}
+		t.Day = day
+		t.Opens = [NumSessions]float64{}
+		t.DayVWAP = vwap.Accumulator{}
+		t.DayHigh, t.DayLow = price, price
+	}
+
+	id, start := At(sec), StartOf(sec)
+	if t.Current.Start != start {
+		t.ID = id
+		t.Current = Stats{Start: start, Open: price, High: price, Low: price}
+		t.Opens[id] = price
+	}
+
+	if price > t.Current.High {
+		t.Current.High = price
+	}
+	if price < t.Current.Low {
+		t.Current.Low = price
+	}
+	if price > t.DayHigh {
+		t.DayHigh = price
+	}
+	if price < t.DayLow {
+		t.DayLow = price
+	}
+	if qty > 0 {
+		t.Current.VWAP.Add(price, qty)
+		t.DayVWAP.Add(price, qty)
+	}
+}
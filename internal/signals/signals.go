@@ -0,0 +1,166 @@
+package signals
+
+import (
+	"time"
+
+	csvlogger "market-indikator/internal/logger"
+	"market-indikator/internal/model"
+)
+
+// =============================================================================
+// SCORE SIGNALS — threshold crossings with a trade lifecycle
+// =============================================================================
+//
+// The final score is continuous; automation downstream wants discrete
+// events. The generator turns it into signals that walk one lifecycle, one
+// signal open at a time (side = +1 long, −1 short, s = side × finalScore):
+//
+//   (none) ──s crosses Arm──────────────▶ ARMED        ArmPrice
+//   ARMED  ──s ≥ Trigger────────────────▶ TRIGGERED    EntryPrice (the entry)
+//   ARMED  ──s ≤ 0 / ArmTTL / bias flip─▶ INVALIDATED
+//   TRIGGERED ──adverse move ≥ StopBps──▶ INVALIDATED  (stopped)
+//   TRIGGERED ──s < Exit / MaxHold──────▶ COMPLETED    ExitPrice, ReturnBps
+//
+//   ReturnBps = side × (exit − entry) / entry × 10⁴
+//
+// Arming needs a crossing (the previous snapshot below Arm), so a score
+// parked above Arm after a close does not re-arm at once. With RespectBias a
+// long is not armed against a BEARISH HTF bias (nor a short against
+// BULLISH), and an armed signal is dropped when the bias turns against it.
+//
+// While entries are suppressed (logger.EntriesSuppressed: cold-start warmup,
+// event-risk window) nothing is armed and an armed signal does not trigger;
+// it can still be invalidated, and a triggered one still closes.
+//
+// Every state change is returned to the caller (published on the signals
+// bus: signal log, /ws/signals). The open signal is not checkpointed: a
+// restart starts without one.
+//
+// Engine goroutine only.
+// =============================================================================
+
+// Config — levels are |final score| on its ±100 scale.
+type Config struct {
+	Arm         float64
+	Trigger     float64
+	Exit        float64
+	ArmTTL      time.Duration // armed signal expiry
+	MaxHold     time.Duration // triggered signal completes after this
+	StopBps     float64       // adverse move from entry that invalidates (0 = off)
+	RespectBias bool          // no signals against the HTF bias
+}
+
+// DefaultConfig — arm at 30, enter at 50, done below 10.
+func DefaultConfig() Config {
+	return Config{
+		Arm:         30,
+		Trigger:     50,
+		Exit:        10,
+		ArmTTL:      time.Minute,
+		MaxHold:     15 * time.Minute,
+		StopBps:     30,
+		RespectBias: true,
+	}
+}
+
+// Generator — see above.
+type Generator struct {
+	cfg       Config
+	active    model.Signal // State 0 = none open
+	prevScore float64
+	started   bool
+}
+
+func NewGenerator(cfg Config) *Generator {
+	return &Generator{cfg: cfg}
+}
+
+// SetConfig replaces the thresholds (config reload). Open signals stay open
+// and continue under the new ones. Same goroutine as Observe.
+func (g *Generator) SetConfig(cfg Config) {
+	g.cfg = cfg
+}
+
+// Observe feeds a snapshot and returns the state changes it caused (out[:n]:
+// a close and a new arm can fall on the same snapshot).
+func (g *Generator) Observe(snap *model.Snapshot) (out [2]model.Signal, n int) {
+	score := snap.FinalScore
+	bias := ""
+	if g.cfg.RespectBias {
+		bias, _, _ = csvlogger.DecisionFor(snap)
+	}
+	suppressed := csvlogger.EntriesSuppressed(snap)
+	if g.active.Open() {
+		if g.step(snap, score, bias, suppressed) {
+			out[n], n = g.active, n+1
+		}
+	}
+	if !g.active.Open() && g.started && !suppressed {
+		for _, side := range [2]int{1, -1} {
+			s, prev := float64(side)*score, float64(side)*g.prevScore
+			if s >= g.cfg.Arm && prev < g.cfg.Arm && !against(side, bias) {
+				g.active = model.Signal{
+					ID: snap.Time, Side: side, State: model.SignalArmed, Reason: "score crossed arm level",
+					ArmedAt: snap.Time, ArmPrice: snap.Price, Score: score,
+				}
+				out[n], n = g.active, n+1
+				break
+			}
+		}
+	}
+	g.prevScore, g.started = score, true
+	return out, n
+}
+
+// step advances the open signal; true when its state changed. suppressed
+// holds an armed signal back from triggering.
+func (g *Generator) step(snap *model.Snapshot, score float64, bias string, suppressed bool) bool {
+	a := &g.active
+	side := float64(a.Side)
+	s := side * score
+	switch a.State {
+	case model.SignalArmed:
+		switch {
+		case s >= g.cfg.Trigger && !suppressed:
+			a.State, a.Reason = model.SignalTriggered, "score crossed trigger level"
+			a.TriggeredAt, a.EntryPrice = snap.Time, snap.Price
+		case s <= 0:
+			g.close(snap, model.SignalInvalidated, "score reversed before trigger")
+		case snap.Time-a.ArmedAt >= g.cfg.ArmTTL.Milliseconds():
+			g.close(snap, model.SignalInvalidated, "armed signal expired")
+		case against(a.Side, bias):
+			g.close(snap, model.SignalInvalidated, "HTF bias turned against")
+		default:
+			return false
+		}
+
+	case model.SignalTriggered:
+		move := side * (snap.Price - a.EntryPrice) / a.EntryPrice * 1e4
+		switch {
+		case g.cfg.StopBps > 0 && move <= -g.cfg.StopBps:
+			g.close(snap, model.SignalInvalidated, "stopped: adverse move")
+		case s < g.cfg.Exit:
+			g.close(snap, model.SignalCompleted, "score faded")
+		case snap.Time-a.TriggeredAt >= g.cfg.MaxHold.Milliseconds():
+			g.close(snap, model.SignalCompleted, "max hold reached")
+		default:
+			return false
+		}
+	}
+	a.Score = score
+	return true
+}
+
+func (g *Generator) close(snap *model.Snapshot, state int, reason string) {
+	a := &g.active
+	a.State, a.Reason = state, reason
+	a.ClosedAt, a.ExitPrice = snap.Time, snap.Price
+	if a.EntryPrice > 0 {
+		a.ReturnBps = float64(a.Side) * (snap.Price - a.EntryPrice) / a.EntryPrice * 1e4
+	}
+}
+
+// against — the HTF bias opposes side ("" = not checked).
+func against(side int, bias string) bool {
+	return side > 0 && bias == "BEARISH" || side < 0 && bias == "BULLISH"
+}
@@ -2,6 +2,7 @@ package state
 
 import (
 	"market-indikator/internal/model"
+	"sort"
 	"sync"
 )
 
@@ -71,6 +72,83 @@ func (rb *RingBuffer) GetAll() []model.Snapshot {
 	return out
 }
 
+// Last — returns a copy of the most recent n snapshots in chronological order.
+// O(n).
+func (rb *RingBuffer) Last(n int) []model.Snapshot {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+
+	if n > rb.size {
+		n = rb.size
+	}
+	if n <= 0 {
+		return nil
+	}
+	return rb.copyRange(rb.size-n, rb.size)
+}
+
+// Latest — returns the most recent snapshot, false if empty.
+func (rb *RingBuffer) Latest() (model.Snapshot, bool) {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+
+	if rb.size == 0 {
+		return model.Snapshot{}, false
+	}
+	return rb.data[rb.index(rb.size-1)], true
+}
+
+// Oldest — returns the oldest snapshot, false if empty.
+func (rb *RingBuffer) Oldest() (model.Snapshot, bool) {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+
+	if rb.size == 0 {
+		return model.Snapshot{}, false
+	}
+	return rb.data[rb.index(0)], true
+}
+
+// Range — returns a copy of snapshots with from <= Time < to, in chronological
+// order. Times are in Snapshot.Time units. O(log N + k): snapshots are appended
+// in time order, so both ends are found by binary search.
+func (rb *RingBuffer) Range(from, to int64) []model.Snapshot {
+	rb.mu.RLock()
+	defer rb.mu.RUnlock()
+
+	lo := sort.Search(rb.size, func(i int) bool { return rb.data[rb.index(i)].Time >= from })
+	hi := sort.Search(rb.size, func(i int) bool { return rb.data[rb.index(i)].Time >= to })
+	if lo >= hi {
+		return nil
+	}
+	return rb.copyRange(lo, hi)
+}
+
+// index — maps a logical position (0 = oldest) to a slot in data.
+func (rb *RingBuffer) index(i int) int {
+	if !rb.full {
+		return i
+	}
+	return (rb.head + i) % rb.capacity
+}
+
+// copyRange — copies logical positions [lo, hi). Caller holds the lock.
+func (rb *RingBuffer) copyRange(lo, hi int) []model.Snapshot {
+	out := make([]model.Snapshot, 0, hi-lo)
+	start, end := rb.index(lo), rb.index(hi-1)+1
+	if start < end {
+		return append(out, rb.data[start:end]...)
+	}
+	// Wraps around the end of the backing slice
+	out = append(out, rb.data[start:]...)
+	return append(out, rb.data[:end]...)
+}
+
+// Capacity — returns the fixed capacity.
+func (rb *RingBuffer) Capacity() int {
+	return rb.capacity
+}
+
 // Size — returns current number of elements.
 func (rb *RingBuffer) Size() int {
 	rb.mu.RLock()
@@ -0,0 +1,45 @@
+package state
+
+import (
+	"market-indikator/internal/model"
+)
+
+// Downsampler — feeds a long-horizon RingBuffer at a coarser resolution.
+//
+// The base buffer keeps one snapshot per trade-second for an hour; that is
+// far too dense to keep a day or a week. A Downsampler keeps one snapshot per
+// interval bucket — the LAST snapshot seen in the bucket, i.e. the bucket's
+// closing state (candles, CVD, scores are all cumulative, so the close is the
+// representative sample).
+//
+// A bucket is committed when the first snapshot of the next bucket arrives,
+// so the tier trails live data by at most one interval; the live edge is
+// always available from the base buffer.
+//
+// Owned by the engine goroutine (single writer); the underlying RingBuffer is
+// safe for concurrent readers.
+type Downsampler struct {
+	Buffer     *RingBuffer
+	intervalMs int64
+
+	pending    model.Snapshot
+	hasPending bool
+}
+
+// NewDownsampler — intervalSec-resolution tier holding capacity snapshots
+// (e.g. 60s × 1440 = 24h, 300s × 2016 = 7d).
+func NewDownsampler(intervalSec int64, capacity int) *Downsampler {
+	return &Downsampler{
+		Buffer:     NewRingBuffer(capacity),
+		intervalMs: intervalSec * 1000,
+	}
+}
+
+// Add — offers a snapshot (Time in unix ms). O(1).
+func (d *Downsampler) Add(snap model.Snapshot) {
+	if d.hasPending && snap.Time/d.intervalMs != d.pending.Time/d.intervalMs {
+		d.Buffer.Add(d.pending)
+	}
+	d.pending = snap
+	d.hasPending = true
+}
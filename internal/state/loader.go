@@ -2,6 +2,7 @@ package state
 
 import (
 	"bufio"
+	"compress/gzip"
 	"encoding/csv"
 	"io"
 	"log"
@@ -11,47 +12,105 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// LoadFromCSV reads the latest CSV log file and returns up to `limit`
+// LoadFromCSV reads the daily CSV log files and returns up to `limit`
 // snapshots (most recent). Used ONLY when ring buffer is empty (restart).
 //
-// CSV header:
+// Files are read newest-first until `limit` rows are collected, so a restart
+// shortly after midnight UTC still fills the buffer from the previous day.
+//
+// CSV header (v1; v2 appends open,high,low,close,buy_vol,sell_vol,
+// score_4h,score_1d,imbalance and v3 buy_count,sell_count,avg_trade_size —
+// see logger):
 //   timestamp,price,final_score,
 //   score_1s,score_1m,score_5m,score_15m,score_1h,
 //   htf_bias,market_state,action_hint,
 //   delta_1s,cvd,ob_score,oi,oi_delta,
 //   behavior,event_flags
 func LoadFromCSV(logDir string, limit int) []model.Snapshot {
-	// Find latest CSV file
-	pattern := filepath.Join(logDir, "*.csv")
-	files, err := filepath.Glob(pattern)
-	if err != nil || len(files) == 0 {
+	// Finished days may have been gzipped by the retention janitor
+	files, _ := filepath.Glob(filepath.Join(logDir, "*.csv"))
+	gzFiles, _ := filepath.Glob(filepath.Join(logDir, "*.csv.gz"))
+	files = append(files, gzFiles...)
+	if len(files) == 0 {
 		log.Printf("[Loader] No CSV files found in %s", logDir)
 		return nil
 	}
 
 	// Sort by name (YYYY-MM-DD.csv) → latest is last
 	sort.Strings(files)
-	latest := files[len(files)-1]
-	log.Printf("[Loader] Loading history from %s", latest)
 
-	f, err := os.Open(latest)
+	// Walk backwards, prepending each older day, until we have enough rows
+	var snapshots []model.Snapshot
+	for i := len(files) - 1; i >= 0 && len(snapshots) < limit; i-- {
+		daySnaps := loadCSVFile(files[i], limit-len(snapshots))
+		snapshots = append(daySnaps, snapshots...)
+	}
+
+	log.Printf("[Loader] Loaded %d snapshots from CSV", len(snapshots))
+	return snapshots
+}
+
+// LoadRangeFromCSV returns the logged snapshots with from <= Time < to
+// (unix ms), oldest first, reading only the day files the range touches.
+// Quiet (no per-file log lines): used by query endpoints.
+func LoadRangeFromCSV(logDir string, from, to int64) []model.Snapshot {
+	files, _ := filepath.Glob(filepath.Join(logDir, "*.csv"))
+	gzFiles, _ := filepath.Glob(filepath.Join(logDir, "*.csv.gz"))
+	files = append(files, gzFiles...)
+	sort.Strings(files)
+
+	first := time.UnixMilli(from).UTC().Format("2006-01-02")
+	last := time.UnixMilli(to).UTC().Format("2006-01-02")
+	var out []model.Snapshot
+	for _, path := range files {
+		name := filepath.Base(path)
+		if len(name) < 10 || name[:10] < first || name[:10] > last {
+			continue
+		}
+		rows, idx, err := readCSVFile(path)
+		if err != nil {
+			continue
+		}
+		for _, row := range rows {
+			if snap := csvRowToSnapshot(row, idx); snap.Time >= from && snap.Time < to {
+				out = append(out, snap)
+			}
+		}
+	}
+	// Rows of a day split across _v3 / rotated parts: restore time order
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time < out[j].Time })
+	return out
+}
+
+// readCSVFile reads every row of one (optionally gzipped) CSV log and its
+// column index.
+func readCSVFile(path string) ([][]string, map[string]int, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		log.Printf("[Loader] Failed to open %s: %v", latest, err)
-		return nil
+		return nil, nil, err
 	}
 	defer f.Close()
 
-	// Read all rows (tail-read: we need the last N rows)
-	reader := csv.NewReader(bufio.NewReaderSize(f, 1<<20)) // 1MB buffer
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	reader := csv.NewReader(bufio.NewReaderSize(r, 1<<20)) // 1MB buffer
 	reader.FieldsPerRecord = -1                             // flexible
 
 	// Skip header
 	header, err := reader.Read()
 	if err != nil {
-		log.Printf("[Loader] Failed to read header: %v", err)
-		return nil
+		return nil, nil, err
 	}
 
 	// Build column index map for safety
@@ -71,13 +130,26 @@ func LoadFromCSV(logDir string, limit int) []model.Snapshot {
 		}
 		rows = append(rows, row)
 	}
+	return rows, idx, nil
+}
+
+// loadCSVFile returns up to `limit` snapshots from the tail of one CSV file.
+func loadCSVFile(path string, limit int) []model.Snapshot {
+	log.Printf("[Loader] Loading history from %s", path)
+
+	// Read all rows (tail-read: we need the last N rows)
+	rows, idx, err := readCSVFile(path)
+	if err != nil {
+		log.Printf("[Loader] Failed to read %s: %v", path, err)
+		return nil
+	}
 
 	// Take only the last `limit` rows
 	if len(rows) > limit {
 		rows = rows[len(rows)-limit:]
 	}
 
-	log.Printf("[Loader] Parsed %d rows from CSV", len(rows))
+	log.Printf("[Loader] Parsed %d rows from %s", len(rows), filepath.Base(path))
 
 	snapshots := make([]model.Snapshot, 0, len(rows))
 	for _, row := range rows {
@@ -91,7 +163,8 @@ func LoadFromCSV(logDir string, limit int) []model.Snapshot {
 }
 
 // csvRowToSnapshot converts a CSV row to a model.Snapshot.
-// Since CSV doesn't have OHLC, we use Price for Open/High/Low/Close.
+// v2 rows carry the 1s candle OHLC/volumes and 4h/1d scores; v1 rows don't,
+// so Price stands in for Open/High/Low/Close.
 // This is a best-effort reconstruction for restart recovery.
 func csvRowToSnapshot(row []string, idx map[string]int) model.Snapshot {
 	get := func(col string) float64 {
@@ -140,6 +213,21 @@ func csvRowToSnapshot(row []string, idx map[string]int) model.Snapshot {
 		Delta:    delta,
 		AvgScore: get("score_1s"),
 	}
+	if _, ok := idx["open"]; ok {
+		// v2: real 1s candle
+		candle1s.Open = get("open")
+		candle1s.High = get("high")
+		candle1s.Low = get("low")
+		candle1s.Close = get("close")
+		candle1s.BuyVol = get("buy_vol")
+		candle1s.SellVol = get("sell_vol")
+	}
+	if _, ok := idx["buy_count"]; ok {
+		// v3: 1s candle trade counts
+		candle1s.BuyCount = getInt64("buy_count")
+		candle1s.SellCount = getInt64("sell_count")
+		candle1s.AvgSize = get("avg_trade_size")
+	}
 
 	candle1m := model.CandleSnapshot{
 		Time:     tsSec / 60 * 60, // align to minute boundary
@@ -150,22 +238,24 @@ func csvRowToSnapshot(row []string, idx map[string]int) model.Snapshot {
 		AvgScore: get("score_1m"),
 	}
 
-	// Reconstruct HTF scores
-	var htf [model.NumHTF]model.CandleSnapshot
-	htf[0] = model.CandleSnapshot{Time: tsSec / 300 * 300, Close: price, AvgScore: get("score_5m")}
-	htf[1] = model.CandleSnapshot{Time: tsSec / 900 * 900, Close: price, AvgScore: get("score_15m")}
-	htf[2] = model.CandleSnapshot{Time: tsSec / 3600 * 3600, Close: price, AvgScore: get("score_1h")}
-	// HTF 4h and 1d not in CSV — leave zero (acceptable for fallback)
+	// Reconstruct HTF scores. Only timeframes with a score_<label> column
+	// (5m, 15m, 1h and, from v2, 4h/1d) carry one; the rest stay at 0.
+	var htf [model.MaxHTF]model.CandleSnapshot
+	for i := 0; i < model.NumHTF; i++ {
+		tf := &model.HTFs[i]
+		htf[i] = model.CandleSnapshot{Time: tf.Bucket(tsSec), Close: price, AvgScore: get("score_" + tf.Label)}
+	}
 
 	return model.Snapshot{
 		Price:      price,
-		Time:       tsSec,
+		Time:       ts, // ms, same as live snapshots
 		CVD:        cvd,
 		Candle1s:   candle1s,
 		Candle1m:   candle1m,
-		Orderbook:  model.OrderbookSnapshot{Score: obScore},
+		Orderbook:  model.OrderbookSnapshot{Score: obScore, Imbalance: get("imbalance")},
 		OI:         model.OISnapshot{OI: oi, OIDelta1m: oiDelta, Behavior: behavior},
 		FinalScore: score,
 		HTF:        htf,
+		Flow:       model.FlowSnapshot{Events: getInt("event_flags")},
 	}
 }
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"encoding/gob"
+	"fmt"
+	"market-indikator/internal/model"
+	"os"
+	"path/filepath"
+)
+
+// =============================================================================
+// RING BUFFER PERSISTENCE
+// =============================================================================
+//
+// The CSV logs only carry scores and a handful of metrics, so a CSV-based
+// restart shows flat candles (O=H=L=C) and no 4h/1d data. Persisting the ring
+// buffer itself restores the exact pre-restart state.
+//
+// Format: gob-encoded persistFile. Written to <path>.tmp then renamed, so a
+// crash mid-write never leaves a truncated file behind.
+// =============================================================================
+
+// PersistVersion — bumped whenever model.Snapshot changes incompatibly.
+const PersistVersion = 2
+
+type persistFile struct {
+	Version   int
+	Snapshots []model.Snapshot
+}
+
+// SaveFile — writes the buffer contents to path atomically.
+func (rb *RingBuffer) SaveFile(path string) error {
+	return WriteGob(path, persistFile{Version: PersistVersion, Snapshots: rb.GetAll()})
+}
+
+// LoadFile — reads snapshots written by SaveFile, keeping the most recent
+// `limit`. A missing file returns (nil, nil).
+func LoadFile(path string, limit int) ([]model.Snapshot, error) {
+	var pf persistFile
+	ok, err := ReadGob(path, &pf)
+	if !ok || err != nil {
+		return nil, err
+	}
+	if pf.Version != PersistVersion {
+		return nil, fmt.Errorf("%s: version %d, want %d", path, pf.Version, PersistVersion)
+	}
+
+	snaps := pf.Snapshots
+	if len(snaps) > limit {
+		snaps = snaps[len(snaps)-limit:]
+	}
+	return snaps, nil
+}
+
+// WriteGob — gob-encodes v to path via a temp file + rename.
+func WriteGob(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(f).Encode(v); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadGob — decodes path into v. Returns false (and no error) if the file
+// doesn't exist.
+func ReadGob(path string, v any) (bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if err := gob.NewDecoder(f).Decode(v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
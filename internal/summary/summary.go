@@ -0,0 +1,284 @@
+package summary
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"market-indikator/internal/model"
+	"market-indikator/internal/session"
+)
+
+// =============================================================================
+// PERFORMANCE SUMMARIES — per UTC day and per session
+// =============================================================================
+//
+// "What did the indicator do yesterday?" without a script over the CSVs.
+// The tracker folds one completed second (the snapshot that closed it) into
+// two running periods, the UTC day and the current session (Asia / London /
+// NY, internal/session), and at each boundary finishes the period:
+//
+//   score       mean, σ, min, max and seconds per 20-point band of ±100
+//   states      seconds spent in each MarketState
+//   alerts      confirmed HTF bias / market-state transitions and triggered
+//               score signals (there is no separate alert engine)
+//   cvd         lifetime CVD relative to the period's first second:
+//               max, min and net at the close
+//   range       high − low of the snapshot prices, also in bps of the open
+//   coverage    seconds observed / period length (restarts, outages)
+//   tpo         sessions only: the market profile as of the session's last
+//               second — POC, value area, single prints, poor high / low
+//               (internal/tpo)
+//
+// Finished summaries are appended to a JSON-lines file (one object per
+// line) and kept in memory for GET /summary; the file's tail is read back on
+// start. The running periods are not checkpointed: after a restart they
+// cover only the time since, which shows in coverage.
+//
+// Observe / AddAlerts: engine goroutine. Snapshot: any goroutine.
+// =============================================================================
+
+const (
+	numBands   = 10 // score bands of 20 points over [−100, 100]
+	keepRecent = 64 // finished summaries kept in memory (~2 weeks)
+)
+
+// Summary — one finished (or running) period.
+type Summary struct {
+	Kind     string  `json:"kind"` // day / session
+	Name     string  `json:"name"` // 2006-01-02 or 2006-01-02 LONDON
+	Start    int64   `json:"start"`
+	End      int64   `json:"end"` // unix ms, exclusive
+	Seconds  int64   `json:"seconds"`
+	Coverage float64 `json:"coverage"` // % of the period observed
+
+	Open     float64 `json:"open"`
+	High     float64 `json:"high"`
+	Low      float64 `json:"low"`
+	Close    float64 `json:"close"`
+	Range    float64 `json:"range"`
+	RangeBps float64 `json:"range_bps"`
+
+	ScoreMean  float64         `json:"score_mean"`
+	ScoreStd   float64         `json:"score_std"`
+	ScoreMin   float64         `json:"score_min"`
+	ScoreMax   float64         `json:"score_max"`
+	ScoreBands [numBands]int64 `json:"score_bands"` // seconds, [−100,−80) … [80,100]
+
+	States map[string]int64 `json:"states"` // MarketState → seconds
+
+	Transitions int `json:"transitions"`
+	Signals     int `json:"signals"`
+
+	CVDMax float64 `json:"cvd_max"`
+	CVDMin float64 `json:"cvd_min"`
+	CVDNet float64 `json:"cvd_net"`
+
+	TPO *TPO `json:"tpo,omitempty"` // sessions only
+}
+
+// TPO — a session's market profile (see internal/tpo).
+type TPO struct {
+	POC          float64 `json:"poc"`
+	VAH          float64 `json:"vah"`
+	VAL          float64 `json:"val"`
+	Brackets     int     `json:"brackets"`
+	SinglePrints int     `json:"single_prints"`
+	PoorHigh     bool    `json:"poor_high"`
+	PoorLow      bool    `json:"poor_low"`
+}
+
+// period — a Summary being accumulated.
+type period struct {
+	Summary
+	cvdBase    float64
+	sum, sumSq float64
+}
+
+func newPeriod(kind, name string, start, end int64, snap *model.Snapshot) *period {
+	return &period{
+		Summary: Summary{
+			Kind: kind, Name: name, Start: start * 1000, End: end * 1000,
+			Open: snap.Price, High: snap.Price, Low: snap.Price,
+			ScoreMin: snap.FinalScore, ScoreMax: snap.FinalScore,
+			States: make(map[string]int64),
+		},
+		cvdBase: snap.CVD,
+	}
+}
+
+func (p *period) add(snap *model.Snapshot, state string) {
+	score, price := snap.FinalScore, snap.Price
+	p.Seconds++
+	p.sum += score
+	p.sumSq += score * score
+	p.ScoreMin = math.Min(p.ScoreMin, score)
+	p.ScoreMax = math.Max(p.ScoreMax, score)
+	band := int((score + 100) / (200 / numBands))
+	p.ScoreBands[max(0, min(numBands-1, band))]++
+	p.States[state]++
+
+	p.High = math.Max(p.High, price)
+	p.Low = math.Min(p.Low, price)
+	p.Close = price
+
+	cvd := snap.CVD - p.cvdBase
+	p.CVDMax = math.Max(p.CVDMax, cvd)
+	p.CVDMin = math.Min(p.CVDMin, cvd)
+	p.CVDNet = cvd
+
+	if t := &snap.TPO; p.Kind == "session" && t.Brackets > 0 {
+		p.TPO = &TPO{POC: t.POC, VAH: t.VAH, VAL: t.VAL, Brackets: t.Brackets,
+			SinglePrints: t.Singles, PoorHigh: t.PoorHigh == 1, PoorLow: t.PoorLow == 1}
+	}
+}
+
+// result — the Summary so far, with the derived fields filled in.
+func (p *period) result() Summary {
+	s := p.Summary
+	s.States = make(map[string]int64, len(p.States))
+	for k, v := range p.States {
+		s.States[k] = v
+	}
+	if n := float64(s.Seconds); n > 0 {
+		s.ScoreMean = p.sum / n
+		s.ScoreStd = math.Sqrt(math.Max(0, p.sumSq/n-s.ScoreMean*s.ScoreMean))
+	}
+	s.Coverage = 100 * float64(s.Seconds) / float64((s.End-s.Start)/1000)
+	s.Range = s.High - s.Low
+	if s.Open > 0 {
+		s.RangeBps = s.Range / s.Open * 1e4
+	}
+	return s
+}
+
+// Tracker — see above.
+type Tracker struct {
+	path string
+
+	mu      sync.Mutex
+	day     *period
+	session *period
+	recent  []Summary // finished, oldest first
+}
+
+// NewTracker — path is the JSON-lines file finished summaries are appended
+// to; its last keepRecent entries are loaded.
+func NewTracker(path string) *Tracker {
+	t := &Tracker{path: path}
+	f, err := os.Open(path)
+	if err != nil {
+		return t
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var s Summary
+		if err := json.Unmarshal(sc.Bytes(), &s); err != nil {
+			continue
+		}
+		t.recent = append(t.recent, s)
+		if len(t.recent) > keepRecent {
+			t.recent = t.recent[1:]
+		}
+	}
+	if len(t.recent) > 0 {
+		log.Printf("Summaries: loaded %d from %s", len(t.recent), path)
+	}
+	return t
+}
+
+// Observe folds the snapshot that closed a second, with its market state.
+func (t *Tracker) Observe(snap *model.Snapshot, state string) {
+	sec := snap.Time / 1000
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.day != nil && sec >= t.day.End/1000 {
+		t.finish(t.day)
+		t.day = nil
+	}
+	if t.session != nil && sec >= t.session.End/1000 {
+		t.finish(t.session)
+		t.session = nil
+	}
+	if t.day == nil {
+		start := sec / 86400 * 86400
+		t.day = newPeriod("day", time.Unix(start, 0).UTC().Format("2006-01-02"), start, start+86400, snap)
+	}
+	if t.session == nil {
+		start := session.StartOf(sec)
+		name := fmt.Sprintf("%s %s", time.Unix(start, 0).UTC().Format("2006-01-02"), session.Names[session.At(sec)])
+		t.session = newPeriod("session", name, start, session.EndOf(sec), snap)
+	}
+	t.day.add(snap, state)
+	t.session.add(snap, state)
+}
+
+// AddAlerts counts confirmed transitions and triggered signals into the
+// running periods.
+func (t *Tracker) AddAlerts(transitions, signals int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range [2]*period{t.day, t.session} {
+		if p != nil {
+			p.Transitions += transitions
+			p.Signals += signals
+		}
+	}
+}
+
+// Snapshot returns the running periods (day first, if any) and the finished
+// summaries, oldest first.
+func (t *Tracker) Snapshot() (running, finished []Summary) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range [2]*period{t.day, t.session} {
+		if p != nil {
+			running = append(running, p.result())
+		}
+	}
+	return running, append([]Summary(nil), t.recent...)
+}
+
+// finish keeps and persists a finished period. Caller holds t.mu.
+func (t *Tracker) finish(p *period) {
+	s := p.result()
+	t.recent = append(t.recent, s)
+	if len(t.recent) > keepRecent {
+		t.recent = t.recent[1:]
+	}
+	log.Printf("Summary %s: score %.1f±%.1f, range %.0fbps, CVD net %.1f, %d transitions, %d signals (%.0f%% covered)",
+		s.Name, s.ScoreMean, s.ScoreStd, s.RangeBps, s.CVDNet, s.Transitions, s.Signals, s.Coverage)
+
+	if err := t.append(&s); err != nil {
+		log.Printf("Summary write failed: %v", err)
+	}
+}
+
+// append writes one summary line — a few a day, so open / write / close.
+func (t *Tracker) append(s *Summary) error {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,198 @@
+package tpo
+
+import (
+	"math"
+
+	"market-indikator/internal/session"
+)
+
+// =============================================================================
+// MARKET PROFILE (TPO) — time at price per session
+// =============================================================================
+//
+// The volume profile says where size traded; the market profile says where
+// price spent its time. Each session (Asia / London / NY, internal/session)
+// is cut into 30-minute brackets; every price bin (width Tick) the bracket's
+// range covered gets one TPO — the range is taken as traded through, as
+// with the letters on a printed profile. From the counts:
+//
+//   POC          the bin with the most TPOs (ties: nearest the range mid)
+//   VAH / VAL    value area — grown from the POC towards the heavier
+//                neighbour until it holds 70% of the TPOs
+//   single prints  bins with one TPO inside the profile, i.e. not part of
+//                the one-TPO tails at the high and low — price passed
+//                through once and never came back
+//   poor high    the top bin has two or more TPOs: the high was revisited
+//                without a tail (no excess), an auction left unfinished;
+//                poor low likewise
+//
+// Heartbeats count as well: a bracket with no trades still spent its time
+// at the last price. The statistics are recomputed whenever a bracket
+// covers a new bin; at the session roll the finished profile is kept in
+// Prev and a new one starts.
+//
+// TRADING INTERPRETATION: poor highs / lows tend to be revisited — the
+// auction did not find the other side there. Single prints are where the
+// market moved with conviction; they act as support / resistance on the
+// way back and get "filled" when the move fails. Open inside the prior
+// session's value area → rotation; outside → initiative.
+// =============================================================================
+
+const (
+	// BracketSeconds — one TPO period.
+	BracketSeconds = 1800
+
+	valueAreaShare = 0.70
+
+	// MaxBins bounds a session's range in bins (a mis-set Tick would
+	// otherwise grow the profile without limit).
+	MaxBins = 20000
+)
+
+// Stats — a profile reduced to its levels (0 prices = empty profile).
+type Stats struct {
+	Start    int64 // session start, unix seconds
+	POC      float64
+	VAH      float64
+	VAL      float64
+	Brackets int // brackets so far
+	Singles  int // single-print bins
+	PoorHigh bool
+	PoorLow  bool
+}
+
+// Profile — the current session's TPO profile. Owned by the engine
+// goroutine; all fields exported for checkpointing.
+type Profile struct {
+	Tick   float64
+	Start  int64   // session start, unix seconds (0 = none yet)
+	Base   int64   // bin index of Count[0]
+	Count  []int32 // TPOs per bin
+	Total  int64
+	Bucket int64 // bracket index being filled (−1 = none)
+	Lo, Hi int64 // bins the bracket covers so far
+
+	Live Stats // developing
+	Prev Stats // last finished session
+}
+
+// New — a profile with bins of tick price units.
+func New(tick float64) *Profile {
+	return &Profile{Tick: tick, Bucket: -1}
+}
+
+// Update — folds a trade or heartbeat price at sec (unix seconds). O(1)
+// unless the bracket reaches a new bin.
+func (p *Profile) Update(sec int64, price float64) {
+	if price <= 0 {
+		return
+	}
+	if start := session.StartOf(sec); start != p.Start {
+		if p.Total > 0 {
+			p.Prev = p.Live
+		}
+		p.Start, p.Count, p.Total, p.Bucket = start, p.Count[:0], 0, -1
+		p.Live = Stats{Start: start}
+	}
+	bin := int64(math.Floor(price / p.Tick))
+	changed := false
+	if b := (sec - p.Start) / BracketSeconds; b != p.Bucket {
+		p.Bucket, p.Lo, p.Hi = b, bin, bin
+		p.Live.Brackets++
+		changed = p.mark(bin)
+	}
+	for ; bin < p.Lo; p.Lo-- {
+		changed = p.mark(p.Lo-1) || changed
+	}
+	for ; bin > p.Hi; p.Hi++ {
+		changed = p.mark(p.Hi+1) || changed
+	}
+	if changed {
+		p.compute()
+	}
+}
+
+// mark adds one TPO at bin; false if it is beyond MaxBins.
+func (p *Profile) mark(bin int64) bool {
+	if len(p.Count) == 0 {
+		p.Base = bin
+	}
+	switch {
+	case bin < p.Base:
+		grow := p.Base - bin
+		if int64(len(p.Count))+grow > MaxBins {
+			return false
+		}
+		count := make([]int32, int64(len(p.Count))+grow)
+		copy(count[grow:], p.Count)
+		p.Count = count
+		p.Base = bin
+	case bin >= p.Base+int64(len(p.Count)):
+		n := bin - p.Base + 1
+		if n > MaxBins {
+			return false
+		}
+		for int64(len(p.Count)) < n {
+			p.Count = append(p.Count, 0)
+		}
+	}
+	p.Count[bin-p.Base]++
+	p.Total++
+	return true
+}
+
+func (p *Profile) price(i int) float64 {
+	return (float64(p.Base+int64(i)) + 0.5) * p.Tick
+}
+
+// compute — rebuilds Live from the counts. O(bins).
+func (p *Profile) compute() {
+	c, n := p.Count, len(p.Count)
+	if p.Total == 0 || n == 0 {
+		return
+	}
+	// A price gap at a bracket open leaves zero bins; they count as nothing
+	poc, mid := 0, float64(n-1)/2
+	for i := range c {
+		if c[i] > c[poc] || c[i] == c[poc] && math.Abs(float64(i)-mid) < math.Abs(float64(poc)-mid) {
+			poc = i
+		}
+	}
+	lo, hi := poc, poc
+	acc := int64(c[poc])
+	for float64(acc) < valueAreaShare*float64(p.Total) && (lo > 0 || hi < n-1) {
+		below, above := int32(-1), int32(-1)
+		if lo > 0 {
+			below = c[lo-1]
+		}
+		if hi < n-1 {
+			above = c[hi+1]
+		}
+		if above >= below {
+			hi++
+			acc += int64(above)
+		} else {
+			lo--
+			acc += int64(below)
+		}
+	}
+
+	// Tails: the one-TPO runs at either extreme
+	top, bottom := n-1, 0
+	for top >= 0 && c[top] == 1 {
+		top--
+	}
+	for top >= 0 && c[bottom] == 1 {
+		bottom++
+	}
+	singles := 0 // all tail (one bracket) leaves top < bottom
+	for i := bottom; i <= top; i++ {
+		if c[i] == 1 {
+			singles++
+		}
+	}
+
+	p.Live.POC, p.Live.VAH, p.Live.VAL = p.price(poc), p.price(hi), p.price(lo)
+	p.Live.Singles = singles
+	p.Live.PoorHigh, p.Live.PoorLow = c[n-1] >= 2, c[0] >= 2
+}
@@ -0,0 +1,131 @@
+package vwap
+
+import (
+	"math"
+)
+
+// =============================================================================
+// VWAP + DEVIATION BANDS — Mathematical Foundation
+// =============================================================================
+//
+// Volume-weighted average price over a set of trades:
+//
+//   VWAP = Σ(pᵢ·qᵢ) / Σqᵢ
+//
+// Volume-weighted standard deviation of price around it:
+//
+//   σ² = Σ(pᵢ²·qᵢ) / Σqᵢ − VWAP²
+//
+// Both only need three running sums (Σpq, Σp²q, Σq), so an anchor costs
+// three additions per trade. Bands are VWAP ± k·σ (k = 1, 2).
+//
+// TRADING INTERPRETATION:
+//   Price above VWAP → buyers have paid up on average (bullish control).
+//   ±1σ — normal rotation; ±2σ — stretched, mean reversion likely unless
+//   flow (CVD, pressure score) keeps expanding in the same direction.
+//
+// Numerical note: σ² is a difference of two large numbers. Prices are
+// centered on the anchor's first price before squaring, which keeps the
+// cancellation error negligible at BTC-sized prices.
+// =============================================================================
+
+// Accumulator — running sums for one VWAP anchor.
+type Accumulator struct {
+	Ref float64 // first price, the centering offset
+	PV  float64 // Σ (p-Ref)·q
+	PPV float64 // Σ (p-Ref)²·q
+	V   float64 // Σ q
+}
+
+// Add — folds in one trade. O(1).
+func (a *Accumulator) Add(price, qty float64) {
+	if a.V == 0 && a.PV == 0 {
+		a.Ref = price
+	}
+	d := price - a.Ref
+	a.PV += d * qty
+	a.PPV += d * d * qty
+	a.V += qty
+}
+
+// Merge — adds another accumulator's sums (used by the rolling window).
+func (a *Accumulator) Merge(o *Accumulator) {
+	if o.V == 0 {
+		return
+	}
+	if a.V == 0 {
+		*a = *o
+		return
+	}
+	// Re-center o onto a.Ref: Σ(p-r)q = Σ(p-o)q + (o-r)Σq, likewise squared.
+	s := o.Ref - a.Ref
+	a.PV += o.PV + s*o.V
+	a.PPV += o.PPV + 2*s*o.PV + s*s*o.V
+	a.V += o.V
+}
+
+// VWAP — 0 until the first trade.
+func (a *Accumulator) VWAP() float64 {
+	if a.V == 0 {
+		return 0
+	}
+	return a.Ref + a.PV/a.V
+}
+
+// Sigma — volume-weighted standard deviation of price around the VWAP.
+func (a *Accumulator) Sigma() float64 {
+	if a.V == 0 {
+		return 0
+	}
+	mean := a.PV / a.V
+	return math.Sqrt(math.Max(0, a.PPV/a.V-mean*mean))
+}
+
+// Rolling — VWAP over a trailing window, kept as a ring of per-bucket
+// accumulators. The window slides one bucket at a time.
+type Rolling struct {
+	BucketSec int64
+	Buckets   []Accumulator
+	Times     []int64 // bucket start (unix seconds) of each slot
+}
+
+// NewRolling — window of n buckets of bucketSec each (e.g. 60 × 60s = 1h).
+func NewRolling(bucketSec int64, n int) *Rolling {
+	return &Rolling{
+		BucketSec: bucketSec,
+		Buckets:   make([]Accumulator, n),
+		Times:     make([]int64, n),
+	}
+}
+
+// Add — folds a trade at sec (unix seconds) into its bucket. O(1).
+func (r *Rolling) Add(sec int64, price, qty float64) {
+	start := sec / r.BucketSec * r.BucketSec
+	i := int(start / r.BucketSec % int64(len(r.Buckets)))
+	if r.Times[i] != start {
+		r.Times[i] = start
+		r.Buckets[i] = Accumulator{}
+	}
+	r.Buckets[i].Add(price, qty)
+}
+
+// Window — merged accumulator of the buckets still inside the window ending
+// at sec. O(n) over the (small) bucket ring.
+func (r *Rolling) Window(sec int64) Accumulator {
+	var out Accumulator
+	oldest := sec/r.BucketSec*r.BucketSec - int64(len(r.Buckets)-1)*r.BucketSec
+	for i := range r.Buckets {
+		if r.Times[i] >= oldest {
+			out.Merge(&r.Buckets[i])
+		}
+	}
+	return out
+}
+
+// Anchor — a VWAP accumulated from a user-chosen start time (swing low, news
+// event, …) onwards.
+type Anchor struct {
+	ID   int64
+	From int64 // unix ms
+	Acc  Accumulator
+}
@@ -0,0 +1,292 @@
+package zmq
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"market-indikator/internal/model"
+)
+
+// =============================================================================
+// ZMQ PUB — MsgPack snapshots for ZeroMQ SUB consumers
+// =============================================================================
+//
+// A ZeroMQ PUB socket bound on a tcp:// endpoint. Every snapshot goes out as
+// a two-frame message:
+//
+//   frame 1  topic  = the symbol (e.g. "BTCUSDT")
+//   frame 2  body   = the snapshot's MsgPack, byte-identical to a full /ws
+//                     live frame (see model.Snapshot.AppendMsgPack)
+//
+// so a consumer does
+//   sub.connect("tcp://host:5556"); sub.setsockopt(SUBSCRIBE, b"BTCUSDT")
+// and decodes the second frame with the same code as the WebSocket client.
+//
+// Topics are filtered here, as libzmq's PUB does. Like a PUB socket with its
+// send high-water mark reached, a subscriber whose queue (peerQueue
+// messages) is full misses the message — the engine is never slowed.
+// =============================================================================
+
+const (
+	peerQueue        = 1024
+	handshakeTimeout = 5 * time.Second
+)
+
+// Publisher — see above.
+type Publisher struct {
+	ln net.Listener
+
+	mu    sync.Mutex
+	peers map[*peer]bool
+
+	sent  int64 // atomic — messages queued to a peer
+	drops int64 // atomic — messages dropped for a full peer
+}
+
+type peer struct {
+	conn net.Conn
+	send chan []byte
+	done chan struct{}
+	once sync.Once
+
+	mu   sync.Mutex
+	subs map[string]int // topic prefix → subscription count
+}
+
+// Listen binds a PUB socket on endpoint ("tcp://*:5556",
+// "tcp://127.0.0.1:5556").
+func Listen(endpoint string) (*Publisher, error) {
+	addr, ok := strings.CutPrefix(endpoint, "tcp://")
+	if !ok {
+		return nil, fmt.Errorf("zmq endpoint %q: only tcp:// is supported", endpoint)
+	}
+	if strings.HasPrefix(addr, "*:") {
+		addr = addr[1:]
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p := &Publisher{ln: ln, peers: make(map[*peer]bool)}
+	go p.accept()
+	log.Printf("ZMQ PUB listening on %s", endpoint)
+	return p, nil
+}
+
+// Run publishes each snapshot under topic until snaps closes, then closes
+// the socket.
+func (p *Publisher) Run(snaps <-chan model.Snapshot, topic string) {
+	buf := make([]byte, 0, 512)
+	for snap := range snaps {
+		buf = snap.AppendMsgPack(buf[:0])
+		p.Publish(topic, buf)
+	}
+	p.Close()
+}
+
+// Publish sends a [topic, body] message to every peer subscribed to a
+// prefix of topic. body is copied.
+func (p *Publisher) Publish(topic string, body []byte) {
+	msg := appendFrame(nil, flagMore, []byte(topic))
+	msg = appendFrame(msg, 0, body)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for pr := range p.peers {
+		if !pr.subscribed(topic) {
+			continue
+		}
+		select {
+		case pr.send <- msg:
+			atomic.AddInt64(&p.sent, 1)
+		default:
+			atomic.AddInt64(&p.drops, 1)
+		}
+	}
+}
+
+// Close stops accepting and disconnects every peer.
+func (p *Publisher) Close() {
+	p.ln.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for pr := range p.peers {
+		pr.close()
+		delete(p.peers, pr)
+	}
+}
+
+// Peers returns the number of connected subscribers.
+func (p *Publisher) Peers() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return int64(len(p.peers))
+}
+
+// Sent returns the messages queued to subscribers since start.
+func (p *Publisher) Sent() int64 {
+	return atomic.LoadInt64(&p.sent)
+}
+
+// Drops returns the messages dropped for full subscriber queues.
+func (p *Publisher) Drops() int64 {
+	return atomic.LoadInt64(&p.drops)
+}
+
+func (p *Publisher) accept() {
+	for {
+		conn, err := p.ln.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			return // listener closed
+		}
+		go p.serve(conn)
+	}
+}
+
+// serve — handshake, then the peer's reader and writer.
+func (p *Publisher) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	pr := &peer{conn: conn, send: make(chan []byte, peerQueue), done: make(chan struct{}), subs: make(map[string]int)}
+	if err := pr.handshake(r); err != nil {
+		log.Printf("ZMQ peer %s: handshake failed: %v", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+
+	p.mu.Lock()
+	p.peers[pr] = true
+	p.mu.Unlock()
+	log.Printf("ZMQ subscriber connected: %s", conn.RemoteAddr())
+
+	go func() {
+		err := pr.read(r)
+		select {
+		case <-pr.done: // closed on our side
+		default:
+			log.Printf("ZMQ subscriber %s disconnected: %v", conn.RemoteAddr(), err)
+		}
+		p.mu.Lock()
+		delete(p.peers, pr)
+		p.mu.Unlock()
+		pr.close()
+	}()
+	pr.write()
+}
+
+func (pr *peer) handshake(r *bufio.Reader) error {
+	pr.conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer pr.conn.SetDeadline(time.Time{})
+
+	out := greeting()
+	out = append(out, readyCommand(map[string]string{"Socket-Type": "PUB"})...)
+	if _, err := pr.conn.Write(out); err != nil {
+		return err
+	}
+	if err := readGreeting(r); err != nil {
+		return err
+	}
+	flags, body, err := readFrame(r)
+	if err != nil {
+		return err
+	}
+	name, data, err := parseCommand(body)
+	if err != nil || flags&flagCommand == 0 || name != "READY" {
+		return fmt.Errorf("%w: expected READY", errProtocol)
+	}
+	props, err := parseProperties(data)
+	if err != nil {
+		return err
+	}
+	if st := props["Socket-Type"]; st != "SUB" && st != "XSUB" {
+		return fmt.Errorf("zmtp: peer socket type %q, need SUB or XSUB", st)
+	}
+	return nil
+}
+
+// read handles subscriptions and heartbeats until the connection ends.
+func (pr *peer) read(r *bufio.Reader) error {
+	for {
+		flags, body, err := readFrame(r)
+		if err != nil {
+			return err
+		}
+		if flags&flagCommand == 0 {
+			// ZMTP 3.0 subscription message: 1 = subscribe, 0 = cancel
+			if len(body) > 0 && body[0] <= 1 {
+				pr.subscribe(string(body[1:]), body[0] == 1)
+			}
+			continue
+		}
+		name, data, err := parseCommand(body)
+		if err != nil {
+			return err
+		}
+		switch name {
+		case "SUBSCRIBE", "CANCEL":
+			pr.subscribe(string(data), name == "SUBSCRIBE")
+		case "PING":
+			if len(data) >= 2 {
+				select {
+				case pr.send <- appendCommand(nil, "PONG", data[2:]):
+				default:
+				}
+			}
+		}
+	}
+}
+
+// write drains the send queue until the peer closes.
+func (pr *peer) write() {
+	defer pr.conn.Close()
+	for {
+		select {
+		case msg := <-pr.send:
+			pr.conn.SetWriteDeadline(time.Now().Add(handshakeTimeout))
+			if _, err := pr.conn.Write(msg); err != nil {
+				pr.close()
+				return
+			}
+		case <-pr.done:
+			return
+		}
+	}
+}
+
+func (pr *peer) close() {
+	pr.once.Do(func() {
+		close(pr.done)
+		pr.conn.Close()
+	})
+}
+
+func (pr *peer) subscribe(prefix string, on bool) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if on {
+		pr.subs[prefix]++
+	} else if pr.subs[prefix] > 1 {
+		pr.subs[prefix]--
+	} else {
+		delete(pr.subs, prefix)
+	}
+}
+
+func (pr *peer) subscribed(topic string) bool {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	for prefix := range pr.subs {
+		if strings.HasPrefix(topic, prefix) {
+			return true
+		}
+	}
+	return false
+}
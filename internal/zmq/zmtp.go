@@ -0,0 +1,169 @@
+package zmq
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// =============================================================================
+// ZMTP 3.x — the ZeroMQ wire protocol, the parts a PUB socket needs
+// =============================================================================
+//
+// No libzmq (cgo) and no third-party module: a PUB socket only has to greet,
+// exchange READY, write message frames and read subscriptions (RFC 23/ZMTP,
+// RFC 37/ZMTP 3.1):
+//
+//   greeting  FF 00×8 7F | 03 00 | "NULL" + 00×16 | as-server | 00×31  (64 B)
+//   frame     flags | size (1 B, or 8 B BE with LONG) | body
+//             flags: 0x01 MORE, 0x02 LONG, 0x04 COMMAND
+//   command   name-len (1 B) | name | data
+//   READY     properties: name-len (1 B) | name | value-len (4 B BE) | value
+//
+// Subscriptions arrive as messages (ZMTP 3.0: first byte 1 = subscribe,
+// 0 = cancel, the rest is the topic prefix) or as SUBSCRIBE / CANCEL
+// commands (3.1). PING is answered with PONG. Only the NULL mechanism is
+// spoken: no CURVE, so keep the endpoint on a trusted network.
+// =============================================================================
+
+const (
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+
+	greetingLen = 64
+	maxFrame    = 1 << 20 // longest frame accepted from a peer
+)
+
+var errProtocol = errors.New("zmtp: protocol error")
+
+// greeting — ZMTP 3.0, NULL mechanism, as-server 0.
+func greeting() []byte {
+	g := make([]byte, greetingLen)
+	g[0], g[9] = 0xFF, 0x7F
+	g[10], g[11] = 3, 0
+	copy(g[12:32], "NULL")
+	return g
+}
+
+// readGreeting checks the peer's greeting: ZMTP 3+ with NULL.
+func readGreeting(r io.Reader) error {
+	var g [greetingLen]byte
+	if _, err := io.ReadFull(r, g[:]); err != nil {
+		return err
+	}
+	if g[0] != 0xFF || g[9] != 0x7F {
+		return fmt.Errorf("%w: bad signature", errProtocol)
+	}
+	if g[10] < 3 {
+		return fmt.Errorf("zmtp: peer speaks ZMTP %d.%d, need 3.x", g[10], g[11])
+	}
+	if mech := string(trimZero(g[12:32])); mech != "NULL" {
+		return fmt.Errorf("zmtp: mechanism %q, only NULL is supported", mech)
+	}
+	return nil
+}
+
+func trimZero(b []byte) []byte {
+	for i, c := range b {
+		if c == 0 {
+			return b[:i]
+		}
+	}
+	return b
+}
+
+// appendFrame appends one frame with the given flags (LONG is added as
+// needed).
+func appendFrame(dst []byte, flags byte, body []byte) []byte {
+	if len(body) > 255 {
+		dst = append(dst, flags|flagLong)
+		dst = binary.BigEndian.AppendUint64(dst, uint64(len(body)))
+	} else {
+		dst = append(dst, flags, byte(len(body)))
+	}
+	return append(dst, body...)
+}
+
+// appendCommand appends a command frame: name, then data.
+func appendCommand(dst []byte, name string, data []byte) []byte {
+	body := make([]byte, 0, 1+len(name)+len(data))
+	body = append(body, byte(len(name)))
+	body = append(body, name...)
+	body = append(body, data...)
+	return appendFrame(dst, flagCommand, body)
+}
+
+// readyCommand — READY with the given properties.
+func readyCommand(props map[string]string) []byte {
+	var data []byte
+	for name, value := range props {
+		data = append(data, byte(len(name)))
+		data = append(data, name...)
+		data = binary.BigEndian.AppendUint32(data, uint32(len(value)))
+		data = append(data, value...)
+	}
+	return appendCommand(nil, "READY", data)
+}
+
+// parseCommand splits a command body into name and data.
+func parseCommand(body []byte) (string, []byte, error) {
+	if len(body) < 1 || int(body[0]) > len(body)-1 {
+		return "", nil, fmt.Errorf("%w: short command", errProtocol)
+	}
+	n := int(body[0])
+	return string(body[1 : 1+n]), body[1+n:], nil
+}
+
+// parseProperties reads READY metadata.
+func parseProperties(data []byte) (map[string]string, error) {
+	props := make(map[string]string)
+	for len(data) > 0 {
+		n := int(data[0])
+		if len(data) < 1+n+4 {
+			return nil, fmt.Errorf("%w: short property", errProtocol)
+		}
+		name := string(data[1 : 1+n])
+		data = data[1+n:]
+		vn := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint64(vn) > uint64(len(data)) {
+			return nil, fmt.Errorf("%w: short property value", errProtocol)
+		}
+		props[name] = string(data[:vn])
+		data = data[vn:]
+	}
+	return props, nil
+}
+
+// readFrame reads one frame, returning its flags and body.
+func readFrame(r *bufio.Reader) (byte, []byte, error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var size uint64
+	if flags&flagLong != 0 {
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	} else {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(b)
+	}
+	if size > maxFrame {
+		return 0, nil, fmt.Errorf("%w: %d-byte frame", errProtocol, size)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return flags, body, nil
+}
@@ -481,7 +481,7 @@ export default function App() {
           <div className="HTF-grid">
             <div className="HTF-row head"><span>TF</span><span>PRICE</span><span>DELTA</span><span>VOL</span><span>SCORE</span></div>
             {h.htfCandles && h.htfCandles.map((c, i) => {
-              const tf = ['5m', '15m', '1H', '4H', '1D'][i];
+              const tf = c?.label?.replace(/[hdw]$/, (u) => u.toUpperCase()) || ['5m', '15m', '1H', '4H', '1D'][i];
               if (!c) return null;
               const chg = c.close - c.open;
               return (
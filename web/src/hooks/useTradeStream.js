@@ -3,28 +3,89 @@ import { decode } from '@msgpack/msgpack';
 
 const getWsUrl = () => {
   const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
+  // Behind the shard gateway (orderflow gateway) ?symbol= picks the engine;
+  // ?encoding=compact asks for float32 / scaled-price frames (see descriptor);
+  // ?res=1m&span=6h for history at 1m resolution over the last 6h
+  const page = new URLSearchParams(window.location.search);
+  const params = new URLSearchParams();
+  for (const key of ['symbol', 'encoding', 'res', 'span']) {
+    if (page.get(key)) params.set(key, page.get(key));
+  }
+  params.set('delta', '1'); // applyDelta handles diff frames when the server sends them
+  const query = `?${params}`;
   // If running on Vite dev server (port 5173), hardcode to backend port 8080
   if (window.location.port === '5173') {
-    return `ws://${window.location.hostname}:8080/ws`;
+    return `ws://${window.location.hostname}:8080/ws${query}`;
   }
   // Otherwise (production/ngrok), use same host/port relative path
-  return `${protocol}//${window.location.host}/ws`;
+  return `${protocol}//${window.location.host}/ws${query}`;
 };
 
 const WS_URL = getWsUrl();
+
+// Flattened snapshot layout (must match model.Flatten): sizes of each top-level
+// field, 0 = scalar. An array entry is a nested array, one element per size (0
+// again a scalar), so an empty one takes no scalars. The htf entry has one
+// candle per configured timeframe, anchors one entry per anchored VWAP and
+// levels one per profile level (a count change always arrives as a full
+// snapshot). Formulas and custom analyzer fields follow the descriptor's names.
+const flatLayout = (numHTF, numAnchors, numRibbon, numLevels, numFormulas, numCustom) =>
+  [0, 0, 0, 15, 15, 8, 7, 0, Array(numHTF).fill(15), 4, 11, [5, 5, 5], Array(numAnchors).fill(7), 8,
+    Array(3).fill(4 + numRibbon), [4, 4], Array(numLevels).fill(3), 3, numHTF, Array(numFormulas).fill(0), Array(numCustom).fill(0), 8, 6, 7, 2, 10, 2, 3, 3, 0, 1 + numHTF, 6, 7, 6];
+
+// Volume-profile level kinds (model.LevelSnapshot.Kind).
+const LEVEL_KINDS = ['POC', 'VAH', 'VAL', 'PREV_POC', 'PREV_VAH', 'PREV_VAL', 'HVN', 'LVN'];
+
+// Positioning context labels (model.ContextSnapshot.Label).
+const CONTEXT_LABELS = ['NONE', 'CROWDED', 'SQUEEZE_SETUP', 'DELEVERAGING'];
+
+const flattenSnapshot = (raw) => raw.flat(Infinity);
+
+const unflattenSnapshot = (flat, numHTF, numAnchors, numRibbon, numLevels, numFormulas, numCustom) => {
+  let i = 0;
+  const take = (n) => { const out = flat.slice(i, i + n); i += n; return out; };
+  return flatLayout(numHTF, numAnchors, numRibbon, numLevels, numFormulas, numCustom).map((f) => {
+    if (f === 0) return flat[i++];
+    if (Array.isArray(f)) return f.map((g) => (g === 0 ? flat[i++] : take(g)));
+    return take(f);
+  });
+};
+
+// Scalars a layout takes; must equal the flattened keyframe's length (FlatLen).
+const layoutLen = (layout) => layout.reduce((n, f) => n + (Array.isArray(f) ? layoutLen(f) : Math.max(f, 1)), 0);
+
+const applyDelta = (flat, delta) => {
+  const mask = delta.m;
+  let v = 0;
+  for (let i = 0; i < flat.length; i++) {
+    if (mask[i >> 3] & (1 << (i & 7))) flat[i] = delta.v[v++];
+  }
+};
 const RECONNECT_DELAY_MS = 2000;
+const WIRE_VERSION = 1; // snapshot layout this client decodes (model.WireVersion)
 
 /**
  * useTradeStream — WebSocket data layer (v9: streaming history protocol)
  *
  * PROTOCOL:
- *   Message 1 (on connect): MsgPack uint32 = history snapshot count
+ *   Message 0 (on connect): descriptor {tf: [[label, seconds], ...], ribbon: [periods], formulas: [names],
+ *     custom: [names], symbol, leader, build, wire, encoding, price_scale} naming the htf candles,
+ *     indicator EMA ribbon, user-defined scores and analyzer fields in order, the traded / lead-lag
+ *     leader symbols, the server's commit and snapshot wire version (a mismatch with WIRE_VERSION is
+ *     warned about), and the encoding: with "compact" prices arrive as integers × price_scale.
+ *     Detection: object with a 'tf' key
+ *
+ *   Message 1: MsgPack uint32 = history snapshot count
  *     Detection: typeof decoded === 'number'
  *
- *   Message 2..N+1: Individual history snapshots (same format as live ticks)
- *     Format: FixArray(9) [price, cvd, time, candle1s, candle1m, ob, oi, score, htf]
+ *   Message 2..: History batches — array of snapshots (same format as live ticks)
+ *     Format: Array16 [price, cvd, time, candle1s, candle1m, ob, oi, score, htf, ...] — the full field
+ *     list is the wire comment on model.Snapshot (internal/model/snapshot.go); parseSnapshot follows it
+ *     Detection: Array.isArray(decoded[0]) (a snapshot starts with a number)
  *
- *   Message N+2+: Live tick snapshots (identical format)
+ *   Message N+2+: Live tick snapshots (identical format), or — when the server
+ *     runs with delta encoding — delta frames {m: bitmask, v: changed values}
+ *     against the flattened previous snapshot (see internal/model/delta.go).
  *
  * @param {Function} onSnapshot - Called for EVERY snapshot (history + live)
  * @param {Function} onLoadingChange - Called with (active, current, total)
@@ -39,45 +100,235 @@ export function useTradeStream(onSnapshot, onLoadingChange) {
   const reconnectRef = useRef(null);
   const historyTotal = useRef(0);
   const historyCount = useRef(0);
+  const lastFlat = useRef(null);
+  const timeframes = useRef([]);
+  const ribbon = useRef([]);
+  const formulaNames = useRef([]);
+  const customNames = useRef([]);
+  const symbols = useRef({ symbol: '', leader: '' });
+  const lastHTF = useRef(0);
+  const lastAnchors = useRef(0);
+  const lastLevels = useRef(0);
+  const priceScale = useRef(1);
+
+  // Prices of a compact-encoded snapshot are integers × price_scale.
+  const px = (v) => (priceScale.current === 1 ? v : v / priceScale.current);
 
   const parseCandle = (c) => ({
     time: c[0],
-    open: c[1],
-    high: c[2],
-    low: c[3],
-    close: c[4],
+    open: px(c[1]),
+    high: px(c[2]),
+    low: px(c[3]),
+    close: px(c[4]),
     buyVol: c[5],
     sellVol: c[6],
     delta: c[7],
     avgScore: c[8],
+    buyCount: c[9],
+    sellCount: c[10],
+    avgSize: c[11],
+    deltaHigh: c[12],
+    deltaLow: c[13],
+    deltaPct: c[14],
   });
 
   const parseSnapshot = (raw) => {
     const ob = raw[5];
     const oiRaw = raw[6];
     const htfRaw = raw[8];
+    const q = raw[9];
+    const ss = raw[10];
+    const vw = raw[11];
+    const an = raw[12] || [];
+    const fl = raw[13];
+    const ind = raw[14] || [];
+    const sq = raw[15] || [];
+    const lv = raw[16] || [];
+    const sd = raw[17];
+    const tfs = raw[18] || [];
+    const fm = raw[19] || [];
+    const cu = raw[20] || [];
+    const cx = raw[21];
+    const ld = raw[22];
+    const ps = raw[23];
+    const lt = raw[24];
+    const l1 = raw[25];
+    const cvdAnchor = raw[26];
+    const oiVenues = raw[27];
+    const st = raw[28];
+    const parseInd = (v) => ({
+      rsi: v[0],
+      macd: v[1],
+      signal: v[2],
+      hist: v[3],
+      ribbon: v.slice(4).map((ema, i) => ({ period: ribbon.current[i], ema: px(ema) })),
+    });
+    const parseSqueeze = (v) => ({ on: v[0] === 1, bars: v[1], ratio: v[2], dir: v[3] });
+    const parseBand = (v) => ({ vwap: px(v[0]), upper1: px(v[1]), lower1: px(v[2]), upper2: px(v[3]), lower2: px(v[4]) });
 
     return {
-      price: raw[0],
+      price: px(raw[0]),
       cvd: raw[1],
       time: raw[2],
       candle1s: parseCandle(raw[3]),
       candle1m: parseCandle(raw[4]),
       orderbook: {
-        bestBid: ob[0],
-        bestAsk: ob[1],
-        spread: ob[2],
+        bestBid: px(ob[0]),
+        bestAsk: px(ob[1]),
+        spread: px(ob[2]),
         imbalance: ob[3],
         score: ob[4],
+        bidCoG: px(ob[5]),
+        askCoG: px(ob[6]),
+        cogSkew: ob[7],
       },
       oi: {
         value: oiRaw[0],
         delta1s: oiRaw[1],
         delta1m: oiRaw[2],
         behavior: oiRaw[3],
+        notional: oiRaw[4],
+        notionalDelta1s: oiRaw[5],
+        notionalDelta1m: oiRaw[6],
+        // Per-venue OI with -oi-venues (0 = venue not polled)
+        venues: oiVenues ? { binance: oiVenues[0], bybit: oiVenues[1], okx: oiVenues[2] } : null,
       },
       finalScore: raw[7],
-      htf: htfRaw.map(parseCandle),
+      htf: htfRaw.map((c, i) => ({ ...parseCandle(c), label: timeframes.current[i]?.[0], tfScore: tfs[i] })),
+      quality: q ? {
+        depthAgeMs: q[0],
+        oiAgeMs: q[1],
+        tradeGapMs: q[2],
+        flags: q[3],
+        thinBook: (q[3] & 32) !== 0, // model.QualityThinBook: depth below its rolling percentile
+        eventRisk: (q[3] & 64) !== 0, // model.QualityEventRisk: inside a calendar event's window
+      } : null,
+      session: ss ? {
+        id: ss[0],
+        name: ['ASIA', 'LONDON', 'NY'][ss[0]],
+        start: ss[1],
+        open: px(ss[2]),
+        high: px(ss[3]),
+        low: px(ss[4]),
+        vwap: px(ss[5]),
+        asiaOpen: px(ss[6]),
+        londonOpen: px(ss[7]),
+        nyOpen: px(ss[8]),
+        prevDayHigh: px(ss[9]),
+        prevDayLow: px(ss[10]),
+      } : null,
+      vwap: vw ? {
+        session: parseBand(vw[0]),
+        day: parseBand(vw[1]),
+        rolling: parseBand(vw[2]),
+      } : null,
+      anchors: an.map((a) => ({ id: a[0], from: a[1], ...parseBand(a.slice(2)) })),
+      flow: fl ? {
+        tradesPerSec: fl[0],
+        avgTradeSize: fl[1],
+        intensityZ: fl[2],
+        effortResult1s: fl[3],
+        effortResult1m: fl[4],
+        events: fl[5],
+        vpin: fl[6],
+        lambda: fl[7],
+      } : null,
+      indicators: { m1: ind[0] && parseInd(ind[0]), m5: ind[1] && parseInd(ind[1]), h1: ind[2] && parseInd(ind[2]) },
+      squeeze: { m5: sq[0] && parseSqueeze(sq[0]), m15: sq[1] && parseSqueeze(sq[1]) },
+      scoreDyn: sd ? { velocity: sd[0], accel: sd[1], percentile: sd[2] } : null,
+      levels: lv.map((l) => ({ price: px(l[0]), kind: LEVEL_KINDS[l[1]], strength: l[2] })),
+      formulas: Object.fromEntries(fm.map((v, i) => [formulaNames.current[i], v])),
+      custom: Object.fromEntries(cu.map((v, i) => [customNames.current[i], v])),
+      // Funding / basis / liquidations with -derivs (zero without)
+      context: cx ? {
+        label: CONTEXT_LABELS[cx[0]],
+        side: cx[1],
+        oiZ: cx[2],
+        oiRange: cx[3],
+        funding: cx[4],
+        basisBps: cx[5],
+        liq1m: cx[6],
+        liqZ: cx[7],
+      } : null,
+      leader: ld && symbols.current.leader ? {
+        symbol: symbols.current.leader,
+        lag: ld[0],
+        corr: ld[1],
+        cvdCorr: ld[2],
+        expected: ld[3],
+        ret10s: ld[4],
+        cvd10s: ld[5],
+      } : null,
+      // Own position (-user-stream); all zero when the overlay is off
+      position: ps && (ps[0] !== 0 || ps[4] !== 0) ? {
+        size: ps[0],
+        entryPrice: px(ps[1]),
+        unrealizedPnl: ps[2],
+        realizedPnl: ps[3],
+        lastFill: ps[4] ? { time: ps[4], price: px(ps[5]), qty: ps[6] } : null,
+      } : null,
+      // Pipeline latency (-latency-field); all zero when off
+      latency: lt && (lt[0] !== 0 || lt[1] !== 0) ? {
+        eventUs: lt[0],
+        recvUs: lt[1],
+      } : null,
+      // bookTicker metrics (-book-ticker); all zero when off
+      l1: l1 && l1[2] !== 0 ? {
+        bidQty: l1[0],
+        askQty: l1[1],
+        microprice: px(l1[2]),
+        spreadRatio: l1[3],
+        ofi1s: l1[4],
+        ofi10s: l1[5],
+        bidRefill: l1[6],
+        askRefill: l1[7],
+        bidRefillRatio: l1[8],
+        askRefillRatio: l1[9],
+      } : null,
+      // CVD since the -cvd-reset anchor (since 0 = lifetime, same as cvd)
+      cvdAnchored: cvdAnchor ? { value: cvdAnchor[0], since: cvdAnchor[1] } : { value: raw[1], since: 0 },
+      // Seconds the confirmed HTF bias / market state / OI behavior have held
+      stateAge: st ? { biasSec: st[0], stateSec: st[1], oiBehaviorSec: st[2] } : null,
+      // Confidence in finalScore, 0..1 (freshness, domain agreement, warmup, intensity)
+      confidence: raw[29] ?? null,
+      // Estimated time (ms) each component is warm after a cold start, 0 = warm;
+      // the decision layer opens no position while the score or 1h/4h/1d warm up
+      warmup: raw[30] ? {
+        scoreReadyMs: raw[30][0],
+        htfReadyMs: raw[30].slice(1),
+        warming: raw[30].some((t) => t !== 0),
+      } : null,
+      // Rolling 1m range (-structure-window) and latest swing points; distances
+      // in bp of price. Breakouts fire flow.events bits 14 (up) / 15 (down).
+      structure: raw[31] ? {
+        high: px(raw[31][0]),
+        low: px(raw[31][1]),
+        swingHigh: px(raw[31][2]),
+        swingLow: px(raw[31][3]),
+        toHighBps: raw[31][4],
+        toLowBps: raw[31][5],
+      } : null,
+      // Session market profile (TPO, 30-minute brackets), developing
+      tpo: raw[32] && raw[32][3] > 0 ? {
+        poc: px(raw[32][0]),
+        vah: px(raw[32][1]),
+        val: px(raw[32][2]),
+        brackets: raw[32][3],
+        singlePrints: raw[32][4],
+        poorHigh: raw[32][5] === 1,
+        poorLow: raw[32][6] === 1,
+      } : null,
+      // Session opening range (-open-range); forming until endMs. Breakouts fire
+      // flow.events bits 16 (up) / 17 (down), failures bit 18.
+      openRange: raw[33] && raw[33][2] > 0 ? {
+        high: px(raw[33][0]),
+        low: px(raw[33][1]),
+        endMs: raw[33][2],
+        dir: raw[33][3],
+        withDelta: (raw[33][4] & 1) !== 0,
+        withOI: (raw[33][4] & 2) !== 0,
+        failed: raw[33][5],
+      } : null,
     };
   };
 
@@ -109,15 +360,52 @@ export function useTradeStream(onSnapshot, onLoadingChange) {
           return;
         }
 
-        // ═══ SNAPSHOT (history or live) ═══
-        const snapshot = parseSnapshot(raw);
-        onSnapshotRef.current(snapshot);
+        // ═══ TIMEFRAME DESCRIPTOR ═══
+        if (!Array.isArray(raw) && raw.tf) {
+          timeframes.current = raw.tf;
+          ribbon.current = raw.ribbon || [];
+          formulaNames.current = raw.formulas || [];
+          customNames.current = raw.custom || [];
+          symbols.current = { symbol: raw.symbol || '', leader: raw.leader || '' };
+          priceScale.current = raw.price_scale || 1;
+          if (raw.wire !== undefined && raw.wire !== WIRE_VERSION) {
+            console.warn(`[WS] Server ${raw.build} sends wire v${raw.wire}, this client decodes v${WIRE_VERSION}`);
+          } else {
+            console.log(`[WS] Server build ${raw.build || 'unknown'}`);
+          }
+          return;
+        }
+
+        // ═══ DELTA FRAME (live, delta mode) ═══
+        if (!Array.isArray(raw)) {
+          if (!lastFlat.current) return; // no base yet — server sends a keyframe next
+          applyDelta(lastFlat.current, raw);
+          onSnapshotRef.current(parseSnapshot(unflattenSnapshot(lastFlat.current, lastHTF.current, lastAnchors.current, ribbon.current.length,
+            lastLevels.current, formulaNames.current.length, customNames.current.length)));
+          return;
+        }
+
+        // ═══ SNAPSHOT (history batch, or single history/live snapshot) ═══
+        const batch = Array.isArray(raw[0]) ? raw : [raw];
+        for (const item of batch) {
+          onSnapshotRef.current(parseSnapshot(item));
+        }
+        lastFlat.current = flattenSnapshot(batch[batch.length - 1]);
+        lastHTF.current = batch[batch.length - 1][8].length;
+        lastAnchors.current = batch[batch.length - 1][12]?.length || 0;
+        lastLevels.current = batch[batch.length - 1][16]?.length || 0;
+        const want = layoutLen(flatLayout(lastHTF.current, lastAnchors.current, ribbon.current.length,
+          lastLevels.current, formulaNames.current.length, customNames.current.length));
+        if (want !== lastFlat.current.length) {
+          console.warn(`[WS] Snapshot flattens to ${lastFlat.current.length} scalars, delta layout expects ${want}`);
+        }
 
         // Track history progress
         if (historyCount.current < historyTotal.current) {
-          historyCount.current++;
+          const before = historyCount.current;
+          historyCount.current += batch.length;
           // Update loading progress every 100 snapshots (avoid excessive re-renders)
-          if (historyCount.current % 100 === 0 || historyCount.current >= historyTotal.current) {
+          if (Math.floor(historyCount.current / 100) !== Math.floor(before / 100) || historyCount.current >= historyTotal.current) {
             if (onLoadingRef.current) {
               onLoadingRef.current(
                 historyCount.current < historyTotal.current,
@@ -140,6 +428,7 @@ export function useTradeStream(onSnapshot, onLoadingChange) {
       wsRef.current = null;
       historyTotal.current = 0;
       historyCount.current = 0;
+      lastFlat.current = null;
       reconnectRef.current = setTimeout(connect, RECONNECT_DELAY_MS);
     };
 